@@ -0,0 +1,47 @@
+// Command dexnames loads a dump of species, move, ability and item names
+// used to cross-link them in content.
+//
+//	go run ./cmd/dexnames -file data/dex_names.csv
+//
+// Names are upserted by kind, dex_id and language, so the import can be
+// re-run after the dump is updated; all content is scanned again afterwards.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"pokemon/internal/config"
+	"pokemon/internal/database"
+	"pokemon/internal/domains/dexlink"
+)
+
+func main() {
+	file := flag.String("file", "data/dex_names.csv", "path to the name CSV dump")
+	flag.Parse()
+
+	cfg := config.Load()
+
+	db, err := database.NewPostgres(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	if err := (dexlink.DexLinkMigrator{}).Migrate(db); err != nil {
+		log.Fatal("migration failed:", err)
+	}
+
+	redis := database.NewRedis(cfg.RedisURL)
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatal("Failed to open dump:", err)
+	}
+	defer f.Close()
+
+	result, err := dexlink.ImportNames(context.Background(), db, redis, f)
+	if err != nil {
+		log.Fatal("import failed: ", err)
+	}
+	log.Printf("imported %d names for %d entities", result.Names, result.Entities)
+}
@@ -0,0 +1,47 @@
+// Command encounters loads an encounter location CSV dump into the database.
+//
+//	go run ./cmd/encounters -file data/encounters.csv
+//
+// Every game present in the file has its locations and encounters replaced,
+// so the import can be re-run safely after the dump is updated.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"pokemon/internal/config"
+	"pokemon/internal/database"
+	"pokemon/internal/domains/game"
+)
+
+func main() {
+	file := flag.String("file", "data/encounters.csv", "path to the encounter CSV dump")
+	flag.Parse()
+
+	cfg := config.Load()
+
+	db, err := database.NewPostgres(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	if err := (game.GameMigrator{}).Migrate(db); err != nil {
+		log.Fatal("migration failed:", err)
+	}
+
+	redis := database.NewRedis(cfg.RedisURL)
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatal("Failed to open dump:", err)
+	}
+	defer f.Close()
+
+	result, err := game.ImportEncounters(context.Background(), db, redis, f)
+	if err != nil {
+		log.Fatal("import failed: ", err)
+	}
+	log.Printf("imported %d encounters across %d locations in %d games",
+		result.Encounters, result.Locations, result.Games)
+}
@@ -0,0 +1,135 @@
+// Command library imports guides and walkthroughs from Markdown files with
+// front matter, or exports the whole library into the same layout.
+//
+//	go run ./cmd/library -import content/ -user admin -dry-run
+//	go run ./cmd/library -import library.zip -user admin
+//	go run ./cmd/library -export content/
+//
+// Content is upserted by slug; see the library domain for the layout. A dry
+// run only prints the report.
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"flag"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"pokemon/internal/config"
+	"pokemon/internal/database"
+	"pokemon/internal/domains/guide"
+	"pokemon/internal/domains/library"
+	"pokemon/internal/domains/user"
+	"pokemon/internal/domains/walkthrough"
+	"strings"
+)
+
+func main() {
+	importFrom := flag.String("import", "", "directory or .zip to import")
+	exportTo := flag.String("export", "", "directory or .zip to export to")
+	dryRun := flag.Bool("dry-run", false, "report what the import would do without writing")
+	username := flag.String("user", "", "username credited with the imported revisions")
+	flag.Parse()
+
+	if (*importFrom == "") == (*exportTo == "") {
+		log.Fatal("either -import or -export is required")
+	}
+
+	cfg := config.Load()
+
+	db, err := database.NewPostgres(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	if err := (guide.GuideMigrator{}).Migrate(db); err != nil {
+		log.Fatal("migration failed:", err)
+	}
+	if err := (walkthrough.WalkthroughMigrator{}).Migrate(db); err != nil {
+		log.Fatal("migration failed:", err)
+	}
+
+	redis := database.NewRedis(cfg.RedisURL)
+	ctx := context.Background()
+
+	if *exportTo != "" {
+		files, err := library.Export(ctx, db, redis)
+		if err != nil {
+			log.Fatal("export failed: ", err)
+		}
+		if err := write(*exportTo, files); err != nil {
+			log.Fatal("export failed: ", err)
+		}
+		log.Printf("exported %d files to %s", len(files), *exportTo)
+		return
+	}
+
+	if *username == "" {
+		log.Fatal("-user is required to import")
+	}
+	var editor user.User
+	if err := db.Where("username = ?", *username).First(&editor).Error; err != nil {
+		log.Fatalf("unknown user %q: %v", *username, err)
+	}
+
+	var fsys fs.FS
+	if strings.EqualFold(filepath.Ext(*importFrom), ".zip") {
+		archive, err := zip.OpenReader(*importFrom)
+		if err != nil {
+			log.Fatal("Failed to open archive:", err)
+		}
+		defer archive.Close()
+		fsys = archive
+	} else {
+		fsys = os.DirFS(*importFrom)
+	}
+
+	report, err := library.Import(ctx, db, redis, fsys, library.Options{DryRun: *dryRun, Editor: editor.ID})
+	if err != nil {
+		log.Fatal("import failed: ", err)
+	}
+	for _, item := range report.Items {
+		line := item.Action + " " + item.Kind + " " + item.Slug + " (" + item.Path + ")"
+		if len(item.Changes) > 0 {
+			line += ": " + strings.Join(item.Changes, ", ")
+		}
+		if item.Error != "" {
+			line += ": " + item.Error
+		}
+		log.Print(line)
+		for _, w := range item.Warnings {
+			log.Print("  warning: ", w)
+		}
+	}
+	for _, p := range report.Skipped {
+		log.Print("skipped ", p)
+	}
+	log.Printf("%d created, %d updated, %d unchanged, %d failed (dry run: %t, applied: %t)",
+		report.Created, report.Updated, report.Unchanged, report.Failed, report.DryRun, report.Applied)
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// write puts the files in a directory, or in a zip when the path ends in .zip.
+func write(to string, files []library.File) error {
+	if strings.EqualFold(filepath.Ext(to), ".zip") {
+		f, err := os.Create(to)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return library.WriteZip(f, files)
+	}
+	for _, file := range files {
+		p := filepath.Join(to, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, file.Data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
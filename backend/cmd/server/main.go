@@ -5,16 +5,30 @@ import (
 	"pokemon/internal/config"
 	"pokemon/internal/database"
 	"pokemon/internal/migrations"
+	"pokemon/pkg/publishing"
 	"pokemon/pkg/utils"
 
 	"pokemon/internal/domains/blog"
+	"pokemon/internal/domains/collection"
+	"pokemon/internal/domains/dexlink"
+	"pokemon/internal/domains/ebook"
 	favMon "pokemon/internal/domains/favorite-pokemon"
+	"pokemon/internal/domains/feed"
 	"pokemon/internal/domains/forum"
 	"pokemon/internal/domains/game"
 	"pokemon/internal/domains/guide"
+	"pokemon/internal/domains/hunt"
+	"pokemon/internal/domains/library"
 	"pokemon/internal/domains/news"
+	"pokemon/internal/domains/notification"
+	"pokemon/internal/domains/nuzlocke"
+	"pokemon/internal/domains/search"
+	"pokemon/internal/domains/seo"
+	"pokemon/internal/domains/series"
 	"pokemon/internal/domains/shout"
+	"pokemon/internal/domains/taglink"
 	"pokemon/internal/domains/team"
+	"pokemon/internal/domains/translation"
 	"pokemon/internal/domains/user"
 	"pokemon/internal/domains/walkthrough"
 	"pokemon/internal/middleware"
@@ -58,14 +72,39 @@ func main() {
     // Initialize domains
     user.NewHandler(db, redis).RegisterRoutes(api)
     blog.NewHandler(db, redis).RegisterRoutes(api)
+    collection.NewHandler(db, redis).RegisterRoutes(api)
+    dexlink.NewHandler(db, redis).RegisterRoutes(api)
+    ebook.NewHandler(db, redis, cfg.SiteURL, cfg.UploadPath).RegisterRoutes(api)
     favMon.NewHandler(db, redis).RegisterRoutes(api)
+    feed.NewHandler(db, redis, cfg.SiteURL).RegisterRoutes(api)
     forum.NewHandler(db, redis).RegisterRoutes(api)
     game.NewHandler(db, redis).RegisterRoutes(api)
     guide.NewHandler(db, redis).RegisterRoutes(api)
+    hunt.NewHandler(db, redis).RegisterRoutes(api)
+    library.NewHandler(db, redis).RegisterRoutes(api)
     news.NewHandler(db, redis).RegisterRoutes(api)
+    notification.NewHandler(db, redis).RegisterRoutes(api)
+    nuzlocke.NewHandler(db, redis).RegisterRoutes(api)
+    search.NewHandler(db, redis).RegisterRoutes(api)
+    seoHandler := seo.NewHandler(db, redis, cfg.SiteURL)
+    seoHandler.RegisterRoutes(api)
+    seoHandler.RegisterSitemapRoutes(app)
+    series.NewHandler(db, redis).RegisterRoutes(api)
     shout.NewHandler(db, redis).RegisterRoutes(api)
+    taglink.NewHandler(db, redis).RegisterRoutes(api)
     team.NewHandler(db, redis).RegisterRoutes(api)
+    translation.NewHandler(db, redis).RegisterRoutes(api)
     walkthrough.NewHandler(db, redis).RegisterRoutes(api)
+
+    // Background jobs
+    game.StartLeaderboardWorker(db, redis, cfg.LeaderboardRecomputeInterval)
+    dexlink.StartIndexWorker(db, redis, cfg.DexLinkIndexInterval)
+    notification.Listen(db, redis)
+    publishing.StartScheduler(redis, cfg.PublishSchedulerInterval,
+        blog.PublishScheduled(db, redis),
+        news.PublishScheduled(db, redis),
+        guide.PublishScheduled(db, redis),
+    )
     
     log.Fatal(app.Listen(":" + cfg.Port))
 }
@@ -55,8 +55,9 @@ import (
 
 type Config struct {
     // Server
-    Port string
-    Env  string
+    Port    string
+    Env     string
+    SiteURL string // public address of the site, used in feeds and sitemaps
 
     // Database
     DatabaseURL         string
@@ -104,6 +105,11 @@ type Config struct {
     // External APIs
     StripeSecretKey    string
     StripeWebhookSecret string
+
+    // Background Jobs
+    LeaderboardRecomputeInterval time.Duration
+    PublishSchedulerInterval     time.Duration
+    DexLinkIndexInterval         time.Duration
 }
 
 func Load() *Config {
@@ -116,8 +122,9 @@ func Load() *Config {
 
     return &Config{
         // Server
-        Port: getEnv("PORT", "3000"),
-        Env:  getEnv("ENV", "development"),
+        Port:    getEnv("PORT", "3000"),
+        Env:     getEnv("ENV", "development"),
+        SiteURL: strings.TrimSuffix(getEnv("SITE_URL", ""), "/"),
 
         // Database
         DatabaseURL:         getEnvRequired("DATABASE_URL"),
@@ -165,6 +172,11 @@ func Load() *Config {
         // External APIs
         StripeSecretKey:     getEnv("PAYMENT_STRIPE_SECRET_KEY", ""),
         StripeWebhookSecret: getEnv("PAYMENT_STRIPE_WEBHOOK_SECRET", ""),
+
+        // Background Jobs
+        LeaderboardRecomputeInterval: getEnvAsDuration("LEADERBOARD_RECOMPUTE_INTERVAL", "1h"),
+        PublishSchedulerInterval:     getEnvAsDuration("PUBLISH_SCHEDULER_INTERVAL", "1m"),
+        DexLinkIndexInterval:         getEnvAsDuration("DEXLINK_INDEX_INTERVAL", "5m"),
     }
 }
 
@@ -6,7 +6,7 @@ import (
 )
 
 func NewPostgres(dsn string) (*gorm.DB, error) {
-    db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+    db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
     if err != nil {
         return nil, err
     }
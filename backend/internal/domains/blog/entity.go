@@ -2,7 +2,11 @@ package blog
 
 import (
 	"errors"
+	"pokemon/internal/domains/series"
+	"pokemon/internal/domains/translation"
 	"pokemon/internal/domains/user"
+	"pokemon/pkg/content"
+	"pokemon/pkg/publishing"
 	"strings"
 	"time"
 
@@ -14,20 +18,39 @@ import (
  * MAIN *
  *********/
 
+const maxTagsPerPost = 10
+
 type Post struct {
 	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
 	Title     string         `gorm:"not null" json:"title"`
 	Content   string         `gorm:"type:text;not null" json:"content"`
+	Rendered  *content.Rendered `gorm:"-" json:"rendered,omitempty"`                                // sanitized HTML of Content
 
 	UserID    uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`                         // FK field
 	User      user.User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"user"` // preloadable
 	Pinned    bool           `gorm:"default:false" json:"pinned"`
+	Series    []series.Navigation `gorm:"-" json:"series,omitempty"`                              // previous/next in the series holding the post
+	Language    string               `gorm:"type:varchar(10);not null;default:'en'" json:"language"` // of the text, translations are separate
+	Translation *translation.Variant `gorm:"-" json:"translation,omitempty"`                         // language served and the others available
+
+	Tags      []Tag          `gorm:"many2many:post_tags" json:"tags"`
+	TagNames  []string       `gorm:"-" json:"tag_names,omitempty"`                               // input only, replaces Tags when set
+
+	publishing.State // draft, scheduled or published
 
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 }
 
+// Tag is identified by its slug, so "Shiny Hunting" and "shiny  hunting" are the same tag.
+type Tag struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	Slug      string    `gorm:"uniqueIndex;not null" json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 /****************
  * API RESPONSE *
  ****************/
@@ -44,6 +67,18 @@ type Response struct {
 	Pinned       bool      `json:"pinned"`     // pinned to top?
 }
 
+/************
+ * REQUESTS *
+ ************/
+
+type renameTagRequest struct {
+	Name string `json:"name"`
+}
+
+type mergeTagRequest struct {
+	IntoID uuid.UUID `json:"into_id"`
+}
+
 /***************
  * VALIDATIONS *
  ***************/
@@ -69,6 +104,10 @@ func (p *Post) Validate() error {
 		return errors.New("author_id is required and must be valid")
 	}
 
+	if len(p.TagNames) > maxTagsPerPost {
+		return errors.New("a post cannot have more than 10 tags")
+	}
+
 	return nil
 }
 
@@ -1,6 +1,12 @@
 package blog
 
 import (
+	"pokemon/internal/domains/dexlink"
+	"pokemon/internal/domains/notification"
+	"pokemon/internal/domains/series"
+	"pokemon/internal/domains/translation"
+	"pokemon/pkg/utils"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
@@ -12,13 +18,23 @@ import (
  *****************************/
 
 type handler struct {
-	s postService
+	s          postService
+	tags       tagService
+	navigator  *series.Navigator
+	translator *translation.Translator
 }
 
 func NewHandler(db *gorm.DB, redis *redis.Client) *handler {
 	repo := newRepository(db)
-	service := newService(repo, redis)
-	return &handler{s: service}
+	tags := newTagRepo(db)
+	renderer := dexlink.NewRenderer(db, redis)
+	service := newService(repo, tags, renderer, redis)
+	return &handler{
+		s:          service,
+		tags:       newTagService(tags, redis),
+		navigator:  series.NewNavigator(db),
+		translator: translation.NewTranslator(db, renderer),
+	}
 }
 
 /******************************
@@ -45,6 +61,16 @@ func (h *handler) getPost(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "post not found"})
 	}
+	viewerID, _ := utils.GetUserIDFromLocals(c)
+	if !post.VisibleTo(viewerID, post.UserID) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "post not found"})
+	}
+	post.Series = h.navigator.Around(c.Context(), series.ContentPost, post.ID, viewerID)
+
+	post.Translation = h.translator.Negotiate(c, translation.ContentPost, post.ID, post.Language)
+	if v := post.Translation; v.Translated() {
+		post.Title, post.Content, post.Rendered = v.Title, v.Body, v.Rendered
+	}
 	return c.JSON(post)
 }
 
@@ -65,7 +91,9 @@ func (h *handler) listPostsByUser(c *fiber.Ctx) error {
 	}
 	limit := c.QueryInt("limit", 10)
 	offset := c.QueryInt("offset", 0)
-	posts, err := h.s.listPostsByUser(userID, limit, offset)
+	// Authors also see their own drafts and scheduled posts
+	viewerID, _ := utils.GetUserIDFromLocals(c)
+	posts, err := h.s.listPostsByUser(userID, viewerID != userID, limit, offset)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -142,6 +170,9 @@ func (h *handler) softDeletePost(c *fiber.Ctx) error {
 	if err := h.s.softDeletePost(id); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	// Authors hiding their own post aren't notified
+	moderatorID, _ := utils.GetUserIDFromLocals(c)
+	notification.Send(notification.Event{Type: notification.TypeModeration, ActorID: moderatorID, SubjectType: notification.SubjectPost, SubjectID: id, Note: notification.NoteRemoved})
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
@@ -153,6 +184,8 @@ func (h *handler) restorePost(c *fiber.Ctx) error {
 	if err := h.s.restorePost(id); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	moderatorID, _ := utils.GetUserIDFromLocals(c)
+	notification.Send(notification.Event{Type: notification.TypeModeration, ActorID: moderatorID, SubjectType: notification.SubjectPost, SubjectID: id, Note: notification.NoteRestored})
 	return c.SendStatus(fiber.StatusOK)
 }
 
@@ -195,6 +228,7 @@ func (h *handler) likePost(c *fiber.Ctx) error {
 	if err := h.s.likePost(userID, postID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	notification.Send(notification.Event{Type: notification.TypeLike, ActorID: userID, SubjectType: notification.SubjectPost, SubjectID: postID})
 	return c.SendStatus(fiber.StatusCreated)
 }
 
@@ -7,6 +7,7 @@ type BlogMigrator struct{}
 func (m BlogMigrator) Migrate(db *gorm.DB) error {
 	return db.AutoMigrate(
 		&Post{},
+		&Tag{},
 		&PostLike{},
 		&PostComment{},
 		&PostView{},
@@ -1,6 +1,8 @@
 package blog
 
 import (
+	"pokemon/pkg/publishing"
+	"pokemon/pkg/tagging"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,11 +21,11 @@ import (
 
 type blogRepository interface {
 	list(limit int, offset int) ([]Post, error)
-	listByUser(userID uuid.UUID, limit int, offset int) ([]Post, error)
+	listByUser(userID uuid.UUID, publicOnly bool, limit int, offset int) ([]Post, error)
 
 	create(post *Post) error
 	getByID(id uuid.UUID) (*Post, error)
-	update(post *Post) error
+	update(post *Post, tags []Tag) error
 	delete(id uuid.UUID) error
 
 	// Extended Services
@@ -62,13 +64,18 @@ func (r *repository) create(post *Post) error {
 
 func (r *repository) getByID(id uuid.UUID) (*Post, error) {
 	var post Post
-	err := r.db.Preload("Userr").First(&post, "id = ?", id).Error
+	err := r.db.Preload("User").Preload("Tags").First(&post, "id = ?", id).Error
 	return &post, err
 }
 
-func (r *repository) listByUser(userID uuid.UUID, limit int, offset int) ([]Post, error) {
+func (r *repository) listByUser(userID uuid.UUID, publicOnly bool, limit int, offset int) ([]Post, error) {
+	tx := r.db.Preload("User").Preload("Tags")
+	if publicOnly {
+		tx = tx.Scopes(publishing.Live("posts"))
+	}
+
 	var posts []Post
-	err := r.db.Preload("Userr").
+	err := tx.
 		Where("user_id = ?", userID).
 		Limit(limit).Offset(offset).
 		Find(&posts).Error
@@ -77,15 +84,27 @@ func (r *repository) listByUser(userID uuid.UUID, limit int, offset int) ([]Post
 
 func (r *repository) list(limit int, offset int) ([]Post, error) {
 	var posts []Post
-	err := r.db.Preload("User").
+	err := r.db.Preload("User").Preload("Tags").
+		Scopes(publishing.Live("posts")).
 		Order("created_at ASC").
 		Limit(limit).Offset(offset).
 		Find(&posts).Error
 	return posts, err
 }
 
-func (r *repository) update(post *Post) error {
-	return r.db.Save(post).Error
+// update saves the post and, when tags is not nil, replaces its tags.
+// Otherwise the current tags are loaded back onto the post.
+func (r *repository) update(post *Post, tags []Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("User", "Tags").Save(post).Error; err != nil {
+			return err
+		}
+		if tags == nil {
+			return tx.Model(post).Association("Tags").Find(&post.Tags)
+		}
+		post.Tags = tags
+		return tx.Model(post).Association("Tags").Replace(tags)
+	})
 }
 
 func (r *repository) delete(id uuid.UUID) error {
@@ -105,18 +124,21 @@ func (r *repository) delete(id uuid.UUID) error {
 func (r *repository) search(query string, limit int, offset int) ([]Post, error) {
 	var posts []Post
 	err := r.db.Preload("User").
+		Scopes(publishing.Live("posts")).
 		Where("title ILIKE ? OR content ILIKE ?", "%"+query+"%", "%"+query+"%").
 		Limit(limit).Offset(offset).
 		Find(&posts).Error
 	return posts, err
 }
 
-func (r *repository) listByTag(tag string, limit int, offset int) ([]Post, error) {
+func (r *repository) listByTag(slug string, limit int, offset int) ([]Post, error) {
 	var posts []Post
-	err := r.db.
+	err := r.db.Preload("User").Preload("Tags").
 		Joins("JOIN post_tags ON post_tags.post_id = posts.id").
 		Joins("JOIN tags ON tags.id = post_tags.tag_id").
-		Where("tags.name = ?", tag).
+		Where("tags.slug = ?", slug).
+		Scopes(publishing.Live("posts")).
+		Order("posts.created_at DESC").
 		Limit(limit).Offset(offset).
 		Find(&posts).Error
 	return posts, err
@@ -125,6 +147,7 @@ func (r *repository) listByTag(tag string, limit int, offset int) ([]Post, error
 func (r *repository) listRecent(limit int) ([]Post, error) {
 	var posts []Post
 	err := r.db.Preload("User").
+		Scopes(publishing.Live("posts")).
 		Order("created_at DESC").
 		Limit(limit).
 		Find(&posts).Error
@@ -231,3 +254,82 @@ func (r *repository) listReported(limit int, offset int) ([]Post, error) {
 		Find(&posts).Error
 	return posts, err
 }
+
+/********
+ * TAGS *
+ ********/
+
+type tagRepository interface {
+	getBySlug(slug string) (*Tag, error)
+	findOrCreate(names []string) ([]Tag, error)
+	cloud(limit int) ([]tagging.Count, error)
+	related(tagID uuid.UUID, limit int) ([]tagging.Count, error)
+	rename(id uuid.UUID, name string) error
+	merge(fromID, intoID uuid.UUID) error
+}
+
+// Only live posts that aren't deleted count towards the cloud and related tags
+var postTags = tagging.Table{
+	Tags:     "tags",
+	Join:     "post_tags",
+	OwnerKey: "post_id",
+	TagKey:   "tag_id",
+	Owners:   "posts",
+	Visible:  "o.deleted_at IS NULL AND " + publishing.LiveCondition("o"),
+}
+
+type tagRepoImpl struct {
+	db *gorm.DB
+}
+
+func newTagRepo(db *gorm.DB) tagRepository {
+	return &tagRepoImpl{db: db}
+}
+
+func (r *tagRepoImpl) getBySlug(slug string) (*Tag, error) {
+	var tag Tag
+	if err := r.db.First(&tag, "slug = ?", slug).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// findOrCreate resolves tag names to tags, creating the missing ones. Names
+// with the same slug collapse into one tag.
+func (r *tagRepoImpl) findOrCreate(names []string) ([]Tag, error) {
+	tags := []Tag{}
+	seen := map[string]bool{}
+	for _, raw := range names {
+		name, slug, err := tagging.Normalize(raw)
+		if err != nil {
+			return nil, err
+		}
+		if seen[slug] {
+			continue
+		}
+		seen[slug] = true
+
+		var tag Tag
+		if err := r.db.Where(Tag{Slug: slug}).Attrs(Tag{Name: name}).FirstOrCreate(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func (r *tagRepoImpl) cloud(limit int) ([]tagging.Count, error) {
+	return postTags.Cloud(r.db, limit)
+}
+
+func (r *tagRepoImpl) related(tagID uuid.UUID, limit int) ([]tagging.Count, error) {
+	return postTags.Related(r.db, tagID, limit)
+}
+
+func (r *tagRepoImpl) rename(id uuid.UUID, name string) error {
+	return postTags.Rename(r.db, id, name)
+}
+
+func (r *tagRepoImpl) merge(fromID, intoID uuid.UUID) error {
+	return postTags.Merge(r.db, fromID, intoID)
+}
@@ -1,7 +1,9 @@
 package blog
 
 import (
+	"pokemon/internal/domains/user"
 	"pokemon/internal/middleware"
+	"pokemon/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -14,12 +16,14 @@ func (h *handler) RegisterRoutes(app fiber.Router) {
 	 **************************/
 
 	blogGroup.Get("/", h.listPosts)
-	blogGroup.Get("/user/:user_id", h.listPostsByUser)
+	blogGroup.Get("/user/:user_id", middleware.AuthOptional(), h.listPostsByUser)
 	blogGroup.Get("/search", h.searchPosts)
 	blogGroup.Get("/tag/:tag", h.listPostsByTag)
+	blogGroup.Get("/tags", h.tagCloud)
+	blogGroup.Get("/tags/:slug/related", h.relatedTags)
 	blogGroup.Get("/recent", h.listRecentPosts)
 	blogGroup.Get("/deleted", h.listDeletedPosts)
-	blogGroup.Get("/:id", h.getPost)
+	blogGroup.Get("/:id", middleware.AuthOptional(), h.getPost)
 
 	/***************************
 	 * INTERACTIONS (PUBLIC)   *
@@ -50,6 +54,14 @@ func (h *handler) RegisterRoutes(app fiber.Router) {
 	blogGroup.Patch("/:id/soft-delete", h.softDeletePost)
 	blogGroup.Patch("/:id/restore", h.restorePost)
 
+	/**************************
+	 * TAG MODERATION (ADMIN) *
+	 **************************/
+
+	tagGroup := blogGroup.Group("/tags", utils.RoleMiddleware(user.RoleAdmin))
+	tagGroup.Put("/:id", h.renameTag)
+	tagGroup.Post("/:id/merge", h.mergeTag)
+
 	/***************************
 	 * FUTURE ROUTES PLACEHOLDER
 	 ***************************/
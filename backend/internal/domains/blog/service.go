@@ -4,10 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"pokemon/pkg/content"
+	"pokemon/pkg/language"
+	"pokemon/pkg/publishing"
+	"pokemon/pkg/utils"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 )
 
 /*********************
@@ -17,7 +22,7 @@ import (
 type postService interface {
 	createPost(post *Post) error
 	getPost(id uuid.UUID) (*Post, error)
-	listPostsByUser(userID uuid.UUID, limit int, offset int) ([]Post, error)
+	listPostsByUser(userID uuid.UUID, publicOnly bool, limit int, offset int) ([]Post, error)
 	listPosts(limit int, offset int) ([]Post, error)
 	updatePost(post *Post) error
 	deletePost(id uuid.UUID) error
@@ -32,7 +37,6 @@ type postService interface {
 	countPostsByUser(userID uuid.UUID) (int, error)
 	countTotalPosts() (int, error)
 	archivePost(id uuid.UUID) error
-	restorePost(id uuid.UUID) error
 	postExists(id uuid.UUID) (bool, error)
 
 	incrementPostViewCount(id uuid.UUID) error
@@ -49,12 +53,14 @@ func redisPostKey(id uuid.UUID) string {
  **************************/
 
 type service struct {
-	repo  blogRepository
-	redis *redis.Client
+	repo     blogRepository
+	tags     tagRepository
+	renderer content.Renderer
+	redis    *redis.Client
 }
 
-func newService(repo blogRepository, redis *redis.Client) postService {
-	return &service{repo: repo, redis: redis}
+func newService(repo blogRepository, tags tagRepository, renderer content.Renderer, redis *redis.Client) postService {
+	return &service{repo: repo, tags: tags, renderer: renderer, redis: redis}
 }
 
 func (s *service) createPost(post *Post) error {
@@ -62,10 +68,28 @@ func (s *service) createPost(post *Post) error {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
+	if err := post.State.Prepare(nil, time.Now()); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	lang, err := language.Resolve(post.Language, "")
+	if err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	post.Language = lang
+
+	tags, err := s.tags.findOrCreate(post.TagNames)
+	if err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	post.Tags = tags
+	post.TagNames = nil
+
 	// Persist to DB
 	if err := s.repo.create(post); err != nil {
 		return err
 	}
+	s.render(post)
+	s.redis.Del(context.Background(), redisTagCloudKey)
 
 	// Cache in Redis
 	ctx := context.Background()
@@ -95,6 +119,7 @@ func (s *service) getPost(id uuid.UUID) (*Post, error) {
 	if err != nil {
 		return nil, err
 	}
+	s.render(post)
 
 	// Store in Redis
 	if jsonData, err := json.Marshal(post); err == nil {
@@ -104,12 +129,15 @@ func (s *service) getPost(id uuid.UUID) (*Post, error) {
 	return post, nil
 }
 
-func (s *service) listPostsByUser(userID uuid.UUID, limit int, offset int) ([]Post, error) {
-	return s.repo.listByUser(userID, limit, offset)
+// listPostsByUser includes drafts and scheduled posts unless publicOnly is set.
+func (s *service) listPostsByUser(userID uuid.UUID, publicOnly bool, limit int, offset int) ([]Post, error) {
+	posts, err := s.repo.listByUser(userID, publicOnly, limit, offset)
+	return s.renderAll(posts), err
 }
 
 func (s *service) listPosts(limit int, offset int) ([]Post, error) {
-	return s.repo.list(limit, offset)
+	posts, err := s.repo.list(limit, offset)
+	return s.renderAll(posts), err
 }
 
 func (s *service) updatePost(post *Post) error {
@@ -117,13 +145,33 @@ func (s *service) updatePost(post *Post) error {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
-	err := s.repo.update(post)
+	previous, err := s.repo.getByID(post.ID)
 	if err != nil {
 		return err
 	}
+	if err := post.State.Prepare(&previous.State, time.Now()); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	if post.Language, err = language.Resolve(post.Language, previous.Language); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Tags are only replaced when tag_names is sent
+	var tags []Tag
+	if post.TagNames != nil {
+		if tags, err = s.tags.findOrCreate(post.TagNames); err != nil {
+			return fmt.Errorf("validation failed: %w", err)
+		}
+		post.TagNames = nil
+	}
+
+	if err := s.repo.update(post, tags); err != nil {
+		return err
+	}
+	s.render(post)
 
 	// Invalidate Redis
-	s.redis.Del(context.Background(), redisPostKey(post.ID))
+	s.redis.Del(context.Background(), redisPostKey(post.ID), redisTagCloudKey)
 
 	return nil
 }
@@ -135,11 +183,31 @@ func (s *service) deletePost(id uuid.UUID) error {
 	}
 
 	// Invalidate Redis
-	s.redis.Del(context.Background(), redisPostKey(id))
+	s.redis.Del(context.Background(), redisPostKey(id), redisTagCloudKey)
 
 	return nil
 }
 
+/*************
+ * RENDERING *
+ *************/
+
+// render attaches the sanitized HTML of the Markdown content to the post.
+func (s *service) render(post *Post) {
+	post.Rendered = s.renderer.Render(context.Background(), post.Content)
+}
+
+func (s *service) renderAll(posts []Post) []Post {
+	sources := make([]string, len(posts))
+	for i := range posts {
+		sources[i] = posts[i].Content
+	}
+	for i, rendered := range s.renderer.RenderAll(context.Background(), sources) {
+		posts[i].Rendered = rendered
+	}
+	return posts
+}
+
 /************************************
  ************************************
  ************ EXTENSIONS ************
@@ -150,37 +218,35 @@ func (s *service) deletePost(id uuid.UUID) error {
  * SEARCH AND FILTERING *
  ************************/
 
+// searchPosts uses the search_vector column maintained by the search domain.
 func (s *service) searchPosts(query string, limit int, offset int) ([]Post, error) {
 	var posts []Post
 	err := s.repo.(*repository).db.
 		Preload("User").
-		Where("title ILIKE ? OR content ILIKE ?", "%"+query+"%", "%"+query+"%").
+		Scopes(publishing.Live("posts")).
+		Where("search_vector @@ websearch_to_tsquery('english', ?)", query).
+		Order(gorm.Expr("ts_rank_cd(search_vector, websearch_to_tsquery('english', ?)) DESC", query)).
 		Limit(limit).
 		Offset(offset).
 		Find(&posts).Error
-	return posts, err
+	return s.renderAll(posts), err
 }
 
+// listPostsByTag accepts a tag slug or name.
 func (s *service) listPostsByTag(tag string, limit int, offset int) ([]Post, error) {
-	var posts []Post
-	err := s.repo.(*repository).db.
-		Joins("JOIN post_tags ON post_tags.post_id = posts.id").
-		Joins("JOIN tags ON tags.id = post_tags.tag_id").
-		Where("tags.name = ?", tag).
-		Limit(limit).
-		Offset(offset).
-		Find(&posts).Error
-	return posts, err
+	posts, err := s.repo.listByTag(utils.Slugify(tag), limit, offset)
+	return s.renderAll(posts), err
 }
 
 func (s *service) listRecentPosts(limit int) ([]Post, error) {
 	var posts []Post
 	err := s.repo.(*repository).db.
+		Scopes(publishing.Live("posts")).
 		Order("created_at DESC").
 		Limit(limit).
 		Preload("User").
 		Find(&posts).Error
-	return posts, err
+	return s.renderAll(posts), err
 }
 
 /***************************
@@ -212,7 +278,7 @@ func (s *service) listDeletedPosts(limit int, offset int) ([]Post, error) {
 		Limit(limit).
 		Offset(offset).
 		Find(&posts).Error
-	return posts, err
+	return s.renderAll(posts), err
 }
 
 func (s *service) archivePost(id uuid.UUID) error {
@@ -292,5 +358,25 @@ func (s *service) listReportedPosts(limit int, offset int) ([]Post, error) {
 		Limit(limit).
 		Offset(offset).
 		Find(&posts).Error
-	return posts, err
+	return s.renderAll(posts), err
+}
+
+/************************
+ * SCHEDULED PUBLISHING *
+ ************************/
+
+// PublishScheduled returns the scheduler job publishing due blog posts.
+func PublishScheduled(db *gorm.DB, redis *redis.Client) publishing.Job {
+	return func(ctx context.Context, now time.Time) error {
+		ids, err := publishing.PublishDue(ctx, db, "posts", now)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		keys := []string{redisTagCloudKey}
+		for _, id := range ids {
+			keys = append(keys, redisPostKey(id))
+		}
+		return redis.Del(ctx, keys...).Err()
+	}
 }
@@ -0,0 +1,98 @@
+package blog
+
+import (
+	"context"
+	"encoding/json"
+	"pokemon/pkg/tagging"
+	"pokemon/pkg/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type tagService interface {
+	cloud(limit int) ([]tagging.Count, error)
+	related(slug string, limit int) ([]tagging.Count, error)
+	rename(id uuid.UUID, name string) error
+	merge(fromID, intoID uuid.UUID) error
+}
+
+/********************
+ * REDIS KEY UTILS  *
+ ********************/
+
+// The cloud is cached once at its largest size and cut down per request.
+const (
+	redisTagCloudKey = "blog:tags:cloud"
+	maxCloudSize     = 200
+)
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type tagServiceImpl struct {
+	repo  tagRepository
+	redis *redis.Client
+}
+
+func newTagService(repo tagRepository, redis *redis.Client) tagService {
+	return &tagServiceImpl{repo: repo, redis: redis}
+}
+
+func (s *tagServiceImpl) cloud(limit int) ([]tagging.Count, error) {
+	ctx := context.Background()
+
+	var counts []tagging.Count
+	if val, err := s.redis.Get(ctx, redisTagCloudKey).Result(); err == nil {
+		if err := json.Unmarshal([]byte(val), &counts); err != nil {
+			counts = nil
+		}
+	}
+
+	if counts == nil {
+		var err error
+		if counts, err = s.repo.cloud(maxCloudSize); err != nil {
+			return nil, err
+		}
+		if jsonData, err := json.Marshal(counts); err == nil {
+			s.redis.Set(ctx, redisTagCloudKey, jsonData, 10*time.Minute)
+		}
+	}
+
+	if limit > 0 && limit < len(counts) {
+		counts = counts[:limit]
+	}
+	return counts, nil
+}
+
+func (s *tagServiceImpl) related(slug string, limit int) ([]tagging.Count, error) {
+	tag, err := s.repo.getBySlug(utils.Slugify(slug))
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.related(tag.ID, limit)
+}
+
+// Renames and merges change what every cached post shows, but posts only
+// live in the cache for an hour, so only the cloud is dropped right away.
+func (s *tagServiceImpl) rename(id uuid.UUID, name string) error {
+	if err := s.repo.rename(id, name); err != nil {
+		return err
+	}
+	s.redis.Del(context.Background(), redisTagCloudKey)
+	return nil
+}
+
+func (s *tagServiceImpl) merge(fromID, intoID uuid.UUID) error {
+	if err := s.repo.merge(fromID, intoID); err != nil {
+		return err
+	}
+	s.redis.Del(context.Background(), redisTagCloudKey)
+	return nil
+}
@@ -0,0 +1,127 @@
+package collection
+
+import (
+	"errors"
+	"pokemon/internal/domains/game"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// Same layout as Pokémon HOME: 30 slots per box.
+const boxSize = 30
+
+/********
+ * MAIN *
+ ********/
+
+type CollectionBox struct {
+	ID       uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID   uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name     string    `json:"name" gorm:"type:varchar(40);not null"`
+	Position int       `json:"position" gorm:"type:int;not null;default:0"` // box order in the layout
+
+	Pokemon []CollectedPokemon `json:"pokemon,omitempty" gorm:"foreignKey:BoxID;constraint:OnDelete:CASCADE"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CollectedPokemon is one individual Pokémon owned by a collector.
+type CollectedPokemon struct {
+	ID     uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	BoxID  uuid.UUID `json:"box_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_collection_box_slot,where:deleted_at IS NULL"`
+	Slot   int       `json:"slot" gorm:"type:int;not null;uniqueIndex:idx_collection_box_slot"` // 0-29 inside the box
+
+	PokemonID int    `json:"pokemon_id" gorm:"type:int;not null;index"` // National Dex number
+	Form      string `json:"form" gorm:"type:varchar(40)"`              // e.g. alola, galar, gigantamax
+	Nickname  string `json:"nickname" gorm:"type:varchar(20)"`
+	Gender    string `json:"gender" gorm:"type:varchar(12)"`
+	Level     int    `json:"level" gorm:"type:int;default:1"`
+	IsShiny   bool   `json:"is_shiny" gorm:"default:false;index"`
+	Ball      string `json:"ball" gorm:"type:varchar(30);index"`
+
+	OTName       string     `json:"ot_name" gorm:"type:varchar(20)"`
+	OTID         string     `json:"ot_id" gorm:"type:varchar(10)"`
+	OriginGameID *uuid.UUID `json:"origin_game_id" gorm:"type:uuid;index"`
+	OriginGame   *game.Game `json:"origin_game,omitempty" gorm:"foreignKey:OriginGameID"`
+
+	Ribbons pq.StringArray `json:"ribbons" gorm:"type:text[]"`
+	Marks   pq.StringArray `json:"marks" gorm:"type:text[]"`
+	Notes   string         `json:"notes" gorm:"type:text"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+/************
+ * REQUESTS *
+ ************/
+
+type reorderBoxesRequest struct {
+	BoxIDs []uuid.UUID `json:"box_ids"`
+}
+
+type bulkMoveRequest struct {
+	PokemonIDs []uuid.UUID `json:"pokemon_ids"`
+	BoxID      uuid.UUID   `json:"box_id"`
+}
+
+// Every filter is optional; Query matches nickname and OT name.
+type searchFilters struct {
+	BoxID        string
+	PokemonID    int
+	Form         string
+	Ball         string
+	OriginGameID string
+	Ribbon       string
+	Mark         string
+	Shiny        *bool
+	Query        string
+}
+
+/****************
+ * API RESPONSE *
+ ****************/
+
+type SyncResult struct {
+	Games  int      `json:"games"`
+	Marked int      `json:"marked"`
+	Errors []string `json:"errors"`
+}
+
+/***************
+ * VALIDATIONS *
+ ***************/
+
+func (b *CollectionBox) Validate() error {
+	b.Name = strings.TrimSpace(b.Name)
+	if b.Name == "" || len(b.Name) > 40 {
+		return errors.New("box name is required and cannot exceed 40 characters")
+	}
+	return nil
+}
+
+func (p *CollectedPokemon) Validate() error {
+	p.Nickname = strings.TrimSpace(p.Nickname)
+	p.Ball = strings.ToLower(strings.TrimSpace(p.Ball))
+	p.Form = strings.ToLower(strings.TrimSpace(p.Form))
+	if p.PokemonID <= 0 {
+		return errors.New("pokemon_id must be a positive integer")
+	}
+	if len(p.Nickname) > 20 || len(p.OTName) > 20 {
+		return errors.New("nickname and ot_name cannot exceed 20 characters")
+	}
+	if p.Level < 1 || p.Level > 100 {
+		return errors.New("level must be between 1 and 100")
+	}
+	if p.Slot < 0 || p.Slot >= boxSize {
+		return errors.New("slot must be between 0 and 29")
+	}
+	return nil
+}
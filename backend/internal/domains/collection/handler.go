@@ -0,0 +1,281 @@
+package collection
+
+import (
+	"errors"
+	"pokemon/internal/domains/game"
+	"pokemon/pkg/utils"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	s collectionService
+}
+
+func NewHandler(db *gorm.DB, redis *redis.Client) *handler {
+	boxR := newBoxRepo(db)
+	pokemonR := newPokemonRepo(db)
+	serv := newCollectionService(boxR, pokemonR, game.NewPokedexMarker(db, redis))
+
+	return &handler{s: serv}
+}
+
+/*********
+ * BOXES *
+ *********/
+
+func (h *handler) listBoxes(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	list, err := h.s.listBoxes(c.Context(), userID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": len(list),
+		"items": list,
+	})
+}
+
+func (h *handler) getBox(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	box, err := h.s.getBox(c.Context(), userID, c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "box not found")
+	}
+	return c.JSON(box)
+}
+
+func (h *handler) createBox(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	var box CollectionBox
+	if err := c.BodyParser(&box); err != nil {
+		return fiber.ErrBadRequest
+	}
+	box.ID = uuid.Nil
+	box.UserID = userID
+	box.Pokemon = nil
+
+	if err := h.s.createBox(c.Context(), &box); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(box)
+}
+
+func (h *handler) renameBox(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	box, err := h.s.getBox(c.Context(), userID, c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "box not found")
+	}
+
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return fiber.ErrBadRequest
+	}
+	box.Name = body.Name
+
+	if err := h.s.renameBox(c.Context(), box); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.JSON(box)
+}
+
+func (h *handler) deleteBox(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	if err := h.s.deleteBox(c.Context(), userID, c.Params("id")); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// PUT /collection/boxes/order
+func (h *handler) reorderBoxes(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	var req reorderBoxesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	if err := h.s.reorderBoxes(c.Context(), userID, req.BoxIDs); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "box not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+/***********
+ * POKEMON *
+ ***********/
+
+// GET /collection/pokemon?pokemon_id=&form=&ball=&origin_game_id=&ribbon=&mark=&shiny=&box_id=&q=
+func (h *handler) searchPokemon(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	f := searchFilters{
+		BoxID:        c.Query("box_id"),
+		PokemonID:    c.QueryInt("pokemon_id"),
+		Form:         c.Query("form"),
+		Ball:         c.Query("ball"),
+		OriginGameID: c.Query("origin_game_id"),
+		Ribbon:       c.Query("ribbon"),
+		Mark:         c.Query("mark"),
+		Query:        c.Query("q"),
+	}
+	if shiny, err := strconv.ParseBool(c.Query("shiny")); err == nil {
+		f.Shiny = &shiny
+	}
+
+	limit, offset := utils.ParsePagination(c)
+	list, total, err := h.s.search(c.Context(), userID, f, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": total,
+		"items": list,
+	})
+}
+
+func (h *handler) addPokemon(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	var mon CollectedPokemon
+	if err := c.BodyParser(&mon); err != nil {
+		return fiber.ErrBadRequest
+	}
+	mon.ID = uuid.Nil
+	mon.UserID = userID
+	mon.OriginGame = nil
+
+	if err := h.s.addPokemon(c.Context(), &mon); err != nil {
+		if errors.Is(err, errBoxFull) || errors.Is(err, errSlotTaken) {
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(mon)
+}
+
+func (h *handler) getPokemon(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	mon, err := h.s.getPokemon(c.Context(), userID, c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "pokemon not found")
+	}
+	return c.JSON(mon)
+}
+
+func (h *handler) updatePokemon(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	var mon CollectedPokemon
+	if err := c.BodyParser(&mon); err != nil {
+		return fiber.ErrBadRequest
+	}
+	mon.ID = utils.ParseUUID(c.Params("id"))
+	mon.UserID = userID
+	mon.OriginGame = nil
+
+	if err := h.s.updatePokemon(c.Context(), &mon); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "pokemon not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.JSON(mon)
+}
+
+func (h *handler) deletePokemon(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	if err := h.s.deletePokemon(c.Context(), userID, c.Params("id")); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// POST /collection/pokemon/move
+func (h *handler) movePokemon(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	var req bulkMoveRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	if err := h.s.movePokemon(c.Context(), userID, req.PokemonIDs, req.BoxID); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "box or pokemon not found")
+		case errors.Is(err, errBoxFull), errors.Is(err, errSlotTaken):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// POST /collection/sync
+func (h *handler) syncPokedex(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	result, err := h.s.syncPokedex(c.Context(), userID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(result)
+}
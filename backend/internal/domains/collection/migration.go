@@ -0,0 +1,57 @@
+package collection
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CollectionMigrator struct{}
+
+func (m CollectionMigrator) Migrate(db *gorm.DB) error {
+	if err := spreadSharedSlots(db); err != nil {
+		return err
+	}
+	return db.AutoMigrate(
+		&CollectionBox{},
+		&CollectedPokemon{},
+	)
+}
+
+// spreadSharedSlots moves Pokémon sharing a slot with an older one to a free
+// slot of their box, so the unique slot index can be created on databases
+// filled before it existed.
+func spreadSharedSlots(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&CollectedPokemon{}) {
+		return nil
+	}
+
+	var shared []struct {
+		ID    uuid.UUID
+		BoxID uuid.UUID
+	}
+	err := db.Raw(`SELECT id, box_id FROM (
+			SELECT id, box_id, ROW_NUMBER() OVER (PARTITION BY box_id, slot ORDER BY created_at, id) AS n
+			FROM collected_pokemons
+			WHERE deleted_at IS NULL
+		) ranked
+		WHERE n > 1`).Scan(&shared).Error
+	if err != nil {
+		return err
+	}
+
+	for _, mon := range shared {
+		free, err := freeSlots(db, mon.BoxID, nil)
+		if err != nil {
+			return err
+		}
+		if len(free) == 0 {
+			return fmt.Errorf("box %s holds more Pokémon than it has slots", mon.BoxID)
+		}
+		if err := db.Model(&CollectedPokemon{}).Where("id = ?", mon.ID).Update("slot", free[0]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
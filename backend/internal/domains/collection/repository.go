@@ -0,0 +1,308 @@
+package collection
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	errBoxFull   = errors.New("not enough free slots in the target box")
+	errSlotTaken = errors.New("the slot was just taken, try again")
+)
+
+/******************************
+ ******************************
+ ************ MAIN ************
+ ******************************
+ ******************************/
+
+/*********
+ * BOXES *
+ *********/
+
+type boxRepository interface {
+	listByUser(ctx context.Context, userID uuid.UUID) ([]CollectionBox, error)
+	getByID(ctx context.Context, userID uuid.UUID, id string) (*CollectionBox, error)
+	create(ctx context.Context, box *CollectionBox) error
+	update(ctx context.Context, box *CollectionBox) error
+	delete(ctx context.Context, userID uuid.UUID, id string) error
+	reorder(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) error
+}
+
+type boxRepoImpl struct {
+	db *gorm.DB
+}
+
+func newBoxRepo(db *gorm.DB) boxRepository {
+	return &boxRepoImpl{db: db}
+}
+
+func (r *boxRepoImpl) listByUser(ctx context.Context, userID uuid.UUID) ([]CollectionBox, error) {
+	var boxes []CollectionBox
+	err := r.db.WithContext(ctx).
+		Preload("Pokemon", func(db *gorm.DB) *gorm.DB { return db.Order("slot ASC") }).
+		Where("user_id = ?", userID).
+		Order("position ASC, created_at ASC").
+		Find(&boxes).Error
+	return boxes, err
+}
+
+func (r *boxRepoImpl) getByID(ctx context.Context, userID uuid.UUID, id string) (*CollectionBox, error) {
+	var box CollectionBox
+	err := r.db.WithContext(ctx).
+		Preload("Pokemon", func(db *gorm.DB) *gorm.DB { return db.Order("slot ASC") }).
+		First(&box, "id = ? AND user_id = ?", id, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &box, nil
+}
+
+// create appends the box at the end of the user's layout.
+func (r *boxRepoImpl) create(ctx context.Context, box *CollectionBox) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var last int
+		err := tx.Model(&CollectionBox{}).Where("user_id = ?", box.UserID).
+			Select("COALESCE(MAX(position), -1)").Scan(&last).Error
+		if err != nil {
+			return err
+		}
+		box.Position = last + 1
+		return tx.Omit("Pokemon").Create(box).Error
+	})
+}
+
+func (r *boxRepoImpl) update(ctx context.Context, box *CollectionBox) error {
+	return r.db.WithContext(ctx).Model(box).Update("name", box.Name).Error
+}
+
+func (r *boxRepoImpl) delete(ctx context.Context, userID uuid.UUID, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("box_id = ? AND user_id = ?", id, userID).Delete(&CollectedPokemon{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&CollectionBox{}, "id = ? AND user_id = ?", id, userID).Error
+	})
+}
+
+// reorder sets every listed box's position to its index in ids.
+func (r *boxRepoImpl) reorder(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			res := tx.Model(&CollectionBox{}).Where("id = ? AND user_id = ?", id, userID).Update("position", i)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+		}
+		return nil
+	})
+}
+
+/***********
+ * POKEMON *
+ ***********/
+
+type pokemonRepository interface {
+	search(ctx context.Context, userID uuid.UUID, f searchFilters, limit, offset int) ([]CollectedPokemon, int64, error)
+	listOrigins(ctx context.Context, userID uuid.UUID) ([]CollectedPokemon, error)
+
+	create(ctx context.Context, mon *CollectedPokemon) error
+	getByID(ctx context.Context, userID uuid.UUID, id string) (*CollectedPokemon, error)
+	update(ctx context.Context, mon *CollectedPokemon) error
+	delete(ctx context.Context, userID uuid.UUID, id string) error
+
+	bulkMove(ctx context.Context, userID uuid.UUID, ids []uuid.UUID, boxID uuid.UUID) error
+}
+
+type pokemonRepoImpl struct {
+	db *gorm.DB
+}
+
+func newPokemonRepo(db *gorm.DB) pokemonRepository {
+	return &pokemonRepoImpl{db: db}
+}
+
+func (r *pokemonRepoImpl) search(ctx context.Context, userID uuid.UUID, f searchFilters, limit, offset int) ([]CollectedPokemon, int64, error) {
+	var mons []CollectedPokemon
+	var count int64
+
+	tx := r.db.WithContext(ctx).Model(&CollectedPokemon{}).Where("user_id = ?", userID)
+	if f.BoxID != "" {
+		tx = tx.Where("box_id = ?", f.BoxID)
+	}
+	if f.PokemonID > 0 {
+		tx = tx.Where("pokemon_id = ?", f.PokemonID)
+	}
+	if f.Form != "" {
+		tx = tx.Where("form = ?", f.Form)
+	}
+	if f.Ball != "" {
+		tx = tx.Where("ball = ?", f.Ball)
+	}
+	if f.OriginGameID != "" {
+		tx = tx.Where("origin_game_id = ?", f.OriginGameID)
+	}
+	if f.Ribbon != "" {
+		tx = tx.Where("? = ANY(ribbons)", f.Ribbon)
+	}
+	if f.Mark != "" {
+		tx = tx.Where("? = ANY(marks)", f.Mark)
+	}
+	if f.Shiny != nil {
+		tx = tx.Where("is_shiny = ?", *f.Shiny)
+	}
+	if f.Query != "" {
+		like := "%" + f.Query + "%"
+		tx = tx.Where("nickname ILIKE ? OR ot_name ILIKE ?", like, like)
+	}
+
+	if err := tx.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Preload("OriginGame").Order("pokemon_id ASC, created_at ASC").Limit(limit).Offset(offset).Find(&mons).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return mons, count, nil
+}
+
+// listOrigins returns only what the pokedex roll-up needs.
+func (r *pokemonRepoImpl) listOrigins(ctx context.Context, userID uuid.UUID) ([]CollectedPokemon, error) {
+	var mons []CollectedPokemon
+	err := r.db.WithContext(ctx).
+		Select("pokemon_id", "is_shiny", "origin_game_id").
+		Where("user_id = ? AND origin_game_id IS NOT NULL", userID).
+		Find(&mons).Error
+	return mons, err
+}
+
+// create puts the Pokémon in the requested slot, or the first free one when
+// the slot is taken.
+func (r *pokemonRepoImpl) create(ctx context.Context, mon *CollectedPokemon) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		free, err := freeSlots(tx, mon.BoxID, nil)
+		if err != nil {
+			return err
+		}
+		if len(free) == 0 {
+			return errBoxFull
+		}
+		if !containsSlot(free, mon.Slot) {
+			mon.Slot = free[0]
+		}
+		return slotError(tx.Omit("OriginGame").Create(mon).Error)
+	})
+}
+
+func (r *pokemonRepoImpl) getByID(ctx context.Context, userID uuid.UUID, id string) (*CollectedPokemon, error) {
+	var mon CollectedPokemon
+	if err := r.db.WithContext(ctx).Preload("OriginGame").First(&mon, "id = ? AND user_id = ?", id, userID).Error; err != nil {
+		return nil, err
+	}
+	return &mon, nil
+}
+
+func (r *pokemonRepoImpl) update(ctx context.Context, mon *CollectedPokemon) error {
+	return r.db.WithContext(ctx).Omit("OriginGame").Save(mon).Error
+}
+
+func (r *pokemonRepoImpl) delete(ctx context.Context, userID uuid.UUID, id string) error {
+	return r.db.WithContext(ctx).Delete(&CollectedPokemon{}, "id = ? AND user_id = ?", id, userID).Error
+}
+
+// bulkMove moves the Pokémon into the free slots of the target box, keeping
+// the order of ids. Nothing moves if the box can't hold them all.
+func (r *pokemonRepoImpl) bulkMove(ctx context.Context, userID uuid.UUID, ids []uuid.UUID, boxID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var owned int64
+		if err := tx.Model(&CollectionBox{}).Where("id = ? AND user_id = ?", boxID, userID).Count(&owned).Error; err != nil {
+			return err
+		}
+		if owned == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		free, err := freeSlots(tx, boxID, ids)
+		if err != nil {
+			return err
+		}
+		if len(free) < len(ids) {
+			return errBoxFull
+		}
+
+		// Park the Pokémon outside the box slots first: the slot one moves to
+		// may still be held by another one that is moving
+		err = tx.Model(&CollectedPokemon{}).
+			Where("id IN ? AND user_id = ?", ids, userID).
+			Update("slot", gorm.Expr("-1 - slot")).Error
+		if err != nil {
+			return err
+		}
+
+		for i, id := range ids {
+			res := tx.Model(&CollectedPokemon{}).
+				Where("id = ? AND user_id = ?", id, userID).
+				Updates(map[string]any{"box_id": boxID, "slot": free[i]})
+			if res.Error != nil {
+				return slotError(res.Error)
+			}
+			if res.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+		}
+		return nil
+	})
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// freeSlots lists the empty slots of a box, ignoring the Pokémon about to leave it.
+func freeSlots(tx *gorm.DB, boxID uuid.UUID, moving []uuid.UUID) ([]int, error) {
+	var used []int
+	q := tx.Model(&CollectedPokemon{}).Where("box_id = ?", boxID)
+	if len(moving) > 0 {
+		q = q.Where("id NOT IN ?", moving)
+	}
+	if err := q.Pluck("slot", &used).Error; err != nil {
+		return nil, err
+	}
+
+	taken := make(map[int]bool, len(used))
+	for _, s := range used {
+		taken[s] = true
+	}
+
+	free := []int{}
+	for s := 0; s < boxSize; s++ {
+		if !taken[s] {
+			free = append(free, s)
+		}
+	}
+	return free, nil
+}
+
+// slotError reports a slot filled by a concurrent request as errSlotTaken.
+func slotError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return errSlotTaken
+	}
+	return err
+}
+
+func containsSlot(slots []int, slot int) bool {
+	for _, s := range slots {
+		if s == slot {
+			return true
+		}
+	}
+	return false
+}
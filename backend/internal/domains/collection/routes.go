@@ -0,0 +1,31 @@
+package collection
+
+import (
+	"pokemon/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *handler) RegisterRoutes(router fiber.Router) {
+	group := router.Group("/collection")
+	group.Use(middleware.AuthRequired())
+
+	// Boxes
+	group.Get("/boxes", h.listBoxes)
+	group.Post("/boxes", h.createBox)
+	group.Put("/boxes/order", h.reorderBoxes)
+	group.Get("/boxes/:id", h.getBox)
+	group.Put("/boxes/:id", h.renameBox)
+	group.Delete("/boxes/:id", h.deleteBox)
+
+	// Pokémon
+	group.Get("/pokemon", h.searchPokemon)
+	group.Post("/pokemon", h.addPokemon)
+	group.Post("/pokemon/move", h.movePokemon)
+	group.Get("/pokemon/:id", h.getPokemon)
+	group.Put("/pokemon/:id", h.updatePokemon)
+	group.Delete("/pokemon/:id", h.deletePokemon)
+
+	// GamePokedex roll-up
+	group.Post("/sync", h.syncPokedex)
+}
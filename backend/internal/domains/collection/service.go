@@ -0,0 +1,179 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"pokemon/internal/domains/game"
+
+	"github.com/google/uuid"
+)
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type collectionService interface {
+	listBoxes(ctx context.Context, userID uuid.UUID) ([]CollectionBox, error)
+	getBox(ctx context.Context, userID uuid.UUID, id string) (*CollectionBox, error)
+	createBox(ctx context.Context, box *CollectionBox) error
+	renameBox(ctx context.Context, box *CollectionBox) error
+	deleteBox(ctx context.Context, userID uuid.UUID, id string) error
+	reorderBoxes(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) error
+
+	search(ctx context.Context, userID uuid.UUID, f searchFilters, limit, offset int) ([]CollectedPokemon, int64, error)
+	addPokemon(ctx context.Context, mon *CollectedPokemon) error
+	getPokemon(ctx context.Context, userID uuid.UUID, id string) (*CollectedPokemon, error)
+	updatePokemon(ctx context.Context, mon *CollectedPokemon) error
+	deletePokemon(ctx context.Context, userID uuid.UUID, id string) error
+	movePokemon(ctx context.Context, userID uuid.UUID, ids []uuid.UUID, boxID uuid.UUID) error
+
+	syncPokedex(ctx context.Context, userID uuid.UUID) (*SyncResult, error)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+// Collections are user-specific and change constantly, so nothing is cached.
+type collectionServiceImpl struct {
+	boxes   boxRepository
+	pokemon pokemonRepository
+	pokedex game.PokedexMarker
+}
+
+func newCollectionService(boxes boxRepository, pokemon pokemonRepository, pokedex game.PokedexMarker) collectionService {
+	return &collectionServiceImpl{boxes: boxes, pokemon: pokemon, pokedex: pokedex}
+}
+
+func (s *collectionServiceImpl) listBoxes(ctx context.Context, userID uuid.UUID) ([]CollectionBox, error) {
+	return s.boxes.listByUser(ctx, userID)
+}
+
+func (s *collectionServiceImpl) getBox(ctx context.Context, userID uuid.UUID, id string) (*CollectionBox, error) {
+	return s.boxes.getByID(ctx, userID, id)
+}
+
+func (s *collectionServiceImpl) createBox(ctx context.Context, box *CollectionBox) error {
+	if err := box.Validate(); err != nil {
+		return err
+	}
+	return s.boxes.create(ctx, box)
+}
+
+func (s *collectionServiceImpl) renameBox(ctx context.Context, box *CollectionBox) error {
+	if err := box.Validate(); err != nil {
+		return err
+	}
+	return s.boxes.update(ctx, box)
+}
+
+func (s *collectionServiceImpl) deleteBox(ctx context.Context, userID uuid.UUID, id string) error {
+	return s.boxes.delete(ctx, userID, id)
+}
+
+func (s *collectionServiceImpl) reorderBoxes(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return errors.New("box_ids is required")
+	}
+	return s.boxes.reorder(ctx, userID, ids)
+}
+
+func (s *collectionServiceImpl) search(ctx context.Context, userID uuid.UUID, f searchFilters, limit, offset int) ([]CollectedPokemon, int64, error) {
+	return s.pokemon.search(ctx, userID, f, limit, offset)
+}
+
+func (s *collectionServiceImpl) addPokemon(ctx context.Context, mon *CollectedPokemon) error {
+	if err := mon.Validate(); err != nil {
+		return err
+	}
+	if _, err := s.boxes.getByID(ctx, mon.UserID, mon.BoxID.String()); err != nil {
+		return errors.New("box not found")
+	}
+	if err := s.pokemon.create(ctx, mon); err != nil {
+		return err
+	}
+	s.rollUp(ctx, mon)
+	return nil
+}
+
+func (s *collectionServiceImpl) getPokemon(ctx context.Context, userID uuid.UUID, id string) (*CollectedPokemon, error) {
+	return s.pokemon.getByID(ctx, userID, id)
+}
+
+// updatePokemon edits everything but the position; moves go through movePokemon.
+func (s *collectionServiceImpl) updatePokemon(ctx context.Context, mon *CollectedPokemon) error {
+	existing, err := s.pokemon.getByID(ctx, mon.UserID, mon.ID.String())
+	if err != nil {
+		return err
+	}
+	mon.BoxID = existing.BoxID
+	mon.Slot = existing.Slot
+	mon.CreatedAt = existing.CreatedAt
+
+	if err := mon.Validate(); err != nil {
+		return err
+	}
+	if err := s.pokemon.update(ctx, mon); err != nil {
+		return err
+	}
+	s.rollUp(ctx, mon)
+	return nil
+}
+
+func (s *collectionServiceImpl) deletePokemon(ctx context.Context, userID uuid.UUID, id string) error {
+	return s.pokemon.delete(ctx, userID, id)
+}
+
+func (s *collectionServiceImpl) movePokemon(ctx context.Context, userID uuid.UUID, ids []uuid.UUID, boxID uuid.UUID) error {
+	if len(ids) == 0 {
+		return errors.New("pokemon_ids is required")
+	}
+	if len(ids) > boxSize {
+		return fmt.Errorf("a box only holds %d Pokémon", boxSize)
+	}
+	return s.pokemon.bulkMove(ctx, userID, ids, boxID)
+}
+
+// syncPokedex replays the roll-up for the whole collection. Bits are only
+// ever set, so releasing a Pokémon never un-captures it in a GamePokedex.
+func (s *collectionServiceImpl) syncPokedex(ctx context.Context, userID uuid.UUID) (*SyncResult, error) {
+	mons, err := s.pokemon.listOrigins(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	type target struct {
+		gameID uuid.UUID
+		shiny  bool
+	}
+	groups := map[target][]int{}
+	games := map[uuid.UUID]bool{}
+	for _, m := range mons {
+		t := target{gameID: *m.OriginGameID, shiny: m.IsShiny}
+		groups[t] = append(groups[t], m.PokemonID)
+		games[t.gameID] = true
+	}
+
+	result := &SyncResult{Games: len(games), Errors: []string{}}
+	for t, ids := range groups {
+		if err := s.pokedex.MarkCaptured(ctx, userID, t.gameID, ids, t.shiny); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("game %s: %v", t.gameID, err))
+			continue
+		}
+		result.Marked += len(ids)
+	}
+	return result, nil
+}
+
+// rollUp flags the Pokémon as captured in the pokedex of its origin game. A
+// failure doesn't undo the catalog change and can be repaired by a sync.
+func (s *collectionServiceImpl) rollUp(ctx context.Context, mon *CollectedPokemon) {
+	if mon.OriginGameID == nil {
+		return
+	}
+	if err := s.pokedex.MarkCaptured(ctx, mon.UserID, *mon.OriginGameID, []int{mon.PokemonID}, mon.IsShiny); err != nil {
+		log.Printf("pokedex roll-up failed for collected pokemon %s: %v", mon.ID, err)
+	}
+}
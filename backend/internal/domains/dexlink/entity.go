@@ -0,0 +1,117 @@
+package dexlink
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of Pokédex entity recognized in content. When two kinds share a name
+// (Metronome is a move and an item) the one listed first wins.
+const (
+	KindPokemon = "pokemon"
+	KindMove    = "move"
+	KindAbility = "ability"
+	KindItem    = "item"
+)
+
+var kinds = []string{KindPokemon, KindMove, KindAbility, KindItem}
+
+// Kinds of content scanned for mentions.
+const (
+	ContentPost            = "post"
+	ContentGuide           = "guide"
+	ContentWalkthroughStep = "walkthrough_step"
+	ContentTopic           = "topic"
+	ContentTopicComment    = "topic_comment"
+	ContentShout           = "shout"
+)
+
+/********
+ * MAIN *
+ ********/
+
+// DexName is the name of a species, move, ability or item in one language.
+// Names are imported from a dump; DexID is the national dex number for
+// species and the PokeAPI id for the other kinds.
+type DexName struct {
+	ID       uuid.UUID `json:"-" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Kind     string    `json:"kind" gorm:"type:varchar(12);not null;uniqueIndex:idx_dex_name_entry"`
+	DexID    int       `json:"dex_id" gorm:"not null;uniqueIndex:idx_dex_name_entry"`
+	Language string    `json:"language" gorm:"type:varchar(10);not null;uniqueIndex:idx_dex_name_entry"`
+	Slug     string    `json:"slug" gorm:"type:varchar(100);not null"`
+	Name     string    `json:"name" gorm:"type:varchar(100);not null"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ContentMention records that a piece of content names an entity, for the
+// "mentioned in" lists of the Pokédex pages. Rows are rebuilt by the index
+// worker whenever the content changes.
+type ContentMention struct {
+	ContentType string     `json:"content_type" gorm:"type:varchar(20);primaryKey"`
+	ContentID   uuid.UUID  `json:"content_id" gorm:"type:uuid;primaryKey"`
+	Kind        string     `json:"kind" gorm:"type:varchar(12);primaryKey;index:idx_content_mention_entity"`
+	DexID       int        `json:"dex_id" gorm:"primaryKey;autoIncrement:false;index:idx_content_mention_entity"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty" gorm:"type:uuid"` // topic of a comment, walkthrough of a step
+	Count       int        `json:"count" gorm:"not null;default:1"`
+
+	IndexedAt time.Time `json:"indexed_at"`
+}
+
+/*************
+ * RESPONSES *
+ *************/
+
+// Entity is a species, move, ability or item with its names by language.
+type Entity struct {
+	Kind  string            `json:"kind"`
+	DexID int               `json:"dex_id"`
+	Slug  string            `json:"slug"`
+	Names map[string]string `json:"names"`
+}
+
+// Mention is a visible piece of content naming an entity.
+type Mention struct {
+	ContentType string     `json:"content_type"`
+	ContentID   uuid.UUID  `json:"content_id"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Count       int        `json:"count"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Match locates an entity in plain text, for content that isn't rendered
+// (shouts). Start and End count characters (code points) from the start of
+// the text.
+type Match struct {
+	Kind  string `json:"kind"`
+	DexID int    `json:"dex_id"`
+	Slug  string `json:"slug"`
+	URL   string `json:"url"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// NameImportResult summarizes a name dump import.
+type NameImportResult struct {
+	Names    int
+	Entities int
+}
+
+/***************
+ * VALIDATIONS *
+ ***************/
+
+var errUnknownKind = errors.New("kind must be pokemon, move, ability or item")
+
+func validKind(kind string) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
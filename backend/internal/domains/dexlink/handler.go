@@ -0,0 +1,62 @@
+package dexlink
+
+import (
+	"errors"
+	"pokemon/pkg/utils"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	s service
+}
+
+func NewHandler(db *gorm.DB, redis *redis.Client) *handler {
+	return &handler{s: newServ(newRepo(db), redis)}
+}
+
+// GET /dex/:kind/:id
+func (h *handler) entity(c *fiber.Ctx) error {
+	dexID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	entity, err := h.s.entity(c.Context(), c.Params("kind"), dexID)
+	if err != nil {
+		return dexError(err)
+	}
+	return c.JSON(entity)
+}
+
+// GET /dex/:kind/:id/mentions
+func (h *handler) mentions(c *fiber.Ctx) error {
+	dexID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	limit, offset := utils.ParsePagination(c)
+	list, total, err := h.s.mentions(c.Context(), c.Params("kind"), dexID, limit, offset)
+	if err != nil {
+		return dexError(err)
+	}
+	return c.JSON(fiber.Map{"total": total, "items": list})
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func dexError(err error) error {
+	switch {
+	case errors.Is(err, errUnknownKind):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, errEntityNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
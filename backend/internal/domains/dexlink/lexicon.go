@@ -0,0 +1,258 @@
+package dexlink
+
+import (
+	"html"
+	"pokemon/pkg/permalink"
+	"strings"
+	"unicode"
+
+	xhtml "golang.org/x/net/html"
+)
+
+// minNameRunes keeps one-character names (mostly Korean) from matching
+// inside ordinary words.
+const minNameRunes = 2
+
+// entry is the entity a name points at.
+type entry struct {
+	kind  string
+	dexID int
+	slug  string
+}
+
+func (e entry) url() string {
+	switch e.kind {
+	case KindPokemon:
+		return permalink.Pokemon("", e.dexID)
+	case KindMove:
+		return permalink.Move("", e.slug)
+	case KindAbility:
+		return permalink.Ability("", e.slug)
+	}
+	return permalink.Item("", e.slug)
+}
+
+// lexicon maps every known name, in every language, folded with fold, to
+// its entity.
+type lexicon struct {
+	names    map[string]entry
+	maxRunes int
+}
+
+// span is a name found in text, in rune offsets.
+type span struct {
+	start, end int
+	entry      entry
+}
+
+// newLexicon indexes the names. They must come in kind priority order so the
+// first kind claiming a name keeps it, and English first: every language
+// links to the page of the first slug seen.
+func newLexicon(names []DexName) *lexicon {
+	l := &lexicon{names: make(map[string]entry, len(names))}
+	slugs := map[entry]string{}
+	for _, n := range names {
+		id := entry{kind: n.Kind, dexID: n.DexID}
+		if _, ok := slugs[id]; !ok {
+			slugs[id] = n.Slug
+		}
+
+		key := []rune(foldString(n.Name))
+		if len(key) < minNameRunes {
+			continue
+		}
+		if _, taken := l.names[string(key)]; taken {
+			continue
+		}
+		l.names[string(key)] = entry{kind: n.Kind, dexID: n.DexID, slug: slugs[id]}
+		if len(key) > l.maxRunes {
+			l.maxRunes = len(key)
+		}
+	}
+	return l
+}
+
+func (l *lexicon) empty() bool {
+	return l == nil || len(l.names) == 0
+}
+
+// find returns the names in the text, longest first at each position and
+// never overlapping. Names must start and end on a word boundary and, in
+// cased scripts, start with a capital so "surf" in prose or "leftovers" in
+// a recipe stay plain words.
+func (l *lexicon) find(text []rune) []span {
+	if l.empty() {
+		return nil
+	}
+	folded := make([]rune, len(text))
+	for i, r := range text {
+		folded[i] = fold(r)
+	}
+
+	var spans []span
+	for start := 0; start < len(text); {
+		if !isWordRune(text[start]) || !isBoundary(text, start) || unicode.IsLower(text[start]) {
+			start++
+			continue
+		}
+		end := min(len(text), start+l.maxRunes)
+		for ; end >= start+minNameRunes; end-- {
+			if !isBoundary(text, end) {
+				continue
+			}
+			if e, ok := l.names[string(folded[start:end])]; ok {
+				spans = append(spans, span{start: start, end: end, entry: e})
+				break
+			}
+		}
+		if end >= start+minNameRunes {
+			start = end
+		} else {
+			start++
+		}
+	}
+	return spans
+}
+
+// link wraps the first mention of every entity in rendered HTML in a link to
+// its page. Text already inside a link, code or a heading is left alone.
+func (l *lexicon) link(source string) string {
+	if l.empty() {
+		return source
+	}
+
+	var b strings.Builder
+	linked := map[entry]bool{}
+	walk(source, notLinked, &b, func(text string) {
+		runes := []rune(text)
+		last := 0
+		for _, s := range l.find(runes) {
+			if linked[s.entry] {
+				continue
+			}
+			linked[s.entry] = true
+
+			b.WriteString(html.EscapeString(string(runes[last:s.start])))
+			b.WriteString(`<a href="` + s.entry.url() + `" class="dex-link dex-` + s.entry.kind + `">`)
+			b.WriteString(html.EscapeString(string(runes[s.start:s.end])))
+			b.WriteString("</a>")
+			last = s.end
+		}
+		b.WriteString(html.EscapeString(string(runes[last:])))
+	})
+	return b.String()
+}
+
+// count tallies the mentions of every entity in rendered HTML.
+func (l *lexicon) count(source string) map[entry]int {
+	counts := map[entry]int{}
+	if l.empty() {
+		return counts
+	}
+	walk(source, notCounted, nil, func(text string) {
+		for _, s := range l.find([]rune(text)) {
+			counts[s.entry]++
+		}
+	})
+	return counts
+}
+
+// matches locates the entities in plain text.
+func (l *lexicon) matches(text string) []Match {
+	var matches []Match
+	for _, s := range l.find([]rune(text)) {
+		matches = append(matches, Match{
+			Kind:  s.entry.kind,
+			DexID: s.entry.dexID,
+			Slug:  s.entry.slug,
+			URL:   s.entry.url(),
+			Start: s.start,
+			End:   s.end,
+		})
+	}
+	return matches
+}
+
+// Elements whose text is never linked: links can't nest, code is literal and
+// headings carry their own anchors. Only code is left out of the counts.
+var (
+	notLinked = map[string]bool{
+		"a": true, "code": true, "pre": true,
+		"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	}
+	notCounted = map[string]bool{"code": true, "pre": true}
+)
+
+// walk calls text with the unescaped content of every text node outside the
+// skipped elements. When out is set everything else is copied to it as is,
+// and text is expected to write its own (escaped) version of the node.
+func walk(source string, skipped map[string]bool, out *strings.Builder, text func(string)) {
+	z := xhtml.NewTokenizer(strings.NewReader(source))
+	depth := 0
+	for {
+		tt := z.Next()
+		if tt == xhtml.ErrorToken {
+			return
+		}
+
+		raw := z.Raw()
+		switch tt {
+		case xhtml.StartTagToken, xhtml.EndTagToken:
+			name, _ := z.TagName()
+			if skipped[string(name)] {
+				if tt == xhtml.StartTagToken {
+					depth++
+				} else if depth > 0 {
+					depth--
+				}
+			}
+		case xhtml.TextToken:
+			if depth == 0 {
+				text(html.UnescapeString(string(raw)))
+				continue
+			}
+		}
+		if out != nil {
+			out.Write(raw)
+		}
+	}
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// fold lowercases a rune and unifies the apostrophes found in names
+// (Farfetch’d), so lookups ignore case and typography.
+func fold(r rune) rune {
+	switch r {
+	case '’', 'ʼ', '`':
+		return '\''
+	}
+	if unicode.IsSpace(r) {
+		return ' '
+	}
+	return unicode.ToLower(r)
+}
+
+func foldString(s string) string {
+	return strings.Map(fold, strings.TrimSpace(s))
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+// isCJK tells whether the rune belongs to a script written without spaces,
+// where every position is a word boundary.
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+func isBoundary(text []rune, i int) bool {
+	if i == 0 || i == len(text) {
+		return true
+	}
+	prev, next := text[i-1], text[i]
+	return !isWordRune(prev) || !isWordRune(next) || isCJK(prev) || isCJK(next)
+}
@@ -0,0 +1,65 @@
+package dexlink
+
+import (
+	"context"
+	"pokemon/pkg/content"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// linkingRenderer links the entities named in rendered content to their
+// Pokédex pages. The rendered HTML stays cached without links, so renamed or
+// newly imported entities show up without invalidating anything.
+type linkingRenderer struct {
+	next content.Renderer
+	repo repository
+}
+
+// NewRenderer is a content.Renderer that also cross-links species, moves,
+// abilities and items, for the domains whose pages show rendered content.
+func NewRenderer(db *gorm.DB, redis *redis.Client) content.Renderer {
+	return &linkingRenderer{next: content.NewRenderer(redis), repo: newRepo(db)}
+}
+
+func (r *linkingRenderer) Render(ctx context.Context, source string) *content.Rendered {
+	rendered := r.next.Render(ctx, source)
+
+	lex := currentLexicon(ctx, r.repo)
+	if lex.empty() {
+		return rendered
+	}
+	linked := *rendered
+	linked.HTML = lex.link(rendered.HTML)
+	return &linked
+}
+
+func (r *linkingRenderer) RenderAll(ctx context.Context, sources []string) []*content.Rendered {
+	all := r.next.RenderAll(ctx, sources)
+
+	lex := currentLexicon(ctx, r.repo)
+	if lex.empty() {
+		return all
+	}
+	for i, rendered := range all {
+		linked := *rendered
+		linked.HTML = lex.link(rendered.HTML)
+		all[i] = &linked
+	}
+	return all
+}
+
+// Matcher finds entities in content that isn't rendered, so clients can link
+// them themselves.
+type Matcher struct {
+	repo repository
+}
+
+func NewMatcher(db *gorm.DB) *Matcher {
+	return &Matcher{repo: newRepo(db)}
+}
+
+// Find returns the entities named in the plain text, in order.
+func (m *Matcher) Find(ctx context.Context, text string) []Match {
+	return currentLexicon(ctx, m.repo).matches(text)
+}
@@ -0,0 +1,12 @@
+package dexlink
+
+import "gorm.io/gorm"
+
+type DexLinkMigrator struct{}
+
+func (m DexLinkMigrator) Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&DexName{},
+		&ContentMention{},
+	)
+}
@@ -0,0 +1,132 @@
+package dexlink
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// row is a piece of content waiting to be scanned.
+type row struct {
+	ID        uuid.UUID
+	ParentID  *uuid.UUID
+	Text      string
+	UpdatedAt time.Time
+}
+
+// watermark is the position of the last row scanned in a source, in
+// (updated_at, id) order.
+type watermark struct {
+	UpdatedAt time.Time
+	ID        uuid.UUID
+}
+
+type repository interface {
+	names(ctx context.Context) ([]DexName, error)
+	entity(ctx context.Context, kind string, dexID int) ([]DexName, error)
+	upsertNames(ctx context.Context, names []DexName) error
+
+	pending(ctx context.Context, src source, after watermark, limit int) ([]row, error)
+	replaceMentions(ctx context.Context, contentType string, ids []uuid.UUID, mentions []ContentMention) error
+	prune(ctx context.Context, src source) error
+	mentions(ctx context.Context, kind string, dexID, limit, offset int) ([]Mention, int64, error)
+}
+
+type repositoryImpl struct {
+	db *gorm.DB
+}
+
+func newRepo(db *gorm.DB) repository {
+	return &repositoryImpl{db: db}
+}
+
+// names lists every name in kind priority order, English first, so newLexicon
+// resolves clashes the same way on every load.
+func (r *repositoryImpl) names(ctx context.Context) ([]DexName, error) {
+	var names []DexName
+	err := r.db.WithContext(ctx).
+		Order("CASE kind WHEN 'pokemon' THEN 0 WHEN 'move' THEN 1 WHEN 'ability' THEN 2 ELSE 3 END").
+		Order("language <> 'en'").
+		Order("dex_id").
+		Find(&names).Error
+	return names, err
+}
+
+func (r *repositoryImpl) entity(ctx context.Context, kind string, dexID int) ([]DexName, error) {
+	var names []DexName
+	err := r.db.WithContext(ctx).
+		Where("kind = ? AND dex_id = ?", kind, dexID).
+		Order("language").
+		Find(&names).Error
+	return names, err
+}
+
+func (r *repositoryImpl) upsertNames(ctx context.Context, names []DexName) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "kind"}, {Name: "dex_id"}, {Name: "language"}},
+		DoUpdates: clause.AssignmentColumns([]string{"slug", "name", "updated_at"}),
+	}).CreateInBatches(names, 500).Error
+}
+
+// pending returns the rows of the source changed after the watermark, oldest
+// first. Hidden rows are scanned too; visibility is checked when listing.
+func (r *repositoryImpl) pending(ctx context.Context, src source, after watermark, limit int) ([]row, error) {
+	query := "SELECT t.id, " + src.parentID + " AS parent_id, " + src.text + " AS text, t.updated_at" +
+		" FROM " + src.from +
+		" WHERE (t.updated_at, t.id) > (?, ?)" +
+		" ORDER BY t.updated_at, t.id LIMIT ?"
+
+	var rows []row
+	err := r.db.WithContext(ctx).Raw(query, after.UpdatedAt, after.ID, limit).Scan(&rows).Error
+	return rows, err
+}
+
+// replaceMentions swaps the mentions of the scanned rows for the new ones.
+func (r *repositoryImpl) replaceMentions(ctx context.Context, contentType string, ids []uuid.UUID, mentions []ContentMention) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("content_type = ? AND content_id IN ?", contentType, ids).
+			Delete(&ContentMention{}).Error
+		if err != nil || len(mentions) == 0 {
+			return err
+		}
+		return tx.CreateInBatches(mentions, 500).Error
+	})
+}
+
+// prune drops the mentions of rows deleted from the source table.
+func (r *repositoryImpl) prune(ctx context.Context, src source) error {
+	return r.db.WithContext(ctx).Exec(
+		"DELETE FROM content_mentions m WHERE m.content_type = ? AND NOT EXISTS "+
+			"(SELECT 1 FROM "+src.table+" t WHERE t.id = m.content_id)", src.typ).Error
+}
+
+// mentions lists the visible content naming the entity, latest first.
+func (r *repositoryImpl) mentions(ctx context.Context, kind string, dexID, limit, offset int) ([]Mention, int64, error) {
+	parts := make([]string, 0, len(sources))
+	for _, src := range sources {
+		parts = append(parts, "SELECT '"+src.typ+"' AS content_type, t.id AS content_id, "+
+			src.parentID+" AS parent_id, "+src.title+" AS title, m.count, t.updated_at"+
+			" FROM "+src.from+
+			" JOIN content_mentions m ON m.content_id = t.id AND m.content_type = '"+src.typ+"'"+
+			" WHERE m.kind = @kind AND m.dex_id = @dex AND "+src.visible)
+	}
+	union := strings.Join(parts, " UNION ALL ")
+	args := []any{sql.Named("kind", kind), sql.Named("dex", dexID)}
+
+	db := r.db.WithContext(ctx)
+	var total int64
+	if err := db.Raw("SELECT COUNT(*) FROM ("+union+") u", args...).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var list []Mention
+	err := db.Raw("SELECT * FROM ("+union+") u ORDER BY u.updated_at DESC, u.content_id LIMIT @limit OFFSET @offset",
+		append(args, sql.Named("limit", limit), sql.Named("offset", offset))...).
+		Scan(&list).Error
+	return list, total, err
+}
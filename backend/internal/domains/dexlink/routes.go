@@ -0,0 +1,10 @@
+package dexlink
+
+import "github.com/gofiber/fiber/v2"
+
+func (h *handler) RegisterRoutes(router fiber.Router) {
+	group := router.Group("/dex")
+
+	group.Get("/:kind/:id", h.entity)
+	group.Get("/:kind/:id/mentions", h.mentions)
+}
@@ -0,0 +1,372 @@
+package dexlink
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"pokemon/pkg/content"
+	"pokemon/pkg/utils"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	// lexiconTTL bounds how long a process keeps linking with names that were
+	// re-imported since it loaded them.
+	lexiconTTL     = 10 * time.Minute
+	indexBatchSize = 500
+
+	redisIndexLockKey = "lock:dexlink:index"
+	indexLockTTL      = 10 * time.Minute
+)
+
+var errEntityNotFound = errors.New("entity not found")
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type service interface {
+	entity(ctx context.Context, kind string, dexID int) (*Entity, error)
+	mentions(ctx context.Context, kind string, dexID, limit, offset int) ([]Mention, int64, error)
+
+	importCSV(ctx context.Context, r io.Reader) (*NameImportResult, error)
+	index(ctx context.Context) error
+}
+
+/********************
+ * REDIS KEY UTILS  *
+ ********************/
+
+func redisWatermarkKey(contentType string) string {
+	return "dexlink:indexed:" + contentType
+}
+
+/***********
+ * LEXICON *
+ ***********/
+
+type loadedLexicon struct {
+	lex      *lexicon
+	loadedAt time.Time
+}
+
+// lexicons holds the names loaded by this process, shared by every renderer
+// and the index worker. It is swapped whole, so readers never wait on a load.
+var (
+	lexicons       atomic.Pointer[loadedLexicon]
+	lexiconLoading atomic.Bool
+)
+
+// currentLexicon returns the shared lexicon, reloading it once it is older
+// than lexiconTTL. One caller reloads while the others keep the previous
+// names; a failed load keeps them until the next try.
+func currentLexicon(ctx context.Context, repo repository) *lexicon {
+	current := lexicons.Load()
+	if current != nil && time.Since(current.loadedAt) < lexiconTTL {
+		return current.lex
+	}
+	if current != nil {
+		if !lexiconLoading.CompareAndSwap(false, true) {
+			return current.lex
+		}
+		defer lexiconLoading.Store(false)
+	}
+
+	loaded := &loadedLexicon{loadedAt: time.Now()}
+	if current != nil {
+		loaded.lex = current.lex
+	}
+	if names, err := repo.names(ctx); err != nil {
+		log.Printf("loading dex names failed: %v", err)
+	} else {
+		loaded.lex = newLexicon(names)
+	}
+	lexicons.Store(loaded)
+	return loaded.lex
+}
+
+// reloadLexicon forces the next currentLexicon call to load the names again.
+func reloadLexicon() {
+	if current := lexicons.Load(); current != nil {
+		lexicons.CompareAndSwap(current, &loadedLexicon{lex: current.lex})
+	}
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type serviceImpl struct {
+	repo  repository
+	cache *redis.Client
+}
+
+func newServ(repo repository, cache *redis.Client) service {
+	return &serviceImpl{repo: repo, cache: cache}
+}
+
+func (s *serviceImpl) entity(ctx context.Context, kind string, dexID int) (*Entity, error) {
+	if !validKind(kind) {
+		return nil, errUnknownKind
+	}
+	names, err := s.repo.entity(ctx, kind, dexID)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, errEntityNotFound
+	}
+
+	entity := &Entity{Kind: kind, DexID: dexID, Slug: names[0].Slug, Names: make(map[string]string, len(names))}
+	for _, n := range names {
+		entity.Names[n.Language] = n.Name
+		if n.Language == "en" {
+			entity.Slug = n.Slug
+		}
+	}
+	return entity, nil
+}
+
+func (s *serviceImpl) mentions(ctx context.Context, kind string, dexID, limit, offset int) ([]Mention, int64, error) {
+	if !validKind(kind) {
+		return nil, 0, errUnknownKind
+	}
+	list, total, err := s.repo.mentions(ctx, kind, dexID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range list {
+		if src, ok := sourceByType(list[i].ContentType); ok {
+			list[i].URL = src.url(list[i].ContentID, list[i].ParentID)
+		}
+	}
+	return list, total, nil
+}
+
+// importCSV upserts the names of the dump and has all content scanned again
+// against them.
+//
+// Columns, in any order: kind, dex_id, language, name, slug. Language
+// defaults to en and slug to the slug of the name.
+func (s *serviceImpl) importCSV(ctx context.Context, r io.Reader) (*NameImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	col := make(map[string]int, len(header))
+	for i, name := range header {
+		col[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"kind", "dex_id", "name"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("missing required column %q", name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := col[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var names []DexName
+	entities := map[entry]bool{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		n := DexName{
+			Kind:     strings.ToLower(field(record, "kind")),
+			Language: strings.ToLower(field(record, "language")),
+			Name:     field(record, "name"),
+			Slug:     field(record, "slug"),
+		}
+		if !validKind(n.Kind) {
+			return nil, fmt.Errorf("line %d: %w", line, errUnknownKind)
+		}
+		if n.DexID, err = strconv.Atoi(field(record, "dex_id")); err != nil || n.DexID <= 0 {
+			return nil, fmt.Errorf("line %d: invalid dex_id", line)
+		}
+		if n.Name == "" {
+			return nil, fmt.Errorf("line %d: name is required", line)
+		}
+		if n.Language == "" {
+			n.Language = "en"
+		}
+		if n.Slug == "" {
+			n.Slug = utils.Slugify(n.Name)
+		}
+
+		names = append(names, n)
+		entities[entry{kind: n.Kind, dexID: n.DexID}] = true
+	}
+
+	if len(names) > 0 {
+		if err := s.repo.upsertNames(ctx, names); err != nil {
+			return nil, err
+		}
+	}
+
+	// Content scanned with the old names may miss or mislink entities
+	for _, src := range sources {
+		s.cache.Del(ctx, redisWatermarkKey(src.typ))
+	}
+	reloadLexicon()
+
+	return &NameImportResult{Names: len(names), Entities: len(entities)}, nil
+}
+
+// index scans the content changed since the last run and records the
+// entities it mentions.
+func (s *serviceImpl) index(ctx context.Context) error {
+	reloadLexicon()
+	lex := currentLexicon(ctx, s.repo)
+	if lex.empty() {
+		return nil
+	}
+
+	for _, src := range sources {
+		if err := s.indexSource(ctx, lex, src); err != nil {
+			return fmt.Errorf("%s: %w", src.typ, err)
+		}
+		if err := s.repo.prune(ctx, src); err != nil {
+			return fmt.Errorf("%s: %w", src.typ, err)
+		}
+	}
+	return nil
+}
+
+func (s *serviceImpl) indexSource(ctx context.Context, lex *lexicon, src source) error {
+	mark := s.watermark(ctx, src.typ)
+	for {
+		rows, err := s.repo.pending(ctx, src, mark, indexBatchSize)
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+
+		now := time.Now()
+		ids := make([]uuid.UUID, 0, len(rows))
+		var mentions []ContentMention
+		for _, row := range rows {
+			ids = append(ids, row.ID)
+
+			// Rendering drops what readers never see as text: code, markup, URLs
+			var rendered string
+			if src.markdown {
+				rendered = content.Render(row.Text).HTML
+			} else {
+				rendered = html.EscapeString(row.Text)
+			}
+			for e, count := range lex.count(rendered) {
+				mentions = append(mentions, ContentMention{
+					ContentType: src.typ,
+					ContentID:   row.ID,
+					Kind:        e.kind,
+					DexID:       e.dexID,
+					ParentID:    row.ParentID,
+					Count:       count,
+					IndexedAt:   now,
+				})
+			}
+		}
+		if err := s.repo.replaceMentions(ctx, src.typ, ids, mentions); err != nil {
+			return err
+		}
+
+		last := rows[len(rows)-1]
+		mark = watermark{UpdatedAt: last.UpdatedAt, ID: last.ID}
+		s.cache.Set(ctx, redisWatermarkKey(src.typ), mark.UpdatedAt.Format(time.RFC3339Nano)+"|"+mark.ID.String(), 0)
+
+		if len(rows) < indexBatchSize {
+			return nil
+		}
+	}
+}
+
+// watermark reads where the last scan of the source stopped; a missing or
+// unreadable one starts over from the beginning.
+func (s *serviceImpl) watermark(ctx context.Context, contentType string) watermark {
+	val, err := s.cache.Get(ctx, redisWatermarkKey(contentType)).Result()
+	if err != nil {
+		return watermark{}
+	}
+	at, id, ok := strings.Cut(val, "|")
+	if !ok {
+		return watermark{}
+	}
+	updatedAt, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return watermark{}
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return watermark{}
+	}
+	return watermark{UpdatedAt: updatedAt, ID: parsed}
+}
+
+/***********
+ * EXPORTS *
+ ***********/
+
+// ImportNames loads a name dump, see importCSV for its columns.
+func ImportNames(ctx context.Context, db *gorm.DB, redis *redis.Client, r io.Reader) (*NameImportResult, error) {
+	return newServ(newRepo(db), redis).importCSV(ctx, r)
+}
+
+// StartIndexWorker keeps the reverse index of mentions up to date, scanning
+// the content changed since its previous run every interval.
+func StartIndexWorker(db *gorm.DB, redis *redis.Client, interval time.Duration) {
+	svc := newServ(newRepo(db), redis)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			indexOnce(svc, redis)
+			<-ticker.C
+		}
+	}()
+}
+
+// indexOnce runs one scan on the instance holding the lock, so instances don't
+// race on the mentions and the watermarks.
+func indexOnce(svc service, redis *redis.Client) {
+	token, err := utils.AcquireLock(redis, redisIndexLockKey, indexLockTTL)
+	if err != nil {
+		log.Printf("dex mention indexing lock failed: %v", err)
+		return
+	}
+	if token == "" {
+		return // another instance is already on it
+	}
+	defer utils.ReleaseLock(redis, redisIndexLockKey, token)
+
+	ctx, cancel := context.WithTimeout(context.Background(), indexLockTTL)
+	defer cancel()
+
+	if err := svc.index(ctx); err != nil {
+		log.Printf("dex mention indexing failed: %v", err)
+	}
+}
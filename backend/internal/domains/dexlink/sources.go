@@ -0,0 +1,112 @@
+package dexlink
+
+import (
+	"pokemon/pkg/permalink"
+	"pokemon/pkg/publishing"
+
+	"github.com/google/uuid"
+)
+
+// source describes how one table is scanned for mentions. Its rows in from
+// are aliased as t; text is the Markdown scanned (plain text when markdown is
+// false) and visible the rule a mention must pass to be listed.
+type source struct {
+	typ      string
+	table    string
+	from     string
+	parentID string
+	title    string
+	text     string
+	markdown bool
+	visible  string
+	url      func(id uuid.UUID, parentID *uuid.UUID) string
+}
+
+var sources = []source{
+	{
+		typ:      ContentPost,
+		table:    "posts",
+		from:     "posts t",
+		parentID: "NULL::uuid",
+		title:    "t.title",
+		text:     "t.content",
+		markdown: true,
+		visible:  "t.deleted_at IS NULL AND " + publishing.LiveCondition("t"),
+		url: func(id uuid.UUID, _ *uuid.UUID) string {
+			return permalink.Post("", id)
+		},
+	},
+	{
+		typ:      ContentGuide,
+		table:    "game_guides",
+		from:     "game_guides t",
+		parentID: "NULL::uuid",
+		title:    "t.title",
+		text:     "coalesce(t.summary, '') || E'\\n\\n' || t.content",
+		markdown: true,
+		visible:  publishing.LiveCondition("t"),
+		url: func(id uuid.UUID, _ *uuid.UUID) string {
+			return permalink.Guide("", id)
+		},
+	},
+	{
+		typ:      ContentWalkthroughStep,
+		table:    "walkthrough_steps",
+		from:     "walkthrough_steps t JOIN walkthroughs p ON p.id = t.walkthrough_id",
+		parentID: "t.walkthrough_id",
+		title:    "p.title || ' – ' || t.title",
+		text:     "t.content",
+		markdown: true,
+		visible:  "TRUE",
+		url: func(_ uuid.UUID, parentID *uuid.UUID) string {
+			return permalink.Walkthrough("", *parentID)
+		},
+	},
+	{
+		typ:      ContentTopic,
+		table:    "topics",
+		from:     "topics t",
+		parentID: "NULL::uuid",
+		title:    "t.title",
+		text:     "t.content",
+		markdown: true,
+		visible:  "t.deleted_at IS NULL",
+		url: func(id uuid.UUID, _ *uuid.UUID) string {
+			return permalink.Topic("", id)
+		},
+	},
+	{
+		typ:      ContentTopicComment,
+		table:    "topic_comments",
+		from:     "topic_comments t JOIN topics p ON p.id = t.topic_id",
+		parentID: "t.topic_id",
+		title:    "p.title",
+		text:     "t.content",
+		markdown: true,
+		visible:  "t.deleted_at IS NULL AND p.deleted_at IS NULL",
+		url: func(id uuid.UUID, parentID *uuid.UUID) string {
+			return permalink.TopicComment("", *parentID, id)
+		},
+	},
+	{
+		typ:      ContentShout,
+		table:    "shouts",
+		from:     "shouts t",
+		parentID: "NULL::uuid",
+		title:    "left(t.content, 80)",
+		text:     "t.content",
+		visible:  "t.deleted_at IS NULL AND NOT t.is_flagged",
+		url: func(id uuid.UUID, _ *uuid.UUID) string {
+			return permalink.Shout("", id)
+		},
+	},
+}
+
+func sourceByType(typ string) (source, bool) {
+	for _, s := range sources {
+		if s.typ == typ {
+			return s, true
+		}
+	}
+	return source{}, false
+}
@@ -0,0 +1,142 @@
+package ebook
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"pokemon/internal/domains/walkthrough"
+	"pokemon/pkg/epub"
+	"strings"
+)
+
+// uploadsPrefix is the path uploaded files are served under, from the
+// upload directory.
+const uploadsPrefix = "/uploads/"
+
+const (
+	maxImageSize = 10 << 20
+	maxBookSize  = 50 << 20 // of all the images of a book
+)
+
+// builder assembles a book, embedding the uploaded images its content shows.
+// Remote images are left out: e-readers may be offline.
+type builder struct {
+	book   *epub.Book
+	base   string
+	host   string
+	root   *os.Root // nil when the upload directory can't be opened
+	images map[string]string
+	size   int
+}
+
+func newBuilder(uploads, base string) *builder {
+	b := &builder{book: &epub.Book{}, base: base, images: map[string]string{}}
+	if u, err := url.Parse(base); err == nil {
+		b.host = u.Host
+	}
+	if uploads != "" {
+		root, err := os.OpenRoot(uploads)
+		if err != nil {
+			log.Printf("epub export without images, cannot open %s: %v", uploads, err)
+		}
+		b.root = root
+	}
+	return b
+}
+
+func (b *builder) close() {
+	if b.root != nil {
+		b.root.Close()
+	}
+}
+
+// rewriter prepares rendered content for the book, prefixing its ids.
+func (b *builder) rewriter(prefix string) epub.Rewriter {
+	return epub.Rewriter{Image: b.image, Link: b.link, Prefix: prefix}
+}
+
+// image embeds the uploaded image at src once and returns where the book
+// keeps it.
+func (b *builder) image(src string) (string, bool) {
+	if b.root == nil {
+		return "", false
+	}
+	u, err := url.Parse(src)
+	if err != nil || (u.IsAbs() && u.Host != b.host) {
+		return "", false
+	}
+	name, ok := strings.CutPrefix(u.Path, uploadsPrefix)
+	if !ok || name == "" {
+		return "", false
+	}
+	if embedded, ok := b.images[name]; ok {
+		return embedded, true
+	}
+
+	ext := strings.ToLower(path.Ext(name))
+	mediaType, _, _ := strings.Cut(mime.TypeByExtension(ext), ";")
+	if !epub.ImageTypes[mediaType] {
+		return "", false
+	}
+	f, err := b.root.Open(filepath.FromSlash(name))
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxImageSize+1))
+	if err != nil || len(data) > maxImageSize || b.size+len(data) > maxBookSize {
+		return "", false
+	}
+
+	img := epub.Image{Name: fmt.Sprintf("image-%03d%s", len(b.book.Images)+1, ext), MediaType: mediaType, Data: data}
+	b.book.Images = append(b.book.Images, img)
+	b.images[name] = epub.Src(img.Name)
+	b.size += len(data)
+	return b.images[name], true
+}
+
+// cover makes the uploaded image at src the cover of the book.
+func (b *builder) cover(src string) {
+	embedded, ok := b.image(src)
+	if !ok {
+		return
+	}
+	for i := range b.book.Images {
+		if epub.Src(b.book.Images[i].Name) == embedded {
+			b.book.Images[i].Cover = true
+		}
+	}
+}
+
+// link makes site relative links absolute, as the book isn't on the site.
+func (b *builder) link(href string) string {
+	if strings.HasPrefix(href, "/") && !strings.HasPrefix(href, "//") {
+		return b.base + href
+	}
+	return href
+}
+
+// stepExtras renders the pictures and the checklist of a step, which aren't
+// part of its text.
+func (b *builder) stepExtras(step *walkthrough.WalkthroughStep) string {
+	var out strings.Builder
+	for _, media := range step.MediaURLs {
+		if src, ok := b.image(media); ok {
+			fmt.Fprintf(&out, "<figure><img src=\"%s\" alt=\"\"/></figure>\n", html.EscapeString(src))
+		}
+	}
+	if len(step.Checklist) > 0 {
+		out.WriteString("<h3>Checklist</h3>\n<ul class=\"checklist\">\n")
+		for _, item := range step.Checklist {
+			fmt.Fprintf(&out, "<li>☐ %s</li>\n", html.EscapeString(item.Label))
+		}
+		out.WriteString("</ul>\n")
+	}
+	return out.String()
+}
@@ -0,0 +1,31 @@
+package ebook
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	errNotFound = errors.New("nothing to export")
+	errEmpty    = errors.New("there is nothing to read in it yet")
+	errVersion  = errors.New("unknown version for this walkthrough")
+)
+
+// Exported kinds of books.
+const (
+	KindWalkthrough = "walkthrough"
+	KindSeries      = "series"
+)
+
+/*************
+ * RESPONSES *
+ *************/
+
+// Export is a packaged EPUB. Revision identifies the text it was built from,
+// so it changes whenever the content does.
+type Export struct {
+	Filename string
+	Revision string
+	Modified time.Time
+	Data     []byte
+}
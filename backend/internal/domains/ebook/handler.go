@@ -0,0 +1,79 @@
+package ebook
+
+import (
+	"errors"
+	"net/http"
+	"pokemon/internal/domains/dexlink"
+	"pokemon/pkg/epub"
+	"pokemon/pkg/permalink"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	s       service
+	siteURL string
+}
+
+// NewHandler exports books linking to siteURL, or to the address the request
+// came in on when it is empty. Images under /uploads/ are embedded from the
+// uploads directory.
+func NewHandler(db *gorm.DB, redis *redis.Client, siteURL, uploads string) *handler {
+	serv := newServ(newRepo(db, redis), dexlink.NewRenderer(db, redis), redis, uploads)
+	return &handler{s: serv, siteURL: siteURL}
+}
+
+// GET /ebooks/walkthroughs/:id?version=
+func (h *handler) walkthrough(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+	export, err := h.s.walkthrough(c.Context(), permalink.Base(c, h.siteURL), id, c.Query("version"))
+	return h.send(c, export, err)
+}
+
+// GET /ebooks/series/:id
+func (h *handler) series(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+	export, err := h.s.series(c.Context(), permalink.Base(c, h.siteURL), id)
+	return h.send(c, export, err)
+}
+
+// send serves the book as a download. Clients that already hold the current
+// revision get 304 Not Modified.
+func (h *handler) send(c *fiber.Ctx, export *Export, err error) error {
+	switch {
+	case errors.Is(err, errNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, errEmpty):
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, errVersion):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case err != nil:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	etag := `"` + export.Revision + `"`
+	c.Set(fiber.HeaderETag, etag)
+	if !export.Modified.IsZero() {
+		c.Set(fiber.HeaderLastModified, export.Modified.UTC().Format(http.TimeFormat))
+	}
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	for _, tag := range strings.Split(c.Get(fiber.HeaderIfNoneMatch), ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+	}
+
+	c.Attachment(export.Filename)
+	c.Set(fiber.HeaderContentType, epub.MediaType)
+	return c.Send(export.Data)
+}
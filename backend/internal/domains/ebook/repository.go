@@ -0,0 +1,92 @@
+package ebook
+
+import (
+	"context"
+	"errors"
+	"pokemon/internal/domains/guide"
+	"pokemon/internal/domains/series"
+	"pokemon/internal/domains/user"
+	"pokemon/internal/domains/walkthrough"
+	"pokemon/pkg/publishing"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type repository interface {
+	walkthrough(ctx context.Context, id uuid.UUID, version string) (*walkthrough.Walkthrough, error)
+	series(ctx context.Context, id uuid.UUID) (*series.Series, error)
+	guides(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]guide.GameGuide, error)
+	usernames(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]string, error)
+}
+
+type repositoryImpl struct {
+	db           *gorm.DB
+	walkthroughs *walkthrough.Library
+}
+
+func newRepo(db *gorm.DB, redis *redis.Client) repository {
+	return &repositoryImpl{db: db, walkthroughs: walkthrough.NewLibrary(db, redis)}
+}
+
+// walkthrough returns the walkthrough with its steps in order, narrowed down
+// to one version of the game unless version is empty.
+func (r *repositoryImpl) walkthrough(ctx context.Context, id uuid.UUID, version string) (*walkthrough.Walkthrough, error) {
+	wt, err := r.walkthroughs.ByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if wt == nil {
+		return nil, errNotFound
+	}
+	if version != "" {
+		if err := r.walkthroughs.FilterVersion(ctx, wt, version); err != nil {
+			return nil, errVersion
+		}
+	}
+	return wt, nil
+}
+
+func (r *repositoryImpl) series(ctx context.Context, id uuid.UUID) (*series.Series, error) {
+	var s series.Series
+	err := r.db.WithContext(ctx).
+		Preload("Owner").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		First(&s, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errNotFound
+	}
+	return &s, err
+}
+
+// guides returns the live guides among ids.
+func (r *repositoryImpl) guides(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]guide.GameGuide, error) {
+	byID := make(map[uuid.UUID]guide.GameGuide, len(ids))
+	if len(ids) == 0 {
+		return byID, nil
+	}
+	var list []guide.GameGuide
+	err := r.db.WithContext(ctx).
+		Preload("Tags").
+		Scopes(publishing.Live("game_guides")).
+		Where("id IN ?", ids).
+		Find(&list).Error
+	for _, g := range list {
+		byID[g.ID] = g
+	}
+	return byID, err
+}
+
+func (r *repositoryImpl) usernames(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]string, error) {
+	names := make(map[uuid.UUID]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+	var users []user.User
+	err := r.db.WithContext(ctx).Select("id", "username").Where("id IN ?", ids).Find(&users).Error
+	for _, u := range users {
+		names[u.ID] = u.Username
+	}
+	return names, err
+}
@@ -0,0 +1,9 @@
+package ebook
+
+import "github.com/gofiber/fiber/v2"
+
+func (h *handler) RegisterRoutes(router fiber.Router) {
+	group := router.Group("/ebooks")
+	group.Get("/walkthroughs/:id", h.walkthrough)
+	group.Get("/series/:id", h.series)
+}
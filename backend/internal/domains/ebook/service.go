@@ -0,0 +1,364 @@
+package ebook
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"pokemon/internal/domains/guide"
+	"pokemon/internal/domains/series"
+	"pokemon/internal/domains/walkthrough"
+	"pokemon/pkg/content"
+	"pokemon/pkg/epub"
+	"pokemon/pkg/language"
+	"pokemon/pkg/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// bookFormat is part of every revision, so changing how books are laid out
+// doesn't serve the books cached before.
+const bookFormat = "1"
+
+const (
+	exportTTL = 24 * time.Hour
+	// maxCachedSize keeps books heavy with images out of Redis.
+	maxCachedSize = 16 << 20
+)
+
+func redisExportKey(kind string, id uuid.UUID, revision string) string {
+	return "epub:" + kind + ":" + id.String() + ":" + revision
+}
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type service interface {
+	walkthrough(ctx context.Context, base string, id uuid.UUID, version string) (*Export, error)
+	series(ctx context.Context, base string, id uuid.UUID) (*Export, error)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type serviceImpl struct {
+	repo     repository
+	renderer content.Renderer
+	redis    *redis.Client
+	uploads  string
+}
+
+func newServ(repo repository, renderer content.Renderer, redis *redis.Client, uploads string) service {
+	return &serviceImpl{repo: repo, renderer: renderer, redis: redis, uploads: uploads}
+}
+
+// walkthrough exports every step of the walkthrough as a chapter, with its
+// pictures and checklist. Links point at base.
+func (s *serviceImpl) walkthrough(ctx context.Context, base string, id uuid.UUID, version string) (*Export, error) {
+	wt, err := s.repo.walkthrough(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	if len(wt.Steps) == 0 {
+		return nil, errEmpty
+	}
+
+	title := wt.Title
+	if wt.Version != "" {
+		title += " (" + wt.Version + ")"
+	}
+	export := &Export{
+		Filename: filename(title, KindWalkthrough),
+		Revision: revisionOf(base, walkthroughText(wt)),
+		Modified: walkthroughModified(wt),
+	}
+	return s.cached(ctx, KindWalkthrough, id, base, export, func(b *builder) error {
+		b.book.Title = title
+		b.book.Authors = []string{wt.User.Username}
+		b.book.Description = wt.Game + " walkthrough"
+		b.book.Subjects = wt.Tags
+		b.book.Published = wt.CreatedAt
+
+		renders := s.renderer.RenderAll(ctx, stepSources(wt.Steps))
+		for i, step := range wt.Steps {
+			rendered := renders[i]
+			body, err := b.rewriter("").XHTML(rendered.HTML)
+			if err != nil {
+				return err
+			}
+			b.book.Chapters = append(b.book.Chapters, epub.Chapter{
+				Title:    step.Title,
+				Body:     body + b.stepExtras(&step),
+				Sections: sections(rendered.TOC, ""),
+			})
+		}
+		return nil
+	})
+}
+
+// series exports the live guides and walkthroughs of the series, one chapter
+// each. Posts and news are left out: they date quickly and aren't meant to be
+// read offline.
+func (s *serviceImpl) series(ctx context.Context, base string, id uuid.UUID) (*Export, error) {
+	sr, err := s.repo.series(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var guideIDs []uuid.UUID
+	for _, item := range sr.Items {
+		if item.ContentType == series.ContentGuide {
+			guideIDs = append(guideIDs, item.ContentID)
+		}
+	}
+	guides, err := s.repo.guides(ctx, guideIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load everything first: the text decides whether the cached book is current
+	var parts []part
+	text := []string{sr.Title, sr.Description, sr.Owner.Username}
+	modified := sr.UpdatedAt
+	for _, item := range sr.Items {
+		switch item.ContentType {
+		case series.ContentGuide:
+			g, ok := guides[item.ContentID]
+			if !ok {
+				continue
+			}
+			parts = append(parts, part{guide: &g})
+			text = append(text, g.ID.String(), g.Title, g.Summary, g.Content, g.CoverImageURL, g.AuthorID.String())
+			modified = latest(modified, g.UpdatedAt)
+		case series.ContentWalkthrough:
+			wt, err := s.repo.walkthrough(ctx, item.ContentID, "")
+			if errors.Is(err, errNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if len(wt.Steps) == 0 {
+				continue
+			}
+			parts = append(parts, part{walkthrough: wt})
+			text = append(text, walkthroughText(wt)...)
+			modified = latest(modified, walkthroughModified(wt))
+		}
+	}
+	if len(parts) == 0 {
+		return nil, errEmpty
+	}
+
+	export := &Export{
+		Filename: filename(sr.Title, KindSeries),
+		Revision: revisionOf(base, text),
+		Modified: modified,
+	}
+	return s.cached(ctx, KindSeries, id, base, export, func(b *builder) error {
+		b.book.Title = sr.Title
+		b.book.Description = sr.Description
+		b.book.Authors = []string{sr.Owner.Username}
+		b.book.Published = sr.CreatedAt
+		if err := s.credit(ctx, b.book, sr.OwnerID, parts); err != nil {
+			return err
+		}
+
+		for i, p := range parts {
+			var chapter epub.Chapter
+			var err error
+			if p.guide != nil {
+				chapter, err = s.guideChapter(ctx, b, p.guide)
+				if i == 0 {
+					b.book.Language = p.guide.Language
+					b.cover(p.guide.CoverImageURL)
+				}
+			} else {
+				chapter, err = s.walkthroughChapter(ctx, b, p.walkthrough)
+			}
+			if err != nil {
+				return err
+			}
+			b.book.Chapters = append(b.book.Chapters, chapter)
+		}
+		return nil
+	})
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// part is a guide or a walkthrough of a series.
+type part struct {
+	guide       *guide.GameGuide
+	walkthrough *walkthrough.Walkthrough
+}
+
+// cached returns the book of the revision from the cache, or builds it with
+// build and caches it.
+func (s *serviceImpl) cached(ctx context.Context, kind string, id uuid.UUID, base string, export *Export, build func(b *builder) error) (*Export, error) {
+	key := redisExportKey(kind, id, export.Revision)
+	if data, err := s.redis.Get(ctx, key).Bytes(); err == nil {
+		export.Data = data
+		return export, nil
+	}
+
+	b := newBuilder(s.uploads, base)
+	defer b.close()
+	b.book.ID = "urn:uuid:" + id.String()
+	b.book.Language = language.Default
+	b.book.Modified = export.Modified
+	if err := build(b); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := epub.Write(&buf, b.book); err != nil {
+		return nil, err
+	}
+	export.Data = buf.Bytes()
+	if len(export.Data) <= maxCachedSize {
+		s.redis.Set(ctx, key, export.Data, exportTTL)
+	}
+	return export, nil
+}
+
+// credit lists the authors of the parts besides the owner as contributors.
+func (s *serviceImpl) credit(ctx context.Context, book *epub.Book, ownerID uuid.UUID, parts []part) error {
+	seen := map[uuid.UUID]bool{ownerID: true}
+	var ids []uuid.UUID
+	for _, p := range parts {
+		var id uuid.UUID
+		if p.guide != nil {
+			id = p.guide.AuthorID
+		} else {
+			id = p.walkthrough.UserID
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	names, err := s.repo.usernames(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if name := names[id]; name != "" {
+			book.Contributors = append(book.Contributors, name)
+		}
+	}
+	return nil
+}
+
+func (s *serviceImpl) guideChapter(ctx context.Context, b *builder, g *guide.GameGuide) (epub.Chapter, error) {
+	rendered := s.renderer.Render(ctx, g.Content)
+	body, err := b.rewriter("").XHTML(rendered.HTML)
+	if err != nil {
+		return epub.Chapter{}, err
+	}
+	if g.Summary != "" {
+		body = `<p class="summary"><em>` + html.EscapeString(g.Summary) + "</em></p>\n" + body
+	}
+	return epub.Chapter{Title: g.Title, Body: body, Sections: sections(rendered.TOC, "")}, nil
+}
+
+// walkthroughChapter puts every step in one chapter, each a section whose ids
+// are prefixed so they don't clash.
+func (s *serviceImpl) walkthroughChapter(ctx context.Context, b *builder, wt *walkthrough.Walkthrough) (epub.Chapter, error) {
+	chapter := epub.Chapter{Title: wt.Title}
+	var body strings.Builder
+	renders := s.renderer.RenderAll(ctx, stepSources(wt.Steps))
+	for i, step := range wt.Steps {
+		anchor := fmt.Sprintf("step-%d", i+1)
+		rendered := renders[i]
+		text, err := b.rewriter(anchor + "-").XHTML(rendered.HTML)
+		if err != nil {
+			return chapter, err
+		}
+		fmt.Fprintf(&body, "<section>\n<h2 id=\"%s\">%s</h2>\n%s%s</section>\n",
+			anchor, html.EscapeString(step.Title), text, b.stepExtras(&step))
+		chapter.Sections = append(chapter.Sections, epub.Section{Title: step.Title, Anchor: anchor})
+	}
+	chapter.Body = body.String()
+	return chapter, nil
+}
+
+// stepSources lists the Markdown of every step, to render them together.
+func stepSources(steps []walkthrough.WalkthroughStep) []string {
+	sources := make([]string, len(steps))
+	for i := range steps {
+		sources[i] = steps[i].Content
+	}
+	return sources
+}
+
+// sections lists the top level headings of a table of contents.
+func sections(toc []content.Heading, prefix string) []epub.Section {
+	top := 0
+	for _, h := range toc {
+		if top == 0 || h.Level < top {
+			top = h.Level
+		}
+	}
+	var list []epub.Section
+	for _, h := range toc {
+		if h.Level == top && h.ID != "" {
+			list = append(list, epub.Section{Title: h.Text, Anchor: prefix + h.ID})
+		}
+	}
+	return list
+}
+
+// walkthroughText is what goes in the book of a walkthrough.
+func walkthroughText(wt *walkthrough.Walkthrough) []string {
+	text := []string{wt.ID.String(), wt.Title, wt.Game, wt.Version, wt.User.Username, strings.Join(wt.Tags, ",")}
+	for _, step := range wt.Steps {
+		text = append(text, step.ID.String(), step.Title, step.Content, strings.Join(step.MediaURLs, ","))
+		for _, item := range step.Checklist {
+			text = append(text, item.Label)
+		}
+	}
+	return text
+}
+
+func walkthroughModified(wt *walkthrough.Walkthrough) time.Time {
+	modified := wt.UpdatedAt
+	for _, step := range wt.Steps {
+		modified = latest(modified, step.UpdatedAt)
+	}
+	return modified
+}
+
+// revisionOf hashes the text of a book and the address its links point at.
+func revisionOf(base string, text []string) string {
+	h := sha256.New()
+	for _, t := range append([]string{bookFormat, base}, text...) {
+		fmt.Fprintf(h, "%d:%s", len(t), t)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:12])
+}
+
+func filename(title, fallback string) string {
+	name := utils.Slugify(title)
+	if name == "" {
+		name = fallback
+	}
+	return name + ".epub"
+}
+
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
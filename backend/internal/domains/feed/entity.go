@@ -0,0 +1,38 @@
+package feed
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Kinds of content a feed is made of.
+const (
+	KindNews    = "news"
+	KindPost    = "post"
+	KindGuide   = "guide"
+	KindTopic   = "topic"
+	KindComment = "comment"
+)
+
+// entry is one published piece of content, as read from its own table.
+// Entries are what gets cached: links are built per request.
+type entry struct {
+	ID        uuid.UUID      `json:"id"`
+	Kind      string         `json:"kind"`
+	Title     string         `json:"title"`
+	Summary   string         `json:"summary"`
+	Body      string         `json:"body"`
+	Author    string         `json:"author"`
+	Tags      pq.StringArray `json:"tags" gorm:"type:text[]"`
+	TopicID   *uuid.UUID     `json:"topic_id,omitempty"` // comments only
+	Published time.Time      `json:"published"`
+	Updated   time.Time      `json:"updated"`
+}
+
+// source names the owner of a user or category feed.
+type source struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,130 @@
+package feed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"pokemon/pkg/content"
+	"pokemon/pkg/permalink"
+	"pokemon/pkg/syndication"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	s       service
+	siteURL string
+}
+
+// NewHandler serves feeds linking to siteURL, or to the address the request
+// came in on when it is empty.
+func NewHandler(db *gorm.DB, redis *redis.Client, siteURL string) *handler {
+	repo := newRepo(db)
+	serv := newServ(repo, content.NewRenderer(redis), redis)
+	return &handler{s: serv, siteURL: siteURL}
+}
+
+// GET /feeds/news.:format
+func (h *handler) news(c *fiber.Ctx) error {
+	feed, err := h.s.news(c.Context(), permalink.Base(c, h.siteURL))
+	return h.send(c, feed, err)
+}
+
+// GET /feeds/blog.:format?tag=
+func (h *handler) blog(c *fiber.Ctx) error {
+	feed, err := h.s.blog(c.Context(), permalink.Base(c, h.siteURL), c.Query("tag"))
+	return h.send(c, feed, err)
+}
+
+// GET /feeds/guides.:format?tag=
+func (h *handler) guides(c *fiber.Ctx) error {
+	feed, err := h.s.guides(c.Context(), permalink.Base(c, h.siteURL), c.Query("tag"))
+	return h.send(c, feed, err)
+}
+
+// GET /feeds/users/:id.:format
+func (h *handler) user(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user id")
+	}
+	feed, err := h.s.user(c.Context(), permalink.Base(c, h.siteURL), userID)
+	return h.send(c, feed, err)
+}
+
+// GET /feeds/forum/categories/:id.:format
+func (h *handler) category(c *fiber.Ctx) error {
+	categoryID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid category id")
+	}
+	feed, err := h.s.category(c.Context(), permalink.Base(c, h.siteURL), categoryID)
+	return h.send(c, feed, err)
+}
+
+// send encodes the feed in the requested format and mode. Clients that
+// already hold the current version get 304 Not Modified, by ETag or by date.
+func (h *handler) send(c *fiber.Ctx, feed *syndication.Feed, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, "feed not found")
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	mode := c.Query("mode", syndication.ModeFull)
+	if mode != syndication.ModeFull && mode != syndication.ModeSummary {
+		return fiber.NewError(fiber.StatusBadRequest, "mode must be full or summary")
+	}
+
+	feed.Self = permalink.Base(c, h.siteURL) + c.OriginalURL()
+	body, contentType, err := syndication.Encode(feed, c.Params("format"), mode)
+	if errors.Is(err, syndication.ErrUnknownFormat) {
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	updated := feed.Updated()
+
+	c.Set(fiber.HeaderETag, etag)
+	if !updated.IsZero() {
+		c.Set(fiber.HeaderLastModified, updated.UTC().Format(http.TimeFormat))
+	}
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+
+	if notModified(c, etag, updated) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Send(body)
+}
+
+// notModified checks the request's validators. If-None-Match takes
+// precedence over If-Modified-Since, as RFC 9110 requires.
+func notModified(c *fiber.Ctx, etag string, updated time.Time) bool {
+	if match := c.Get(fiber.HeaderIfNoneMatch); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if since := c.Get(fiber.HeaderIfModifiedSince); since != "" && !updated.IsZero() {
+		t, err := http.ParseTime(since)
+		return err == nil && !updated.Truncate(time.Second).After(t)
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package feed
+
+import (
+	"context"
+	"pokemon/pkg/publishing"
+	"sort"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type repository interface {
+	news(ctx context.Context, limit int) ([]entry, error)
+	posts(ctx context.Context, tag string, limit int) ([]entry, error)
+	guides(ctx context.Context, tag string, limit int) ([]entry, error)
+	byUser(ctx context.Context, userID uuid.UUID, limit int) ([]entry, error)
+	byCategory(ctx context.Context, categoryID uuid.UUID, limit int) ([]entry, error)
+
+	user(ctx context.Context, userID uuid.UUID) (*source, error)
+	category(ctx context.Context, categoryID uuid.UUID) (*source, error)
+	tag(ctx context.Context, table, slug string) (*source, error)
+}
+
+type repositoryImpl struct {
+	db *gorm.DB
+}
+
+func newRepo(db *gorm.DB) repository {
+	return &repositoryImpl{db: db}
+}
+
+// Scheduled items count as published when their time came. Publishing
+// doesn't always touch updated_at, so an item is never older than that.
+const (
+	publishedAt = "COALESCE(t.published_at, t.publish_at, t.created_at)"
+	updatedAt   = "GREATEST(t.updated_at, " + publishedAt + ")"
+)
+
+var (
+	newsQuery = `SELECT t.id, '` + KindNews + `' AS kind, t.title, t.sub_title AS summary, t.body, u.username AS author,
+			'{}'::text[] AS tags, ` + publishedAt + ` AS published, ` + updatedAt + ` AS updated
+		FROM news t JOIN users u ON u.id = t.user_id
+		WHERE t.deleted_at IS NULL AND ` + publishing.LiveCondition("t")
+
+	postsQuery = `SELECT t.id, '` + KindPost + `' AS kind, t.title, '' AS summary, t.content AS body, u.username AS author,
+			ARRAY(SELECT g.name FROM post_tags r JOIN tags g ON g.id = r.tag_id WHERE r.post_id = t.id ORDER BY g.name) AS tags,
+			` + publishedAt + ` AS published, ` + updatedAt + ` AS updated
+		FROM posts t JOIN users u ON u.id = t.user_id
+		WHERE t.deleted_at IS NULL AND ` + publishing.LiveCondition("t")
+
+	guidesQuery = `SELECT t.id, '` + KindGuide + `' AS kind, t.title, t.summary, t.content AS body, u.username AS author,
+			ARRAY(SELECT g.name FROM game_guide_tags_relation r JOIN game_guide_tags g ON g.id = r.game_guide_tag_id
+				WHERE r.game_guide_id = t.id ORDER BY g.name) AS tags,
+			` + publishedAt + ` AS published, ` + updatedAt + ` AS updated
+		FROM game_guides t JOIN users u ON u.id = t.author_id
+		WHERE ` + publishing.LiveCondition("t")
+
+	topicsQuery = `SELECT t.id, '` + KindTopic + `' AS kind, t.title, '' AS summary, t.content AS body, u.username AS author,
+			'{}'::text[] AS tags, t.created_at AS published, t.updated_at AS updated
+		FROM topics t JOIN users u ON u.id = t.user_id
+		WHERE t.deleted_at IS NULL`
+
+	commentsQuery = `SELECT t.id, '` + KindComment + `' AS kind, 'Re: ' || p.title AS title, '' AS summary, t.content AS body,
+			u.username AS author, '{}'::text[] AS tags, t.topic_id, t.created_at AS published, t.updated_at AS updated
+		FROM topic_comments t JOIN topics p ON p.id = t.topic_id JOIN users u ON u.id = t.user_id
+		WHERE t.deleted_at IS NULL AND p.deleted_at IS NULL`
+)
+
+func (r *repositoryImpl) news(ctx context.Context, limit int) ([]entry, error) {
+	var entries []entry
+	err := r.db.WithContext(ctx).
+		Raw(newsQuery+" ORDER BY published DESC LIMIT ?", limit).
+		Scan(&entries).Error
+	return entries, err
+}
+
+func (r *repositoryImpl) posts(ctx context.Context, tag string, limit int) ([]entry, error) {
+	query, args := postsQuery, []any{}
+	if tag != "" {
+		query += ` AND EXISTS (SELECT 1 FROM post_tags r JOIN tags g ON g.id = r.tag_id
+			WHERE r.post_id = t.id AND g.slug = ?)`
+		args = append(args, tag)
+	}
+
+	var entries []entry
+	err := r.db.WithContext(ctx).
+		Raw(query+" ORDER BY published DESC LIMIT ?", append(args, limit)...).
+		Scan(&entries).Error
+	return entries, err
+}
+
+func (r *repositoryImpl) guides(ctx context.Context, tag string, limit int) ([]entry, error) {
+	query, args := guidesQuery, []any{}
+	if tag != "" {
+		query += ` AND EXISTS (SELECT 1 FROM game_guide_tags_relation r JOIN game_guide_tags g ON g.id = r.game_guide_tag_id
+			WHERE r.game_guide_id = t.id AND g.slug = ?)`
+		args = append(args, tag)
+	}
+
+	var entries []entry
+	err := r.db.WithContext(ctx).
+		Raw(query+" ORDER BY published DESC LIMIT ?", append(args, limit)...).
+		Scan(&entries).Error
+	return entries, err
+}
+
+// byUser is everything the user published: posts, news, guides and topics.
+func (r *repositoryImpl) byUser(ctx context.Context, userID uuid.UUID, limit int) ([]entry, error) {
+	queries := []string{
+		newsQuery + " AND t.user_id = ?",
+		postsQuery + " AND t.user_id = ?",
+		guidesQuery + " AND t.author_id = ?",
+		topicsQuery + " AND t.user_id = ?",
+	}
+	return r.merge(ctx, queries, limit, userID)
+}
+
+// byCategory is the topics of the category and the comments on them.
+func (r *repositoryImpl) byCategory(ctx context.Context, categoryID uuid.UUID, limit int) ([]entry, error) {
+	queries := []string{
+		topicsQuery + " AND t.category_id = ?",
+		commentsQuery + " AND p.category_id = ?",
+	}
+	return r.merge(ctx, queries, limit, categoryID)
+}
+
+// merge runs every query with the same argument and keeps the latest
+// entries of all of them.
+func (r *repositoryImpl) merge(ctx context.Context, queries []string, limit int, arg any) ([]entry, error) {
+	var all []entry
+	for _, query := range queries {
+		var entries []entry
+		if err := r.db.WithContext(ctx).
+			Raw(query+" ORDER BY published DESC LIMIT ?", arg, limit).
+			Scan(&entries).Error; err != nil {
+			return nil, err
+		}
+		all = append(all, entries...)
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Published.After(all[j].Published)
+	})
+	if len(all) > limit {
+		all = all[:limit]
+	}
+	return all, nil
+}
+
+func (r *repositoryImpl) user(ctx context.Context, userID uuid.UUID) (*source, error) {
+	var src source
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Select("username AS name").
+		Where("id = ? AND deleted_at IS NULL AND active", userID).
+		Take(&src).Error
+	if err != nil {
+		return nil, err
+	}
+	return &src, nil
+}
+
+// tag finds the tag with the slug in a tag table, "tags" or "game_guide_tags".
+func (r *repositoryImpl) tag(ctx context.Context, table, slug string) (*source, error) {
+	var src source
+	err := r.db.WithContext(ctx).
+		Table(table).
+		Select("name").
+		Where("slug = ?", slug).
+		Take(&src).Error
+	if err != nil {
+		return nil, err
+	}
+	return &src, nil
+}
+
+func (r *repositoryImpl) category(ctx context.Context, categoryID uuid.UUID) (*source, error) {
+	var src source
+	err := r.db.WithContext(ctx).
+		Table("topic_categories").
+		Select("name, description").
+		Where("id = ?", categoryID).
+		Take(&src).Error
+	if err != nil {
+		return nil, err
+	}
+	return &src, nil
+}
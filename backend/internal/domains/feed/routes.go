@@ -0,0 +1,15 @@
+package feed
+
+import "github.com/gofiber/fiber/v2"
+
+// Every feed comes in three formats, chosen by extension: .rss, .atom and
+// .json. ?mode=summary leaves the full content out.
+func (h *handler) RegisterRoutes(app fiber.Router) {
+	feeds := app.Group("/feeds")
+
+	feeds.Get("/news.:format", h.news)
+	feeds.Get("/blog.:format", h.blog)
+	feeds.Get("/guides.:format", h.guides)
+	feeds.Get("/users/:id.:format", h.user)
+	feeds.Get("/forum/categories/:id.:format", h.category)
+}
@@ -0,0 +1,244 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"pokemon/pkg/content"
+	"pokemon/pkg/permalink"
+	"pokemon/pkg/syndication"
+	"pokemon/pkg/tagging"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	feedSize      = 30
+	feedCacheTTL  = 5 * time.Minute
+	summaryLength = 300
+)
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type service interface {
+	news(ctx context.Context, base string) (*syndication.Feed, error)
+	blog(ctx context.Context, base, tag string) (*syndication.Feed, error)
+	guides(ctx context.Context, base, tag string) (*syndication.Feed, error)
+	user(ctx context.Context, base string, userID uuid.UUID) (*syndication.Feed, error)
+	category(ctx context.Context, base string, categoryID uuid.UUID) (*syndication.Feed, error)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type serviceImpl struct {
+	repo     repository
+	renderer content.Renderer
+	redis    *redis.Client
+}
+
+func newServ(repo repository, renderer content.Renderer, redis *redis.Client) service {
+	return &serviceImpl{repo: repo, renderer: renderer, redis: redis}
+}
+
+func (s *serviceImpl) news(ctx context.Context, base string) (*syndication.Feed, error) {
+	entries, err := s.entries(ctx, "feed:news", func() ([]entry, error) {
+		return s.repo.news(ctx, feedSize)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.build(ctx, base, entries, syndication.Feed{
+		Title:       permalink.SiteName + " – News",
+		Description: "The latest news",
+		Link:        base + "/news",
+	}), nil
+}
+
+func (s *serviceImpl) blog(ctx context.Context, base, tag string) (*syndication.Feed, error) {
+	slug, tag, err := s.tag(ctx, "tags", tag)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.entries(ctx, "feed:blog:"+slug, func() ([]entry, error) {
+		return s.repo.posts(ctx, slug, feedSize)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	meta := syndication.Feed{
+		Title:       permalink.SiteName + " – Blog",
+		Description: "The latest blog posts",
+		Link:        base + "/blog",
+	}
+	if tag != "" {
+		meta.Title += " – " + tag
+		meta.Description = "The latest blog posts tagged " + tag
+	}
+	return s.build(ctx, base, entries, meta), nil
+}
+
+func (s *serviceImpl) guides(ctx context.Context, base, tag string) (*syndication.Feed, error) {
+	slug, tag, err := s.tag(ctx, "game_guide_tags", tag)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.entries(ctx, "feed:guides:"+slug, func() ([]entry, error) {
+		return s.repo.guides(ctx, slug, feedSize)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	meta := syndication.Feed{
+		Title:       permalink.SiteName + " – Guides",
+		Description: "The latest game guides",
+		Link:        base + "/guides",
+	}
+	if tag != "" {
+		meta.Title += " – " + tag
+		meta.Description = "The latest game guides tagged " + tag
+	}
+	return s.build(ctx, base, entries, meta), nil
+}
+
+func (s *serviceImpl) user(ctx context.Context, base string, userID uuid.UUID) (*syndication.Feed, error) {
+	owner, err := s.repo.user(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.entries(ctx, "feed:user:"+userID.String(), func() ([]entry, error) {
+		return s.repo.byUser(ctx, userID, feedSize)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.build(ctx, base, entries, syndication.Feed{
+		Title:       owner.Name + " – " + permalink.SiteName,
+		Description: "Posts, news, guides and topics by " + owner.Name,
+		Link:        permalink.User(base, userID),
+	}), nil
+}
+
+func (s *serviceImpl) category(ctx context.Context, base string, categoryID uuid.UUID) (*syndication.Feed, error) {
+	category, err := s.repo.category(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.entries(ctx, "feed:category:"+categoryID.String(), func() ([]entry, error) {
+		return s.repo.byCategory(ctx, categoryID, feedSize)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	description := category.Description
+	if description == "" {
+		description = "New topics and replies in " + category.Name
+	}
+	return s.build(ctx, base, entries, syndication.Feed{
+		Title:       permalink.SiteName + " Forum – " + category.Name,
+		Description: description,
+		Link:        permalink.Category(base, categoryID),
+	}), nil
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// tag resolves the tag a feed is filtered by to its slug and name, so every
+// spelling of a tag shares one cached feed. No tag gives empty ones; unknown
+// tags are gorm.ErrRecordNotFound.
+func (s *serviceImpl) tag(ctx context.Context, table, raw string) (string, string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return "", "", nil
+	}
+	_, slug, err := tagging.Normalize(raw)
+	if err != nil {
+		return "", "", gorm.ErrRecordNotFound
+	}
+	tag, err := s.repo.tag(ctx, table, slug)
+	if err != nil {
+		return "", "", err
+	}
+	return slug, tag.Name, nil
+}
+
+// entries reads the entries of a feed from the cache, loading them on a miss.
+// Feeds are allowed to lag behind by the cache TTL.
+func (s *serviceImpl) entries(ctx context.Context, key string, load func() ([]entry, error)) ([]entry, error) {
+	if cached, err := s.redis.Get(ctx, key).Bytes(); err == nil {
+		var entries []entry
+		if json.Unmarshal(cached, &entries) == nil {
+			return entries, nil
+		}
+	}
+
+	entries, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if data, err := json.Marshal(entries); err == nil {
+		s.redis.Set(ctx, key, data, feedCacheTTL)
+	}
+	return entries, nil
+}
+
+// build turns the entries into items linking to the site at base.
+func (s *serviceImpl) build(ctx context.Context, base string, entries []entry, feed syndication.Feed) *syndication.Feed {
+	sources := make([]string, len(entries))
+	for i, e := range entries {
+		sources[i] = e.Body
+	}
+	renders := s.renderer.RenderAll(ctx, sources)
+
+	feed.Items = make([]syndication.Item, 0, len(entries))
+	for i, e := range entries {
+		rendered := renders[i]
+
+		summary := strings.TrimSpace(e.Summary)
+		if summary == "" {
+			summary = rendered.Excerpt(summaryLength)
+		}
+
+		link := entryLink(base, e)
+		feed.Items = append(feed.Items, syndication.Item{
+			ID:          link,
+			Title:       e.Title,
+			Link:        link,
+			Author:      e.Author,
+			Summary:     summary,
+			ContentHTML: rendered.HTML,
+			Categories:  e.Tags,
+			Published:   e.Published,
+			Updated:     e.Updated,
+		})
+	}
+	return &feed
+}
+
+func entryLink(base string, e entry) string {
+	switch e.Kind {
+	case KindNews:
+		return permalink.News(base, e.ID)
+	case KindPost:
+		return permalink.Post(base, e.ID)
+	case KindGuide:
+		return permalink.Guide(base, e.ID)
+	case KindTopic:
+		return permalink.Topic(base, e.ID)
+	case KindComment:
+		if e.TopicID != nil {
+			return permalink.TopicComment(base, *e.TopicID, e.ID)
+		}
+	}
+	return base
+}
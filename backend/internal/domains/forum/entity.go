@@ -2,7 +2,9 @@ package forum
 
 import (
 	"errors"
+	"pokemon/internal/domains/taglink"
 	"pokemon/internal/domains/user"
+	"pokemon/pkg/content"
 	"strings"
 	"time"
 
@@ -19,7 +21,9 @@ type Topic struct {
 	UserID       uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
 	Title        string         `json:"title" gorm:"not null"`
 	Content      string         `json:"content" gorm:"not null"`
+	Rendered     *content.Rendered `json:"rendered,omitempty" gorm:"-"`
 	Pinned       bool           `json:"pinned" gorm:"default:false"`
+	CategoryID   *uuid.UUID     `json:"category_id,omitempty" gorm:"type:uuid;index"`
 
 	ViewCount    int64          `json:"view_count" gorm:"default:0"`
 	CommentCount int64          `json:"comment_count" gorm:"default:0"`
@@ -127,6 +131,9 @@ type TopicComment struct {
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
     DeletedAt    gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+
+	// Hashtags and mentions in the content, for the client to link
+	Entities     *taglink.Entities `json:"entities,omitempty" gorm:"-"`
 }
 
 type TopicCommentLike struct {
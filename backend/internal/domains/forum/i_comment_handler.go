@@ -1,6 +1,9 @@
 package forum
 
 import (
+	"pokemon/internal/domains/notification"
+	"pokemon/internal/domains/taglink"
+	"pokemon/internal/domains/user"
 	"pokemon/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -28,9 +31,26 @@ func (h *handler) createComment(c *fiber.Ctx) error {
 	if err := h.commentService.create(&comment); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	h.tagComment(c, &comment)
+	h.notifyReply(&comment)
 	return c.Status(fiber.StatusCreated).JSON(comment)
 }
 
+// notifyReply tells the author of the topic, or of the comment replied to.
+func (h *handler) notifyReply(comment *TopicComment) {
+	e := notification.Event{
+		Type:        notification.TypeReply,
+		ActorID:     comment.UserID,
+		SubjectType: notification.SubjectTopic,
+		SubjectID:   comment.TopicID,
+		ContentID:   &comment.ID,
+	}
+	if comment.ParentID != uuid.Nil {
+		e.SubjectType, e.SubjectID = notification.SubjectTopicComment, comment.ParentID
+	}
+	notification.Send(e)
+}
+
 func (h *handler) updateComment(c *fiber.Ctx) error {
 	var comment TopicComment
 	if err := c.BodyParser(&comment); err != nil {
@@ -64,6 +84,7 @@ func (h *handler) updateComment(c *fiber.Ctx) error {
 	if err := h.commentService.update(&comment); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	h.tagComment(c, &comment)
 	return c.Status(fiber.StatusAccepted).JSON(comment)
 }
 
@@ -104,11 +125,23 @@ func (h *handler) deleteComment(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
-	if existing.UserID != userID {
+	moderated := existing.UserID != userID
+	if moderated && !utils.HasRole(c, user.RoleModerator, user.RoleAdmin) {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
 	}
 	if err := h.commentService.delete(commentID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	h.tagger.Remove(c.Context(), taglink.ContentTopicComment, commentID)
+	if moderated {
+		notification.Send(notification.Event{Type: notification.TypeModeration, ActorID: userID, SubjectType: notification.SubjectTopicComment, SubjectID: commentID, Note: notification.NoteRemoved})
+	}
 	return c.SendStatus(fiber.StatusNoContent)
 }
+
+// tagComment stores the hashtags and mentions of a saved comment.
+func (h *handler) tagComment(c *fiber.Ctx, comment *TopicComment) {
+	comment.Entities = h.tagger.Sync(c.Context(), taglink.Ref{
+		Type: taglink.ContentTopicComment, ID: comment.ID, ParentID: &comment.TopicID, AuthorID: comment.UserID,
+	}, comment.Content)
+}
@@ -2,6 +2,7 @@ package forum
 
 import (
 	"net/http"
+	"pokemon/internal/domains/notification"
 	"pokemon/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -24,6 +25,9 @@ func (h *handler) createLike(c *fiber.Ctx) error {
 	if err := h.commentLikeService.create(&like); err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	if like.Like {
+		notification.Send(notification.Event{Type: notification.TypeLike, ActorID: userID, SubjectType: notification.SubjectTopicComment, SubjectID: like.CommentID})
+	}
 	return c.Status(http.StatusCreated).JSON(like)
 }
 
@@ -2,6 +2,7 @@ package forum
 
 import (
 	"errors"
+	"pokemon/internal/domains/notification"
 	"pokemon/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -58,6 +59,9 @@ func (h *handler) createTopicLike(c *fiber.Ctx) error {
 	if err := h.likeService.create(like); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	if like.Like {
+		notification.Send(notification.Event{Type: notification.TypeLike, ActorID: userID, SubjectType: notification.SubjectTopic, SubjectID: topicID})
+	}
 
 	return c.SendStatus(fiber.StatusCreated)
 }
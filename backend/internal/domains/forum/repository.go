@@ -21,6 +21,7 @@ type topicRepository interface {
 	create(topic *Topic) error
 	getByID(id uuid.UUID) (*Topic, error)
 	listByUser(userID uuid.UUID, limit, offset int) ([]Topic, error)
+	listByCategory(categoryID uuid.UUID, limit, offset int) ([]Topic, error)
 	list(limit, offset int) ([]Topic, error)
 	update(topic *Topic) error
 	delete(id uuid.UUID) error
@@ -60,6 +61,18 @@ func (r *repository) listByUser(userID uuid.UUID, limit, offset int) ([]Topic, e
 	return topics, err
 }
 
+func (r *repository) listByCategory(categoryID uuid.UUID, limit, offset int) ([]Topic, error) {
+	var topics []Topic
+	err := r.db.
+		Preload("User").
+		Order("created_at DESC").
+		Where("category_id = ?", categoryID).
+		Limit(limit).
+		Offset(offset).
+		Find(&topics).Error
+	return topics, err
+}
+
 func (r *repository) list(limit, offset int) ([]Topic, error) {
 	var topics []Topic
 	err := r.db.
@@ -32,6 +32,7 @@ func (h *handler) RegisterRoutes(app fiber.Router) {
 	categoryGroup := forumGroup.Group("/categories")
 	categoryGroup.Get("/", h.listCategories)
 	categoryGroup.Get("/:id", h.getCategoryByID)
+	categoryGroup.Get("/:id/topics", h.listTopicByCategory)
 	categoryGroup.Use(middleware.AuthRequired())
 	categoryGroup.Post("/", h.createCategory)
 	categoryGroup.Put("/:id", h.updateCategory)
@@ -2,6 +2,8 @@ package forum
 
 import (
 	"errors"
+	"pokemon/internal/domains/dexlink"
+	"pokemon/internal/domains/taglink"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
@@ -17,11 +19,12 @@ type handler struct {
 	commentLikeService commentLikeService
 	viewService topicViewService
 	likeService topicLikeService
+	tagger *taglink.Tagger
 }
 
 func NewHandler(db *gorm.DB, redis *redis.Client) *handler {
 	repo := newTopicRepository(db)
-	service := newTopicService(repo, redis)
+	service := newTopicService(repo, dexlink.NewRenderer(db, redis), redis)
 
 	categoryRepo := newTopicCategoryRepository(db)
 	categoryService := newTopicCategoryService(categoryRepo, redis)
@@ -45,6 +48,7 @@ func NewHandler(db *gorm.DB, redis *redis.Client) *handler {
 		commentLikeService: commentLikeService,
 		viewService: viewService,
 		likeService: likeService,
+		tagger: taglink.NewTagger(db, redis),
 	}
 }
 
@@ -149,3 +153,21 @@ func (h *handler) listTopicByUser(c *fiber.Ctx) error {
 
 	return c.JSON(topics)
 }
+
+// GET /categories/:id/topics
+func (h *handler) listTopicByCategory(c *fiber.Ctx) error {
+	categoryID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid category id format")
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	topics, err := h.s.listByCategory(categoryID, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(topics)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"pokemon/pkg/content"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,11 +17,13 @@ type topicService interface {
 	update(topic *Topic) error
 	delete(id string) error
 	listByUser(userID uuid.UUID, limit, offset int) ([]Topic, error)
+	listByCategory(categoryID uuid.UUID, limit, offset int) ([]Topic, error)
 	list(limit, offset int) ([]Topic, error)
 }
 
 type service struct {
 	repo topicRepository
+	renderer content.Renderer
 	redis *redis.Client
 }
 
@@ -28,12 +31,16 @@ func redisTopicKey(ID uuid.UUID) string {
 	return fmt.Sprintf("topic:%s", ID)
 }
 
-func newTopicService(repo topicRepository, redis *redis.Client) topicService {
-	return &service{repo: repo, redis: redis}
+func newTopicService(repo topicRepository, renderer content.Renderer, redis *redis.Client) topicService {
+	return &service{repo: repo, renderer: renderer, redis: redis}
 }
 
 func (s *service) create(topic *Topic) error {
-	return s.repo.create(topic)
+	if err := s.repo.create(topic); err != nil {
+		return err
+	}
+	s.render(topic)
+	return nil
 }
 
 func (s *service) getByID(id string) (*Topic, error) {
@@ -41,7 +48,12 @@ func (s *service) getByID(id string) (*Topic, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid UUID: %w", err)
 	}
-	return s.repo.getByID(uid)
+	topic, err := s.repo.getByID(uid)
+	if err != nil {
+		return nil, err
+	}
+	s.render(topic)
+	return topic, nil
 }
 
 func (s *service) update(topic *Topic) error {
@@ -53,6 +65,7 @@ func (s *service) update(topic *Topic) error {
 	if err != nil {
 		return err
 	}
+	s.render(topic)
 
 	// Invalidate Redis
 	s.redis.Del(context.Background(), redisTopicKey(topic.ID))
@@ -69,11 +82,34 @@ func (s *service) delete(id string) error {
 }
 
 func (s *service) listByUser(userID uuid.UUID, limit, offset int) ([]Topic, error) {
-	return s.repo.listByUser(userID, limit, offset)
+	topics, err := s.repo.listByUser(userID, limit, offset)
+	return s.renderAll(topics), err
+}
+
+func (s *service) listByCategory(categoryID uuid.UUID, limit, offset int) ([]Topic, error) {
+	topics, err := s.repo.listByCategory(categoryID, limit, offset)
+	return s.renderAll(topics), err
 }
 
 func (s *service) list(limit, offset int) ([]Topic, error) {
-	return s.repo.list(limit, offset)
+	topics, err := s.repo.list(limit, offset)
+	return s.renderAll(topics), err
+}
+
+// render attaches the sanitized HTML of the Markdown content to the topic.
+func (s *service) render(topic *Topic) {
+	topic.Rendered = s.renderer.Render(context.Background(), topic.Content)
+}
+
+func (s *service) renderAll(topics []Topic) []Topic {
+	sources := make([]string, len(topics))
+	for i := range topics {
+		sources[i] = topics[i].Content
+	}
+	for i, rendered := range s.renderer.RenderAll(context.Background(), sources) {
+		topics[i].Rendered = rendered
+	}
+	return topics
 }
 
 /*************************
@@ -0,0 +1,67 @@
+package game
+
+import (
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GET /games/:id/locations
+func (h *handler) listLocations(c *fiber.Ctx) error {
+	list, err := h.encounterSvc.locations(c.Context(), c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": len(list),
+		"items": list,
+	})
+}
+
+// GET /games/:id/locations/:location (id or slug)
+func (h *handler) getLocationEncounters(c *fiber.Ctx) error {
+	loc, list, err := h.encounterSvc.byLocation(c.Context(), c.Params("id"), c.Params("location"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "location not found")
+	}
+	return c.JSON(fiber.Map{
+		"location": loc,
+		"total":    len(list),
+		"items":    list,
+	})
+}
+
+// GET /games/:id/encounters/:pokemon_id
+func (h *handler) getPokemonEncounters(c *fiber.Ctx) error {
+	pokemonID, err := c.ParamsInt("pokemon_id")
+	if err != nil || pokemonID <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid pokemon id")
+	}
+
+	list, err := h.encounterSvc.byPokemon(c.Context(), c.Params("id"), pokemonID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": len(list),
+		"items": list,
+	})
+}
+
+// GET /games/:id/pokedex/missing
+func (h *handler) listMissingPokemon(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	limit, offset := utils.ParsePagination(c)
+	list, total, err := h.encounterSvc.missing(c.Context(), userID, c.Params("id"), limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "game not found")
+	}
+	return c.JSON(fiber.Map{
+		"total": total,
+		"items": list,
+	})
+}
@@ -0,0 +1,307 @@
+package game
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"pokemon/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type encounterService interface {
+	locations(ctx context.Context, gameID string) ([]Location, error)
+	byPokemon(ctx context.Context, gameID string, pokemonID int) ([]Encounter, error)
+	byLocation(ctx context.Context, gameID, locationRef string) (*Location, []Encounter, error)
+	missing(ctx context.Context, userID uuid.UUID, gameID string, limit, offset int) ([]MissingPokemon, int64, error)
+
+	importCSV(ctx context.Context, r io.Reader) (*EncounterImportResult, error)
+}
+
+/********************
+ * REDIS KEY UTILS  *
+ ********************/
+
+func redisEncounterKey(gameID string, pokemonID int) string {
+	return fmt.Sprintf("game:%s:encounters:%d", gameID, pokemonID)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type encounterServiceImpl struct {
+	db       encounterRepository
+	pokedex  gamePokedexRepository
+	versions versionRepository
+	cache    *redis.Client
+}
+
+func newEncounterService(repo encounterRepository, pokedex gamePokedexRepository, versions versionRepository, cache *redis.Client) encounterService {
+	return &encounterServiceImpl{db: repo, pokedex: pokedex, versions: versions, cache: cache}
+}
+
+func (s *encounterServiceImpl) locations(ctx context.Context, gameID string) ([]Location, error) {
+	return s.db.listLocations(ctx, gameID)
+}
+
+// Encounter data only changes on import, so species lookups are cached for an hour.
+func (s *encounterServiceImpl) byPokemon(ctx context.Context, gameID string, pokemonID int) ([]Encounter, error) {
+	cacheKey := redisEncounterKey(gameID, pokemonID)
+
+	val, err := s.cache.Get(ctx, cacheKey).Result()
+	if err == nil {
+		var encounters []Encounter
+		if err := json.Unmarshal([]byte(val), &encounters); err == nil {
+			return encounters, nil
+		}
+	}
+
+	encounters, err := s.db.listByPokemon(ctx, gameID, []int{pokemonID})
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, _ := json.Marshal(encounters)
+	s.cache.Set(ctx, cacheKey, bytes, time.Hour)
+
+	return encounters, nil
+}
+
+func (s *encounterServiceImpl) byLocation(ctx context.Context, gameID, locationRef string) (*Location, []Encounter, error) {
+	loc, err := s.db.getLocation(ctx, gameID, locationRef)
+	if err != nil {
+		return nil, nil, err
+	}
+	encounters, err := s.db.listByLocation(ctx, loc.ID.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	return loc, encounters, nil
+}
+
+// missing lists the Pokémon available in the game that the user hasn't
+// captured yet, each with the places it can be found.
+func (s *encounterServiceImpl) missing(ctx context.Context, userID uuid.UUID, gameID string, limit, offset int) ([]MissingPokemon, int64, error) {
+	game, err := s.pokedex.getGame(ctx, gameID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var captured []byte
+	dex, err := s.pokedex.getByUserAndGame(ctx, userID.String(), gameID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, 0, err
+	}
+	if dex != nil {
+		captured = dex.Captured
+	}
+
+	var expansions []Expansion
+	if game.VersionGroupID != nil {
+		if expansions, err = s.versions.listExpansions(ctx, *game.VersionGroupID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	start := int(game.DexStartID)
+	var ids []int
+	for _, id := range game.availablePokemon(expansions) {
+		if !isDexBitSet(captured, start, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	total := int64(len(ids))
+	if offset >= len(ids) {
+		return []MissingPokemon{}, total, nil
+	}
+	ids = ids[offset:min(offset+limit, len(ids))]
+
+	encounters, err := s.db.listByPokemon(ctx, gameID, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	byID := make(map[int][]Encounter, len(ids))
+	for _, e := range encounters {
+		byID[e.PokemonID] = append(byID[e.PokemonID], e)
+	}
+
+	result := make([]MissingPokemon, 0, len(ids))
+	for _, id := range ids {
+		found := byID[id]
+		if found == nil {
+			found = []Encounter{}
+		}
+		result = append(result, MissingPokemon{PokemonID: id, Encounters: found})
+	}
+	return result, total, nil
+}
+
+/**************
+ * CSV IMPORT *
+ **************/
+
+type EncounterImportResult struct {
+	Games      int `json:"games"`
+	Locations  int `json:"locations"`
+	Encounters int `json:"encounters"`
+}
+
+type gameEncounters struct {
+	locations  map[string]*Location
+	encounters []Encounter
+}
+
+// importCSV replaces the encounters of every game present in the dump.
+//
+// Columns, in any order: game, location, region, pokemon_id, method,
+// min_level, max_level, rate, time_of_day, version. Only game, location,
+// pokemon_id and method are required; game holds either the id or the name.
+func (s *encounterServiceImpl) importCSV(ctx context.Context, r io.Reader) (*EncounterImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	col := make(map[string]int, len(header))
+	for i, name := range header {
+		col[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"game", "location", "pokemon_id", "method"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("missing required column %q", name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := col[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	number := func(record []string, name string) (int, error) {
+		value := field(record, name)
+		if value == "" {
+			return 0, nil
+		}
+		return strconv.Atoi(value)
+	}
+
+	games := map[string]*Game{}
+	parsed := map[uuid.UUID]*gameEncounters{}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		ref := field(record, "game")
+		game, ok := games[ref]
+		if !ok {
+			if game, err = s.db.findGame(ctx, ref); err != nil {
+				return nil, fmt.Errorf("line %d: unknown game %q", line, ref)
+			}
+			games[ref] = game
+		}
+
+		enc := Encounter{
+			ID:        uuid.New(),
+			GameID:    game.ID,
+			Method:    strings.ToLower(field(record, "method")),
+			TimeOfDay: strings.ToLower(field(record, "time_of_day")),
+			Version:   field(record, "version"),
+		}
+		if enc.PokemonID, err = number(record, "pokemon_id"); err != nil || enc.PokemonID <= 0 {
+			return nil, fmt.Errorf("line %d: invalid pokemon_id", line)
+		}
+		if enc.MinLevel, err = number(record, "min_level"); err != nil {
+			return nil, fmt.Errorf("line %d: invalid min_level", line)
+		}
+		if enc.MaxLevel, err = number(record, "max_level"); err != nil {
+			return nil, fmt.Errorf("line %d: invalid max_level", line)
+		}
+		if enc.Rate, err = number(record, "rate"); err != nil {
+			return nil, fmt.Errorf("line %d: invalid rate", line)
+		}
+		if enc.Method == "" {
+			return nil, fmt.Errorf("line %d: method is required", line)
+		}
+		if enc.TimeOfDay == "" {
+			enc.TimeOfDay = "any"
+		}
+		if enc.MaxLevel < enc.MinLevel {
+			enc.MaxLevel = enc.MinLevel
+		}
+
+		ge := parsed[game.ID]
+		if ge == nil {
+			ge = &gameEncounters{locations: map[string]*Location{}}
+			parsed[game.ID] = ge
+		}
+
+		name := field(record, "location")
+		slug := utils.Slugify(name)
+		if slug == "" {
+			return nil, fmt.Errorf("line %d: location is required", line)
+		}
+		loc := ge.locations[slug]
+		if loc == nil {
+			loc = &Location{ID: uuid.New(), GameID: game.ID, Name: name, Slug: slug, Region: field(record, "region")}
+			ge.locations[slug] = loc
+		}
+		enc.LocationID = loc.ID
+
+		ge.encounters = append(ge.encounters, enc)
+	}
+
+	result := &EncounterImportResult{}
+	for gameID, ge := range parsed {
+		locations := make([]Location, 0, len(ge.locations))
+		for _, loc := range ge.locations {
+			locations = append(locations, *loc)
+		}
+		if err := s.db.replaceForGame(ctx, gameID, locations, ge.encounters); err != nil {
+			return nil, err
+		}
+		s.clearCache(ctx, gameID.String())
+
+		result.Games++
+		result.Locations += len(locations)
+		result.Encounters += len(ge.encounters)
+	}
+	return result, nil
+}
+
+func (s *encounterServiceImpl) clearCache(ctx context.Context, gameID string) {
+	iter := s.cache.Scan(ctx, 0, fmt.Sprintf("game:%s:encounters:*", gameID), 100).Iterator()
+	for iter.Next(ctx) {
+		s.cache.Del(ctx, iter.Val())
+	}
+}
+
+// ImportEncounters loads an encounter CSV dump, replacing the existing data of
+// every game it mentions.
+func ImportEncounters(ctx context.Context, db *gorm.DB, redis *redis.Client, r io.Reader) (*EncounterImportResult, error) {
+	svc := newEncounterService(newEncounterRepo(db), newGamePokedexRepo(db), newVersionRepo(db), redis)
+	return svc.importCSV(ctx, r)
+}
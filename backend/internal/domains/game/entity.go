@@ -1,10 +1,13 @@
 package game
 
 import (
+	"math/bits"
 	"pokemon/internal/domains/user"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
@@ -23,6 +26,10 @@ type Game struct {
 	// Bitmask: true if Pokémon is present in game
 	Pokedex    []byte `gorm:"type:bytea"`
 
+	// e.g. Scarlet and Violet both belong to the "scarlet-violet" group
+	VersionGroupID *uuid.UUID    `json:"version_group_id" gorm:"type:uuid;index"`
+	VersionGroup   *VersionGroup `json:"version_group,omitempty" gorm:"foreignKey:VersionGroupID"`
+
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 }
@@ -44,6 +51,184 @@ type GamePokedex struct {
 	DeletedAt      gorm.DeletedAt `json:"deleted_at"`
 }
 
+/******************
+ * VERSION GROUPS *
+ ******************/
+
+type VersionGroup struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Name       string    `json:"name" gorm:"type:text;not null"`
+	Slug       string    `json:"slug" gorm:"type:varchar(120);uniqueIndex;not null"`
+	Generation int       `json:"generation" gorm:"not null"`
+	Region     string    `json:"region" gorm:"type:varchar(60)"`
+
+	Games      []Game      `json:"games,omitempty" gorm:"foreignKey:VersionGroupID"`
+	Expansions []Expansion `json:"expansions,omitempty" gorm:"foreignKey:VersionGroupID;constraint:OnDelete:CASCADE"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Expansion is a DLC (e.g. The Teal Mask) that adds Pokémon to every game of its group.
+type Expansion struct {
+	ID             uuid.UUID     `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	VersionGroupID uuid.UUID     `json:"version_group_id" gorm:"type:uuid;not null;index"`
+	Name           string        `json:"name" gorm:"type:text;not null"`
+	Slug           string        `json:"slug" gorm:"type:varchar(120);not null"`
+	ReleasedAt     time.Time     `json:"released_at"`
+	PokemonIDs     pq.Int64Array `json:"pokemon_ids" gorm:"type:integer[]"` // National Dex numbers added by the DLC
+}
+
+// TransferLink is a directed edge of the transfer graph: Pokémon from the
+// From group can be sent to the To group (Pal Park, Poké Transfer, Bank, HOME...).
+type TransferLink struct {
+	ID          uuid.UUID    `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	FromGroupID uuid.UUID    `json:"from_group_id" gorm:"type:uuid;not null;uniqueIndex:idx_transfer_link"`
+	FromGroup   VersionGroup `json:"-" gorm:"foreignKey:FromGroupID;constraint:OnDelete:CASCADE"`
+	ToGroupID   uuid.UUID    `json:"to_group_id" gorm:"type:uuid;not null;uniqueIndex:idx_transfer_link"`
+	ToGroup     VersionGroup `json:"-" gorm:"foreignKey:ToGroupID;constraint:OnDelete:CASCADE"`
+	Via         string       `json:"via" gorm:"type:varchar(40);not null"` // pal_park, poke_transfer, bank, home...
+	Notes       string       `json:"notes" gorm:"type:text"`
+}
+
+/**************
+ * ENCOUNTERS *
+ **************/
+
+type Location struct {
+	ID     uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	GameID uuid.UUID `json:"game_id" gorm:"type:uuid;not null;uniqueIndex:idx_location_game_slug"`
+	Name   string    `json:"name" gorm:"type:text;not null"`
+	Slug   string    `json:"slug" gorm:"type:varchar(120);not null;uniqueIndex:idx_location_game_slug"`
+	Region string    `json:"region" gorm:"type:varchar(60)"`
+}
+
+type Encounter struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	GameID     uuid.UUID `json:"game_id" gorm:"type:uuid;not null;index:idx_encounter_game_pokemon"`
+	LocationID uuid.UUID `json:"location_id" gorm:"type:uuid;not null;index"`
+	Location   Location  `json:"location" gorm:"foreignKey:LocationID;constraint:OnDelete:CASCADE"`
+	PokemonID  int       `json:"pokemon_id" gorm:"type:int;not null;index:idx_encounter_game_pokemon"`
+
+	Method    string `json:"method" gorm:"type:varchar(40);not null"` // walk, surf, old-rod, gift, raid...
+	MinLevel  int    `json:"min_level" gorm:"type:int"`
+	MaxLevel  int    `json:"max_level" gorm:"type:int"`
+	Rate      int    `json:"rate" gorm:"type:int"`                              // percent, 0 for fixed encounters
+	TimeOfDay string `json:"time_of_day" gorm:"type:varchar(20);default:'any'"` // any, morning, day, night...
+	Version   string `json:"version" gorm:"type:varchar(40)"`                   // empty when not version exclusive
+}
+
+/****************
+ * API RESPONSE *
+ ****************/
+
+type MissingPokemon struct {
+	PokemonID  int         `json:"pokemon_id"`
+	Encounters []Encounter `json:"encounters"` // empty when the Pokémon can't be caught in the wild
+}
+
+type LeaderboardEntry struct {
+	Rank     int64     `json:"rank"` // 1-based position inside the requested view
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Score    int64     `json:"score"` // captured or shiny-captured count
+}
+
+type TransferStep struct {
+	FromGroupID uuid.UUID `json:"from_group_id"`
+	ToGroupID   uuid.UUID `json:"to_group_id"`
+	Via         string    `json:"via"`
+}
+
+// DexFeeder is one of the user's pokedexes that can send Pokémon to the target game.
+type DexFeeder struct {
+	Pokedex GamePokedex    `json:"pokedex"`
+	Path    []TransferStep `json:"path"` // empty when both games share a version group
+}
+
+type PlannedTransfer struct {
+	PokemonID  int            `json:"pokemon_id"`
+	FromGameID uuid.UUID      `json:"from_game_id"`
+	FromGame   string         `json:"from_game"`
+	Path       []TransferStep `json:"path"`
+}
+
+// LivingDexPlan says, for every Pokémon missing from the target game, which
+// owned game can provide it, and which ones still have to be caught.
+type LivingDexPlan struct {
+	GameID     uuid.UUID         `json:"game_id"`
+	Available  int               `json:"available"`
+	Owned      int               `json:"owned"`
+	ToTransfer []PlannedTransfer `json:"to_transfer"`
+	ToCatch    []int             `json:"to_catch"`
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// CapturedCount returns how many Pokémon are flagged as captured.
+func (d *GamePokedex) CapturedCount() int64 {
+	return countBits(d.Captured)
+}
+
+// ShinyCapturedCount returns how many Pokémon are flagged as shiny-captured.
+func (d *GamePokedex) ShinyCapturedCount() int64 {
+	return countBits(d.ShinyCaptured)
+}
+
+// availablePokemon lists the National Dex numbers obtainable in the game,
+// including the ones added by its group's expansions, even from outside the
+// game's own dex range. An empty bitmask means the whole dex range is
+// available.
+func (g *Game) availablePokemon(expansions []Expansion) []int {
+	start, end := int(g.DexStartID), int(g.DexEndID)
+	extra := expansionPokemon(expansions)
+
+	var ids []int
+	for _, id := range makeRange(start, end) {
+		if len(g.Pokedex) == 0 || isDexBitSet(g.Pokedex, start, id) || extra[id] {
+			ids = append(ids, id)
+		}
+	}
+	for id := range extra {
+		if id < start || id > end {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// registrable tells whether the Pokémon can be marked in a pokedex of the
+// game: it is in the game's dex range or added by one of its expansions.
+func (g *Game) registrable(id int, expansions []Expansion) bool {
+	if id >= int(g.DexStartID) && id <= int(g.DexEndID) {
+		return true
+	}
+	return expansionPokemon(expansions)[id]
+}
+
+func expansionPokemon(expansions []Expansion) map[int]bool {
+	ids := map[int]bool{}
+	for _, e := range expansions {
+		for _, id := range e.PokemonIDs {
+			if id > 0 && id < maxNationalDex {
+				ids[int(id)] = true
+			}
+		}
+	}
+	return ids
+}
+
+func countBits(data []byte) int64 {
+	total := 0
+	for _, b := range data {
+		total += bits.OnesCount8(b)
+	}
+	return int64(total)
+}
+
 func dexBitmaskSize(startID, endID int) int {
 	numBits := (endID - startID + 1)
 	return (numBits + 7) / 8
@@ -61,14 +246,32 @@ func createGameBitmask(startID, endID int, availableIDs []int) []byte {
 	return data
 }
 
+// maxNationalDex bounds the National Dex numbers a pokedex bitmask holds.
+// Bits are offsets from the game's DexStartID; numbers below it, which only
+// expansions bring in, are stored past the bound so they never collide with
+// the numbers above the range.
+const maxNationalDex = 2048
+
+func dexIndex(dexStart, pokeID int) int {
+	switch {
+	case pokeID <= 0 || pokeID >= maxNationalDex:
+		return -1
+	case pokeID < dexStart:
+		return maxNationalDex - dexStart + pokeID
+	}
+	return pokeID - dexStart
+}
+
 func setDexBit(data []byte, dexStart, pokeID int) []byte {
-	index := pokeID - dexStart
+	index := dexIndex(dexStart, pokeID)
+	if index < 0 {
+		return data
+	}
 	return setBit(data, index)
 }
 
 func isDexBitSet(data []byte, dexStart, pokeID int) bool {
-	index := pokeID - dexStart
-	return isBitSet(data, index)
+	return isBitSet(data, dexIndex(dexStart, pokeID))
 }
 
 func makeRange(start, end int) []int {
@@ -101,6 +304,9 @@ func clearBit(data []byte, index int) []byte {
 }
 
 func isBitSet(data []byte, index int) bool {
+	if index < 0 {
+		return false
+	}
 	byteIndex := index / 8
 	bitIndex := index % 8
 	if byteIndex >= len(data) {
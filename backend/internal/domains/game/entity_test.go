@@ -0,0 +1,103 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestAvailablePokemon(t *testing.T) {
+	tests := []struct {
+		name       string
+		game       Game
+		expansions []Expansion
+		want       []int
+	}{
+		{
+			name: "whole range",
+			game: Game{DexStartID: 1, DexEndID: 5},
+			want: []int{1, 2, 3, 4, 5},
+		},
+		{
+			name: "bitmask",
+			game: Game{DexStartID: 1, DexEndID: 5, Pokedex: createGameBitmask(1, 5, []int{2, 4})},
+			want: []int{2, 4},
+		},
+		{
+			name:       "expansion inside the range",
+			game:       Game{DexStartID: 1, DexEndID: 5, Pokedex: createGameBitmask(1, 5, []int{2})},
+			expansions: []Expansion{{PokemonIDs: pq.Int64Array{3}}},
+			want:       []int{2, 3},
+		},
+		{
+			name:       "expansion outside the range",
+			game:       Game{DexStartID: 10, DexEndID: 12},
+			expansions: []Expansion{{PokemonIDs: pq.Int64Array{1012, 4}}, {PokemonIDs: pq.Int64Array{11, 1012}}},
+			want:       []int{4, 10, 11, 12, 1012},
+		},
+		{
+			name:       "invalid expansion numbers",
+			game:       Game{DexStartID: 1, DexEndID: 2},
+			expansions: []Expansion{{PokemonIDs: pq.Int64Array{0, -3, maxNationalDex}}},
+			want:       []int{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.game.availablePokemon(tt.expansions); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("availablePokemon() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistrable(t *testing.T) {
+	game := Game{DexStartID: 152, DexEndID: 251}
+	expansions := []Expansion{{PokemonIDs: pq.Int64Array{25, 1012}}}
+
+	tests := []struct {
+		id   int
+		want bool
+	}{
+		{152, true},
+		{251, true},
+		{25, true},
+		{1012, true},
+		{151, false},
+		{252, false},
+		{0, false},
+	}
+
+	for _, tt := range tests {
+		if got := game.registrable(tt.id, expansions); got != tt.want {
+			t.Errorf("registrable(%d) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+// Expansion Pokémon from either side of the dex range get their own bits.
+func TestDexBits(t *testing.T) {
+	const start = 152
+	ids := []int{1, 151, 152, 200, 251, 252, 1012, maxNationalDex - 1}
+
+	for _, id := range ids {
+		var data []byte
+		data = setDexBit(data, start, id)
+		for _, other := range ids {
+			if got := isDexBitSet(data, start, other); got != (other == id) {
+				t.Errorf("after setting %d, bit of %d = %v", id, other, got)
+			}
+		}
+	}
+
+	for _, id := range []int{0, -1, maxNationalDex} {
+		if data := setDexBit(nil, start, id); len(data) != 0 {
+			t.Errorf("setDexBit(%d) stored a bit", id)
+		}
+		if isDexBitSet([]byte{0xff, 0xff}, start, id) {
+			t.Errorf("isDexBitSet(%d) = true", id)
+		}
+	}
+}
@@ -9,20 +9,35 @@ import (
 )
 
 type handler struct {
-	gameSvc    gameService
-	pokedexSvc gamePokedexService
+	gameSvc      gameService
+	pokedexSvc   gamePokedexService
+	boardSvc     leaderboardService
+	encounterSvc encounterService
+	versionSvc   versionService
 }
 
 func NewHandler(db *gorm.DB, redis *redis.Client) *handler {
 	gameR := newGameRepo(db)
 	gameS := newGameService(gameR, redis)
 
+	boardR := newLeaderboardRepo(db)
+	boardS := newLeaderboardService(boardR, redis)
+
 	pokedexR := newGamePokedexRepo(db)
-	pokedexS := newGamePokedexService(pokedexR, redis)
+	pokedexS := newGamePokedexService(pokedexR, boardS, redis)
+
+	versionR := newVersionRepo(db)
+	versionS := newVersionService(versionR, pokedexR, redis)
+
+	encounterR := newEncounterRepo(db)
+	encounterS := newEncounterService(encounterR, pokedexR, versionR, redis)
 
 	return &handler{
 		gameSvc: gameS,
 		pokedexSvc: pokedexS,
+		boardSvc: boardS,
+		encounterSvc: encounterS,
+		versionSvc: versionS,
 	}
 }
 
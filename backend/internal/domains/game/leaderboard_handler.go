@@ -0,0 +1,88 @@
+package game
+
+import (
+	"errors"
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// GET /games/leaderboard and GET /games/:id/leaderboard
+func (h *handler) listLeaderboard(c *fiber.Ctx) error {
+	board, gameID, err := parseLeaderboardParams(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	limit, offset := utils.ParsePagination(c)
+	list, total, err := h.boardSvc.top(c.Context(), board, gameID, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": total,
+		"items": list,
+	})
+}
+
+// GET /games/leaderboard/me and GET /games/:id/leaderboard/me
+func (h *handler) getMyLeaderboardRank(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	board, gameID, err := parseLeaderboardParams(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	entry, err := h.boardSvc.rank(c.Context(), board, gameID, userID)
+	if errors.Is(err, errNotRanked) {
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(entry)
+}
+
+// GET /games/leaderboard/friends and GET /games/:id/leaderboard/friends
+func (h *handler) listFriendsLeaderboard(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	board, gameID, err := parseLeaderboardParams(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	list, err := h.boardSvc.friends(c.Context(), board, gameID, userID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": len(list),
+		"items": list,
+	})
+}
+
+// parseLeaderboardParams reads ?by=captured|shiny and the optional game id.
+func parseLeaderboardParams(c *fiber.Ctx) (leaderboardBoard, string, error) {
+	board, err := parseLeaderboardBoard(c.Query("by"))
+	if err != nil {
+		return "", "", err
+	}
+
+	if c.Params("id") == "" {
+		return board, "", nil
+	}
+	gameID := utils.ParseUUID(c.Params("id"))
+	if gameID == uuid.Nil {
+		return "", "", errors.New("invalid game id")
+	}
+	return board, gameID.String(), nil
+}
@@ -0,0 +1,390 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"pokemon/pkg/utils"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type leaderboardBoard string
+
+const (
+	boardCaptured leaderboardBoard = "captured"
+	boardShiny    leaderboardBoard = "shiny"
+)
+
+var errNotRanked = errors.New("user is not ranked on this leaderboard")
+
+func parseLeaderboardBoard(value string) (leaderboardBoard, error) {
+	switch leaderboardBoard(value) {
+	case "", boardCaptured:
+		return boardCaptured, nil
+	case boardShiny:
+		return boardShiny, nil
+	}
+	return "", fmt.Errorf("unknown leaderboard %q (expected captured or shiny)", value)
+}
+
+// An empty gameID always refers to the overall (all games) leaderboard.
+type leaderboardService interface {
+	record(ctx context.Context, dex *GamePokedex) error
+	forget(ctx context.Context, userID, gameID uuid.UUID) error
+
+	top(ctx context.Context, board leaderboardBoard, gameID string, limit, offset int) ([]LeaderboardEntry, int64, error)
+	rank(ctx context.Context, board leaderboardBoard, gameID string, userID uuid.UUID) (*LeaderboardEntry, error)
+	friends(ctx context.Context, board leaderboardBoard, gameID string, userID uuid.UUID) ([]LeaderboardEntry, error)
+
+	recompute(ctx context.Context) error
+}
+
+/********************
+ * REDIS KEY UTILS  *
+ ********************/
+
+const (
+	redisLeaderboardPrefix   = "leaderboard:"
+	redisLeaderboardLockKey  = "lock:leaderboard:recompute"
+	leaderboardRecomputeLock = 10 * time.Minute
+	leaderboardBatchSize     = 500
+)
+
+func redisLeaderboardKey(board leaderboardBoard, gameID string) string {
+	if gameID == "" {
+		return fmt.Sprintf("%s%s:overall", redisLeaderboardPrefix, board)
+	}
+	return fmt.Sprintf("%s%s:game:%s", redisLeaderboardPrefix, board, gameID)
+}
+
+// Same key documented in docs/redis_keys.md, filled by the follow graph.
+func redisFollowingKey(userID uuid.UUID) string {
+	return fmt.Sprintf("user:%s:following", userID.String())
+}
+
+// leaderboardRecordScript sets the score of ARGV[1] on the game board KEYS[1]
+// to ARGV[2] and shifts the overall board KEYS[2] by the difference, reading
+// the previous score in the same step so concurrent updates can't both
+// apply their difference to the same old score.
+var leaderboardRecordScript = redis.NewScript(`
+local previous = tonumber(redis.call("ZSCORE", KEYS[1], ARGV[1]) or "0")
+redis.call("ZADD", KEYS[1], ARGV[2], ARGV[1])
+redis.call("ZINCRBY", KEYS[2], tonumber(ARGV[2]) - previous, ARGV[1])
+return 1
+`)
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type leaderboardServiceImpl struct {
+	db    leaderboardRepository
+	cache *redis.Client
+}
+
+func newLeaderboardService(repo leaderboardRepository, cache *redis.Client) leaderboardService {
+	return &leaderboardServiceImpl{db: repo, cache: cache}
+}
+
+// record moves the user's per-game scores to the new counts and shifts the
+// overall score by the difference, so no other game has to be re-read.
+func (s *leaderboardServiceImpl) record(ctx context.Context, dex *GamePokedex) error {
+	scores := map[leaderboardBoard]int64{
+		boardCaptured: dex.CapturedCount(),
+		boardShiny:    dex.ShinyCapturedCount(),
+	}
+	member := dex.UserID.String()
+
+	for board, score := range scores {
+		keys := []string{redisLeaderboardKey(board, dex.GameID.String()), redisLeaderboardKey(board, "")}
+		if err := leaderboardRecordScript.Run(ctx, s.cache, keys, member, score).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *leaderboardServiceImpl) forget(ctx context.Context, userID, gameID uuid.UUID) error {
+	member := userID.String()
+
+	for _, board := range []leaderboardBoard{boardCaptured, boardShiny} {
+		gameKey := redisLeaderboardKey(board, gameID.String())
+		overallKey := redisLeaderboardKey(board, "")
+
+		previous, err := s.cache.ZScore(ctx, gameKey, member).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		s.cache.ZRem(ctx, gameKey, member)
+		if remaining, err := s.cache.ZIncrBy(ctx, overallKey, -previous, member).Result(); err == nil && remaining <= 0 {
+			s.cache.ZRem(ctx, overallKey, member)
+		}
+	}
+	return nil
+}
+
+func (s *leaderboardServiceImpl) top(ctx context.Context, board leaderboardBoard, gameID string, limit, offset int) ([]LeaderboardEntry, int64, error) {
+	key := redisLeaderboardKey(board, gameID)
+
+	total, err := s.cache.ZCard(ctx, key).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.cache.ZRevRangeWithScores(ctx, key, int64(offset), int64(offset+limit-1)).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries := make([]LeaderboardEntry, 0, len(rows))
+	for i, row := range rows {
+		entries = append(entries, LeaderboardEntry{
+			Rank:   int64(offset + i + 1),
+			UserID: utils.ParseUUID(fmt.Sprint(row.Member)),
+			Score:  int64(row.Score),
+		})
+	}
+
+	if err := s.fillUsernames(ctx, entries); err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+func (s *leaderboardServiceImpl) rank(ctx context.Context, board leaderboardBoard, gameID string, userID uuid.UUID) (*LeaderboardEntry, error) {
+	key := redisLeaderboardKey(board, gameID)
+	member := userID.String()
+
+	position, err := s.cache.ZRevRank(ctx, key, member).Result()
+	if err == redis.Nil {
+		return nil, errNotRanked
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	score, err := s.cache.ZScore(ctx, key, member).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []LeaderboardEntry{{Rank: position + 1, UserID: userID, Score: int64(score)}}
+	if err := s.fillUsernames(ctx, entries); err != nil {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+// friends ranks the user against everyone they follow who is on the board.
+// The user is always listed, with 0 when not ranked yet.
+func (s *leaderboardServiceImpl) friends(ctx context.Context, board leaderboardBoard, gameID string, userID uuid.UUID) ([]LeaderboardEntry, error) {
+	following, err := s.following(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	key := redisLeaderboardKey(board, gameID)
+	members := append([]string{userID.String()}, following...)
+	scores := make([]*redis.FloatCmd, len(members))
+	_, err = s.cache.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, member := range members {
+			scores[i] = pipe.ZScore(ctx, key, member)
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	entries := make([]LeaderboardEntry, 0, len(members))
+	for i, member := range members {
+		id, err := uuid.Parse(member)
+		if err != nil {
+			continue
+		}
+		score, err := scores[i].Result()
+		if err == redis.Nil && i > 0 {
+			continue
+		}
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+		entries = append(entries, LeaderboardEntry{UserID: id, Score: int64(score)})
+	}
+
+	if err := s.fillUsernames(ctx, entries); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		return entries[i].Username < entries[j].Username
+	})
+	for i := range entries {
+		entries[i].Rank = int64(i + 1)
+	}
+	return entries, nil
+}
+
+// following returns the IDs the user follows. The set is rebuilt from
+// Postgres when Redis doesn't have it, e.g. after a flush.
+func (s *leaderboardServiceImpl) following(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	key := redisFollowingKey(userID)
+	following, err := s.cache.SMembers(ctx, key).Result()
+	if err != nil || len(following) > 0 {
+		return following, err
+	}
+
+	ids, err := s.db.followingIDs(ctx, userID)
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	following = make([]string, len(ids))
+	members := make([]any, len(ids))
+	for i, id := range ids {
+		following[i] = id.String()
+		members[i] = following[i]
+	}
+	if err := s.cache.SAdd(ctx, key, members...).Err(); err != nil {
+		return nil, err
+	}
+	return following, nil
+}
+
+// recompute rebuilds every leaderboard from Postgres. Boards are written to
+// temporary keys and renamed into place so readers never see a partial board.
+func (s *leaderboardServiceImpl) recompute(ctx context.Context) error {
+	token, err := utils.AcquireLock(s.cache, redisLeaderboardLockKey, leaderboardRecomputeLock)
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return nil // another instance is already on it
+	}
+	defer utils.ReleaseLock(s.cache, redisLeaderboardLockKey, token)
+
+	boards := map[string]map[string]float64{
+		redisLeaderboardKey(boardCaptured, ""): {},
+		redisLeaderboardKey(boardShiny, ""):    {},
+	}
+	add := func(key, member string, score int64) {
+		if boards[key] == nil {
+			boards[key] = map[string]float64{}
+		}
+		boards[key][member] += float64(score)
+	}
+
+	err = s.db.eachPokedexBatch(ctx, leaderboardBatchSize, func(batch []GamePokedex) error {
+		for i := range batch {
+			dex := &batch[i]
+			member := dex.UserID.String()
+			captured, shiny := dex.CapturedCount(), dex.ShinyCapturedCount()
+
+			add(redisLeaderboardKey(boardCaptured, dex.GameID.String()), member, captured)
+			add(redisLeaderboardKey(boardShiny, dex.GameID.String()), member, shiny)
+			add(redisLeaderboardKey(boardCaptured, ""), member, captured)
+			add(redisLeaderboardKey(boardShiny, ""), member, shiny)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for key, scores := range boards {
+		if err := s.replaceBoard(ctx, key, scores); err != nil {
+			return err
+		}
+	}
+
+	// Drop boards of games that no longer have any pokedex
+	iter := s.cache.Scan(ctx, 0, redisLeaderboardPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		if _, ok := boards[iter.Val()]; !ok {
+			s.cache.Del(ctx, iter.Val())
+		}
+	}
+	return iter.Err()
+}
+
+func (s *leaderboardServiceImpl) replaceBoard(ctx context.Context, key string, scores map[string]float64) error {
+	if len(scores) == 0 {
+		return s.cache.Del(ctx, key).Err()
+	}
+
+	tmpKey := "tmp:" + key
+	members := make([]redis.Z, 0, leaderboardBatchSize)
+
+	s.cache.Del(ctx, tmpKey)
+	for member, score := range scores {
+		members = append(members, redis.Z{Score: score, Member: member})
+		if len(members) == leaderboardBatchSize {
+			if err := s.cache.ZAdd(ctx, tmpKey, members...).Err(); err != nil {
+				return err
+			}
+			members = members[:0]
+		}
+	}
+	if len(members) > 0 {
+		if err := s.cache.ZAdd(ctx, tmpKey, members...).Err(); err != nil {
+			return err
+		}
+	}
+
+	return s.cache.Rename(ctx, tmpKey, key).Err()
+}
+
+func (s *leaderboardServiceImpl) fillUsernames(ctx context.Context, entries []LeaderboardEntry) error {
+	ids := make([]uuid.UUID, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.UserID)
+	}
+
+	names, err := s.db.usernames(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range entries {
+		entries[i].Username = names[entries[i].UserID]
+	}
+	return nil
+}
+
+/*********************
+ * BACKGROUND WORKER *
+ *********************/
+
+// StartLeaderboardWorker rebuilds the leaderboards on startup and then on every
+// interval, healing any drift left by the incremental updates.
+func StartLeaderboardWorker(db *gorm.DB, redis *redis.Client, interval time.Duration) {
+	board := newLeaderboardService(newLeaderboardRepo(db), redis)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := board.recompute(context.Background()); err != nil {
+				log.Printf("leaderboard recompute failed: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,17 @@
+package game
+
+import "gorm.io/gorm"
+
+type GameMigrator struct{}
+
+func (m GameMigrator) Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&VersionGroup{},
+		&Expansion{},
+		&TransferLink{},
+		&Game{},
+		&GamePokedex{},
+		&Location{},
+		&Encounter{},
+	)
+}
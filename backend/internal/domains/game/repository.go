@@ -2,8 +2,12 @@ package game
 
 import (
 	"context"
+	"errors"
+	"pokemon/internal/domains/user"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 /******************************
@@ -35,7 +39,7 @@ func (r *gameRepoImpl) create(ctx context.Context, game *Game) error {
 
 func (r *gameRepoImpl) getByID(ctx context.Context, id string) (*Game, error) {
 	var game Game
-	if err := r.db.WithContext(ctx).First(&game, "id = ?", id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("VersionGroup").First(&game, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &game, nil
@@ -75,7 +79,10 @@ type gamePokedexRepository interface {
 	getByUserAndGame(ctx context.Context, userID, gameID string) (*GamePokedex, error)
 	listByUser(ctx context.Context, userID string, limit, offset int) ([]GamePokedex, int64, error)
 	update(ctx context.Context, pokedex *GamePokedex) error
+	updateBits(ctx context.Context, userID, gameID uuid.UUID, change func(dex *GamePokedex, game *Game, expansions []Expansion) error) (*GamePokedex, error)
 	delete(ctx context.Context, id string) error
+
+	getGame(ctx context.Context, id string) (*Game, error)
 }
 
 type gamePokedexRepoImpl struct {
@@ -126,10 +133,329 @@ func (r *gamePokedexRepoImpl) update(ctx context.Context, pokedex *GamePokedex)
 	return r.db.WithContext(ctx).Save(pokedex).Error
 }
 
+// updateBits lets change set bits of the user's pokedex of the game and saves
+// them, with the pokedex row locked in between so concurrent roll-ups can't
+// drop each other's bits. A missing pokedex is created; the user row is locked
+// first so two roll-ups can't both create it.
+func (r *gamePokedexRepoImpl) updateBits(ctx context.Context, userID, gameID uuid.UUID, change func(dex *GamePokedex, game *Game, expansions []Expansion) error) (*GamePokedex, error) {
+	var dex GamePokedex
+	var game Game
+	var expansions []Expansion
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var owner user.User
+		err := tx.Clauses(clause.Locking{Strength: "NO KEY UPDATE"}).
+			Select("id").First(&owner, "id = ?", userID).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.First(&game, "id = ?", gameID).Error; err != nil {
+			return err
+		}
+		if game.VersionGroupID != nil {
+			if err := tx.Where("version_group_id = ?", *game.VersionGroupID).Find(&expansions).Error; err != nil {
+				return err
+			}
+		}
+
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND game_id = ?", userID, gameID).
+			First(&dex).Error
+		isNew := errors.Is(err, gorm.ErrRecordNotFound)
+		if err != nil && !isNew {
+			return err
+		}
+		if isNew {
+			dex = GamePokedex{UserID: userID, GameID: gameID}
+		}
+
+		if err := change(&dex, &game, expansions); err != nil {
+			return err
+		}
+		if isNew {
+			return tx.Create(&dex).Error
+		}
+		return tx.Model(&dex).
+			Select("seen", "captured", "shiny_seen", "shiny_captured").
+			Updates(&dex).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	dex.Game = game
+	return &dex, nil
+}
+
 func (r *gamePokedexRepoImpl) delete(ctx context.Context, id string) error {
 	return r.db.WithContext(ctx).Delete(&GamePokedex{}, "id = ?", id).Error
 }
 
+func (r *gamePokedexRepoImpl) getGame(ctx context.Context, id string) (*Game, error) {
+	var game Game
+	if err := r.db.WithContext(ctx).First(&game, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &game, nil
+}
+
+/***************
+ * LEADERBOARD *
+ ***************/
+
+type leaderboardRepository interface {
+	usernames(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]string, error)
+	followingIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	eachPokedexBatch(ctx context.Context, size int, fn func([]GamePokedex) error) error
+}
+
+type leaderboardRepoImpl struct {
+	db *gorm.DB
+}
+
+func newLeaderboardRepo(db *gorm.DB) leaderboardRepository {
+	return &leaderboardRepoImpl{db: db}
+}
+
+// followingIDs reads the follow graph the shout domain keeps in the follows table.
+func (r *leaderboardRepoImpl) followingIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Table("follows").Where("follower_id = ?", userID).Pluck("followee_id", &ids).Error
+	return ids, err
+}
+
+func (r *leaderboardRepoImpl) usernames(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]string, error) {
+	result := make(map[uuid.UUID]string, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	var users []user.User
+	if err := r.db.WithContext(ctx).Select("id", "username").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	for _, u := range users {
+		result[u.ID] = u.Username
+	}
+	return result, nil
+}
+
+func (r *leaderboardRepoImpl) eachPokedexBatch(ctx context.Context, size int, fn func([]GamePokedex) error) error {
+	var batch []GamePokedex
+	return r.db.WithContext(ctx).
+		Select("id", "user_id", "game_id", "captured", "shiny_captured").
+		FindInBatches(&batch, size, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		}).Error
+}
+
+/******************
+ * VERSION GROUPS *
+ ******************/
+
+type versionRepository interface {
+	listGroups(ctx context.Context) ([]VersionGroup, error)
+	getGroup(ctx context.Context, id string) (*VersionGroup, error)
+	createGroup(ctx context.Context, group *VersionGroup) error
+	updateGroup(ctx context.Context, group *VersionGroup) error
+	deleteGroup(ctx context.Context, id string) error
+
+	listExpansions(ctx context.Context, groupID uuid.UUID) ([]Expansion, error)
+	createExpansion(ctx context.Context, expansion *Expansion) error
+	deleteExpansion(ctx context.Context, groupID, id string) error
+
+	listLinks(ctx context.Context) ([]TransferLink, error)
+	createLink(ctx context.Context, link *TransferLink) error
+	deleteLink(ctx context.Context, id string) error
+
+	listUserDexes(ctx context.Context, userID uuid.UUID) ([]GamePokedex, error)
+}
+
+type versionRepoImpl struct {
+	db *gorm.DB
+}
+
+func newVersionRepo(db *gorm.DB) versionRepository {
+	return &versionRepoImpl{db: db}
+}
+
+func (r *versionRepoImpl) listGroups(ctx context.Context) ([]VersionGroup, error) {
+	var groups []VersionGroup
+	err := r.db.WithContext(ctx).
+		Preload("Games", func(db *gorm.DB) *gorm.DB { return db.Order("released_at ASC") }).
+		Preload("Expansions", func(db *gorm.DB) *gorm.DB { return db.Order("released_at ASC") }).
+		Order("generation ASC, name ASC").
+		Find(&groups).Error
+	return groups, err
+}
+
+func (r *versionRepoImpl) getGroup(ctx context.Context, id string) (*VersionGroup, error) {
+	var group VersionGroup
+	err := r.db.WithContext(ctx).
+		Preload("Games", func(db *gorm.DB) *gorm.DB { return db.Order("released_at ASC") }).
+		Preload("Expansions", func(db *gorm.DB) *gorm.DB { return db.Order("released_at ASC") }).
+		First(&group, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *versionRepoImpl) createGroup(ctx context.Context, group *VersionGroup) error {
+	return r.db.WithContext(ctx).Omit("Games", "Expansions").Create(group).Error
+}
+
+func (r *versionRepoImpl) updateGroup(ctx context.Context, group *VersionGroup) error {
+	return r.db.WithContext(ctx).Omit("Games", "Expansions").Save(group).Error
+}
+
+// deleteGroup detaches the group's games before removing it.
+func (r *versionRepoImpl) deleteGroup(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Game{}).Where("version_group_id = ?", id).Update("version_group_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&VersionGroup{}, "id = ?", id).Error
+	})
+}
+
+func (r *versionRepoImpl) listExpansions(ctx context.Context, groupID uuid.UUID) ([]Expansion, error) {
+	var expansions []Expansion
+	err := r.db.WithContext(ctx).Where("version_group_id = ?", groupID).Order("released_at ASC").Find(&expansions).Error
+	return expansions, err
+}
+
+func (r *versionRepoImpl) createExpansion(ctx context.Context, expansion *Expansion) error {
+	return r.db.WithContext(ctx).Create(expansion).Error
+}
+
+func (r *versionRepoImpl) deleteExpansion(ctx context.Context, groupID, id string) error {
+	return r.db.WithContext(ctx).Delete(&Expansion{}, "id = ? AND version_group_id = ?", id, groupID).Error
+}
+
+func (r *versionRepoImpl) listLinks(ctx context.Context) ([]TransferLink, error) {
+	var links []TransferLink
+	err := r.db.WithContext(ctx).Find(&links).Error
+	return links, err
+}
+
+func (r *versionRepoImpl) createLink(ctx context.Context, link *TransferLink) error {
+	return r.db.WithContext(ctx).Omit("FromGroup", "ToGroup").Create(link).Error
+}
+
+func (r *versionRepoImpl) deleteLink(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&TransferLink{}, "id = ?", id).Error
+}
+
+func (r *versionRepoImpl) listUserDexes(ctx context.Context, userID uuid.UUID) ([]GamePokedex, error) {
+	var dexes []GamePokedex
+	err := r.db.WithContext(ctx).Preload("Game").Where("user_id = ?", userID).Find(&dexes).Error
+	return dexes, err
+}
+
+/**************
+ * ENCOUNTERS *
+ **************/
+
+type encounterRepository interface {
+	findGame(ctx context.Context, ref string) (*Game, error)
+	getLocation(ctx context.Context, gameID, ref string) (*Location, error)
+	listLocations(ctx context.Context, gameID string) ([]Location, error)
+
+	listByPokemon(ctx context.Context, gameID string, pokemonIDs []int) ([]Encounter, error)
+	listByLocation(ctx context.Context, locationID string) ([]Encounter, error)
+
+	replaceForGame(ctx context.Context, gameID uuid.UUID, locations []Location, encounters []Encounter) error
+}
+
+type encounterRepoImpl struct {
+	db *gorm.DB
+}
+
+func newEncounterRepo(db *gorm.DB) encounterRepository {
+	return &encounterRepoImpl{db: db}
+}
+
+// findGame accepts either a game id or its (case-insensitive) name.
+func (r *encounterRepoImpl) findGame(ctx context.Context, ref string) (*Game, error) {
+	var game Game
+	tx := r.db.WithContext(ctx)
+	if id, err := uuid.Parse(ref); err == nil {
+		tx = tx.Where("id = ?", id)
+	} else {
+		tx = tx.Where("LOWER(name) = LOWER(?)", ref)
+	}
+	if err := tx.First(&game).Error; err != nil {
+		return nil, err
+	}
+	return &game, nil
+}
+
+// getLocation accepts either a location id or its slug.
+func (r *encounterRepoImpl) getLocation(ctx context.Context, gameID, ref string) (*Location, error) {
+	var loc Location
+	tx := r.db.WithContext(ctx).Where("game_id = ?", gameID)
+	if id, err := uuid.Parse(ref); err == nil {
+		tx = tx.Where("id = ?", id)
+	} else {
+		tx = tx.Where("slug = ?", ref)
+	}
+	if err := tx.First(&loc).Error; err != nil {
+		return nil, err
+	}
+	return &loc, nil
+}
+
+func (r *encounterRepoImpl) listLocations(ctx context.Context, gameID string) ([]Location, error) {
+	var locations []Location
+	err := r.db.WithContext(ctx).Where("game_id = ?", gameID).Order("name ASC").Find(&locations).Error
+	return locations, err
+}
+
+func (r *encounterRepoImpl) listByPokemon(ctx context.Context, gameID string, pokemonIDs []int) ([]Encounter, error) {
+	var encounters []Encounter
+	if len(pokemonIDs) == 0 {
+		return encounters, nil
+	}
+	err := r.db.WithContext(ctx).Preload("Location").
+		Where("game_id = ? AND pokemon_id IN ?", gameID, pokemonIDs).
+		Order("pokemon_id ASC, rate DESC").
+		Find(&encounters).Error
+	return encounters, err
+}
+
+func (r *encounterRepoImpl) listByLocation(ctx context.Context, locationID string) ([]Encounter, error) {
+	var encounters []Encounter
+	err := r.db.WithContext(ctx).
+		Where("location_id = ?", locationID).
+		Order("method ASC, rate DESC, pokemon_id ASC").
+		Find(&encounters).Error
+	return encounters, err
+}
+
+// replaceForGame swaps the whole encounter table of a game in one transaction,
+// which keeps CSV imports idempotent.
+func (r *encounterRepoImpl) replaceForGame(ctx context.Context, gameID uuid.UUID, locations []Location, encounters []Encounter) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("game_id = ?", gameID).Delete(&Encounter{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("game_id = ?", gameID).Delete(&Location{}).Error; err != nil {
+			return err
+		}
+		if len(locations) > 0 {
+			if err := tx.CreateInBatches(locations, 500).Error; err != nil {
+				return err
+			}
+		}
+		if len(encounters) > 0 {
+			if err := tx.Omit("Location").CreateInBatches(encounters, 500).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 /**************************************
  **************************************
  ************ INTERACTIONS ************
@@ -1,16 +1,51 @@
 package game
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"pokemon/internal/domains/user"
+	"pokemon/internal/middleware"
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
 
 func (h *handler) RegisterRoutes(router fiber.Router) {
 	group := router.Group("/games")
 
+	// Leaderboards (?by=captured|shiny)
+	group.Get("/leaderboard", h.listLeaderboard)
+	group.Get("/leaderboard/me", middleware.AuthRequired(), h.getMyLeaderboardRank)
+	group.Get("/leaderboard/friends", middleware.AuthRequired(), h.listFriendsLeaderboard)
+	group.Get("/:id/leaderboard", h.listLeaderboard)
+	group.Get("/:id/leaderboard/me", middleware.AuthRequired(), h.getMyLeaderboardRank)
+	group.Get("/:id/leaderboard/friends", middleware.AuthRequired(), h.listFriendsLeaderboard)
+
+	// Version groups, DLC and transfer graph; only admins edit them
+	admin := utils.RoleMiddleware(user.RoleAdmin)
+	group.Get("/version-groups", h.listVersionGroups)
+	group.Get("/version-groups/:group_id", h.getVersionGroup)
+	group.Post("/version-groups", middleware.AuthRequired(), admin, h.createVersionGroup)
+	group.Put("/version-groups/:group_id", middleware.AuthRequired(), admin, h.updateVersionGroup)
+	group.Delete("/version-groups/:group_id", middleware.AuthRequired(), admin, h.deleteVersionGroup)
+	group.Post("/version-groups/:group_id/expansions", middleware.AuthRequired(), admin, h.createExpansion)
+	group.Delete("/version-groups/:group_id/expansions/:expansion_id", middleware.AuthRequired(), admin, h.deleteExpansion)
+	group.Get("/transfers", h.listTransferLinks)
+	group.Post("/transfers", middleware.AuthRequired(), admin, h.createTransferLink)
+	group.Delete("/transfers/:link_id", middleware.AuthRequired(), admin, h.deleteTransferLink)
+	group.Get("/:id/feeders", middleware.AuthRequired(), h.listDexFeeders)
+	group.Get("/:id/living-dex-plan", middleware.AuthRequired(), h.getLivingDexPlan)
+
 	group.Post("/", h.createGame)
 	group.Get("/", h.listGames)
 	group.Get("/:id", h.getGame)
 	group.Put("/:id", h.updateGame)
 	group.Delete("/:id", h.deleteGame)
 
+	// Encounter locations
+	group.Get("/:id/locations", h.listLocations)
+	group.Get("/:id/locations/:location", h.getLocationEncounters)
+	group.Get("/:id/encounters/:pokemon_id", h.getPokemonEncounters)
+	group.Get("/:id/pokedex/missing", middleware.AuthRequired(), h.listMissingPokemon)
+
 	group.Get("/:id/pokedex", h.getUserGamePokedex)
 	group.Post("/:id/pokedex", h.createUserGamePokedex)
 	group.Put("/:id/pokedex", h.updateUserGamePokedex)
@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 )
 
 type gameService interface {
@@ -89,19 +92,25 @@ type gamePokedexService interface {
 	listByUser(ctx context.Context, userID string, limit, offset int) ([]GamePokedex, int64, error)
 	update(ctx context.Context, dex *GamePokedex) error
 	delete(ctx context.Context, id string) error
+	markCaptured(ctx context.Context, userID, gameID uuid.UUID, pokemonIDs []int, shiny bool) (*GamePokedex, error)
 }
 
 type gamePokedexServiceImpl struct {
 	db    gamePokedexRepository
+	board leaderboardService
 	cache *redis.Client
 }
 
-func newGamePokedexService(repo gamePokedexRepository, cache *redis.Client) gamePokedexService {
-	return &gamePokedexServiceImpl{db: repo, cache: cache}
+func newGamePokedexService(repo gamePokedexRepository, board leaderboardService, cache *redis.Client) gamePokedexService {
+	return &gamePokedexServiceImpl{db: repo, board: board, cache: cache}
 }
 
 func (s *gamePokedexServiceImpl) create(ctx context.Context, dex *GamePokedex) error {
-	return s.db.create(ctx, dex)
+	if err := s.db.create(ctx, dex); err != nil {
+		return err
+	}
+	s.recordLeaderboard(ctx, dex)
+	return nil
 }
 
 func (s *gamePokedexServiceImpl) getByID(ctx context.Context, id string) (*GamePokedex, error) {
@@ -140,18 +149,84 @@ func (s *gamePokedexServiceImpl) update(ctx context.Context, dex *GamePokedex) e
 		return err
 	}
 	s.cache.Del(ctx, redisDexKey(dex.ID.String()))
+	s.recordLeaderboard(ctx, dex)
 	return nil
 }
 
 func (s *gamePokedexServiceImpl) delete(ctx context.Context, id string) error {
+	dex, err := s.db.getByID(ctx, id)
+	if err != nil {
+		return err
+	}
 	if err := s.db.delete(ctx, id); err != nil {
 		return err
 	}
 	s.cache.Del(ctx, redisDexKey(id))
+	if err := s.board.forget(ctx, dex.UserID, dex.GameID); err != nil {
+		log.Printf("leaderboard forget failed for pokedex %s: %v", id, err)
+	}
 	return nil
 }
 
+// markCaptured flags every Pokémon as seen and captured, plus the shiny bits when
+// shiny is set. The pokedex is created on first use.
+func (s *gamePokedexServiceImpl) markCaptured(ctx context.Context, userID, gameID uuid.UUID, pokemonIDs []int, shiny bool) (*GamePokedex, error) {
+	dex, err := s.db.updateBits(ctx, userID, gameID, func(dex *GamePokedex, game *Game, expansions []Expansion) error {
+		start := int(game.DexStartID)
+		for _, id := range pokemonIDs {
+			if !game.registrable(id, expansions) {
+				return fmt.Errorf("pokemon %d is not in the %s pokedex (%d-%d) or its expansions", id, game.Name, start, game.DexEndID)
+			}
+			dex.Seen = setDexBit(dex.Seen, start, id)
+			dex.Captured = setDexBit(dex.Captured, start, id)
+			if shiny {
+				dex.ShinySeen = setDexBit(dex.ShinySeen, start, id)
+				dex.ShinyCaptured = setDexBit(dex.ShinyCaptured, start, id)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.Del(ctx, redisDexKey(dex.ID.String()))
+	s.recordLeaderboard(ctx, dex)
+	return dex, nil
+}
+
+// Leaderboards are rebuilt periodically, so a failed incremental update is only logged.
+func (s *gamePokedexServiceImpl) recordLeaderboard(ctx context.Context, dex *GamePokedex) {
+	if err := s.board.record(ctx, dex); err != nil {
+		log.Printf("leaderboard update failed for pokedex %s: %v", dex.ID, err)
+	}
+}
+
 func redisDexKey(id string) string {
 	return fmt.Sprintf("pokedex:%s", id)
 }
 
+
+/******************
+ * POKEDEX MARKER *
+ ******************/
+
+// PokedexMarker lets other domains (shiny hunts, collections...) roll their
+// progress up into a user's GamePokedex without going through the HTTP API.
+type PokedexMarker interface {
+	MarkCaptured(ctx context.Context, userID, gameID uuid.UUID, pokemonIDs []int, shiny bool) error
+}
+
+type pokedexMarker struct {
+	svc gamePokedexService
+}
+
+func NewPokedexMarker(db *gorm.DB, redis *redis.Client) PokedexMarker {
+	board := newLeaderboardService(newLeaderboardRepo(db), redis)
+	return &pokedexMarker{svc: newGamePokedexService(newGamePokedexRepo(db), board, redis)}
+}
+
+func (m *pokedexMarker) MarkCaptured(ctx context.Context, userID, gameID uuid.UUID, pokemonIDs []int, shiny bool) error {
+	_, err := m.svc.markCaptured(ctx, userID, gameID, pokemonIDs, shiny)
+	return err
+}
@@ -0,0 +1,147 @@
+package game
+
+import (
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+/******************
+ * VERSION GROUPS *
+ ******************/
+
+func (h *handler) listVersionGroups(c *fiber.Ctx) error {
+	list, err := h.versionSvc.listGroups(c.Context())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": len(list),
+		"items": list,
+	})
+}
+
+func (h *handler) getVersionGroup(c *fiber.Ctx) error {
+	group, err := h.versionSvc.getGroup(c.Context(), c.Params("group_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "version group not found")
+	}
+	return c.JSON(group)
+}
+
+func (h *handler) createVersionGroup(c *fiber.Ctx) error {
+	var group VersionGroup
+	if err := c.BodyParser(&group); err != nil {
+		return fiber.ErrBadRequest
+	}
+	if err := h.versionSvc.createGroup(c.Context(), &group); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(group)
+}
+
+func (h *handler) updateVersionGroup(c *fiber.Ctx) error {
+	var group VersionGroup
+	if err := c.BodyParser(&group); err != nil {
+		return fiber.ErrBadRequest
+	}
+	group.ID = utils.ParseUUID(c.Params("group_id"))
+	if err := h.versionSvc.updateGroup(c.Context(), &group); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.JSON(group)
+}
+
+func (h *handler) deleteVersionGroup(c *fiber.Ctx) error {
+	if err := h.versionSvc.deleteGroup(c.Context(), c.Params("group_id")); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+/**************
+ * EXPANSIONS *
+ **************/
+
+func (h *handler) createExpansion(c *fiber.Ctx) error {
+	var expansion Expansion
+	if err := c.BodyParser(&expansion); err != nil {
+		return fiber.ErrBadRequest
+	}
+	expansion.VersionGroupID = utils.ParseUUID(c.Params("group_id"))
+	if err := h.versionSvc.createExpansion(c.Context(), &expansion); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(expansion)
+}
+
+func (h *handler) deleteExpansion(c *fiber.Ctx) error {
+	if err := h.versionSvc.deleteExpansion(c.Context(), c.Params("group_id"), c.Params("expansion_id")); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+/******************
+ * TRANSFER GRAPH *
+ ******************/
+
+func (h *handler) listTransferLinks(c *fiber.Ctx) error {
+	list, err := h.versionSvc.listLinks(c.Context())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": len(list),
+		"items": list,
+	})
+}
+
+func (h *handler) createTransferLink(c *fiber.Ctx) error {
+	var link TransferLink
+	if err := c.BodyParser(&link); err != nil {
+		return fiber.ErrBadRequest
+	}
+	if err := h.versionSvc.createLink(c.Context(), &link); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(link)
+}
+
+func (h *handler) deleteTransferLink(c *fiber.Ctx) error {
+	if err := h.versionSvc.deleteLink(c.Context(), c.Params("link_id")); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GET /games/:id/feeders
+func (h *handler) listDexFeeders(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	list, err := h.versionSvc.feeders(c.Context(), userID, c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "game not found")
+	}
+	return c.JSON(fiber.Map{
+		"total": len(list),
+		"items": list,
+	})
+}
+
+// GET /games/:id/living-dex-plan
+func (h *handler) getLivingDexPlan(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	plan, err := h.versionSvc.livingDexPlan(c.Context(), userID, c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "game not found")
+	}
+	return c.JSON(plan)
+}
@@ -0,0 +1,297 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"pokemon/pkg/utils"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type versionService interface {
+	listGroups(ctx context.Context) ([]VersionGroup, error)
+	getGroup(ctx context.Context, id string) (*VersionGroup, error)
+	createGroup(ctx context.Context, group *VersionGroup) error
+	updateGroup(ctx context.Context, group *VersionGroup) error
+	deleteGroup(ctx context.Context, id string) error
+
+	createExpansion(ctx context.Context, expansion *Expansion) error
+	deleteExpansion(ctx context.Context, groupID, id string) error
+
+	listLinks(ctx context.Context) ([]TransferLink, error)
+	createLink(ctx context.Context, link *TransferLink) error
+	deleteLink(ctx context.Context, id string) error
+
+	feeders(ctx context.Context, userID uuid.UUID, gameID string) ([]DexFeeder, error)
+	livingDexPlan(ctx context.Context, userID uuid.UUID, gameID string) (*LivingDexPlan, error)
+}
+
+/********************
+ * REDIS KEY UTILS  *
+ ********************/
+
+const redisTransferGraphKey = "game:transfer-graph"
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type versionServiceImpl struct {
+	db      versionRepository
+	pokedex gamePokedexRepository
+	cache   *redis.Client
+}
+
+func newVersionService(repo versionRepository, pokedex gamePokedexRepository, cache *redis.Client) versionService {
+	return &versionServiceImpl{db: repo, pokedex: pokedex, cache: cache}
+}
+
+func (s *versionServiceImpl) listGroups(ctx context.Context) ([]VersionGroup, error) {
+	return s.db.listGroups(ctx)
+}
+
+func (s *versionServiceImpl) getGroup(ctx context.Context, id string) (*VersionGroup, error) {
+	return s.db.getGroup(ctx, id)
+}
+
+func (s *versionServiceImpl) createGroup(ctx context.Context, group *VersionGroup) error {
+	if err := group.validate(); err != nil {
+		return err
+	}
+	return s.db.createGroup(ctx, group)
+}
+
+func (s *versionServiceImpl) updateGroup(ctx context.Context, group *VersionGroup) error {
+	if err := group.validate(); err != nil {
+		return err
+	}
+	return s.db.updateGroup(ctx, group)
+}
+
+func (s *versionServiceImpl) deleteGroup(ctx context.Context, id string) error {
+	if err := s.db.deleteGroup(ctx, id); err != nil {
+		return err
+	}
+	s.cache.Del(ctx, redisTransferGraphKey)
+	return nil
+}
+
+func (s *versionServiceImpl) createExpansion(ctx context.Context, expansion *Expansion) error {
+	expansion.Name = strings.TrimSpace(expansion.Name)
+	if expansion.Name == "" {
+		return errors.New("name is required")
+	}
+	if expansion.Slug == "" {
+		expansion.Slug = utils.Slugify(expansion.Name)
+	}
+	for _, id := range expansion.PokemonIDs {
+		if id <= 0 || id >= maxNationalDex {
+			return fmt.Errorf("pokemon_ids must be National Dex numbers below %d", maxNationalDex)
+		}
+	}
+	return s.db.createExpansion(ctx, expansion)
+}
+
+func (s *versionServiceImpl) deleteExpansion(ctx context.Context, groupID, id string) error {
+	return s.db.deleteExpansion(ctx, groupID, id)
+}
+
+// The graph is tiny and read on every plan, so it is cached until an edge changes.
+func (s *versionServiceImpl) listLinks(ctx context.Context) ([]TransferLink, error) {
+	val, err := s.cache.Get(ctx, redisTransferGraphKey).Result()
+	if err == nil {
+		var links []TransferLink
+		if err := json.Unmarshal([]byte(val), &links); err == nil {
+			return links, nil
+		}
+	}
+
+	links, err := s.db.listLinks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, _ := json.Marshal(links)
+	s.cache.Set(ctx, redisTransferGraphKey, bytes, time.Hour)
+
+	return links, nil
+}
+
+func (s *versionServiceImpl) createLink(ctx context.Context, link *TransferLink) error {
+	link.Via = strings.TrimSpace(link.Via)
+	if link.Via == "" {
+		return errors.New("via is required")
+	}
+	if link.FromGroupID == uuid.Nil || link.ToGroupID == uuid.Nil || link.FromGroupID == link.ToGroupID {
+		return errors.New("a transfer link needs two different version groups")
+	}
+	if err := s.db.createLink(ctx, link); err != nil {
+		return err
+	}
+	s.cache.Del(ctx, redisTransferGraphKey)
+	return nil
+}
+
+func (s *versionServiceImpl) deleteLink(ctx context.Context, id string) error {
+	if err := s.db.deleteLink(ctx, id); err != nil {
+		return err
+	}
+	s.cache.Del(ctx, redisTransferGraphKey)
+	return nil
+}
+
+// feeders returns the user's pokedexes whose game can send Pokémon to gameID,
+// closest first.
+func (s *versionServiceImpl) feeders(ctx context.Context, userID uuid.UUID, gameID string) ([]DexFeeder, error) {
+	target, err := s.pokedex.getGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := s.listLinks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	paths := transferPaths(links, target.VersionGroupID)
+
+	dexes, err := s.db.listUserDexes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	feeders := []DexFeeder{}
+	for _, dex := range dexes {
+		if dex.GameID == target.ID || dex.Game.VersionGroupID == nil {
+			continue
+		}
+		if path, ok := paths[*dex.Game.VersionGroupID]; ok {
+			feeders = append(feeders, DexFeeder{Pokedex: dex, Path: path})
+		}
+	}
+
+	sort.SliceStable(feeders, func(i, j int) bool {
+		return len(feeders[i].Path) < len(feeders[j].Path)
+	})
+	return feeders, nil
+}
+
+// livingDexPlan goes through every Pokémon obtainable in the target game that
+// the user hasn't captured there, and picks the closest owned game where it
+// is already captured. Whatever is left has to be caught.
+func (s *versionServiceImpl) livingDexPlan(ctx context.Context, userID uuid.UUID, gameID string) (*LivingDexPlan, error) {
+	target, err := s.pokedex.getGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	var expansions []Expansion
+	if target.VersionGroupID != nil {
+		if expansions, err = s.db.listExpansions(ctx, *target.VersionGroupID); err != nil {
+			return nil, err
+		}
+	}
+
+	var captured []byte
+	if dex, err := s.pokedex.getByUserAndGame(ctx, userID.String(), gameID); err == nil {
+		captured = dex.Captured
+	}
+
+	feeders, err := s.feeders(ctx, userID, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	available := target.availablePokemon(expansions)
+	plan := &LivingDexPlan{
+		GameID:     target.ID,
+		Available:  len(available),
+		ToTransfer: []PlannedTransfer{},
+		ToCatch:    []int{},
+	}
+
+	start := int(target.DexStartID)
+	for _, id := range available {
+		if isDexBitSet(captured, start, id) {
+			plan.Owned++
+			continue
+		}
+
+		planned := false
+		for _, f := range feeders {
+			if isDexBitSet(f.Pokedex.Captured, int(f.Pokedex.Game.DexStartID), id) {
+				plan.ToTransfer = append(plan.ToTransfer, PlannedTransfer{
+					PokemonID:  id,
+					FromGameID: f.Pokedex.GameID,
+					FromGame:   f.Pokedex.Game.Name,
+					Path:       f.Path,
+				})
+				planned = true
+				break
+			}
+		}
+		if !planned {
+			plan.ToCatch = append(plan.ToCatch, id)
+		}
+	}
+	return plan, nil
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func (g *VersionGroup) validate() error {
+	g.Name = strings.TrimSpace(g.Name)
+	if g.Name == "" {
+		return errors.New("name is required")
+	}
+	if g.Slug == "" {
+		g.Slug = utils.Slugify(g.Name)
+	}
+	if g.Generation <= 0 {
+		return errors.New("generation must be a positive integer")
+	}
+	return nil
+}
+
+// transferPaths walks the transfer graph backwards from the target group and
+// returns, for every group that can reach it, the shortest chain of transfers.
+// Games of the target group itself get an empty path (a plain trade).
+func transferPaths(links []TransferLink, target *uuid.UUID) map[uuid.UUID][]TransferStep {
+	paths := map[uuid.UUID][]TransferStep{}
+	if target == nil {
+		return paths
+	}
+
+	incoming := map[uuid.UUID][]TransferLink{}
+	for _, l := range links {
+		incoming[l.ToGroupID] = append(incoming[l.ToGroupID], l)
+	}
+
+	paths[*target] = []TransferStep{}
+	queue := []uuid.UUID{*target}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, l := range incoming[current] {
+			if _, seen := paths[l.FromGroupID]; seen {
+				continue
+			}
+			step := TransferStep{FromGroupID: l.FromGroupID, ToGroupID: l.ToGroupID, Via: l.Via}
+			paths[l.FromGroupID] = append([]TransferStep{step}, paths[current]...)
+			queue = append(queue, l.FromGroupID)
+		}
+	}
+	return paths
+}
@@ -2,27 +2,42 @@ package guide
 
 import (
 	"errors"
+	"pokemon/internal/domains/series"
+	"pokemon/internal/domains/translation"
+	"pokemon/internal/domains/user"
+	"pokemon/pkg/content"
+	"pokemon/pkg/publishing"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type GameGuide struct {
-	ID            uuid.UUID      `gorm:"primaryKey" json:"id"`
-	Title         string         `gorm:"type:varchar(255);not null" json:"title"`
-	Slug          string         `gorm:"uniqueIndex;not null" json:"slug"`
-	Summary       string         `gorm:"type:text" json:"summary"`
-	Content       string         `gorm:"type:text;not null" json:"content"`
-	CoverImageURL string         `gorm:"type:text" json:"cover_image_url"`
-	AuthorID      uuid.UUID      `gorm:"not null" json:"author_id"`
-	CreatedAt     time.Time      `json:"created_at"`
-	UpdatedAt     time.Time      `json:"updated_at"`
-	Tags          []GameGuideTag `gorm:"many2many:game_guide_tags_relation" json:"tags"`
+	ID            uuid.UUID            `gorm:"primaryKey" json:"id"`
+	Title         string               `gorm:"type:varchar(255);not null" json:"title"`
+	Slug          string               `gorm:"uniqueIndex;not null" json:"slug"`
+	Summary       string               `gorm:"type:text" json:"summary"`
+	Content       string               `gorm:"type:text;not null" json:"content"`
+	Rendered      *content.Rendered    `gorm:"-" json:"rendered,omitempty"`
+	CoverImageURL string               `gorm:"type:text" json:"cover_image_url"`
+	AuthorID      uuid.UUID            `gorm:"not null" json:"author_id"`
+	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+	Tags          []GameGuideTag       `gorm:"many2many:game_guide_tags_relation" json:"tags"`
+	EditSummary   string               `gorm:"-" json:"edit_summary,omitempty"` // input only, stored on the revision
+	Contributors  []Contributor        `gorm:"-" json:"contributors,omitempty"`
+	Series        []series.Navigation  `gorm:"-" json:"series,omitempty"`                              // previous/next in the series holding the guide
+	Language      string               `gorm:"type:varchar(10);not null;default:'en'" json:"language"` // of the text, translations are separate
+	Translation   *translation.Variant `gorm:"-" json:"translation,omitempty"`                         // language served and the others available
+	Rating        *RatingSummary       `gorm:"-" json:"rating,omitempty"`
+
+	publishing.State // draft, scheduled or published
 }
 
 type GameGuideTag struct {
 	ID   uuid.UUID `gorm:"primaryKey" json:"id"`
 	Name string    `gorm:"uniqueIndex;not null" json:"name"`
+	Slug string    `gorm:"uniqueIndex;not null" json:"slug"`
 }
 
 type GameGuideView struct {
@@ -39,6 +54,203 @@ type GameGuideLike struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// GameGuideRevision is a snapshot of a guide after one edit. Numbers start at
+// 1 for every guide; the highest one matches the current guide.
+type GameGuideRevision struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	GuideID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_guide_revision" json:"guide_id"`
+	Number      int       `gorm:"not null;uniqueIndex:idx_guide_revision" json:"number"`
+	Title       string    `gorm:"type:varchar(255);not null" json:"title"`
+	Summary     string    `gorm:"type:text" json:"summary"`
+	Content     string    `gorm:"type:text;not null" json:"content,omitempty"`
+	AuthorID    uuid.UUID `gorm:"type:uuid;not null;index" json:"author_id"`
+	EditSummary string    `gorm:"type:varchar(255)" json:"edit_summary"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Suggestion statuses. Only pending suggestions can be reviewed.
+const (
+	SuggestionPending  = "pending"
+	SuggestionAccepted = "accepted"
+	SuggestionRejected = "rejected"
+)
+
+// GameGuideSuggestion is an edit proposed by a reader. It holds the full
+// proposed text and only changes the guide once the author or a moderator
+// accepts it, which records it as a revision credited to the proposer.
+type GameGuideSuggestion struct {
+	ID           uuid.UUID                    `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	GuideID      uuid.UUID                    `gorm:"type:uuid;not null;index" json:"guide_id"`
+	AuthorID     uuid.UUID                    `gorm:"type:uuid;not null;index" json:"author_id"`
+	BaseRevision int                          `gorm:"not null" json:"base_revision"`
+	Title        string                       `gorm:"type:varchar(255);not null" json:"title"`
+	Summary      string                       `gorm:"type:text" json:"summary"`
+	Content      string                       `gorm:"type:text;not null" json:"content,omitempty"`
+	Message      string                       `gorm:"type:varchar(255)" json:"message"`
+	Status       string                       `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	ReviewerID   *uuid.UUID                   `gorm:"type:uuid" json:"reviewer_id,omitempty"`
+	ReviewNote   string                       `gorm:"type:text" json:"review_note,omitempty"`
+	ReviewedAt   *time.Time                   `json:"reviewed_at,omitempty"`
+	Revision     *int                         `json:"revision,omitempty"` // revision created when accepted
+	CreatedAt    time.Time                    `json:"created_at"`
+	UpdatedAt    time.Time                    `json:"updated_at"`
+	Comments     []GameGuideSuggestionComment `gorm:"foreignKey:SuggestionID;constraint:OnDelete:CASCADE" json:"comments,omitempty"`
+	Changes      *Changes                     `gorm:"-" json:"changes,omitempty"`
+}
+
+type GameGuideSuggestionComment struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	SuggestionID uuid.UUID `gorm:"type:uuid;not null;index" json:"suggestion_id"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	Content      string    `gorm:"type:text;not null" json:"content"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// GameGuideRating is a reader's 1 to 5 star rating of a guide, with an
+// optional review. Readers rate a guide once and may change their rating.
+type GameGuideRating struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	GuideID   uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_user_guide_rating" json:"guide_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_guide_rating" json:"user_id"`
+	User      user.User `gorm:"foreignKey:UserID" json:"user"`
+	Stars     int       `gorm:"not null" json:"stars"`
+	Review    string    `gorm:"type:text" json:"review,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GameGuideSectionVote answers "was this helpful?" for one section of a
+// guide, the section being the anchor of its heading in the table of contents.
+type GameGuideSectionVote struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	GuideID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_guide_section_vote" json:"guide_id"`
+	Section   string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_guide_section_vote" json:"section"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_guide_section_vote" json:"user_id"`
+	Helpful   bool      `gorm:"not null" json:"helpful"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Accuracy report statuses. Only open reports can be resolved or dismissed.
+const (
+	ReportOpen      = "open"
+	ReportResolved  = "resolved"
+	ReportDismissed = "dismissed"
+)
+
+// GameGuideReport flags something inaccurate in a guide, optionally in one
+// section. The author finds it in their report inbox and resolves it, usually
+// by fixing the guide, or dismisses it.
+type GameGuideReport struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	GuideID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"guide_id"`
+	ReporterID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"reporter_id"`
+	Reporter    user.User  `gorm:"foreignKey:ReporterID" json:"reporter"`
+	Section     string     `gorm:"type:varchar(255)" json:"section,omitempty"`
+	Description string     `gorm:"type:text;not null" json:"description"`
+	Status      string     `gorm:"type:varchar(20);not null;default:'open';index" json:"status"`
+	ResolverID  *uuid.UUID `gorm:"type:uuid" json:"resolver_id,omitempty"`
+	Note        string     `gorm:"type:text" json:"note,omitempty"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+	SeenAt      *time.Time `json:"seen_at,omitempty"` // first time the author listed it in their inbox
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	GuideTitle string `gorm:"->;-:migration" json:"guide_title,omitempty"` // inbox only
+	GuideSlug  string `gorm:"->;-:migration" json:"guide_slug,omitempty"`  // inbox only
+}
+
+/*************
+ * RESPONSES *
+ *************/
+
+// Changes between two versions of a guide's text.
+type Changes struct {
+	Title   *content.Change   `json:"title,omitempty"`
+	Summary *content.Change   `json:"summary,omitempty"`
+	Content *content.LineDiff `json:"content"`
+}
+
+// RatingSummary sums up the ratings of a guide. Score is the Bayesian
+// average lists are ranked by: the average pulled toward the mean of every
+// rating until the guide has enough ratings of its own.
+type RatingSummary struct {
+	Count        int64            `json:"count"`
+	Average      float64          `json:"average"`
+	Score        float64          `json:"score"`
+	Distribution []int64          `json:"distribution,omitempty"` // number of 1 to 5 star ratings
+	Mine         *GameGuideRating `json:"mine,omitempty"`
+}
+
+// SectionHelpfulness counts the votes on one section of the guide.
+type SectionHelpfulness struct {
+	Section    string `json:"section"`
+	Title      string `json:"title"`
+	Helpful    int64  `json:"helpful"`
+	NotHelpful int64  `json:"not_helpful"`
+	Mine       *bool  `json:"mine,omitempty"`
+}
+
+type RevisionDiff struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+	Changes
+}
+
+// Contributor is someone other than the author who edited the guide, mostly
+// through accepted suggestions.
+type Contributor struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Edits    int64     `json:"edits"`
+}
+
+/************
+ * REQUESTS *
+ ************/
+
+type renameTagRequest struct {
+	Name string `json:"name"`
+}
+
+type mergeTagRequest struct {
+	IntoID uuid.UUID `json:"into_id"`
+}
+
+// suggestEditRequest leaves out the fields the suggestion doesn't change.
+type suggestEditRequest struct {
+	Title   *string `json:"title"`
+	Summary *string `json:"summary"`
+	Content *string `json:"content"`
+	Message string  `json:"message"`
+}
+
+type reviewSuggestionRequest struct {
+	Note string `json:"note"`
+}
+
+type suggestionCommentRequest struct {
+	Content string `json:"content"`
+}
+
+type rateRequest struct {
+	Stars  int    `json:"stars"`
+	Review string `json:"review"`
+}
+
+type sectionVoteRequest struct {
+	Helpful *bool `json:"helpful"`
+}
+
+type reportRequest struct {
+	Section     string `json:"section"`
+	Description string `json:"description"`
+}
+
+type resolveReportRequest struct {
+	Note string `json:"note"`
+}
+
 /***************
  * VALIDATIONS *
  ***************/
@@ -56,5 +268,41 @@ func (g *GameGuide) Validate() error {
 	if g.AuthorID == uuid.Nil {
 		return errors.New("author_id is required")
 	}
+	if len(g.EditSummary) > 255 {
+		return errors.New("edit_summary cannot be longer than 255 characters")
+	}
+	return nil
+}
+
+func (s *GameGuideSuggestion) Validate() error {
+	if s.Title == "" {
+		return errors.New("title is required")
+	}
+	if s.Content == "" {
+		return errors.New("content is required")
+	}
+	if len(s.Message) > 255 {
+		return errors.New("message cannot be longer than 255 characters")
+	}
+	return nil
+}
+
+func (r *GameGuideRating) Validate() error {
+	if r.Stars < 1 || r.Stars > 5 {
+		return errors.New("stars must be between 1 and 5")
+	}
+	if len(r.Review) > 5000 {
+		return errors.New("review cannot be longer than 5000 characters")
+	}
+	return nil
+}
+
+func (r *GameGuideReport) Validate() error {
+	if r.Description == "" {
+		return errors.New("description is required")
+	}
+	if len(r.Description) > 5000 {
+		return errors.New("description cannot be longer than 5000 characters")
+	}
 	return nil
 }
@@ -1,6 +1,12 @@
 package guide
 
 import (
+	"errors"
+	"log"
+	"pokemon/internal/domains/dexlink"
+	"pokemon/internal/domains/series"
+	"pokemon/internal/domains/translation"
+	"pokemon/pkg/tagging"
 	"pokemon/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -10,14 +16,35 @@ import (
 )
 
 type gameGuideHandler struct {
-	s gameGuideService
+	s           gameGuideService
+	tags        gameGuideTagService
+	revisions   revisionService
+	suggestions suggestionService
+	ratings     ratingService
+	reports     reportService
+	navigator   *series.Navigator
+	translator  *translation.Translator
 }
 
 func NewHandler(db *gorm.DB, redis *redis.Client) *gameGuideHandler {
 	repo := newGameGuideRepo(db)
-	serv := newGameGuideService(repo, redis)
+	renderer := dexlink.NewRenderer(db, redis)
+	serv := newGameGuideService(repo, renderer, redis)
 
-	return &gameGuideHandler{s: serv}
+	tags := newGameGuideTagService(newGameGuideTagRepo(db), redis)
+	revisions := newRevisionService(repo, serv)
+	suggestions := newSuggestionService(newSuggestionRepo(db), repo, serv)
+
+	return &gameGuideHandler{
+		s:           serv,
+		tags:        tags,
+		revisions:   revisions,
+		suggestions: suggestions,
+		ratings:     newRatingService(newRatingRepo(db), redis),
+		reports:     newReportService(newReportRepo(db), redis),
+		navigator:   series.NewNavigator(db),
+		translator:  translation.NewTranslator(db, renderer),
+	}
 }
 
 func (h *gameGuideHandler) create(c *fiber.Ctx) error {
@@ -54,6 +81,14 @@ func (h *gameGuideHandler) getByID(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusNotFound, err.Error())
 	}
 
+	viewerID, _ := utils.GetUserIDFromLocals(c)
+	if !guide.VisibleTo(viewerID, guide.AuthorID) {
+		return fiber.NewError(fiber.StatusNotFound, "guide not found")
+	}
+	guide.Series = h.navigator.Around(c.Context(), series.ContentGuide, guide.ID, viewerID)
+	h.rating(guide, viewerID)
+	h.translate(c, guide)
+
 	return c.JSON(guide)
 }
 
@@ -68,9 +103,35 @@ func (h *gameGuideHandler) getBySlug(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusNotFound, err.Error())
 	}
 
+	viewerID, _ := utils.GetUserIDFromLocals(c)
+	if !guide.VisibleTo(viewerID, guide.AuthorID) {
+		return fiber.NewError(fiber.StatusNotFound, "guide not found")
+	}
+	guide.Series = h.navigator.Around(c.Context(), series.ContentGuide, guide.ID, viewerID)
+	h.rating(guide, viewerID)
+	h.translate(c, guide)
+
 	return c.JSON(guide)
 }
 
+// rating attaches the rating summary; the guide is still served without it.
+func (h *gameGuideHandler) rating(guide *GameGuide, viewerID uuid.UUID) {
+	summary, err := h.ratings.summary(guide.ID, viewerID)
+	if err != nil {
+		log.Printf("rating summary failed for guide %s: %v", guide.ID, err)
+		return
+	}
+	guide.Rating = summary
+}
+
+// translate serves the guide in the language the reader asks for.
+func (h *gameGuideHandler) translate(c *fiber.Ctx, guide *GameGuide) {
+	guide.Translation = h.translator.Negotiate(c, translation.ContentGuide, guide.ID, guide.Language)
+	if v := guide.Translation; v.Translated() {
+		guide.Title, guide.Summary, guide.Content, guide.Rendered = v.Title, v.Summary, v.Body, v.Rendered
+	}
+}
+
 func (h *gameGuideHandler) update(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
@@ -82,18 +143,28 @@ func (h *gameGuideHandler) update(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
 	}
 
-	guide.ID = id
 	userID, err := utils.GetUserIDFromLocals(c)
 	if err != nil {
 		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
 	}
-	guide.AuthorID = userID
+
+	existing, err := h.s.getByID(id)
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "guide not found")
+	}
+	if !canEdit(c, existing, userID) {
+		return fiber.NewError(fiber.StatusForbidden, "only the author or a moderator can edit this guide")
+	}
+
+	// Edits keep the original author; the editor is credited on the revision
+	guide.ID = id
+	guide.AuthorID = existing.AuthorID
 
 	if err := guide.Validate(); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
-	if err := h.s.update(&guide); err != nil {
+	if err := h.s.update(&guide, userID); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
@@ -113,12 +184,20 @@ func (h *gameGuideHandler) delete(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
+// GET /game-guides?sort=new|rating
 func (h *gameGuideHandler) list(c *fiber.Ctx) error {
+	order, err := h.listOrder(c)
+	if err != nil {
+		return err
+	}
 	limit, offset := utils.ParsePagination(c)
-	guides, err := h.s.list(limit, offset)
+	guides, err := h.s.list(order, limit, offset)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
+	if err := h.ratings.attach(guides); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
 	return c.JSON(guides)
 }
 
@@ -128,10 +207,109 @@ func (h *gameGuideHandler) listByUser(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid user_id")
 	}
 
+	order, err := h.listOrder(c)
+	if err != nil {
+		return err
+	}
 	limit, offset := utils.ParsePagination(c)
-	guides, err := h.s.listByAuthor(userID, limit, offset)
+	// Authors also see their own drafts and scheduled guides
+	viewerID, _ := utils.GetUserIDFromLocals(c)
+	guides, err := h.s.listByAuthor(userID, viewerID != userID, order, limit, offset)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
+	if err := h.ratings.attach(guides); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
 	return c.JSON(guides)
 }
+
+// listOrder reads the sort query param: newest first by default, or by the
+// Bayesian average of the ratings.
+func (h *gameGuideHandler) listOrder(c *fiber.Ctx) (listOrder, error) {
+	switch c.Query("sort") {
+	case "", "new":
+		return listOrder{}, nil
+	case "rating":
+		return listOrder{byRating: true, prior: h.ratings.prior()}, nil
+	}
+	return listOrder{}, fiber.NewError(fiber.StatusBadRequest, "sort must be new or rating")
+}
+
+/* GAME GUIDE TAG */
+
+func (h *gameGuideHandler) listTags(c *fiber.Ctx) error {
+	tags, err := h.tags.listAll()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(tags)
+}
+
+func (h *gameGuideHandler) tagCloud(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > maxGuideTagCloudSize {
+		limit = maxGuideTagCloudSize
+	}
+	tags, err := h.tags.cloud(limit)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(tags)
+}
+
+func (h *gameGuideHandler) relatedTags(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 10)
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+	tags, err := h.tags.related(c.Params("slug"), limit)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, "tag not found")
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(tags)
+}
+
+func (h *gameGuideHandler) renameTag(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+	var req renameTagRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.tags.rename(id, req.Name); err != nil {
+		return tagError(err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *gameGuideHandler) mergeTag(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+	var req mergeTagRequest
+	if err := c.BodyParser(&req); err != nil || req.IntoID == uuid.Nil {
+		return fiber.NewError(fiber.StatusBadRequest, "into_id is required")
+	}
+	if err := h.tags.merge(id, req.IntoID); err != nil {
+		return tagError(err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func tagError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "tag not found")
+	case errors.Is(err, tagging.ErrTagExists):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+}
@@ -0,0 +1,94 @@
+package guide
+
+import (
+	"context"
+	"errors"
+	"pokemon/pkg/content"
+	"pokemon/pkg/tagging"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Library gives bulk tools, like the Markdown import, access to guides under
+// the same rules as the API: validation, revisions and cache invalidation.
+type Library struct {
+	s       gameGuideService
+	repo    gameGuideRepository
+	tags    gameGuideTagService
+	tagRepo gameGuideTagRepository
+	redis   *redis.Client
+}
+
+func NewLibrary(db *gorm.DB, redis *redis.Client) *Library {
+	repo := newGameGuideRepo(db)
+	tagRepo := newGameGuideTagRepo(db)
+	return &Library{
+		s:       newGameGuideService(repo, content.NewRenderer(redis), redis),
+		repo:    repo,
+		tags:    newGameGuideTagService(tagRepo, redis),
+		tagRepo: tagRepo,
+		redis:   redis,
+	}
+}
+
+// BySlug returns the guide with its tags, or nil when there is none.
+func (l *Library) BySlug(ctx context.Context, slug string) (*GameGuide, error) {
+	guide, err := l.repo.getBySlug(slug)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return guide, err
+}
+
+// All returns every guide with its tags, drafts included, by slug.
+func (l *Library) All(ctx context.Context) ([]GameGuide, error) {
+	return l.repo.all()
+}
+
+// Save creates the guide, or updates it when it has an ID, as a revision
+// credited to editorID. Its tags become the named ones, created as needed.
+func (l *Library) Save(ctx context.Context, guide *GameGuide, tagNames []string, editorID uuid.UUID) error {
+	tags := make([]GameGuideTag, 0, len(tagNames))
+	for _, name := range tagNames {
+		tag, err := l.tag(name)
+		if err != nil {
+			return err
+		}
+		tags = append(tags, *tag)
+	}
+
+	guide.Tags = nil
+	if guide.ID == uuid.Nil {
+		if err := l.s.create(guide); err != nil {
+			return err
+		}
+	} else if err := l.s.update(guide, editorID); err != nil {
+		return err
+	}
+
+	if err := l.repo.replaceTags(guide, tags); err != nil {
+		return err
+	}
+	guide.Tags = tags
+	l.redis.Del(ctx, redisGuideTagCloudKey, redisGuideSlugKey(guide.Slug))
+	return nil
+}
+
+// tag finds the tag by the slug of its name, creating it when missing.
+func (l *Library) tag(name string) (*GameGuideTag, error) {
+	name, slug, err := tagging.Normalize(name)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := l.tagRepo.getBySlug(slug)
+	if err == nil {
+		return tag, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	tag = &GameGuideTag{Name: name}
+	return tag, l.tags.create(tag)
+}
@@ -0,0 +1,77 @@
+package guide
+
+import (
+	"pokemon/pkg/tagging"
+	"pokemon/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GuideMigrator struct{}
+
+func (m GuideMigrator) Migrate(db *gorm.DB) error {
+	if err := backfillTagSlugs(db); err != nil {
+		return err
+	}
+	return db.AutoMigrate(
+		&GameGuide{},
+		&GameGuideTag{},
+		&GameGuideView{},
+		&GameGuideLike{},
+		&GameGuideRevision{},
+		&GameGuideSuggestion{},
+		&GameGuideSuggestionComment{},
+		&GameGuideRating{},
+		&GameGuideSectionVote{},
+		&GameGuideReport{},
+	)
+}
+
+// backfillTagSlugs gives every guide tag created before slugs existed the slug
+// of its name, so AutoMigrate can then make the column unique and required.
+// The column is added nullable first; tags whose names share a slug are merged
+// into the first one by name, as they would be one tag today.
+func backfillTagSlugs(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&GameGuideTag{}) {
+		return nil
+	}
+	if !db.Migrator().HasColumn(&GameGuideTag{}, "Slug") {
+		if err := db.Exec("ALTER TABLE game_guide_tags ADD COLUMN slug text").Error; err != nil {
+			return err
+		}
+	}
+
+	var tags []struct {
+		ID   uuid.UUID
+		Name string
+	}
+	if err := db.Raw("SELECT id, name FROM game_guide_tags WHERE slug IS NULL ORDER BY name, id").Scan(&tags).Error; err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		_, slug, err := tagging.Normalize(tag.Name)
+		if err != nil {
+			slug = utils.Slugify(tag.Name)
+		}
+		if slug == "" {
+			slug = tag.ID.String()
+		}
+
+		var holders []uuid.UUID
+		if err := db.Raw("SELECT id FROM game_guide_tags WHERE slug = ?", slug).Scan(&holders).Error; err != nil {
+			return err
+		}
+		if len(holders) > 0 {
+			if err := gameGuideTags.Merge(db, tag.ID, holders[0]); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := db.Exec("UPDATE game_guide_tags SET slug = ? WHERE id = ?", slug, tag.ID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
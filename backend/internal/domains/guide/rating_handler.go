@@ -0,0 +1,154 @@
+package guide
+
+import (
+	"errors"
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+/* GAME GUIDE RATING */
+
+// PUT /game-guides/:id/rating
+func (h *gameGuideHandler) rate(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	guide, err := h.visibleGuide(c)
+	if err != nil {
+		return err
+	}
+
+	var req rateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+	rating := GameGuideRating{UserID: userID, Stars: req.Stars, Review: req.Review}
+	if err := rating.Validate(); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := h.ratings.rate(guide, &rating); err != nil {
+		return ratingError(err)
+	}
+	summary, err := h.ratings.summary(guide.ID, userID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(summary)
+}
+
+// DELETE /game-guides/:id/rating
+func (h *gameGuideHandler) unrate(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	guide, err := h.visibleGuide(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.ratings.unrate(guide.ID, userID); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GET /game-guides/:id/ratings lists the ratings that come with a review.
+func (h *gameGuideHandler) listReviews(c *fiber.Ctx) error {
+	guide, err := h.visibleGuide(c)
+	if err != nil {
+		return err
+	}
+
+	viewerID, _ := utils.GetUserIDFromLocals(c)
+	summary, err := h.ratings.summary(guide.ID, viewerID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	limit, offset := utils.ParsePagination(c)
+	reviews, total, err := h.ratings.reviews(guide.ID, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"summary": summary,
+		"total":   total,
+		"items":   reviews,
+	})
+}
+
+/* GAME GUIDE SECTION HELPFULNESS */
+
+// GET /game-guides/:id/sections
+func (h *gameGuideHandler) listSections(c *fiber.Ctx) error {
+	guide, err := h.visibleGuide(c)
+	if err != nil {
+		return err
+	}
+
+	viewerID, _ := utils.GetUserIDFromLocals(c)
+	sections, err := h.ratings.sections(guide, viewerID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(sections)
+}
+
+// PUT /game-guides/:id/sections/:section/vote
+func (h *gameGuideHandler) voteSection(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	guide, err := h.visibleGuide(c)
+	if err != nil {
+		return err
+	}
+
+	var req sectionVoteRequest
+	if err := c.BodyParser(&req); err != nil || req.Helpful == nil {
+		return fiber.NewError(fiber.StatusBadRequest, "helpful is required")
+	}
+
+	if err := h.ratings.vote(guide, c.Params("section"), userID, *req.Helpful); err != nil {
+		return ratingError(err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// DELETE /game-guides/:id/sections/:section/vote
+func (h *gameGuideHandler) unvoteSection(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	guide, err := h.visibleGuide(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.ratings.unvote(guide, c.Params("section"), userID); err != nil {
+		return ratingError(err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func ratingError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "rating not found")
+	case errors.Is(err, errRateOwnGuide):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, errUnknownSection):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
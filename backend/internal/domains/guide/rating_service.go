@@ -0,0 +1,244 @@
+package guide
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+var (
+	errRateOwnGuide   = errors.New("you cannot rate your own guide")
+	errUnknownSection = errors.New("section is not a heading of the guide")
+)
+
+const (
+	redisGuideRatingMeanKey = "game_guide_ratings:mean"
+	guideRatingMeanTTL      = 10 * time.Minute
+
+	// defaultRatingPrior stands in for the mean until anything is rated.
+	defaultRatingPrior = 3.0
+)
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type ratingService interface {
+	rate(guide *GameGuide, rating *GameGuideRating) error
+	unrate(guideID, userID uuid.UUID) error
+	summary(guideID, viewerID uuid.UUID) (*RatingSummary, error)
+	reviews(guideID uuid.UUID, limit, offset int) ([]GameGuideRating, int64, error)
+	attach(guides []GameGuide) error
+	prior() float64
+
+	sections(guide *GameGuide, viewerID uuid.UUID) ([]SectionHelpfulness, error)
+	vote(guide *GameGuide, section string, userID uuid.UUID, helpful bool) error
+	unvote(guide *GameGuide, section string, userID uuid.UUID) error
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type ratingServiceImpl struct {
+	repo  ratingRepository
+	redis *redis.Client
+}
+
+func newRatingService(repo ratingRepository, redis *redis.Client) ratingService {
+	return &ratingServiceImpl{repo: repo, redis: redis}
+}
+
+// rate records the reader's rating of the guide, replacing the previous one.
+func (s *ratingServiceImpl) rate(guide *GameGuide, rating *GameGuideRating) error {
+	if guide.AuthorID == rating.UserID {
+		return errRateOwnGuide
+	}
+	if err := rating.Validate(); err != nil {
+		return err
+	}
+	rating.GuideID = guide.ID
+	if err := s.repo.upsert(rating); err != nil {
+		return err
+	}
+	s.redis.Del(context.Background(), redisGuideRatingMeanKey)
+	return nil
+}
+
+func (s *ratingServiceImpl) unrate(guideID, userID uuid.UUID) error {
+	if err := s.repo.delete(guideID, userID); err != nil {
+		return err
+	}
+	s.redis.Del(context.Background(), redisGuideRatingMeanKey)
+	return nil
+}
+
+// summary includes the star distribution and, for a signed-in viewer, their
+// own rating.
+func (s *ratingServiceImpl) summary(guideID, viewerID uuid.UUID) (*RatingSummary, error) {
+	stats, err := s.repo.stats([]uuid.UUID{guideID})
+	if err != nil {
+		return nil, err
+	}
+	st := ratingStats{GuideID: guideID}
+	if len(stats) > 0 {
+		st = stats[0]
+	}
+	summary := summarize(st, s.prior())
+
+	if summary.Distribution, err = s.repo.distribution(guideID); err != nil {
+		return nil, err
+	}
+	if viewerID != uuid.Nil {
+		mine, err := s.repo.get(guideID, viewerID)
+		switch {
+		case err == nil:
+			summary.Mine = mine
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, err
+		}
+	}
+	return summary, nil
+}
+
+func (s *ratingServiceImpl) reviews(guideID uuid.UUID, limit, offset int) ([]GameGuideRating, int64, error) {
+	return s.repo.list(guideID, true, limit, offset)
+}
+
+// attach sets the rating summary of every guide of a list.
+func (s *ratingServiceImpl) attach(guides []GameGuide) error {
+	ids := make([]uuid.UUID, len(guides))
+	for i := range guides {
+		ids[i] = guides[i].ID
+	}
+	stats, err := s.repo.stats(ids)
+	if err != nil {
+		return err
+	}
+	prior := s.prior()
+	byGuide := make(map[uuid.UUID]ratingStats, len(stats))
+	for _, st := range stats {
+		byGuide[st.GuideID] = st
+	}
+	for i := range guides {
+		guides[i].Rating = summarize(byGuide[guides[i].ID], prior)
+	}
+	return nil
+}
+
+// prior is the mean of every rating, which guides with few ratings of their
+// own are pulled toward. It is cached as it only drifts slowly.
+func (s *ratingServiceImpl) prior() float64 {
+	ctx := context.Background()
+	if cached, err := s.redis.Get(ctx, redisGuideRatingMeanKey).Result(); err == nil {
+		if mean, err := strconv.ParseFloat(cached, 64); err == nil {
+			return mean
+		}
+	}
+
+	mean, err := s.repo.mean()
+	if err != nil {
+		return defaultRatingPrior
+	}
+	if mean == 0 {
+		mean = defaultRatingPrior
+	}
+	s.redis.Set(ctx, redisGuideRatingMeanKey, strconv.FormatFloat(mean, 'f', -1, 64), guideRatingMeanTTL)
+	return mean
+}
+
+/* SECTION HELPFULNESS */
+
+// sections lists every heading of the guide with its votes, in the order of
+// the table of contents.
+func (s *ratingServiceImpl) sections(guide *GameGuide, viewerID uuid.UUID) ([]SectionHelpfulness, error) {
+	counts, err := s.repo.sectionCounts(guide.ID)
+	if err != nil {
+		return nil, err
+	}
+	var mine map[string]bool
+	if viewerID != uuid.Nil {
+		if mine, err = s.repo.userVotes(guide.ID, viewerID); err != nil {
+			return nil, err
+		}
+	}
+
+	result := []SectionHelpfulness{}
+	index := map[string]int{}
+	if guide.Rendered != nil {
+		for _, h := range guide.Rendered.TOC {
+			if _, dup := index[h.ID]; dup || h.ID == "" {
+				continue
+			}
+			index[h.ID] = len(result)
+			section := SectionHelpfulness{Section: h.ID, Title: h.Text}
+			if helpful, ok := mine[h.ID]; ok {
+				section.Mine = &helpful
+			}
+			result = append(result, section)
+		}
+	}
+	// Votes on sections renamed since are left out
+	for _, c := range counts {
+		i, ok := index[c.Section]
+		if !ok {
+			continue
+		}
+		if c.Helpful {
+			result[i].Helpful = c.Count
+		} else {
+			result[i].NotHelpful = c.Count
+		}
+	}
+	return result, nil
+}
+
+func (s *ratingServiceImpl) vote(guide *GameGuide, section string, userID uuid.UUID, helpful bool) error {
+	if !hasSection(guide, section) {
+		return errUnknownSection
+	}
+	return s.repo.vote(&GameGuideSectionVote{GuideID: guide.ID, Section: section, UserID: userID, Helpful: helpful})
+}
+
+func (s *ratingServiceImpl) unvote(guide *GameGuide, section string, userID uuid.UUID) error {
+	return s.repo.unvote(guide.ID, section, userID)
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// summarize computes the average and the Bayesian score of the ratings.
+func summarize(st ratingStats, prior float64) *RatingSummary {
+	summary := &RatingSummary{
+		Count: st.Count,
+		Score: round2((ratingPriorWeight*prior + float64(st.Total)) / (ratingPriorWeight + float64(st.Count))),
+	}
+	if st.Count > 0 {
+		summary.Average = round2(float64(st.Total) / float64(st.Count))
+	}
+	return summary
+}
+
+// hasSection tells whether section is the anchor of a heading of the guide.
+func hasSection(guide *GameGuide, section string) bool {
+	if guide.Rendered == nil || section == "" {
+		return false
+	}
+	for _, h := range guide.Rendered.TOC {
+		if h.ID == section {
+			return true
+		}
+	}
+	return false
+}
+
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
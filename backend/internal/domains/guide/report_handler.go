@@ -0,0 +1,177 @@
+package guide
+
+import (
+	"errors"
+	"pokemon/internal/domains/notification"
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+/* GAME GUIDE ACCURACY REPORT */
+
+// POST /game-guides/:id/reports
+func (h *gameGuideHandler) reportGuide(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	guide, err := h.visibleGuide(c)
+	if err != nil {
+		return err
+	}
+
+	var req reportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+	report := GameGuideReport{ReporterID: userID, Section: req.Section, Description: req.Description}
+	if err := report.Validate(); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := h.reports.create(guide, &report); err != nil {
+		return reportError(err)
+	}
+	notification.Send(notification.Event{Type: notification.TypeModeration, ActorID: userID, SubjectType: notification.SubjectGuide, SubjectID: guide.ID, ContentID: &report.ID, Note: notification.NoteReported})
+	return c.Status(fiber.StatusCreated).JSON(report)
+}
+
+// GET /game-guides/:id/reports?status=
+func (h *gameGuideHandler) listReports(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	guide, err := h.visibleGuide(c)
+	if err != nil {
+		return err
+	}
+	if !canEdit(c, guide, userID) {
+		return fiber.NewError(fiber.StatusForbidden, "only the author or a moderator can see the reports on this guide")
+	}
+	status, err := reportStatus(c)
+	if err != nil {
+		return err
+	}
+
+	limit, offset := utils.ParsePagination(c)
+	reports, total, err := h.reports.list(guide.ID, status, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": total,
+		"items": reports,
+	})
+}
+
+// GET /game-guides/reports/inbox?status= lists the reports on the guides of
+// the signed-in author.
+func (h *gameGuideHandler) reportInbox(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	status, err := reportStatus(c)
+	if err != nil {
+		return err
+	}
+
+	limit, offset := utils.ParsePagination(c)
+	reports, total, err := h.reports.inbox(userID, status, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": total,
+		"items": reports,
+	})
+}
+
+// GET /game-guides/reports/unread
+func (h *gameGuideHandler) unreadReports(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	count, err := h.reports.unread(userID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{"unread": count})
+}
+
+// POST /game-guides/:id/reports/:report_id/resolve
+func (h *gameGuideHandler) resolveReport(c *fiber.Ctx) error {
+	return h.closeReport(c, h.reports.resolve)
+}
+
+// POST /game-guides/:id/reports/:report_id/dismiss
+func (h *gameGuideHandler) dismissReport(c *fiber.Ctx) error {
+	return h.closeReport(c, h.reports.dismiss)
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+type closeReportFunc func(guide *GameGuide, id, resolverID uuid.UUID, note string) (*GameGuideReport, error)
+
+func (h *gameGuideHandler) closeReport(c *fiber.Ctx, settle closeReportFunc) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	guide, err := h.visibleGuide(c)
+	if err != nil {
+		return err
+	}
+	if !canEdit(c, guide, userID) {
+		return fiber.NewError(fiber.StatusForbidden, "only the author or a moderator can close reports")
+	}
+	id, err := uuid.Parse(c.Params("report_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid report id")
+	}
+
+	var req resolveReportRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+		}
+	}
+
+	report, err := settle(guide, id, userID, req.Note)
+	if err != nil {
+		return reportError(err)
+	}
+	// report.Status is resolved or dismissed, as are the notes
+	notification.Send(notification.Event{Type: notification.TypeModeration, ActorID: userID, SubjectType: notification.SubjectGuideReport, SubjectID: report.ID, Note: report.Status})
+	return c.JSON(report)
+}
+
+func reportStatus(c *fiber.Ctx) (string, error) {
+	status := c.Query("status")
+	switch status {
+	case "", ReportOpen, ReportResolved, ReportDismissed:
+		return status, nil
+	}
+	return "", fiber.NewError(fiber.StatusBadRequest, "invalid status")
+}
+
+func reportError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "report not found")
+	case errors.Is(err, errReportOwnGuide):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, errReportClosed):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, errUnknownSection):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
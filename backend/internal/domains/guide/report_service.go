@@ -0,0 +1,140 @@
+package guide
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	errReportOwnGuide = errors.New("you cannot report your own guide")
+	errReportClosed   = errors.New("report has already been resolved or dismissed")
+)
+
+const guideReportsUnreadTTL = 10 * time.Minute
+
+func redisGuideReportsUnreadKey(authorID uuid.UUID) string {
+	return "game_guide_reports:unread:" + authorID.String()
+}
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type reportService interface {
+	create(guide *GameGuide, report *GameGuideReport) error
+	list(guideID uuid.UUID, status string, limit, offset int) ([]GameGuideReport, int64, error)
+	inbox(authorID uuid.UUID, status string, limit, offset int) ([]GameGuideReport, int64, error)
+	unread(authorID uuid.UUID) (int64, error)
+	resolve(guide *GameGuide, id, resolverID uuid.UUID, note string) (*GameGuideReport, error)
+	dismiss(guide *GameGuide, id, resolverID uuid.UUID, note string) (*GameGuideReport, error)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type reportServiceImpl struct {
+	repo  reportRepository
+	redis *redis.Client
+}
+
+func newReportService(repo reportRepository, redis *redis.Client) reportService {
+	return &reportServiceImpl{repo: repo, redis: redis}
+}
+
+// create files an open report and notifies the author through their inbox
+// unread count.
+func (s *reportServiceImpl) create(guide *GameGuide, report *GameGuideReport) error {
+	if guide.AuthorID == report.ReporterID {
+		return errReportOwnGuide
+	}
+	if err := report.Validate(); err != nil {
+		return err
+	}
+	if report.Section != "" && !hasSection(guide, report.Section) {
+		return errUnknownSection
+	}
+
+	report.GuideID = guide.ID
+	report.Status = ReportOpen
+	if err := s.repo.create(report); err != nil {
+		return err
+	}
+	s.redis.Del(context.Background(), redisGuideReportsUnreadKey(guide.AuthorID))
+	return nil
+}
+
+func (s *reportServiceImpl) list(guideID uuid.UUID, status string, limit, offset int) ([]GameGuideReport, int64, error) {
+	return s.repo.list(guideID, status, limit, offset)
+}
+
+// inbox lists the reports on the author's guides and marks them as seen.
+func (s *reportServiceImpl) inbox(authorID uuid.UUID, status string, limit, offset int) ([]GameGuideReport, int64, error) {
+	reports, total, err := s.repo.inbox(authorID, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var unseen []uuid.UUID
+	for _, r := range reports {
+		if r.SeenAt == nil {
+			unseen = append(unseen, r.ID)
+		}
+	}
+	if len(unseen) > 0 {
+		if err := s.repo.markSeen(unseen); err != nil {
+			return nil, 0, err
+		}
+		s.redis.Del(context.Background(), redisGuideReportsUnreadKey(authorID))
+	}
+	return reports, total, nil
+}
+
+// unread counts the reports the author hasn't seen in their inbox yet.
+func (s *reportServiceImpl) unread(authorID uuid.UUID) (int64, error) {
+	ctx := context.Background()
+	key := redisGuideReportsUnreadKey(authorID)
+	if cached, err := s.redis.Get(ctx, key).Result(); err == nil {
+		if count, err := strconv.ParseInt(cached, 10, 64); err == nil {
+			return count, nil
+		}
+	}
+
+	count, err := s.repo.unseen(authorID)
+	if err != nil {
+		return 0, err
+	}
+	s.redis.Set(ctx, key, count, guideReportsUnreadTTL)
+	return count, nil
+}
+
+func (s *reportServiceImpl) resolve(guide *GameGuide, id, resolverID uuid.UUID, note string) (*GameGuideReport, error) {
+	return s.close(guide, id, ReportResolved, resolverID, note)
+}
+
+func (s *reportServiceImpl) dismiss(guide *GameGuide, id, resolverID uuid.UUID, note string) (*GameGuideReport, error) {
+	return s.close(guide, id, ReportDismissed, resolverID, note)
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func (s *reportServiceImpl) close(guide *GameGuide, id uuid.UUID, status string, resolverID uuid.UUID, note string) (*GameGuideReport, error) {
+	report, err := s.repo.getByID(guide.ID, id)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status != ReportOpen {
+		return nil, errReportClosed
+	}
+	if err := s.repo.resolve(id, status, resolverID, note); err != nil {
+		return nil, err
+	}
+	return s.repo.getByID(guide.ID, id)
+}
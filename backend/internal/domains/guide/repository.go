@@ -1,8 +1,14 @@
 package guide
 
 import (
+	"errors"
+	"pokemon/pkg/publishing"
+	"pokemon/pkg/tagging"
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 /******************************
@@ -18,15 +24,48 @@ import (
 /* GAME GUIDE */
 
 type gameGuideRepository interface {
-	list(limit, offset int) ([]GameGuide, error)
-	listByUser(userID uuid.UUID, limit, offset int) ([]GameGuide, error)
+	list(order listOrder, limit, offset int) ([]GameGuide, error)
+	listByUser(userID uuid.UUID, publicOnly bool, order listOrder, limit, offset int) ([]GameGuide, error)
 	countByUser(userID uuid.UUID) (int64, error)
 
-	create(guide *GameGuide) error
+	create(guide *GameGuide, rev *GameGuideRevision) error
 	getByID(id uuid.UUID) (*GameGuide, error)
 	getBySlug(slug string) (*GameGuide, error)
-	update(guide *GameGuide) error
+	update(guide *GameGuide, previous *GameGuide, rev *GameGuideRevision, base *int) error
 	delete(id uuid.UUID) error
+	all() ([]GameGuide, error)
+	replaceTags(guide *GameGuide, tags []GameGuideTag) error
+
+	listRevisions(guideID uuid.UUID, limit, offset int) ([]GameGuideRevision, int64, error)
+	getRevision(guideID uuid.UUID, number int) (*GameGuideRevision, error)
+	latestRevision(guideID uuid.UUID) (int, error)
+	contributors(guideID, authorID uuid.UUID) ([]Contributor, error)
+}
+
+// ratingPriorWeight is how many ratings at the mean every guide is assumed to
+// have on top of its own when ranked: a single 5 star rating doesn't put a
+// guide ahead of one rated 4.8 by a hundred readers.
+const ratingPriorWeight = 10
+
+// listOrder sorts guide lists: newest first, or with byRating by the Bayesian
+// average of their ratings, prior being the mean of every rating.
+type listOrder struct {
+	byRating bool
+	prior    float64
+}
+
+func (o listOrder) scope(db *gorm.DB) *gorm.DB {
+	if !o.byRating {
+		return db.Order("game_guides.created_at DESC")
+	}
+	return db.Select("game_guides.*").
+		Joins(`LEFT JOIN (
+			SELECT guide_id, COUNT(*) AS n, SUM(stars) AS total FROM game_guide_ratings GROUP BY guide_id
+		) r ON r.guide_id = game_guides.id`).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "(? * ? + COALESCE(r.total, 0)) / (? + COALESCE(r.n, 0)) DESC, game_guides.created_at DESC",
+			Vars: []any{ratingPriorWeight, o.prior, ratingPriorWeight},
+		}})
 }
 
 type gameGuideRepoImpl struct {
@@ -37,8 +76,15 @@ func newGameGuideRepo(db *gorm.DB) gameGuideRepository {
 	return &gameGuideRepoImpl{db: db}
 }
 
-func (r *gameGuideRepoImpl) create(guide *GameGuide) error {
-	return r.db.Create(guide).Error
+// create saves the guide along with its first revision.
+func (r *gameGuideRepoImpl) create(guide *GameGuide, rev *GameGuideRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(guide).Error; err != nil {
+			return err
+		}
+		rev.GuideID, rev.Number = guide.ID, 1
+		return tx.Create(rev).Error
+	})
 }
 
 func (r *gameGuideRepoImpl) getByID(id uuid.UUID) (*GameGuide, error) {
@@ -53,35 +99,142 @@ func (r *gameGuideRepoImpl) getBySlug(slug string) (*GameGuide, error) {
 	return &guide, err
 }
 
-func (r *gameGuideRepoImpl) update(guide *GameGuide) error {
-	return r.db.Save(guide).Error
+// update saves the guide and records rev as its next revision. Guides written
+// before revisions existed get their previous state recorded first, so every
+// history starts from the original text. When base is set, the edit was made
+// against that revision and fails with errSuggestionStale if the guide's text
+// changed since. The guide is locked so edits are numbered one after the other.
+func (r *gameGuideRepoImpl) update(guide *GameGuide, previous *GameGuide, rev *GameGuideRevision, base *int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current GameGuide
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", guide.ID).Error; err != nil {
+			return err
+		}
+
+		var last int
+		err := tx.Model(&GameGuideRevision{}).Where("guide_id = ?", guide.ID).
+			Select("COALESCE(MAX(number), 0)").Scan(&last).Error
+		if err != nil {
+			return err
+		}
+		if base != nil && *base != last {
+			// Revision 1 holds the text of guides edited before revisions existed
+			var since GameGuideRevision
+			err := tx.First(&since, "guide_id = ? AND number = ?", guide.ID, max(*base, 1)).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if err != nil || since.Title != current.Title || since.Summary != current.Summary || since.Content != current.Content {
+				return errSuggestionStale
+			}
+		}
+
+		if err := tx.Save(guide).Error; err != nil {
+			return err
+		}
+		if last == 0 {
+			original := revisionOf(previous, previous.AuthorID, "Original version")
+			original.Number, original.CreatedAt = 1, previous.UpdatedAt
+			if err := tx.Create(original).Error; err != nil {
+				return err
+			}
+			last = 1
+		}
+
+		rev.GuideID, rev.Number = guide.ID, last+1
+		return tx.Create(rev).Error
+	})
 }
 
 func (r *gameGuideRepoImpl) delete(id uuid.UUID) error {
 	return r.db.Delete(&GameGuide{}, "id = ?", id).Error
 }
 
-func (r *gameGuideRepoImpl) list(limit, offset int) ([]GameGuide, error) {
+// all returns every guide, drafts included, for exports.
+func (r *gameGuideRepoImpl) all() ([]GameGuide, error) {
+	var guides []GameGuide
+	err := r.db.Preload("Tags", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("name ASC")
+	}).Order("slug ASC").Find(&guides).Error
+	return guides, err
+}
+
+func (r *gameGuideRepoImpl) replaceTags(guide *GameGuide, tags []GameGuideTag) error {
+	return r.db.Model(guide).Association("Tags").Replace(tags)
+}
+
+func (r *gameGuideRepoImpl) list(order listOrder, limit, offset int) ([]GameGuide, error) {
 	var guides []GameGuide
 	err := r.db.Preload("Tags").
-		Order("created_at DESC").
+		Scopes(publishing.Live("game_guides"), order.scope).
 		Limit(limit).
 		Offset(offset).
 		Find(&guides).Error
 	return guides, err
 }
 
-func (r *gameGuideRepoImpl) listByUser(authorID uuid.UUID, limit, offset int) ([]GameGuide, error) {
+func (r *gameGuideRepoImpl) listByUser(authorID uuid.UUID, publicOnly bool, order listOrder, limit, offset int) ([]GameGuide, error) {
+	tx := r.db.Preload("Tags")
+	if publicOnly {
+		tx = tx.Scopes(publishing.Live("game_guides"))
+	}
+
 	var guides []GameGuide
-	err := r.db.Preload("Tags").
-		Where("author_id = ?", authorID).
-		Order("created_at DESC").
+	err := tx.
+		Scopes(order.scope).
+		Where("game_guides.author_id = ?", authorID).
 		Limit(limit).
 		Offset(offset).
 		Find(&guides).Error
 	return guides, err
 }
 
+func (r *gameGuideRepoImpl) listRevisions(guideID uuid.UUID, limit, offset int) ([]GameGuideRevision, int64, error) {
+	var revisions []GameGuideRevision
+	var count int64
+
+	tx := r.db.Model(&GameGuideRevision{}).Where("guide_id = ?", guideID)
+	if err := tx.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err := tx.Omit("content").
+		Order("number DESC").
+		Limit(limit).Offset(offset).
+		Find(&revisions).Error
+	return revisions, count, err
+}
+
+func (r *gameGuideRepoImpl) getRevision(guideID uuid.UUID, number int) (*GameGuideRevision, error) {
+	var rev GameGuideRevision
+	err := r.db.First(&rev, "guide_id = ? AND number = ?", guideID, number).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// latestRevision returns 0 for guides without any recorded revision.
+func (r *gameGuideRepoImpl) latestRevision(guideID uuid.UUID) (int, error) {
+	var last int
+	err := r.db.Model(&GameGuideRevision{}).Where("guide_id = ?", guideID).
+		Select("COALESCE(MAX(number), 0)").Scan(&last).Error
+	return last, err
+}
+
+// contributors lists the editors of the guide other than its author, most
+// active first.
+func (r *gameGuideRepoImpl) contributors(guideID, authorID uuid.UUID) ([]Contributor, error) {
+	var contributors []Contributor
+	err := r.db.Table("game_guide_revisions AS r").
+		Select("r.author_id AS user_id, COALESCE(u.username, '') AS username, COUNT(*) AS edits").
+		Joins("LEFT JOIN users u ON u.id = r.author_id").
+		Where("r.guide_id = ? AND r.author_id <> ?", guideID, authorID).
+		Group("r.author_id, u.username").
+		Order("edits DESC, username ASC").
+		Scan(&contributors).Error
+	return contributors, err
+}
+
 func (r *gameGuideRepoImpl) countByUser(authorID uuid.UUID) (int64, error) {
 	var count int64
 	err := r.db.Model(&GameGuide{}).
@@ -95,7 +248,22 @@ func (r *gameGuideRepoImpl) countByUser(authorID uuid.UUID) (int64, error) {
 type gameGuideTagRepository interface {
 	create(tag *GameGuideTag) error
 	getByName(name string) (*GameGuideTag, error)
+	getBySlug(slug string) (*GameGuideTag, error)
 	listAll() ([]GameGuideTag, error)
+
+	cloud(limit int) ([]tagging.Count, error)
+	related(tagID uuid.UUID, limit int) ([]tagging.Count, error)
+	rename(tagID uuid.UUID, name string) error
+	merge(fromID, intoID uuid.UUID) error
+}
+
+var gameGuideTags = tagging.Table{
+	Tags:     "game_guide_tags",
+	Join:     "game_guide_tags_relation",
+	OwnerKey: "game_guide_id",
+	TagKey:   "game_guide_tag_id",
+	Owners:   "game_guides",
+	Visible:  publishing.LiveCondition("o"),
 }
 
 type gameGuideTagRepo struct {
@@ -116,12 +284,126 @@ func (r *gameGuideTagRepo) getByName(name string) (*GameGuideTag, error) {
 	return &tag, err
 }
 
+func (r *gameGuideTagRepo) getBySlug(slug string) (*GameGuideTag, error) {
+	var tag GameGuideTag
+	err := r.db.First(&tag, "slug = ?", slug).Error
+	return &tag, err
+}
+
 func (r *gameGuideTagRepo) listAll() ([]GameGuideTag, error) {
 	var tags []GameGuideTag
 	err := r.db.Order("name ASC").Find(&tags).Error
 	return tags, err
 }
 
+func (r *gameGuideTagRepo) cloud(limit int) ([]tagging.Count, error) {
+	return gameGuideTags.Cloud(r.db, limit)
+}
+
+func (r *gameGuideTagRepo) related(tagID uuid.UUID, limit int) ([]tagging.Count, error) {
+	return gameGuideTags.Related(r.db, tagID, limit)
+}
+
+func (r *gameGuideTagRepo) rename(tagID uuid.UUID, name string) error {
+	return gameGuideTags.Rename(r.db, tagID, name)
+}
+
+func (r *gameGuideTagRepo) merge(fromID, intoID uuid.UUID) error {
+	return gameGuideTags.Merge(r.db, fromID, intoID)
+}
+
+/* GAME GUIDE SUGGESTION */
+
+type suggestionRepository interface {
+	create(suggestion *GameGuideSuggestion) error
+	getByID(guideID, id uuid.UUID) (*GameGuideSuggestion, error)
+	list(guideID uuid.UUID, status string, limit, offset int) ([]GameGuideSuggestion, int64, error)
+	review(id uuid.UUID, status string, reviewerID uuid.UUID, note string) error
+	reopen(id uuid.UUID) error
+	setRevision(id uuid.UUID, number int) error
+	addComment(comment *GameGuideSuggestionComment) error
+}
+
+type suggestionRepo struct {
+	db *gorm.DB
+}
+
+func newSuggestionRepo(db *gorm.DB) suggestionRepository {
+	return &suggestionRepo{db: db}
+}
+
+func (r *suggestionRepo) create(suggestion *GameGuideSuggestion) error {
+	return r.db.Create(suggestion).Error
+}
+
+func (r *suggestionRepo) getByID(guideID, id uuid.UUID) (*GameGuideSuggestion, error) {
+	var suggestion GameGuideSuggestion
+	err := r.db.
+		Preload("Comments", func(tx *gorm.DB) *gorm.DB { return tx.Order("created_at ASC") }).
+		First(&suggestion, "id = ? AND guide_id = ?", id, guideID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &suggestion, nil
+}
+
+// list leaves out the proposed content; an empty status lists every suggestion.
+func (r *suggestionRepo) list(guideID uuid.UUID, status string, limit, offset int) ([]GameGuideSuggestion, int64, error) {
+	var suggestions []GameGuideSuggestion
+	var count int64
+
+	tx := r.db.Model(&GameGuideSuggestion{}).Where("guide_id = ?", guideID)
+	if status != "" {
+		tx = tx.Where("status = ?", status)
+	}
+	if err := tx.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err := tx.Omit("content").
+		Order("created_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&suggestions).Error
+	return suggestions, count, err
+}
+
+// review closes a pending suggestion. It fails with errSuggestionReviewed when
+// someone else reviewed it first.
+func (r *suggestionRepo) review(id uuid.UUID, status string, reviewerID uuid.UUID, note string) error {
+	res := r.db.Model(&GameGuideSuggestion{}).
+		Where("id = ? AND status = ?", id, SuggestionPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewer_id": reviewerID,
+			"review_note": note,
+			"reviewed_at": time.Now(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errSuggestionReviewed
+	}
+	return nil
+}
+
+func (r *suggestionRepo) reopen(id uuid.UUID) error {
+	return r.db.Model(&GameGuideSuggestion{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":      SuggestionPending,
+			"reviewer_id": nil,
+			"review_note": "",
+			"reviewed_at": nil,
+		}).Error
+}
+
+func (r *suggestionRepo) setRevision(id uuid.UUID, number int) error {
+	return r.db.Model(&GameGuideSuggestion{}).Where("id = ?", id).Update("revision", number).Error
+}
+
+func (r *suggestionRepo) addComment(comment *GameGuideSuggestionComment) error {
+	return r.db.Create(comment).Error
+}
+
 /**************************************
  **************************************
  ************ INTERACTIONS ************
@@ -204,3 +486,280 @@ func (r *gameGuideViewRepo) exists(guideID, userID uuid.UUID) (bool, error) {
 		Count(&count).Error
 	return count > 0, err
 }
+
+/* RATING */
+
+// ratingStats are the raw numbers behind a RatingSummary.
+type ratingStats struct {
+	GuideID uuid.UUID
+	Count   int64
+	Total   int64
+}
+
+// sectionCount is the number of votes one way on a section.
+type sectionCount struct {
+	Section string
+	Helpful bool
+	Count   int64
+}
+
+type ratingRepository interface {
+	upsert(rating *GameGuideRating) error
+	delete(guideID, userID uuid.UUID) error
+	get(guideID, userID uuid.UUID) (*GameGuideRating, error)
+	list(guideID uuid.UUID, reviewsOnly bool, limit, offset int) ([]GameGuideRating, int64, error)
+	stats(guideIDs []uuid.UUID) ([]ratingStats, error)
+	distribution(guideID uuid.UUID) ([]int64, error)
+	mean() (float64, error)
+
+	vote(vote *GameGuideSectionVote) error
+	unvote(guideID uuid.UUID, section string, userID uuid.UUID) error
+	sectionCounts(guideID uuid.UUID) ([]sectionCount, error)
+	userVotes(guideID, userID uuid.UUID) (map[string]bool, error)
+}
+
+type ratingRepo struct {
+	db *gorm.DB
+}
+
+func newRatingRepo(db *gorm.DB) ratingRepository {
+	return &ratingRepo{db: db}
+}
+
+// upsert records the rating, replacing the reader's previous one.
+func (r *ratingRepo) upsert(rating *GameGuideRating) error {
+	return r.db.Omit("User").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "guide_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"stars", "review", "updated_at"}),
+	}).Create(rating).Error
+}
+
+func (r *ratingRepo) delete(guideID, userID uuid.UUID) error {
+	return r.db.
+		Where("guide_id = ? AND user_id = ?", guideID, userID).
+		Delete(&GameGuideRating{}).Error
+}
+
+func (r *ratingRepo) get(guideID, userID uuid.UUID) (*GameGuideRating, error) {
+	var rating GameGuideRating
+	err := r.db.Preload("User").
+		Where("guide_id = ? AND user_id = ?", guideID, userID).
+		First(&rating).Error
+	return &rating, err
+}
+
+func (r *ratingRepo) list(guideID uuid.UUID, reviewsOnly bool, limit, offset int) ([]GameGuideRating, int64, error) {
+	tx := r.db.Model(&GameGuideRating{}).Where("guide_id = ?", guideID)
+	if reviewsOnly {
+		tx = tx.Where("review <> ''")
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var ratings []GameGuideRating
+	err := tx.Preload("User").
+		Order("updated_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&ratings).Error
+	return ratings, total, err
+}
+
+func (r *ratingRepo) stats(guideIDs []uuid.UUID) ([]ratingStats, error) {
+	var stats []ratingStats
+	if len(guideIDs) == 0 {
+		return stats, nil
+	}
+	err := r.db.Model(&GameGuideRating{}).
+		Select("guide_id, COUNT(*) AS count, SUM(stars) AS total").
+		Where("guide_id IN ?", guideIDs).
+		Group("guide_id").
+		Scan(&stats).Error
+	return stats, err
+}
+
+// distribution counts the 1 to 5 star ratings of the guide.
+func (r *ratingRepo) distribution(guideID uuid.UUID) ([]int64, error) {
+	var rows []struct {
+		Stars int
+		Count int64
+	}
+	err := r.db.Model(&GameGuideRating{}).
+		Select("stars, COUNT(*) AS count").
+		Where("guide_id = ?", guideID).
+		Group("stars").
+		Scan(&rows).Error
+
+	counts := make([]int64, 5)
+	for _, row := range rows {
+		if row.Stars >= 1 && row.Stars <= 5 {
+			counts[row.Stars-1] = row.Count
+		}
+	}
+	return counts, err
+}
+
+// mean is the average of every rating of a live guide, 0 when there is none.
+func (r *ratingRepo) mean() (float64, error) {
+	var mean float64
+	err := r.db.Model(&GameGuideRating{}).
+		Joins("JOIN game_guides ON game_guides.id = game_guide_ratings.guide_id").
+		Scopes(publishing.Live("game_guides")).
+		Select("COALESCE(AVG(game_guide_ratings.stars), 0)").
+		Scan(&mean).Error
+	return mean, err
+}
+
+func (r *ratingRepo) vote(vote *GameGuideSectionVote) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "guide_id"}, {Name: "section"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"helpful", "updated_at"}),
+	}).Create(vote).Error
+}
+
+func (r *ratingRepo) unvote(guideID uuid.UUID, section string, userID uuid.UUID) error {
+	return r.db.
+		Where("guide_id = ? AND section = ? AND user_id = ?", guideID, section, userID).
+		Delete(&GameGuideSectionVote{}).Error
+}
+
+func (r *ratingRepo) sectionCounts(guideID uuid.UUID) ([]sectionCount, error) {
+	var counts []sectionCount
+	err := r.db.Model(&GameGuideSectionVote{}).
+		Select("section, helpful, COUNT(*) AS count").
+		Where("guide_id = ?", guideID).
+		Group("section, helpful").
+		Scan(&counts).Error
+	return counts, err
+}
+
+func (r *ratingRepo) userVotes(guideID, userID uuid.UUID) (map[string]bool, error) {
+	var votes []GameGuideSectionVote
+	err := r.db.
+		Select("section", "helpful").
+		Where("guide_id = ? AND user_id = ?", guideID, userID).
+		Find(&votes).Error
+
+	mine := make(map[string]bool, len(votes))
+	for _, v := range votes {
+		mine[v.Section] = v.Helpful
+	}
+	return mine, err
+}
+
+/* ACCURACY REPORT */
+
+type reportRepository interface {
+	create(report *GameGuideReport) error
+	getByID(guideID, id uuid.UUID) (*GameGuideReport, error)
+	list(guideID uuid.UUID, status string, limit, offset int) ([]GameGuideReport, int64, error)
+	inbox(authorID uuid.UUID, status string, limit, offset int) ([]GameGuideReport, int64, error)
+	markSeen(ids []uuid.UUID) error
+	unseen(authorID uuid.UUID) (int64, error)
+	resolve(id uuid.UUID, status string, resolverID uuid.UUID, note string) error
+}
+
+type reportRepo struct {
+	db *gorm.DB
+}
+
+func newReportRepo(db *gorm.DB) reportRepository {
+	return &reportRepo{db: db}
+}
+
+func (r *reportRepo) create(report *GameGuideReport) error {
+	return r.db.Omit("Reporter").Create(report).Error
+}
+
+func (r *reportRepo) getByID(guideID, id uuid.UUID) (*GameGuideReport, error) {
+	var report GameGuideReport
+	err := r.db.Preload("Reporter").
+		Where("guide_id = ? AND id = ?", guideID, id).
+		First(&report).Error
+	return &report, err
+}
+
+func (r *reportRepo) list(guideID uuid.UUID, status string, limit, offset int) ([]GameGuideReport, int64, error) {
+	tx := r.db.Model(&GameGuideReport{}).Where("guide_id = ?", guideID)
+	if status != "" {
+		tx = tx.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var reports []GameGuideReport
+	err := tx.Preload("Reporter").
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&reports).Error
+	return reports, total, err
+}
+
+// inbox lists the reports on every guide of the author, newest first.
+func (r *reportRepo) inbox(authorID uuid.UUID, status string, limit, offset int) ([]GameGuideReport, int64, error) {
+	tx := r.db.Model(&GameGuideReport{}).
+		Joins("JOIN game_guides g ON g.id = game_guide_reports.guide_id").
+		Where("g.author_id = ?", authorID)
+	if status != "" {
+		tx = tx.Where("game_guide_reports.status = ?", status)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var reports []GameGuideReport
+	err := tx.Preload("Reporter").
+		Select("game_guide_reports.*, g.title AS guide_title, g.slug AS guide_slug").
+		Order("game_guide_reports.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&reports).Error
+	return reports, total, err
+}
+
+func (r *reportRepo) markSeen(ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&GameGuideReport{}).
+		Where("id IN ? AND seen_at IS NULL", ids).
+		Update("seen_at", time.Now()).Error
+}
+
+func (r *reportRepo) unseen(authorID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&GameGuideReport{}).
+		Joins("JOIN game_guides g ON g.id = game_guide_reports.guide_id").
+		Where("g.author_id = ? AND game_guide_reports.seen_at IS NULL", authorID).
+		Count(&count).Error
+	return count, err
+}
+
+// resolve closes an open report; errReportClosed when it isn't open anymore.
+func (r *reportRepo) resolve(id uuid.UUID, status string, resolverID uuid.UUID, note string) error {
+	now := time.Now()
+	res := r.db.Model(&GameGuideReport{}).
+		Where("id = ? AND status = ?", id, ReportOpen).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"resolver_id": resolverID,
+			"note":        note,
+			"resolved_at": now,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errReportClosed
+	}
+	return nil
+}
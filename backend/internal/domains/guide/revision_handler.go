@@ -0,0 +1,127 @@
+package guide
+
+import (
+	"errors"
+	"pokemon/internal/domains/user"
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+/* GAME GUIDE REVISION */
+
+// GET /game-guides/:id/revisions
+func (h *gameGuideHandler) listRevisions(c *fiber.Ctx) error {
+	guide, err := h.visibleGuide(c)
+	if err != nil {
+		return err
+	}
+
+	limit, offset := utils.ParsePagination(c)
+	revisions, total, err := h.revisions.list(guide.ID, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": total,
+		"items": revisions,
+	})
+}
+
+// GET /game-guides/:id/revisions/:number
+func (h *gameGuideHandler) getRevision(c *fiber.Ctx) error {
+	guide, err := h.visibleGuide(c)
+	if err != nil {
+		return err
+	}
+	number, err := c.ParamsInt("number")
+	if err != nil || number < 1 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid revision number")
+	}
+
+	rev, err := h.revisions.get(guide.ID, number)
+	if err != nil {
+		return revisionError(err)
+	}
+	return c.JSON(rev)
+}
+
+// GET /game-guides/:id/diff?from=&to=
+func (h *gameGuideHandler) diffRevisions(c *fiber.Ctx) error {
+	guide, err := h.visibleGuide(c)
+	if err != nil {
+		return err
+	}
+	from, to := c.QueryInt("from"), c.QueryInt("to")
+	if from < 1 || to < 1 {
+		return fiber.NewError(fiber.StatusBadRequest, "from and to revision numbers are required")
+	}
+
+	diff, err := h.revisions.diff(guide.ID, from, to)
+	if err != nil {
+		return revisionError(err)
+	}
+	return c.JSON(diff)
+}
+
+// POST /game-guides/:id/revisions/:number/rollback
+func (h *gameGuideHandler) rollback(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	guide, err := h.visibleGuide(c)
+	if err != nil {
+		return err
+	}
+	if !canEdit(c, guide, userID) {
+		return fiber.NewError(fiber.StatusForbidden, "only the author or a moderator can roll back this guide")
+	}
+	number, err := c.ParamsInt("number")
+	if err != nil || number < 1 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid revision number")
+	}
+
+	restored, err := h.revisions.rollback(guide.ID, number, userID)
+	if err != nil {
+		return revisionError(err)
+	}
+	return c.JSON(restored)
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// visibleGuide loads the guide of the :id param, hiding unpublished guides
+// from everyone but their author.
+func (h *gameGuideHandler) visibleGuide(c *fiber.Ctx) (*GameGuide, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+	guide, err := h.s.getByID(id)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "guide not found")
+	}
+	viewerID, _ := utils.GetUserIDFromLocals(c)
+	if !guide.VisibleTo(viewerID, guide.AuthorID) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "guide not found")
+	}
+	return guide, nil
+}
+
+// canEdit tells whether the user may edit the guide directly: its author or
+// a moderator.
+func canEdit(c *fiber.Ctx, guide *GameGuide, userID uuid.UUID) bool {
+	return guide.AuthorID == userID || utils.HasRole(c, user.RoleModerator, user.RoleAdmin)
+}
+
+func revisionError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, "revision not found")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
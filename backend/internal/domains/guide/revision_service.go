@@ -0,0 +1,96 @@
+package guide
+
+import (
+	"fmt"
+	"pokemon/pkg/content"
+
+	"github.com/google/uuid"
+)
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type revisionService interface {
+	list(guideID uuid.UUID, limit, offset int) ([]GameGuideRevision, int64, error)
+	get(guideID uuid.UUID, number int) (*GameGuideRevision, error)
+	diff(guideID uuid.UUID, from, to int) (*RevisionDiff, error)
+	rollback(guideID uuid.UUID, number int, editorID uuid.UUID) (*GameGuide, error)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type revisionServiceImpl struct {
+	repo   gameGuideRepository
+	guides gameGuideService
+}
+
+func newRevisionService(repo gameGuideRepository, guides gameGuideService) revisionService {
+	return &revisionServiceImpl{repo: repo, guides: guides}
+}
+
+func (s *revisionServiceImpl) list(guideID uuid.UUID, limit, offset int) ([]GameGuideRevision, int64, error) {
+	return s.repo.listRevisions(guideID, limit, offset)
+}
+
+func (s *revisionServiceImpl) get(guideID uuid.UUID, number int) (*GameGuideRevision, error) {
+	return s.repo.getRevision(guideID, number)
+}
+
+func (s *revisionServiceImpl) diff(guideID uuid.UUID, from, to int) (*RevisionDiff, error) {
+	older, err := s.repo.getRevision(guideID, from)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := s.repo.getRevision(guideID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RevisionDiff{
+		From: from,
+		To:   to,
+		Changes: Changes{
+			Title:   content.ChangeOf(older.Title, newer.Title),
+			Summary: content.ChangeOf(older.Summary, newer.Summary),
+			Content: content.Diff(older.Content, newer.Content),
+		},
+	}, nil
+}
+
+// rollback restores the text of an old revision. History is never rewritten:
+// the restored text becomes a new revision.
+func (s *revisionServiceImpl) rollback(guideID uuid.UUID, number int, editorID uuid.UUID) (*GameGuide, error) {
+	rev, err := s.repo.getRevision(guideID, number)
+	if err != nil {
+		return nil, err
+	}
+	guide, err := s.repo.getByID(guideID)
+	if err != nil {
+		return nil, err
+	}
+
+	guide.Title, guide.Summary, guide.Content = rev.Title, rev.Summary, rev.Content
+	guide.EditSummary = fmt.Sprintf("Rolled back to revision %d", number)
+	if err := s.guides.update(guide, editorID); err != nil {
+		return nil, err
+	}
+	return guide, nil
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func revisionOf(guide *GameGuide, authorID uuid.UUID, summary string) *GameGuideRevision {
+	return &GameGuideRevision{
+		GuideID:     guide.ID,
+		Title:       guide.Title,
+		Summary:     guide.Summary,
+		Content:     guide.Content,
+		AuthorID:    authorID,
+		EditSummary: summary,
+	}
+}
@@ -1,7 +1,9 @@
 package guide
 
 import (
+	"pokemon/internal/domains/user"
 	"pokemon/internal/middleware"
+	"pokemon/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -11,13 +13,43 @@ func (h *gameGuideHandler) RegisterRoutes(app fiber.Router) {
 
 	// Public routes
 	guides.Get("/", h.list)
-	guides.Get("/:id", h.getByID)
-	guides.Get("/slug/:slug", h.getBySlug)
-	guides.Get("/user/:user_id", h.listByUser)
+	guides.Get("/tags", h.listTags)
+	guides.Get("/tags/cloud", h.tagCloud)
+	guides.Get("/tags/:slug/related", h.relatedTags)
+	guides.Get("/:id", middleware.AuthOptional(), h.getByID)
+	guides.Get("/slug/:slug", middleware.AuthOptional(), h.getBySlug)
+	guides.Get("/user/:user_id", middleware.AuthOptional(), h.listByUser)
+	guides.Get("/:id/revisions", middleware.AuthOptional(), h.listRevisions)
+	guides.Get("/:id/revisions/:number", middleware.AuthOptional(), h.getRevision)
+	guides.Get("/:id/diff", middleware.AuthOptional(), h.diffRevisions)
+	guides.Get("/:id/suggestions", middleware.AuthOptional(), h.listSuggestions)
+	guides.Get("/:id/suggestions/:suggestion_id", middleware.AuthOptional(), h.getSuggestion)
+	guides.Get("/:id/ratings", middleware.AuthOptional(), h.listReviews)
+	guides.Get("/:id/sections", middleware.AuthOptional(), h.listSections)
 
 	// Authenticated routes
 	guides.Use(middleware.AuthRequired())
 	guides.Post("/", h.create)
+	guides.Get("/reports/inbox", h.reportInbox)
+	guides.Get("/reports/unread", h.unreadReports)
 	guides.Put("/:id", h.update)
 	guides.Delete("/:id", h.delete)
+	guides.Post("/:id/revisions/:number/rollback", h.rollback)
+	guides.Post("/:id/suggestions", h.suggestEdit)
+	guides.Post("/:id/suggestions/:suggestion_id/comments", h.commentSuggestion)
+	guides.Post("/:id/suggestions/:suggestion_id/accept", h.acceptSuggestion)
+	guides.Post("/:id/suggestions/:suggestion_id/reject", h.rejectSuggestion)
+	guides.Put("/:id/rating", h.rate)
+	guides.Delete("/:id/rating", h.unrate)
+	guides.Put("/:id/sections/:section/vote", h.voteSection)
+	guides.Delete("/:id/sections/:section/vote", h.unvoteSection)
+	guides.Post("/:id/reports", h.reportGuide)
+	guides.Get("/:id/reports", h.listReports)
+	guides.Post("/:id/reports/:report_id/resolve", h.resolveReport)
+	guides.Post("/:id/reports/:report_id/dismiss", h.dismissReport)
+
+	// Admin tag moderation
+	tags := guides.Group("/tags", utils.RoleMiddleware(user.RoleAdmin))
+	tags.Put("/:id", h.renameTag)
+	tags.Post("/:id/merge", h.mergeTag)
 }
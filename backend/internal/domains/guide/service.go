@@ -3,49 +3,87 @@ package guide
 import (
 	"context"
 	"encoding/json"
-	"errors"
+	"pokemon/pkg/content"
+	"pokemon/pkg/language"
+	"pokemon/pkg/publishing"
+	"pokemon/pkg/tagging"
+	"pokemon/pkg/utils"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 )
 
 /* GAME GUIDE */
 
+func redisGuideSlugKey(slug string) string {
+	return "game_guide_slug:" + slug
+}
+
 type gameGuideService interface {
 	create(guide *GameGuide) error
 	getByID(id uuid.UUID) (*GameGuide, error)
 	getBySlug(slug string) (*GameGuide, error)
-	update(guide *GameGuide) error
+	update(guide *GameGuide, editorID uuid.UUID) error
+	revise(guide *GameGuide, editorID uuid.UUID, base int) (int, error)
 	delete(id uuid.UUID) error
-	list(limit, offset int) ([]GameGuide, error)
-	listByAuthor(authorID uuid.UUID, limit, offset int) ([]GameGuide, error)
+	list(order listOrder, limit, offset int) ([]GameGuide, error)
+	listByAuthor(authorID uuid.UUID, publicOnly bool, order listOrder, limit, offset int) ([]GameGuide, error)
 	countByAuthor(authorID uuid.UUID) (int64, error)
 }
 
 type gameGuideServ struct {
-	repo  gameGuideRepository
-	redis *redis.Client
+	repo     gameGuideRepository
+	renderer content.Renderer
+	redis    *redis.Client
 }
 
-func newGameGuideService(repo gameGuideRepository, redis *redis.Client) gameGuideService {
-	return &gameGuideServ{repo: repo, redis: redis}
+func newGameGuideService(repo gameGuideRepository, renderer content.Renderer, redis *redis.Client) gameGuideService {
+	return &gameGuideServ{repo: repo, renderer: renderer, redis: redis}
 }
 
 func (s *gameGuideServ) create(guide *GameGuide) error {
 	if err := guide.Validate(); err != nil {
 		return err
 	}
-	return s.repo.create(guide)
+	if err := guide.State.Prepare(nil, time.Now()); err != nil {
+		return err
+	}
+	lang, err := language.Resolve(guide.Language, "")
+	if err != nil {
+		return err
+	}
+	guide.Language = lang
+	if guide.ID == uuid.Nil {
+		guide.ID = uuid.New()
+	}
+	summary := guide.EditSummary
+	if summary == "" {
+		summary = "Created"
+	}
+	if err := s.repo.create(guide, revisionOf(guide, guide.AuthorID, summary)); err != nil {
+		return err
+	}
+	s.render(guide)
+	return nil
 }
 
 func (s *gameGuideServ) getByID(id uuid.UUID) (*GameGuide, error) {
-	return s.repo.getByID(id)
+	guide, err := s.repo.getByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if guide.Contributors, err = s.repo.contributors(guide.ID, guide.AuthorID); err != nil {
+		return nil, err
+	}
+	s.render(guide)
+	return guide, nil
 }
 
 func (s *gameGuideServ) getBySlug(slug string) (*GameGuide, error) {
 	ctx := context.Background()
-	cacheKey := "game_guide_slug:" + slug
+	cacheKey := redisGuideSlugKey(slug)
 
 	// Check Redis cache
 	if cached, err := s.redis.Get(ctx, cacheKey).Result(); err == nil && cached != "" {
@@ -60,6 +98,10 @@ func (s *gameGuideServ) getBySlug(slug string) (*GameGuide, error) {
 	if err != nil {
 		return nil, err
 	}
+	if guide.Contributors, err = s.repo.contributors(guide.ID, guide.AuthorID); err != nil {
+		return nil, err
+	}
+	s.render(guide)
 
 	// Store in Redis
 	if data, err := json.Marshal(guide); err == nil {
@@ -69,37 +111,117 @@ func (s *gameGuideServ) getBySlug(slug string) (*GameGuide, error) {
 	return guide, nil
 }
 
-func (s *gameGuideServ) update(guide *GameGuide) error {
+// update saves the guide as a new revision credited to editorID.
+func (s *gameGuideServ) update(guide *GameGuide, editorID uuid.UUID) error {
+	_, err := s.save(guide, editorID, nil)
+	return err
+}
+
+// revise is update for an edit made against revision base of the guide. It
+// fails with errSuggestionStale when the guide's text changed since, and
+// returns the number of the new revision.
+func (s *gameGuideServ) revise(guide *GameGuide, editorID uuid.UUID, base int) (int, error) {
+	return s.save(guide, editorID, &base)
+}
+
+func (s *gameGuideServ) save(guide *GameGuide, editorID uuid.UUID, base *int) (int, error) {
 	if err := guide.Validate(); err != nil {
-		return err
+		return 0, err
+	}
+	previous, err := s.repo.getByID(guide.ID)
+	if err != nil {
+		return 0, err
+	}
+	if err := guide.State.Prepare(&previous.State, time.Now()); err != nil {
+		return 0, err
+	}
+	if guide.Language, err = language.Resolve(guide.Language, previous.Language); err != nil {
+		return 0, err
+	}
+	rev := revisionOf(guide, editorID, guide.EditSummary)
+	if err := s.repo.update(guide, previous, rev, base); err != nil {
+		return 0, err
 	}
-	return s.repo.update(guide)
+	s.render(guide)
+	s.redis.Del(context.Background(), redisGuideSlugKey(previous.Slug), redisGuideSlugKey(guide.Slug))
+	return rev.Number, nil
 }
 
 func (s *gameGuideServ) delete(id uuid.UUID) error {
 	return s.repo.delete(id)
 }
 
-func (s *gameGuideServ) list(limit, offset int) ([]GameGuide, error) {
-	return s.repo.list(limit, offset)
+func (s *gameGuideServ) list(order listOrder, limit, offset int) ([]GameGuide, error) {
+	guides, err := s.repo.list(order, limit, offset)
+	return s.renderAll(guides), err
 }
 
-func (s *gameGuideServ) listByAuthor(authorID uuid.UUID, limit, offset int) ([]GameGuide, error) {
-	return s.repo.listByUser(authorID, limit, offset)
+// listByAuthor includes drafts and scheduled guides unless publicOnly is set.
+func (s *gameGuideServ) listByAuthor(authorID uuid.UUID, publicOnly bool, order listOrder, limit, offset int) ([]GameGuide, error) {
+	guides, err := s.repo.listByUser(authorID, publicOnly, order, limit, offset)
+	return s.renderAll(guides), err
 }
 
 func (s *gameGuideServ) countByAuthor(authorID uuid.UUID) (int64, error) {
 	return s.repo.countByUser(authorID)
 }
 
+// render attaches the sanitized HTML of the Markdown content to the guide.
+func (s *gameGuideServ) render(guide *GameGuide) {
+	guide.Rendered = s.renderer.Render(context.Background(), guide.Content)
+}
+
+func (s *gameGuideServ) renderAll(guides []GameGuide) []GameGuide {
+	sources := make([]string, len(guides))
+	for i := range guides {
+		sources[i] = guides[i].Content
+	}
+	for i, rendered := range s.renderer.RenderAll(context.Background(), sources) {
+		guides[i].Rendered = rendered
+	}
+	return guides
+}
+
+// PublishScheduled returns the scheduler job publishing due guides.
+func PublishScheduled(db *gorm.DB, redis *redis.Client) publishing.Job {
+	return func(ctx context.Context, now time.Time) error {
+		ids, err := publishing.PublishDue(ctx, db, "game_guides", now)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		var slugs []string
+		if err := db.WithContext(ctx).Model(&GameGuide{}).Where("id IN ?", ids).Pluck("slug", &slugs).Error; err != nil {
+			return err
+		}
+
+		keys := []string{redisGuideTagCloudKey}
+		for _, slug := range slugs {
+			keys = append(keys, redisGuideSlugKey(slug))
+		}
+		return redis.Del(ctx, keys...).Err()
+	}
+}
+
 /* GAME GUIDE TAG */
 
 type gameGuideTagService interface {
 	create(tag *GameGuideTag) error
 	getByName(name string) (*GameGuideTag, error)
 	listAll() ([]GameGuideTag, error)
+
+	cloud(limit int) ([]tagging.Count, error)
+	related(slug string, limit int) ([]tagging.Count, error)
+	rename(id uuid.UUID, name string) error
+	merge(fromID, intoID uuid.UUID) error
 }
 
+const (
+	redisGuideTagsKey     = "game_guide_tags"
+	redisGuideTagCloudKey = "game_guide_tags:cloud"
+	maxGuideTagCloudSize  = 200
+)
+
 type gameGuideTagServ struct {
 	repo  gameGuideTagRepository
 	redis *redis.Client
@@ -110,10 +232,19 @@ func newGameGuideTagService(repo gameGuideTagRepository, redis *redis.Client) ga
 }
 
 func (s *gameGuideTagServ) create(tag *GameGuideTag) error {
-	if tag.Name == "" {
-		return errors.New("tag name is required")
+	name, slug, err := tagging.Normalize(tag.Name)
+	if err != nil {
+		return err
 	}
-	return s.repo.create(tag)
+	tag.Name, tag.Slug = name, slug
+	if tag.ID == uuid.Nil {
+		tag.ID = uuid.New()
+	}
+	if err := s.repo.create(tag); err != nil {
+		return err
+	}
+	s.redis.Del(context.Background(), redisGuideTagsKey)
+	return nil
 }
 
 func (s *gameGuideTagServ) getByName(name string) (*GameGuideTag, error) {
@@ -122,7 +253,7 @@ func (s *gameGuideTagServ) getByName(name string) (*GameGuideTag, error) {
 
 func (s *gameGuideTagServ) listAll() ([]GameGuideTag, error) {
 	ctx := context.Background()
-	cacheKey := "game_guide_tags"
+	cacheKey := redisGuideTagsKey
 
 	if cached, err := s.redis.Get(ctx, cacheKey).Result(); err == nil && cached != "" {
 		var tags []GameGuideTag
@@ -142,3 +273,53 @@ func (s *gameGuideTagServ) listAll() ([]GameGuideTag, error) {
 
 	return tags, nil
 }
+
+func (s *gameGuideTagServ) cloud(limit int) ([]tagging.Count, error) {
+	ctx := context.Background()
+
+	var counts []tagging.Count
+	if cached, err := s.redis.Get(ctx, redisGuideTagCloudKey).Result(); err == nil {
+		if err := json.Unmarshal([]byte(cached), &counts); err != nil {
+			counts = nil
+		}
+	}
+
+	if counts == nil {
+		var err error
+		if counts, err = s.repo.cloud(maxGuideTagCloudSize); err != nil {
+			return nil, err
+		}
+		if data, err := json.Marshal(counts); err == nil {
+			s.redis.Set(ctx, redisGuideTagCloudKey, data, 10*time.Minute)
+		}
+	}
+
+	if limit > 0 && limit < len(counts) {
+		counts = counts[:limit]
+	}
+	return counts, nil
+}
+
+func (s *gameGuideTagServ) related(slug string, limit int) ([]tagging.Count, error) {
+	tag, err := s.repo.getBySlug(utils.Slugify(slug))
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.related(tag.ID, limit)
+}
+
+func (s *gameGuideTagServ) rename(id uuid.UUID, name string) error {
+	if err := s.repo.rename(id, name); err != nil {
+		return err
+	}
+	s.redis.Del(context.Background(), redisGuideTagsKey, redisGuideTagCloudKey)
+	return nil
+}
+
+func (s *gameGuideTagServ) merge(fromID, intoID uuid.UUID) error {
+	if err := s.repo.merge(fromID, intoID); err != nil {
+		return err
+	}
+	s.redis.Del(context.Background(), redisGuideTagsKey, redisGuideTagCloudKey)
+	return nil
+}
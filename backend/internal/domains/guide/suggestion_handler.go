@@ -0,0 +1,193 @@
+package guide
+
+import (
+	"errors"
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+/* GAME GUIDE SUGGESTION */
+
+// POST /game-guides/:id/suggestions
+func (h *gameGuideHandler) suggestEdit(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	guide, err := h.visibleGuide(c)
+	if err != nil {
+		return err
+	}
+
+	var req suggestEditRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+
+	// Fields left out keep the current text
+	suggestion := GameGuideSuggestion{
+		AuthorID: userID,
+		Title:    guide.Title,
+		Summary:  guide.Summary,
+		Content:  guide.Content,
+		Message:  req.Message,
+	}
+	if req.Title != nil {
+		suggestion.Title = *req.Title
+	}
+	if req.Summary != nil {
+		suggestion.Summary = *req.Summary
+	}
+	if req.Content != nil {
+		suggestion.Content = *req.Content
+	}
+
+	if err := suggestion.Validate(); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := h.suggestions.propose(guide, &suggestion); err != nil {
+		return suggestionError(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(suggestion)
+}
+
+// GET /game-guides/:id/suggestions?status=
+func (h *gameGuideHandler) listSuggestions(c *fiber.Ctx) error {
+	guide, err := h.visibleGuide(c)
+	if err != nil {
+		return err
+	}
+	status := c.Query("status")
+	switch status {
+	case "", SuggestionPending, SuggestionAccepted, SuggestionRejected:
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "invalid status")
+	}
+
+	limit, offset := utils.ParsePagination(c)
+	suggestions, total, err := h.suggestions.list(guide.ID, status, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": total,
+		"items": suggestions,
+	})
+}
+
+// GET /game-guides/:id/suggestions/:suggestion_id
+func (h *gameGuideHandler) getSuggestion(c *fiber.Ctx) error {
+	guide, err := h.visibleGuide(c)
+	if err != nil {
+		return err
+	}
+	id, err := uuid.Parse(c.Params("suggestion_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid suggestion id")
+	}
+
+	suggestion, err := h.suggestions.get(guide, id)
+	if err != nil {
+		return suggestionError(err)
+	}
+	return c.JSON(suggestion)
+}
+
+// POST /game-guides/:id/suggestions/:suggestion_id/comments
+func (h *gameGuideHandler) commentSuggestion(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	guide, err := h.visibleGuide(c)
+	if err != nil {
+		return err
+	}
+	id, err := uuid.Parse(c.Params("suggestion_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid suggestion id")
+	}
+
+	var req suggestionCommentRequest
+	if err := c.BodyParser(&req); err != nil || req.Content == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "content is required")
+	}
+
+	// The review is a conversation between the proposer and the editors
+	suggestion, err := h.suggestions.get(guide, id)
+	if err != nil {
+		return suggestionError(err)
+	}
+	if suggestion.AuthorID != userID && !canEdit(c, guide, userID) {
+		return fiber.NewError(fiber.StatusForbidden, "only the proposer, the author or a moderator can comment on this suggestion")
+	}
+
+	comment := GameGuideSuggestionComment{SuggestionID: id, UserID: userID, Content: req.Content}
+	if err := h.suggestions.comment(&comment); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(comment)
+}
+
+// POST /game-guides/:id/suggestions/:suggestion_id/accept
+func (h *gameGuideHandler) acceptSuggestion(c *fiber.Ctx) error {
+	return h.reviewSuggestion(c, h.suggestions.accept)
+}
+
+// POST /game-guides/:id/suggestions/:suggestion_id/reject
+func (h *gameGuideHandler) rejectSuggestion(c *fiber.Ctx) error {
+	return h.reviewSuggestion(c, h.suggestions.reject)
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+type reviewFunc func(guide *GameGuide, id, reviewerID uuid.UUID, note string) (*GameGuideSuggestion, error)
+
+func (h *gameGuideHandler) reviewSuggestion(c *fiber.Ctx, review reviewFunc) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	guide, err := h.visibleGuide(c)
+	if err != nil {
+		return err
+	}
+	if !canEdit(c, guide, userID) {
+		return fiber.NewError(fiber.StatusForbidden, "only the author or a moderator can review suggestions")
+	}
+	id, err := uuid.Parse(c.Params("suggestion_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid suggestion id")
+	}
+
+	var req reviewSuggestionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+		}
+	}
+
+	suggestion, err := review(guide, id, userID, req.Note)
+	if err != nil {
+		return suggestionError(err)
+	}
+	return c.JSON(suggestion)
+}
+
+func suggestionError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "suggestion not found")
+	case errors.Is(err, errSuggestionReviewed), errors.Is(err, errSuggestionStale):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, errSuggestionUnchanged):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,149 @@
+package guide
+
+import (
+	"errors"
+	"pokemon/pkg/content"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errSuggestionReviewed  = errors.New("suggestion has already been reviewed")
+	errSuggestionUnchanged = errors.New("suggestion doesn't change the guide")
+	errSuggestionStale     = errors.New("the guide changed since the suggestion was made, it has to be proposed again against the current text")
+)
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type suggestionService interface {
+	propose(guide *GameGuide, suggestion *GameGuideSuggestion) error
+	list(guideID uuid.UUID, status string, limit, offset int) ([]GameGuideSuggestion, int64, error)
+	get(guide *GameGuide, id uuid.UUID) (*GameGuideSuggestion, error)
+	comment(comment *GameGuideSuggestionComment) error
+	accept(guide *GameGuide, id, reviewerID uuid.UUID, note string) (*GameGuideSuggestion, error)
+	reject(guide *GameGuide, id, reviewerID uuid.UUID, note string) (*GameGuideSuggestion, error)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type suggestionServiceImpl struct {
+	repo       suggestionRepository
+	guidesRepo gameGuideRepository
+	guides     gameGuideService
+}
+
+func newSuggestionService(repo suggestionRepository, guidesRepo gameGuideRepository, guides gameGuideService) suggestionService {
+	return &suggestionServiceImpl{repo: repo, guidesRepo: guidesRepo, guides: guides}
+}
+
+// propose records a pending suggestion against the current revision of the
+// guide.
+func (s *suggestionServiceImpl) propose(guide *GameGuide, suggestion *GameGuideSuggestion) error {
+	if err := suggestion.Validate(); err != nil {
+		return err
+	}
+	if changesOf(guide, suggestion) == nil {
+		return errSuggestionUnchanged
+	}
+
+	base, err := s.guidesRepo.latestRevision(guide.ID)
+	if err != nil {
+		return err
+	}
+	suggestion.GuideID = guide.ID
+	suggestion.BaseRevision = base
+	suggestion.Status = SuggestionPending
+	if err := s.repo.create(suggestion); err != nil {
+		return err
+	}
+	suggestion.Changes = changesOf(guide, suggestion)
+	return nil
+}
+
+func (s *suggestionServiceImpl) list(guideID uuid.UUID, status string, limit, offset int) ([]GameGuideSuggestion, int64, error) {
+	return s.repo.list(guideID, status, limit, offset)
+}
+
+// get attaches the diff against the current guide to pending suggestions;
+// reviewed ones are already part of the history or were discarded.
+func (s *suggestionServiceImpl) get(guide *GameGuide, id uuid.UUID) (*GameGuideSuggestion, error) {
+	suggestion, err := s.repo.getByID(guide.ID, id)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion.Status == SuggestionPending {
+		suggestion.Changes = changesOf(guide, suggestion)
+	}
+	return suggestion, nil
+}
+
+func (s *suggestionServiceImpl) comment(comment *GameGuideSuggestionComment) error {
+	return s.repo.addComment(comment)
+}
+
+// accept applies the suggestion as a new revision credited to its proposer.
+// The suggestion is claimed first so it can't be applied twice, and is only
+// applied if the guide's text is still the one it was proposed against.
+func (s *suggestionServiceImpl) accept(guide *GameGuide, id, reviewerID uuid.UUID, note string) (*GameGuideSuggestion, error) {
+	suggestion, err := s.repo.getByID(guide.ID, id)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion.Status != SuggestionPending {
+		return nil, errSuggestionReviewed
+	}
+	if err := s.repo.review(id, SuggestionAccepted, reviewerID, note); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.guidesRepo.getByID(guide.ID)
+	if err != nil {
+		s.repo.reopen(id)
+		return nil, err
+	}
+	updated.Title, updated.Summary, updated.Content = suggestion.Title, suggestion.Summary, suggestion.Content
+	updated.EditSummary = suggestion.Message
+	if updated.EditSummary == "" {
+		updated.EditSummary = "Suggested edit"
+	}
+	number, err := s.guides.revise(updated, suggestion.AuthorID, suggestion.BaseRevision)
+	if err != nil {
+		s.repo.reopen(id)
+		return nil, err
+	}
+	if err := s.repo.setRevision(id, number); err != nil {
+		return nil, err
+	}
+	return s.repo.getByID(guide.ID, id)
+}
+
+func (s *suggestionServiceImpl) reject(guide *GameGuide, id, reviewerID uuid.UUID, note string) (*GameGuideSuggestion, error) {
+	if _, err := s.repo.getByID(guide.ID, id); err != nil {
+		return nil, err
+	}
+	if err := s.repo.review(id, SuggestionRejected, reviewerID, note); err != nil {
+		return nil, err
+	}
+	return s.repo.getByID(guide.ID, id)
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// changesOf returns nil when the suggestion matches the guide.
+func changesOf(guide *GameGuide, suggestion *GameGuideSuggestion) *Changes {
+	changes := &Changes{
+		Title:   content.ChangeOf(guide.Title, suggestion.Title),
+		Summary: content.ChangeOf(guide.Summary, suggestion.Summary),
+	}
+	if changes.Title == nil && changes.Summary == nil && guide.Content == suggestion.Content {
+		return nil
+	}
+	changes.Content = content.Diff(guide.Content, suggestion.Content)
+	return changes
+}
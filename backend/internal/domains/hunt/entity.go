@@ -0,0 +1,182 @@
+package hunt
+
+import (
+	"errors"
+	"pokemon/internal/domains/game"
+	"pokemon/internal/domains/user"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type HuntMethod string
+
+const (
+	MethodFullOdds         HuntMethod = "full_odds"
+	MethodSoftReset        HuntMethod = "soft_reset"
+	MethodMasuda           HuntMethod = "masuda"
+	MethodPokeRadar        HuntMethod = "poke_radar"
+	MethodChainFishing     HuntMethod = "chain_fishing"
+	MethodSOS              HuntMethod = "sos"
+	MethodOutbreak         HuntMethod = "outbreak"
+	MethodDynamaxAdventure HuntMethod = "dynamax_adventure"
+)
+
+type HuntStatus string
+
+const (
+	StatusActive    HuntStatus = "active"
+	StatusPaused    HuntStatus = "paused"
+	StatusCompleted HuntStatus = "completed"
+	StatusAbandoned HuntStatus = "abandoned"
+)
+
+type ShinyHunt struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	User      user.User `json:"user" gorm:"foreignKey:UserID"`
+	GameID    uuid.UUID `json:"game_id" gorm:"type:uuid;not null;index"`
+	Game      game.Game `json:"game" gorm:"foreignKey:GameID"`
+	PokemonID int       `json:"pokemon_id" gorm:"type:int;not null"` // National Dex number of the target
+
+	Method     HuntMethod `json:"method" gorm:"type:varchar(32);not null;default:'full_odds'"`
+	ShinyCharm bool       `json:"shiny_charm" gorm:"default:false"`
+	Chain      int        `json:"chain" gorm:"type:int;default:0"` // radar chain, SOS chain, fishing streak or outbreak KOs
+
+	Encounters      int `json:"encounters" gorm:"type:int;default:0"`       // across all phases
+	PhaseEncounters int `json:"phase_encounters" gorm:"type:int;default:0"` // since the last phase
+
+	// Time spent is accumulated on pause; ResumedAt is set while the timer runs.
+	TimeSpentSeconds int64      `json:"time_spent_seconds" gorm:"default:0"`
+	ResumedAt        *time.Time `json:"resumed_at"`
+
+	Status      HuntStatus `json:"status" gorm:"type:varchar(16);not null;default:'active';index"`
+	IsPublic    bool       `json:"is_public"` // set to true by the create handler unless sent
+	Notes       string     `json:"notes" gorm:"type:text"`
+	CompletedAt *time.Time `json:"completed_at"`
+
+	Phases []ShinyHuntPhase `json:"phases" gorm:"foreignKey:HuntID;constraint:OnDelete:CASCADE"`
+	Odds   *HuntOdds        `json:"odds,omitempty" gorm:"-"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// A phase ends when a shiny that is not the target shows up.
+type ShinyHuntPhase struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	HuntID     uuid.UUID `json:"hunt_id" gorm:"type:uuid;not null;index"`
+	Number     int       `json:"number" gorm:"type:int;not null"`
+	PokemonID  int       `json:"pokemon_id" gorm:"type:int;not null"` // the off-target shiny
+	Encounters int       `json:"encounters" gorm:"type:int;not null"`
+	Caught     bool      `json:"caught" gorm:"default:false"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+/************
+ * REQUESTS *
+ ************/
+
+// updateRequest holds the fields an owner can edit; absent ones are kept.
+// The game, target, status and timer can't change once the hunt exists.
+type updateRequest struct {
+	Method          *HuntMethod `json:"method"`
+	ShinyCharm      *bool       `json:"shiny_charm"`
+	Chain           *int        `json:"chain"`
+	Encounters      *int        `json:"encounters"`
+	PhaseEncounters *int        `json:"phase_encounters"`
+	IsPublic        *bool       `json:"is_public"`
+	Notes           *string     `json:"notes"`
+}
+
+func (r *updateRequest) apply(h *ShinyHunt) {
+	if r.Method != nil {
+		h.Method = *r.Method
+	}
+	if r.ShinyCharm != nil {
+		h.ShinyCharm = *r.ShinyCharm
+	}
+	if r.Chain != nil {
+		h.Chain = *r.Chain
+	}
+	if r.Encounters != nil {
+		h.Encounters = *r.Encounters
+	}
+	if r.PhaseEncounters != nil {
+		h.PhaseEncounters = *r.PhaseEncounters
+	}
+	if r.IsPublic != nil {
+		h.IsPublic = *r.IsPublic
+	}
+	if r.Notes != nil {
+		h.Notes = *r.Notes
+	}
+}
+
+type encounterRequest struct {
+	Count int `json:"count"` // defaults to 1, negative values undo
+}
+
+type phaseRequest struct {
+	PokemonID int  `json:"pokemon_id"`
+	Caught    bool `json:"caught"`
+}
+
+/****************
+ * API RESPONSE *
+ ****************/
+
+type HuntOdds struct {
+	Rolls            int     `json:"rolls"`             // shiny rolls per encounter, 0 for fixed-rate methods
+	OneIn            int     `json:"one_in"`            // rounded "1 in N" odds
+	Probability      float64 `json:"probability"`       // per encounter
+	CumulativeChance float64 `json:"cumulative_chance"` // chance of having seen it by now
+}
+
+/**************
+ * VALIDATION *
+ **************/
+
+func (h *ShinyHunt) Validate() error {
+	h.Notes = strings.TrimSpace(h.Notes)
+	if h.PokemonID <= 0 {
+		return errors.New("pokemon_id must be a positive integer")
+	}
+	if h.GameID == uuid.Nil {
+		return errors.New("game_id is required")
+	}
+	if h.Method == "" {
+		h.Method = MethodFullOdds
+	}
+	if !h.Method.valid() {
+		return errors.New("unknown hunt method")
+	}
+	if h.Chain < 0 || h.Encounters < 0 || h.PhaseEncounters < 0 {
+		return errors.New("chain and encounters cannot be negative")
+	}
+	if len(h.Notes) > 2000 {
+		return errors.New("notes cannot exceed 2000 characters")
+	}
+	return nil
+}
+
+func (m HuntMethod) valid() bool {
+	switch m {
+	case MethodFullOdds, MethodSoftReset, MethodMasuda, MethodPokeRadar,
+		MethodChainFishing, MethodSOS, MethodOutbreak, MethodDynamaxAdventure:
+		return true
+	}
+	return false
+}
+
+// elapsed returns the time spent including the currently running segment.
+func (h *ShinyHunt) elapsed(now time.Time) int64 {
+	total := h.TimeSpentSeconds
+	if h.ResumedAt != nil {
+		total += int64(now.Sub(*h.ResumedAt).Seconds())
+	}
+	return total
+}
@@ -0,0 +1,226 @@
+package hunt
+
+import (
+	"context"
+	"errors"
+	"pokemon/internal/domains/game"
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	s huntService
+}
+
+func NewHandler(db *gorm.DB, redis *redis.Client) *handler {
+	repo := newHuntRepo(db)
+	serv := newHuntService(repo, game.NewPokedexMarker(db, redis), redis)
+
+	return &handler{s: serv}
+}
+
+func (h *handler) create(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	// Hunts are public unless the body says otherwise
+	hunt := ShinyHunt{IsPublic: true}
+	if err := c.BodyParser(&hunt); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	hunt.ID = uuid.Nil
+	hunt.UserID = userID
+
+	if err := h.s.create(c.Context(), &hunt); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(hunt)
+}
+
+// GET /hunts/:id - private hunts are only visible to their hunter
+func (h *handler) get(c *fiber.Ctx) error {
+	hunt, err := h.s.getByID(c.Context(), c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "hunt not found")
+	}
+
+	userID, _ := utils.GetUserIDFromLocals(c)
+	if !hunt.IsPublic && hunt.UserID != userID {
+		return fiber.NewError(fiber.StatusNotFound, "hunt not found")
+	}
+	return c.JSON(hunt)
+}
+
+// GET /hunts/me
+func (h *handler) listMine(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	limit, offset := utils.ParsePagination(c)
+	list, total, err := h.s.listByUser(c.Context(), userID.String(), false, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": total,
+		"items": list,
+	})
+}
+
+// GET /hunts/user/:user_id - public hunt log
+func (h *handler) listByUser(c *fiber.Ctx) error {
+	ownerID := utils.ParseUUID(c.Params("user_id"))
+	if ownerID == uuid.Nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID")
+	}
+
+	userID, _ := utils.GetUserIDFromLocals(c)
+	limit, offset := utils.ParsePagination(c)
+
+	list, total, err := h.s.listByUser(c.Context(), ownerID.String(), ownerID != userID, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": total,
+		"items": list,
+	})
+}
+
+func (h *handler) update(c *fiber.Ctx) error {
+	hunt, err := h.ownHunt(c)
+	if err != nil {
+		return err
+	}
+
+	// Status and timer only move through their own endpoints
+	var req updateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.ErrBadRequest
+	}
+	req.apply(hunt)
+
+	if err := h.s.update(c.Context(), hunt); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.JSON(hunt)
+}
+
+func (h *handler) delete(c *fiber.Ctx) error {
+	hunt, err := h.ownHunt(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.s.delete(c.Context(), hunt.ID.String()); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// POST /hunts/:id/encounters
+func (h *handler) addEncounters(c *fiber.Ctx) error {
+	hunt, err := h.ownHunt(c)
+	if err != nil {
+		return err
+	}
+
+	req := encounterRequest{Count: 1}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.ErrBadRequest
+		}
+	}
+	if req.Count == 0 || hunt.PhaseEncounters+req.Count < 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid encounter count")
+	}
+
+	updated, err := h.s.addEncounters(c.Context(), hunt, req.Count)
+	if err != nil {
+		return huntError(err)
+	}
+	return c.JSON(updated)
+}
+
+// POST /hunts/:id/phases
+func (h *handler) addPhase(c *fiber.Ctx) error {
+	hunt, err := h.ownHunt(c)
+	if err != nil {
+		return err
+	}
+
+	var req phaseRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	updated, err := h.s.addPhase(c.Context(), hunt, req.PokemonID, req.Caught)
+	if err != nil {
+		return huntError(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(updated)
+}
+
+func (h *handler) pause(c *fiber.Ctx) error {
+	return h.transition(c, h.s.pause)
+}
+
+func (h *handler) resume(c *fiber.Ctx) error {
+	return h.transition(c, h.s.resume)
+}
+
+func (h *handler) complete(c *fiber.Ctx) error {
+	return h.transition(c, h.s.complete)
+}
+
+func (h *handler) abandon(c *fiber.Ctx) error {
+	return h.transition(c, h.s.abandon)
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func (h *handler) transition(c *fiber.Ctx, fn func(ctx context.Context, hunt *ShinyHunt) error) error {
+	hunt, err := h.ownHunt(c)
+	if err != nil {
+		return err
+	}
+	if err := fn(c.Context(), hunt); err != nil {
+		return huntError(err)
+	}
+	return c.JSON(hunt)
+}
+
+// ownHunt loads the hunt from :id and makes sure it belongs to the caller.
+func (h *handler) ownHunt(c *fiber.Ctx) (*ShinyHunt, error) {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return nil, fiber.ErrUnauthorized
+	}
+
+	hunt, err := h.s.getByID(c.Context(), c.Params("id"))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "hunt not found")
+	}
+	if hunt.UserID != userID {
+		return nil, fiber.ErrForbidden
+	}
+	return hunt, nil
+}
+
+func huntError(err error) error {
+	if errors.Is(err, errHuntClosed) {
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	}
+	return fiber.NewError(fiber.StatusBadRequest, err.Error())
+}
@@ -0,0 +1,12 @@
+package hunt
+
+import "gorm.io/gorm"
+
+type HuntMigrator struct{}
+
+func (m HuntMigrator) Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&ShinyHunt{},
+		&ShinyHuntPhase{},
+	)
+}
@@ -0,0 +1,139 @@
+package hunt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"pokemon/internal/domains/game"
+	"pokemon/pkg/utils"
+	"strings"
+)
+
+var errNoShinies = errors.New("shiny Pokémon do not exist before generation 2")
+
+// baseOdds is the full odds denominator for a single shiny roll.
+func baseOdds(generation int) float64 {
+	if generation >= 6 {
+		return 4096
+	}
+	return 8192
+}
+
+// legendsArceus tells Legends: Arceus apart from the other generation 8
+// games, whose outbreaks give no extra rolls.
+func legendsArceus(g *game.Game) bool {
+	return strings.HasSuffix(utils.Slugify(g.Name), "legends-arceus")
+}
+
+// computeOdds returns the per-encounter shiny odds for a method in a given
+// game. Most methods work by adding extra shiny rolls on top of the base
+// roll; the Poké Radar and Dynamax Adventures use their own rates.
+func computeOdds(g *game.Game, method HuntMethod, charm bool, chain int) (HuntOdds, error) {
+	generation := g.Generation
+	if generation < 2 {
+		return HuntOdds{}, errNoShinies
+	}
+	if err := checkMethod(generation, method); err != nil {
+		return HuntOdds{}, err
+	}
+
+	// The Shiny Charm exists since Black 2 / White 2 and adds two rolls
+	charmRolls := 0
+	if charm && generation >= 5 {
+		charmRolls = 2
+	}
+
+	switch method {
+	case MethodDynamaxAdventure:
+		if charm {
+			return oddsFromProbability(0, 1.0/100), nil
+		}
+		return oddsFromProbability(0, 1.0/300), nil
+
+	case MethodPokeRadar:
+		chain = min(chain, 40)
+		if generation == 8 {
+			// Brilliant Diamond / Shining Pearl only boost a full 40 chain
+			if chain == 40 {
+				return oddsFromProbability(0, 1.0/99), nil
+			}
+			return oddsFromRolls(1+charmRolls, baseOdds(generation)), nil
+		}
+		// DPPt and XY: floor(65535 / (8200 - chain*200)) / 65536
+		p := math.Floor(65535/float64(8200-chain*200)) / 65536
+		return oddsFromProbability(0, p), nil
+	}
+
+	rolls := 1 + charmRolls
+	switch method {
+	case MethodMasuda:
+		if generation == 4 {
+			rolls += 4
+		} else {
+			rolls += 5
+		}
+	case MethodChainFishing:
+		rolls += 2 * min(chain, 20)
+	case MethodSOS:
+		switch {
+		case chain >= 31:
+			rolls += 12
+		case chain >= 21:
+			rolls += 8
+		case chain >= 11:
+			rolls += 4
+		}
+	case MethodOutbreak:
+		if legendsArceus(g) {
+			// Legends: Arceus mass outbreaks
+			rolls += 25
+		} else if generation == 9 && chain >= 60 {
+			rolls += 2
+		} else if generation == 9 && chain >= 30 {
+			rolls++
+		}
+	}
+	return oddsFromRolls(rolls, baseOdds(generation)), nil
+}
+
+// checkMethod rejects methods that do not exist in the hunt's generation.
+func checkMethod(generation int, method HuntMethod) error {
+	ok := true
+	switch method {
+	case MethodMasuda:
+		ok = generation >= 4
+	case MethodPokeRadar:
+		ok = generation == 4 || generation == 6 || generation == 8
+	case MethodChainFishing:
+		ok = generation == 6
+	case MethodSOS:
+		ok = generation == 7
+	case MethodOutbreak:
+		ok = generation == 8 || generation == 9
+	case MethodDynamaxAdventure:
+		ok = generation == 8
+	}
+	if !ok {
+		return fmt.Errorf("method %s is not available in generation %d", method, generation)
+	}
+	return nil
+}
+
+func oddsFromRolls(rolls int, base float64) HuntOdds {
+	p := 1 - math.Pow(1-1/base, float64(rolls))
+	return oddsFromProbability(rolls, p)
+}
+
+func oddsFromProbability(rolls int, p float64) HuntOdds {
+	return HuntOdds{
+		Rolls:       rolls,
+		OneIn:       int(math.Round(1 / p)),
+		Probability: p,
+	}
+}
+
+// withEncounters fills the chance of having hit the odds at least once.
+func (o HuntOdds) withEncounters(encounters int) HuntOdds {
+	o.CumulativeChance = 1 - math.Pow(1-o.Probability, float64(encounters))
+	return o
+}
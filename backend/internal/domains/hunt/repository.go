@@ -0,0 +1,108 @@
+package hunt
+
+import (
+	"context"
+	"pokemon/internal/domains/game"
+
+	"gorm.io/gorm"
+)
+
+/******************************
+ ******************************
+ ************ MAIN ************
+ ******************************
+ ******************************/
+
+type huntRepository interface {
+	listByUser(ctx context.Context, userID string, publicOnly bool, limit, offset int) ([]ShinyHunt, int64, error)
+
+	create(ctx context.Context, hunt *ShinyHunt) error
+	getByID(ctx context.Context, id string) (*ShinyHunt, error)
+	update(ctx context.Context, hunt *ShinyHunt) error
+	delete(ctx context.Context, id string) error
+
+	addEncounters(ctx context.Context, id string, count int) error
+	addPhase(ctx context.Context, hunt *ShinyHunt, phase *ShinyHuntPhase) error
+
+	getGame(ctx context.Context, id string) (*game.Game, error)
+}
+
+type huntRepoImpl struct {
+	db *gorm.DB
+}
+
+func newHuntRepo(db *gorm.DB) huntRepository {
+	return &huntRepoImpl{db: db}
+}
+
+func (r *huntRepoImpl) listByUser(ctx context.Context, userID string, publicOnly bool, limit, offset int) ([]ShinyHunt, int64, error) {
+	var hunts []ShinyHunt
+	var count int64
+
+	tx := r.db.WithContext(ctx).Model(&ShinyHunt{}).Where("user_id = ?", userID)
+	if publicOnly {
+		tx = tx.Where("is_public = ?", true)
+	}
+	if err := tx.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := tx.Preload("Game").
+		Preload("Phases", func(db *gorm.DB) *gorm.DB { return db.Order("number ASC") }).
+		Order("updated_at DESC").Limit(limit).Offset(offset).
+		Find(&hunts).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return hunts, count, nil
+}
+
+func (r *huntRepoImpl) create(ctx context.Context, hunt *ShinyHunt) error {
+	return r.db.WithContext(ctx).Omit("User", "Game").Create(hunt).Error
+}
+
+func (r *huntRepoImpl) getByID(ctx context.Context, id string) (*ShinyHunt, error) {
+	var hunt ShinyHunt
+	err := r.db.WithContext(ctx).
+		Preload("Game").Preload("User").
+		Preload("Phases", func(db *gorm.DB) *gorm.DB { return db.Order("number ASC") }).
+		First(&hunt, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &hunt, nil
+}
+
+func (r *huntRepoImpl) update(ctx context.Context, hunt *ShinyHunt) error {
+	return r.db.WithContext(ctx).Omit("User", "Game", "Phases").Save(hunt).Error
+}
+
+func (r *huntRepoImpl) delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&ShinyHunt{}, "id = ?", id).Error
+}
+
+func (r *huntRepoImpl) addEncounters(ctx context.Context, id string, count int) error {
+	return r.db.WithContext(ctx).Model(&ShinyHunt{}).Where("id = ?", id).Updates(map[string]any{
+		"encounters":       gorm.Expr("encounters + ?", count),
+		"phase_encounters": gorm.Expr("phase_encounters + ?", count),
+	}).Error
+}
+
+// addPhase stores the phase and resets the hunt's phase counter in one transaction.
+func (r *huntRepoImpl) addPhase(ctx context.Context, hunt *ShinyHunt, phase *ShinyHuntPhase) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(phase).Error; err != nil {
+			return err
+		}
+		return tx.Model(&ShinyHunt{}).Where("id = ?", hunt.ID).Update("phase_encounters", 0).Error
+	})
+}
+
+func (r *huntRepoImpl) getGame(ctx context.Context, id string) (*game.Game, error) {
+	var g game.Game
+	if err := r.db.WithContext(ctx).First(&g, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
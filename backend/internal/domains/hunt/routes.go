@@ -0,0 +1,28 @@
+package hunt
+
+import (
+	"pokemon/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *handler) RegisterRoutes(router fiber.Router) {
+	group := router.Group("/hunts")
+
+	// Public hunt log
+	group.Get("/me", middleware.AuthRequired(), h.listMine)
+	group.Get("/user/:user_id", middleware.AuthOptional(), h.listByUser)
+	group.Get("/:id", middleware.AuthOptional(), h.get)
+
+	group.Use(middleware.AuthRequired())
+	group.Post("/", h.create)
+	group.Put("/:id", h.update)
+	group.Delete("/:id", h.delete)
+
+	group.Post("/:id/encounters", h.addEncounters)
+	group.Post("/:id/phases", h.addPhase)
+	group.Post("/:id/pause", h.pause)
+	group.Post("/:id/resume", h.resume)
+	group.Post("/:id/complete", h.complete)
+	group.Post("/:id/abandon", h.abandon)
+}
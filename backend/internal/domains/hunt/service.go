@@ -0,0 +1,259 @@
+package hunt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"pokemon/internal/domains/game"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+var errHuntClosed = errors.New("hunt is already completed or abandoned")
+
+type huntService interface {
+	listByUser(ctx context.Context, userID string, publicOnly bool, limit, offset int) ([]ShinyHunt, int64, error)
+
+	create(ctx context.Context, hunt *ShinyHunt) error
+	getByID(ctx context.Context, id string) (*ShinyHunt, error)
+	update(ctx context.Context, hunt *ShinyHunt) error
+	delete(ctx context.Context, id string) error
+
+	addEncounters(ctx context.Context, hunt *ShinyHunt, count int) (*ShinyHunt, error)
+	addPhase(ctx context.Context, hunt *ShinyHunt, pokemonID int, caught bool) (*ShinyHunt, error)
+	pause(ctx context.Context, hunt *ShinyHunt) error
+	resume(ctx context.Context, hunt *ShinyHunt) error
+	complete(ctx context.Context, hunt *ShinyHunt) error
+	abandon(ctx context.Context, hunt *ShinyHunt) error
+}
+
+/********************
+ * REDIS KEY UTILS  *
+ ********************/
+
+func redisHuntKey(id string) string {
+	return fmt.Sprintf("hunt:%s", id)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type huntServiceImpl struct {
+	db      huntRepository
+	pokedex game.PokedexMarker
+	cache   *redis.Client
+}
+
+func newHuntService(repo huntRepository, pokedex game.PokedexMarker, cache *redis.Client) huntService {
+	return &huntServiceImpl{db: repo, pokedex: pokedex, cache: cache}
+}
+
+func (s *huntServiceImpl) listByUser(ctx context.Context, userID string, publicOnly bool, limit, offset int) ([]ShinyHunt, int64, error) {
+	hunts, total, err := s.db.listByUser(ctx, userID, publicOnly, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range hunts {
+		s.fillOdds(&hunts[i])
+	}
+	return hunts, total, nil
+}
+
+func (s *huntServiceImpl) create(ctx context.Context, hunt *ShinyHunt) error {
+	if err := hunt.Validate(); err != nil {
+		return err
+	}
+	g, err := s.db.getGame(ctx, hunt.GameID.String())
+	if err != nil {
+		return errors.New("game not found")
+	}
+	if _, err := computeOdds(g, hunt.Method, hunt.ShinyCharm, hunt.Chain); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	hunt.Status = StatusActive
+	hunt.ResumedAt = &now
+	hunt.CompletedAt = nil
+	hunt.PhaseEncounters = hunt.Encounters
+
+	if err := s.db.create(ctx, hunt); err != nil {
+		return err
+	}
+	hunt.Game = *g
+	s.fillOdds(hunt)
+	return nil
+}
+
+func (s *huntServiceImpl) getByID(ctx context.Context, id string) (*ShinyHunt, error) {
+	cacheKey := redisHuntKey(id)
+
+	val, err := s.cache.Get(ctx, cacheKey).Result()
+	if err == nil {
+		var hunt ShinyHunt
+		if err := json.Unmarshal([]byte(val), &hunt); err == nil {
+			s.fillOdds(&hunt)
+			return &hunt, nil
+		}
+	}
+
+	hunt, err := s.db.getByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, _ := json.Marshal(hunt)
+	s.cache.Set(ctx, cacheKey, bytes, 10*time.Minute)
+
+	s.fillOdds(hunt)
+	return hunt, nil
+}
+
+func (s *huntServiceImpl) update(ctx context.Context, hunt *ShinyHunt) error {
+	if err := hunt.Validate(); err != nil {
+		return err
+	}
+	if _, err := computeOdds(&hunt.Game, hunt.Method, hunt.ShinyCharm, hunt.Chain); err != nil {
+		return err
+	}
+	return s.save(ctx, hunt)
+}
+
+func (s *huntServiceImpl) delete(ctx context.Context, id string) error {
+	if err := s.db.delete(ctx, id); err != nil {
+		return err
+	}
+	s.cache.Del(ctx, redisHuntKey(id))
+	return nil
+}
+
+func (s *huntServiceImpl) addEncounters(ctx context.Context, hunt *ShinyHunt, count int) (*ShinyHunt, error) {
+	if hunt.isClosed() {
+		return nil, errHuntClosed
+	}
+	id := hunt.ID.String()
+	if err := s.db.addEncounters(ctx, id, count); err != nil {
+		return nil, err
+	}
+	s.cache.Del(ctx, redisHuntKey(id))
+	return s.getByID(ctx, id)
+}
+
+// addPhase closes the current phase on an off-target shiny. When it was caught
+// it also lands in the user's shiny pokedex.
+func (s *huntServiceImpl) addPhase(ctx context.Context, hunt *ShinyHunt, pokemonID int, caught bool) (*ShinyHunt, error) {
+	if hunt.isClosed() {
+		return nil, errHuntClosed
+	}
+	if pokemonID <= 0 {
+		return nil, errors.New("pokemon_id must be a positive integer")
+	}
+
+	if caught {
+		if err := s.pokedex.MarkCaptured(ctx, hunt.UserID, hunt.GameID, []int{pokemonID}, true); err != nil {
+			return nil, err
+		}
+	}
+
+	phase := ShinyHuntPhase{
+		HuntID:     hunt.ID,
+		Number:     len(hunt.Phases) + 1,
+		PokemonID:  pokemonID,
+		Encounters: hunt.PhaseEncounters,
+		Caught:     caught,
+	}
+	if err := s.db.addPhase(ctx, hunt, &phase); err != nil {
+		return nil, err
+	}
+
+	id := hunt.ID.String()
+	s.cache.Del(ctx, redisHuntKey(id))
+	return s.getByID(ctx, id)
+}
+
+func (s *huntServiceImpl) pause(ctx context.Context, hunt *ShinyHunt) error {
+	if hunt.isClosed() {
+		return errHuntClosed
+	}
+	hunt.stopTimer(time.Now())
+	hunt.Status = StatusPaused
+	return s.save(ctx, hunt)
+}
+
+func (s *huntServiceImpl) resume(ctx context.Context, hunt *ShinyHunt) error {
+	if hunt.isClosed() {
+		return errHuntClosed
+	}
+	if hunt.ResumedAt == nil {
+		now := time.Now()
+		hunt.ResumedAt = &now
+	}
+	hunt.Status = StatusActive
+	return s.save(ctx, hunt)
+}
+
+// complete marks the target as shiny-captured in the user's pokedex before
+// closing the hunt, so a failed pokedex update can simply be retried.
+func (s *huntServiceImpl) complete(ctx context.Context, hunt *ShinyHunt) error {
+	if hunt.isClosed() {
+		return errHuntClosed
+	}
+	if err := s.pokedex.MarkCaptured(ctx, hunt.UserID, hunt.GameID, []int{hunt.PokemonID}, true); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	hunt.stopTimer(now)
+	hunt.Status = StatusCompleted
+	hunt.CompletedAt = &now
+	return s.save(ctx, hunt)
+}
+
+func (s *huntServiceImpl) abandon(ctx context.Context, hunt *ShinyHunt) error {
+	if hunt.isClosed() {
+		return errHuntClosed
+	}
+	hunt.stopTimer(time.Now())
+	hunt.Status = StatusAbandoned
+	return s.save(ctx, hunt)
+}
+
+func (s *huntServiceImpl) save(ctx context.Context, hunt *ShinyHunt) error {
+	if err := s.db.update(ctx, hunt); err != nil {
+		return err
+	}
+	s.cache.Del(ctx, redisHuntKey(hunt.ID.String()))
+	s.fillOdds(hunt)
+	return nil
+}
+
+// Odds depend on the current chain and encounters, so they are never cached.
+func (s *huntServiceImpl) fillOdds(hunt *ShinyHunt) {
+	odds, err := computeOdds(&hunt.Game, hunt.Method, hunt.ShinyCharm, hunt.Chain)
+	if err != nil {
+		hunt.Odds = nil
+		return
+	}
+	odds = odds.withEncounters(hunt.Encounters)
+	hunt.Odds = &odds
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func (h *ShinyHunt) isClosed() bool {
+	return h.Status == StatusCompleted || h.Status == StatusAbandoned
+}
+
+func (h *ShinyHunt) stopTimer(now time.Time) {
+	h.TimeSpentSeconds = h.elapsed(now)
+	h.ResumedAt = nil
+}
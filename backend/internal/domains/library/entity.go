@@ -0,0 +1,72 @@
+package library
+
+import "github.com/google/uuid"
+
+// Kinds of content in the library.
+const (
+	KindGuide       = "guide"
+	KindWalkthrough = "walkthrough"
+)
+
+// Actions an import takes, or would take in a dry run, on one piece of content.
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionUnchanged = "unchanged"
+	ActionError     = "error"
+)
+
+// Options of an import. Editor is credited with the revisions and authors the
+// content whose front matter names no author.
+type Options struct {
+	DryRun bool
+	Editor uuid.UUID
+}
+
+// File is a file of the library layout, its path relative to the root.
+type File struct {
+	Path string
+	Data []byte
+}
+
+/*************
+ * RESPONSES *
+ *************/
+
+// Report tells what an import did or, in a dry run, would do. Nothing is
+// written when a file is invalid or a write fails, so Applied stays false.
+type Report struct {
+	DryRun    bool         `json:"dry_run"`
+	Applied   bool         `json:"applied"`
+	Created   int          `json:"created"`
+	Updated   int          `json:"updated"`
+	Unchanged int          `json:"unchanged"`
+	Failed    int          `json:"failed"`
+	Items     []ReportItem `json:"items"`
+	Skipped   []string     `json:"skipped,omitempty"` // files outside the layout
+}
+
+// ReportItem is the outcome for one guide or walkthrough.
+type ReportItem struct {
+	Kind     string   `json:"kind"`
+	Slug     string   `json:"slug"`
+	Path     string   `json:"path"`
+	Action   string   `json:"action"`
+	Changes  []string `json:"changes,omitempty"` // fields that differ from the stored content
+	Warnings []string `json:"warnings,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+func (r *Report) add(item ReportItem) {
+	switch item.Action {
+	case ActionCreate:
+		r.Created++
+	case ActionUpdate:
+		r.Updated++
+	case ActionUnchanged:
+		r.Unchanged++
+	case ActionError:
+		r.Failed++
+	}
+	r.Items = append(r.Items, item)
+}
@@ -0,0 +1,106 @@
+package library
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"pokemon/pkg/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Limits on uploaded archives: the zip itself, its number of files and the
+// size of the files once unpacked, as declared by the archive (reads stop
+// there).
+const (
+	maxImportSize     = 32 << 20
+	maxImportEntries  = 5000
+	maxImportUnpacked = 128 << 20
+)
+
+type handler struct {
+	s service
+}
+
+func NewHandler(db *gorm.DB, redis *redis.Client) *handler {
+	return &handler{s: newServ(db, redis)}
+}
+
+// POST /library/import?dry_run=true
+// The zip comes as the "file" form field or as the raw body.
+func (h *handler) importZip(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	data := c.Body()
+	if header, err := c.FormFile("file"); err == nil {
+		f, err := header.Open()
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "cannot read the uploaded file")
+		}
+		defer f.Close()
+		if data, err = io.ReadAll(io.LimitReader(f, maxImportSize+1)); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "cannot read the uploaded file")
+		}
+	}
+	if len(data) > maxImportSize {
+		return fiber.NewError(fiber.StatusRequestEntityTooLarge, fmt.Sprintf("the archive is over %d MB", maxImportSize>>20))
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "a zip archive is required")
+	}
+	if err := checkArchive(archive); err != nil {
+		return fiber.NewError(fiber.StatusRequestEntityTooLarge, err.Error())
+	}
+
+	report, err := h.s.importFS(c.Context(), archive, Options{DryRun: c.QueryBool("dry_run"), Editor: userID})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	status := fiber.StatusOK
+	if report.Failed > 0 {
+		status = fiber.StatusUnprocessableEntity
+	}
+	return c.Status(status).JSON(report)
+}
+
+// GET /library/export
+func (h *handler) exportZip(c *fiber.Ctx) error {
+	files, err := h.s.export(c.Context())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := WriteZip(&buf, files); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	c.Attachment("library-" + time.Now().UTC().Format("20060102") + ".zip")
+	c.Set(fiber.HeaderContentType, "application/zip")
+	return c.Send(buf.Bytes())
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func checkArchive(archive *zip.Reader) error {
+	if len(archive.File) > maxImportEntries {
+		return fmt.Errorf("the archive has over %d files", maxImportEntries)
+	}
+	var size uint64
+	for _, f := range archive.File {
+		if size += f.UncompressedSize64; size > maxImportUnpacked {
+			return fmt.Errorf("the archive unpacks to over %d MB", maxImportUnpacked>>20)
+		}
+	}
+	return nil
+}
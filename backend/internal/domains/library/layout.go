@@ -0,0 +1,314 @@
+package library
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"pokemon/pkg/frontmatter"
+	"pokemon/pkg/utils"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// The library is laid out as
+//
+//	guides/<slug>.md
+//	walkthroughs/<slug>/index.md
+//	walkthroughs/<slug>/01-<step>.md
+//
+// under any root folder, so a zip of the repository works as is. Guides may
+// be grouped in subfolders. Steps are read in file name order.
+const (
+	guidesDir       = "guides"
+	walkthroughsDir = "walkthroughs"
+	walkthroughFile = "index.md"
+)
+
+// Front matter keys, in the order exports write them.
+var (
+	guideKeys       = []string{"title", "slug", "summary", "cover", "author", "tags", "status", "language"}
+	walkthroughKeys = []string{"id", "title", "slug", "game", "game_id", "author", "tags"}
+	stepKeys        = []string{"id", "title", "tags", "versions", "media"}
+)
+
+type guideDoc struct {
+	path     string
+	slug     string
+	title    string
+	summary  string
+	cover    string
+	author   string
+	status   string
+	language string
+	tags     []string
+	content  string
+	warnings []string
+}
+
+type walkthroughDoc struct {
+	path     string // of its index.md
+	id       uuid.UUID
+	slug     string
+	title    string
+	game     string
+	gameID   *uuid.UUID
+	author   string
+	tags     []string
+	steps    []stepDoc
+	warnings []string
+}
+
+type stepDoc struct {
+	path     string
+	id       uuid.UUID
+	title    string
+	tags     []string
+	versions []string
+	media    []string
+	content  string
+}
+
+// library is everything read from an import.
+type library struct {
+	guides       []guideDoc
+	walkthroughs []walkthroughDoc
+	invalid      []ReportItem
+	skipped      []string
+}
+
+// read parses every Markdown file of the layout in fsys.
+func read(fsys fs.FS) (*library, error) {
+	lib := &library{}
+	walkthroughs := map[string]*walkthroughDoc{} // by folder
+
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(path.Ext(p), ".md") {
+			return nil
+		}
+
+		parts := strings.Split(p, "/")
+		root := -1
+		for i, part := range parts[:len(parts)-1] {
+			if part == guidesDir || part == walkthroughsDir {
+				root = i
+				break
+			}
+		}
+		if root < 0 {
+			lib.skipped = append(lib.skipped, p)
+			return nil
+		}
+		rest := parts[root+1:]
+
+		src, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		meta, body, err := frontmatter.Parse(src)
+
+		switch {
+		case parts[root] == guidesDir:
+			slug := strings.TrimSuffix(rest[len(rest)-1], path.Ext(p))
+			if err != nil {
+				lib.invalid = append(lib.invalid, invalid(KindGuide, slug, p, err))
+				return nil
+			}
+			lib.guides = append(lib.guides, parseGuide(p, slug, meta, body))
+
+		case len(rest) == 2:
+			folder := path.Join(parts[:root+2]...)
+			wt := walkthroughs[folder]
+			if wt == nil {
+				wt = &walkthroughDoc{slug: rest[0]}
+				walkthroughs[folder] = wt
+			}
+			if err != nil {
+				lib.invalid = append(lib.invalid, invalid(KindWalkthrough, rest[0], p, err))
+				return nil
+			}
+			if strings.EqualFold(rest[1], walkthroughFile) {
+				parseWalkthrough(wt, p, meta, body)
+			} else {
+				wt.steps = append(wt.steps, parseStep(wt, p, meta, body))
+			}
+
+		default:
+			lib.skipped = append(lib.skipped, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	folders := make([]string, 0, len(walkthroughs))
+	for folder := range walkthroughs {
+		folders = append(folders, folder)
+	}
+	sort.Strings(folders)
+	for _, folder := range folders {
+		wt := walkthroughs[folder]
+		if wt.path == "" {
+			lib.invalid = append(lib.invalid, invalid(KindWalkthrough, wt.slug, folder,
+				fmt.Errorf("missing %s", walkthroughFile)))
+			continue
+		}
+		sort.Slice(wt.steps, func(i, j int) bool { return wt.steps[i].path < wt.steps[j].path })
+		lib.walkthroughs = append(lib.walkthroughs, *wt)
+	}
+	return lib, nil
+}
+
+func parseGuide(p, slug string, meta frontmatter.Meta, body string) guideDoc {
+	doc := guideDoc{
+		path:     p,
+		slug:     slug,
+		title:    meta.String("title"),
+		summary:  meta.String("summary"),
+		cover:    meta.String("cover"),
+		author:   meta.String("author"),
+		status:   meta.String("status"),
+		language: meta.String("language"),
+		tags:     meta.List("tags"),
+		content:  strings.TrimSpace(body),
+		warnings: unknownKeys(meta, guideKeys),
+	}
+	if s := meta.String("slug"); s != "" {
+		doc.slug = s
+	}
+	return doc
+}
+
+func parseWalkthrough(wt *walkthroughDoc, p string, meta frontmatter.Meta, body string) {
+	wt.path = p
+	wt.title = meta.String("title")
+	wt.game = meta.String("game")
+	wt.author = meta.String("author")
+	wt.tags = meta.List("tags")
+	wt.warnings = append(wt.warnings, unknownKeys(meta, walkthroughKeys)...)
+	if s := meta.String("slug"); s != "" {
+		wt.slug = s
+	}
+	if id, err := uuid.Parse(meta.String("id")); err == nil {
+		wt.id = id
+	}
+	if id, err := uuid.Parse(meta.String("game_id")); err == nil {
+		wt.gameID = &id
+	}
+	if strings.TrimSpace(body) != "" {
+		wt.warnings = append(wt.warnings, p+": the body of "+walkthroughFile+" is ignored, write it as a step")
+	}
+}
+
+func parseStep(wt *walkthroughDoc, p string, meta frontmatter.Meta, body string) stepDoc {
+	step := stepDoc{
+		path:     p,
+		title:    meta.String("title"),
+		tags:     meta.List("tags"),
+		versions: meta.List("versions"),
+		media:    meta.List("media"),
+		content:  strings.TrimSpace(body),
+	}
+	if id, err := uuid.Parse(meta.String("id")); err == nil {
+		step.id = id
+	}
+	for _, w := range unknownKeys(meta, stepKeys) {
+		wt.warnings = append(wt.warnings, p+": "+w)
+	}
+	return step
+}
+
+/**********
+ * EXPORT *
+ **********/
+
+func formatGuide(doc guideDoc) File {
+	fields := []frontmatter.Field{
+		{Key: "title", Value: doc.title},
+		{Key: "slug", Value: doc.slug},
+		{Key: "summary", Value: doc.summary},
+		{Key: "cover", Value: doc.cover},
+		{Key: "author", Value: doc.author},
+		{Key: "tags", Value: doc.tags},
+		{Key: "status", Value: doc.status},
+		{Key: "language", Value: doc.language},
+	}
+	return File{
+		Path: path.Join(guidesDir, doc.slug+".md"),
+		Data: frontmatter.Format(fields, doc.content),
+	}
+}
+
+func formatWalkthrough(doc walkthroughDoc) []File {
+	folder := path.Join(walkthroughsDir, doc.slug)
+	fields := []frontmatter.Field{
+		{Key: "id", Value: idString(&doc.id)},
+		{Key: "title", Value: doc.title},
+		{Key: "slug", Value: doc.slug},
+		{Key: "game", Value: doc.game},
+		{Key: "game_id", Value: idString(doc.gameID)},
+		{Key: "author", Value: doc.author},
+		{Key: "tags", Value: doc.tags},
+	}
+	files := []File{{Path: path.Join(folder, walkthroughFile), Data: frontmatter.Format(fields, "")}}
+
+	width := max(2, len(fmt.Sprint(len(doc.steps))))
+	for i, step := range doc.steps {
+		name := utils.Slugify(step.title)
+		if name == "" {
+			name = "step"
+		}
+		fields := []frontmatter.Field{
+			{Key: "id", Value: idString(&step.id)},
+			{Key: "title", Value: step.title},
+			{Key: "tags", Value: step.tags},
+			{Key: "versions", Value: step.versions},
+			{Key: "media", Value: step.media},
+		}
+		files = append(files, File{
+			Path: path.Join(folder, fmt.Sprintf("%0*d-%s.md", width, i+1, name)),
+			Data: frontmatter.Format(fields, step.content),
+		})
+	}
+	return files
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func invalid(kind, slug, p string, err error) ReportItem {
+	return ReportItem{Kind: kind, Slug: slug, Path: p, Action: ActionError, Error: err.Error()}
+}
+
+func unknownKeys(meta frontmatter.Meta, known []string) []string {
+	var warnings []string
+	for key := range meta {
+		if !contains(known, key) {
+			warnings = append(warnings, fmt.Sprintf("unknown front matter key %q", key))
+		}
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func idString(id *uuid.UUID) string {
+	if id == nil || *id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
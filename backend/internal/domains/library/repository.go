@@ -0,0 +1,50 @@
+package library
+
+import (
+	"context"
+	"pokemon/internal/domains/user"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type repository interface {
+	userIDs(ctx context.Context, usernames []string) (map[string]uuid.UUID, error)
+	usernames(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]string, error)
+}
+
+type repositoryImpl struct {
+	db *gorm.DB
+}
+
+func newRepo(db *gorm.DB) repository {
+	return &repositoryImpl{db: db}
+}
+
+// userIDs maps the usernames front matter names authors by to their users.
+// Unknown names are left out.
+func (r *repositoryImpl) userIDs(ctx context.Context, usernames []string) (map[string]uuid.UUID, error) {
+	ids := make(map[string]uuid.UUID, len(usernames))
+	if len(usernames) == 0 {
+		return ids, nil
+	}
+	var users []user.User
+	err := r.db.WithContext(ctx).Select("id", "username").Where("username IN ?", usernames).Find(&users).Error
+	for _, u := range users {
+		ids[u.Username] = u.ID
+	}
+	return ids, err
+}
+
+func (r *repositoryImpl) usernames(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]string, error) {
+	names := make(map[uuid.UUID]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+	var users []user.User
+	err := r.db.WithContext(ctx).Select("id", "username").Where("id IN ?", ids).Find(&users).Error
+	for _, u := range users {
+		names[u.ID] = u.Username
+	}
+	return names, err
+}
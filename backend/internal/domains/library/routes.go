@@ -0,0 +1,16 @@
+package library
+
+import (
+	"pokemon/internal/domains/user"
+	"pokemon/internal/middleware"
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *handler) RegisterRoutes(router fiber.Router) {
+	group := router.Group("/library", middleware.AuthRequired(), utils.RoleMiddleware(user.RoleAdmin))
+
+	group.Post("/import", h.importZip)
+	group.Get("/export", h.exportZip)
+}
@@ -0,0 +1,507 @@
+package library
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"pokemon/internal/domains/guide"
+	"pokemon/internal/domains/walkthrough"
+	"pokemon/pkg/language"
+	"pokemon/pkg/publishing"
+	"pokemon/pkg/utils"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const importSummary = "Imported from Markdown"
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type service interface {
+	importFS(ctx context.Context, fsys fs.FS, opts Options) (*Report, error)
+	export(ctx context.Context) ([]File, error)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type serviceImpl struct {
+	db           *gorm.DB
+	redis        *redis.Client
+	repo         repository
+	guides       *guide.Library
+	walkthroughs *walkthrough.Library
+}
+
+func newServ(db *gorm.DB, redis *redis.Client) service {
+	return &serviceImpl{
+		db:           db,
+		redis:        redis,
+		repo:         newRepo(db),
+		guides:       guide.NewLibrary(db, redis),
+		walkthroughs: walkthrough.NewLibrary(db, redis),
+	}
+}
+
+// plan is what an import does with one guide or walkthrough. apply writes it
+// through the libraries of the import transaction.
+type plan struct {
+	item  ReportItem
+	apply func(guides *guide.Library, walkthroughs *walkthrough.Library) error
+}
+
+// importFS upserts the guides and walkthroughs of the layout by slug. Every
+// file is checked first: when one is invalid, or in a dry run, nothing is
+// written and the report tells what would have been done. The writes share
+// one transaction, so a failing one leaves the library as it was.
+func (s *serviceImpl) importFS(ctx context.Context, fsys fs.FS, opts Options) (*Report, error) {
+	if opts.Editor == uuid.Nil {
+		return nil, errors.New("an editor is required to credit the revisions")
+	}
+	lib, err := read(fsys)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{DryRun: opts.DryRun, Skipped: lib.skipped, Items: []ReportItem{}}
+
+	var names []string
+	for _, doc := range lib.guides {
+		names = append(names, doc.author)
+	}
+	for _, doc := range lib.walkthroughs {
+		names = append(names, doc.author)
+	}
+	authors, err := s.repo.userIDs(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	var plans []plan
+	slugs := map[string]string{} // kind and slug to the path using them
+	claim := func(kind, slug, path string) error {
+		key := kind + ":" + slug
+		if other, taken := slugs[key]; taken {
+			return fmt.Errorf("slug %q is also used by %s", slug, other)
+		}
+		slugs[key] = path
+		return nil
+	}
+
+	for _, doc := range lib.guides {
+		p, err := s.planGuide(ctx, doc, authors, opts.Editor)
+		if err == nil {
+			err = claim(KindGuide, doc.slug, doc.path)
+		}
+		if err != nil {
+			p.item.Action, p.item.Error = ActionError, err.Error()
+		}
+		plans = append(plans, p)
+	}
+	for _, doc := range lib.walkthroughs {
+		p, err := s.planWalkthrough(ctx, doc, authors, opts.Editor)
+		if err == nil {
+			err = claim(KindWalkthrough, doc.slug, doc.path)
+		}
+		if err != nil {
+			p.item.Action, p.item.Error = ActionError, err.Error()
+		}
+		plans = append(plans, p)
+	}
+
+	for _, item := range lib.invalid {
+		report.add(item)
+	}
+	valid := len(lib.invalid) == 0
+	for _, p := range plans {
+		valid = valid && p.item.Action != ActionError
+	}
+	report.Applied = valid && !opts.DryRun
+
+	if report.Applied {
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			guides, walkthroughs := guide.NewLibrary(tx, s.redis), walkthrough.NewLibrary(tx, s.redis)
+			for i := range plans {
+				p := &plans[i]
+				if p.item.Action != ActionCreate && p.item.Action != ActionUpdate {
+					continue
+				}
+				if err := p.apply(guides, walkthroughs); err != nil {
+					p.item.Action, p.item.Error = ActionError, err.Error()
+					return err
+				}
+			}
+			return nil
+		})
+		report.Applied = err == nil
+	}
+
+	for _, p := range plans {
+		report.add(p.item)
+	}
+	return report, nil
+}
+
+func (s *serviceImpl) planGuide(ctx context.Context, doc guideDoc, authors map[string]uuid.UUID, editor uuid.UUID) (plan, error) {
+	p := plan{item: ReportItem{Kind: KindGuide, Slug: doc.slug, Path: doc.path, Warnings: doc.warnings}}
+	switch {
+	case doc.title == "":
+		return p, errors.New("title is required")
+	case doc.content == "":
+		return p, errors.New("content is required")
+	case doc.status != "" && doc.status != publishing.Draft && doc.status != publishing.Published:
+		return p, errors.New("status must be draft or published")
+	case doc.language != "" && language.Normalize(doc.language) == "":
+		return p, language.ErrUnsupported
+	}
+	authorID, err := authorOf(doc.author, authors)
+	if err != nil {
+		return p, err
+	}
+
+	existing, err := s.guides.BySlug(ctx, doc.slug)
+	if err != nil {
+		return p, err
+	}
+
+	g := existing
+	if g == nil {
+		p.item.Action = ActionCreate
+		g = &guide.GameGuide{Slug: doc.slug, AuthorID: editor}
+	} else {
+		p.item.Changes = guideChanges(g, doc, authorID)
+		p.item.Action = ActionUpdate
+		if len(p.item.Changes) == 0 {
+			p.item.Action = ActionUnchanged
+		}
+	}
+
+	g.Title, g.Summary, g.Content, g.CoverImageURL = doc.title, doc.summary, doc.content, doc.cover
+	if authorID != uuid.Nil {
+		g.AuthorID = authorID
+	}
+	if doc.status != "" && doc.status != exportedStatus(g.Status) {
+		g.Status = doc.status
+	}
+	if doc.language != "" {
+		g.Language = language.Normalize(doc.language)
+	}
+	g.EditSummary = importSummary
+
+	p.apply = func(guides *guide.Library, _ *walkthrough.Library) error {
+		return guides.Save(ctx, g, doc.tags, editor)
+	}
+	return p, g.Validate()
+}
+
+func (s *serviceImpl) planWalkthrough(ctx context.Context, doc walkthroughDoc, authors map[string]uuid.UUID, editor uuid.UUID) (plan, error) {
+	p := plan{item: ReportItem{Kind: KindWalkthrough, Slug: doc.slug, Path: doc.path, Warnings: doc.warnings}}
+	switch {
+	case doc.title == "":
+		return p, errors.New("title is required")
+	case doc.game == "" && doc.gameID == nil:
+		return p, errors.New("game or game_id is required")
+	}
+	for _, step := range doc.steps {
+		if step.title == "" || step.content == "" {
+			return p, fmt.Errorf("%s: steps need a title and content", step.path)
+		}
+	}
+	authorID, err := authorOf(doc.author, authors)
+	if err != nil {
+		return p, err
+	}
+
+	existing, err := s.walkthroughs.BySlug(ctx, doc.slug)
+	if err == nil && existing == nil && doc.id != uuid.Nil {
+		// Walkthroughs written in the app get their slug on their first round trip
+		existing, err = s.walkthroughs.ByID(ctx, doc.id)
+	}
+	if err != nil {
+		return p, err
+	}
+
+	wt := existing
+	if wt == nil {
+		p.item.Action = ActionCreate
+		wt = &walkthrough.Walkthrough{UserID: editor}
+	} else {
+		p.item.Changes = s.walkthroughChanges(wt, doc, authorID)
+		p.item.Action = ActionUpdate
+		if len(p.item.Changes) == 0 {
+			p.item.Action = ActionUnchanged
+		}
+	}
+
+	wt.Title, wt.Slug, wt.Game, wt.GameID, wt.Tags = doc.title, doc.slug, doc.game, doc.gameID, doc.tags
+	if doc.gameID != nil && existing != nil && !sameID(existing.GameID, doc.gameID) {
+		wt.VersionGroupID = nil // follows the new game
+	}
+	if authorID != uuid.Nil {
+		wt.UserID = authorID
+	}
+
+	steps := make([]walkthrough.WalkthroughStep, 0, len(doc.steps))
+	for _, step := range doc.steps {
+		steps = append(steps, walkthrough.WalkthroughStep{
+			ID:          step.id,
+			Title:       step.title,
+			Content:     step.content,
+			Tags:        step.tags,
+			Versions:    step.versions,
+			MediaURLs:   step.media,
+			EditSummary: importSummary,
+		})
+	}
+
+	p.apply = func(_ *guide.Library, walkthroughs *walkthrough.Library) error {
+		return walkthroughs.Save(ctx, wt, steps, editor)
+	}
+	return p, nil
+}
+
+// export writes every guide and walkthrough in the library layout.
+// Walkthroughs without a slug get one from their title; their ID in the
+// front matter ties them back on import.
+func (s *serviceImpl) export(ctx context.Context) ([]File, error) {
+	guides, err := s.guides.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	walkthroughs, err := s.walkthroughs.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var authorIDs []uuid.UUID
+	for _, g := range guides {
+		authorIDs = append(authorIDs, g.AuthorID)
+	}
+	for _, wt := range walkthroughs {
+		authorIDs = append(authorIDs, wt.UserID)
+	}
+	usernames, err := s.repo.usernames(ctx, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []File
+	for _, g := range guides {
+		tags := make([]string, 0, len(g.Tags))
+		for _, tag := range g.Tags {
+			tags = append(tags, tag.Name)
+		}
+		files = append(files, formatGuide(guideDoc{
+			slug:     g.Slug,
+			title:    g.Title,
+			summary:  g.Summary,
+			cover:    g.CoverImageURL,
+			author:   usernames[g.AuthorID],
+			status:   exportedStatus(g.Status),
+			language: g.Language,
+			tags:     tags,
+			content:  g.Content,
+		}))
+	}
+
+	used := map[string]bool{}
+	for _, wt := range walkthroughs {
+		used[wt.Slug] = true
+	}
+	for _, wt := range walkthroughs {
+		slug := wt.Slug
+		if slug == "" {
+			slug = uniqueSlug(wt.Title, used)
+		}
+
+		doc := walkthroughDoc{
+			id:     wt.ID,
+			slug:   slug,
+			title:  wt.Title,
+			game:   wt.Game,
+			gameID: wt.GameID,
+			author: usernames[wt.UserID],
+			tags:   wt.Tags,
+		}
+		for i := range wt.Steps {
+			step := &wt.Steps[i]
+			doc.steps = append(doc.steps, stepDoc{
+				id:       step.ID,
+				title:    step.Title,
+				tags:     step.Tags,
+				versions: s.walkthroughs.StepVersions(&wt, step),
+				media:    step.MediaURLs,
+				content:  step.Content,
+			})
+		}
+		files = append(files, formatWalkthrough(doc)...)
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+/***********
+ * EXPORTS *
+ ***********/
+
+// Import reads the library layout from fsys, a directory or a zip, into the
+// guides and walkthroughs. See importFS.
+func Import(ctx context.Context, db *gorm.DB, redis *redis.Client, fsys fs.FS, opts Options) (*Report, error) {
+	return newServ(db, redis).importFS(ctx, fsys, opts)
+}
+
+// Export returns the files of the whole library, sorted by path.
+func Export(ctx context.Context, db *gorm.DB, redis *redis.Client) ([]File, error) {
+	return newServ(db, redis).export(ctx)
+}
+
+// WriteZip archives the files.
+func WriteZip(w io.Writer, files []File) error {
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := zw.Create(f.Path)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(f.Data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// authorOf resolves the author named in front matter, uuid.Nil when none is.
+func authorOf(username string, authors map[string]uuid.UUID) (uuid.UUID, error) {
+	if username == "" {
+		return uuid.Nil, nil
+	}
+	id, ok := authors[username]
+	if !ok {
+		return uuid.Nil, fmt.Errorf("unknown author %q", username)
+	}
+	return id, nil
+}
+
+func guideChanges(g *guide.GameGuide, doc guideDoc, authorID uuid.UUID) []string {
+	var changes []string
+	changed := func(field string, differs bool) {
+		if differs {
+			changes = append(changes, field)
+		}
+	}
+	changed("title", g.Title != doc.title)
+	changed("summary", g.Summary != doc.summary)
+	changed("cover", g.CoverImageURL != doc.cover)
+	changed("content", g.Content != doc.content)
+	changed("author", authorID != uuid.Nil && authorID != g.AuthorID)
+	changed("status", doc.status != "" && doc.status != exportedStatus(g.Status))
+	changed("language", doc.language != "" && language.Normalize(doc.language) != g.Language)
+
+	current := make([]string, 0, len(g.Tags))
+	for _, tag := range g.Tags {
+		current = append(current, tag.Slug)
+	}
+	wanted := make([]string, 0, len(doc.tags))
+	for _, name := range doc.tags {
+		wanted = append(wanted, utils.Slugify(name))
+	}
+	changed("tags", !sameSet(current, wanted))
+	return changes
+}
+
+func (s *serviceImpl) walkthroughChanges(wt *walkthrough.Walkthrough, doc walkthroughDoc, authorID uuid.UUID) []string {
+	var changes []string
+	changed := func(field string, differs bool) {
+		if differs {
+			changes = append(changes, field)
+		}
+	}
+	changed("title", wt.Title != doc.title)
+	changed("slug", wt.Slug != doc.slug)
+	changed("game", doc.game != "" && wt.Game != doc.game)
+	changed("game_id", !sameID(wt.GameID, doc.gameID))
+	changed("author", authorID != uuid.Nil && authorID != wt.UserID)
+	changed("tags", !sameSet(wt.Tags, doc.tags))
+
+	stepsChanged := len(wt.Steps) != len(doc.steps)
+	for i := 0; !stepsChanged && i < len(doc.steps); i++ {
+		have, want := &wt.Steps[i], doc.steps[i]
+		stepsChanged = have.ID != want.id || have.Title != want.title || have.Content != want.content ||
+			!sameSet(have.Tags, want.tags) || !sameSet(have.MediaURLs, want.media) ||
+			!sameFold(s.walkthroughs.StepVersions(wt, have), want.versions)
+	}
+	changed("steps", stepsChanged)
+	return changes
+}
+
+// exportedStatus writes scheduled content as a draft: the schedule itself
+// isn't part of the front matter.
+func exportedStatus(status string) string {
+	if status == publishing.Scheduled {
+		return publishing.Draft
+	}
+	return status
+}
+
+func uniqueSlug(title string, used map[string]bool) string {
+	base := utils.Slugify(title)
+	if base == "" {
+		base = "walkthrough"
+	}
+	slug := base
+	for n := 2; used[slug]; n++ {
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+	used[slug] = true
+	return slug
+}
+
+func sameID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	count := map[string]int{}
+	for _, s := range a {
+		count[s]++
+	}
+	for _, s := range b {
+		if count[s] == 0 {
+			return false
+		}
+		count[s]--
+	}
+	return true
+}
+
+func sameFold(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(strings.TrimSpace(a[i]), strings.TrimSpace(b[i])) {
+			return false
+		}
+	}
+	return true
+}
@@ -2,7 +2,11 @@ package news
 
 import (
 	"errors"
+	"pokemon/internal/domains/series"
+	"pokemon/internal/domains/translation"
 	"pokemon/internal/domains/user"
+	"pokemon/pkg/content"
+	"pokemon/pkg/publishing"
 	"strings"
 	"time"
 
@@ -17,6 +21,12 @@ type News struct {
 	Title     string         `json:"title" gorm:"type:text;not null"`
 	SubTitle  string         `json:"subtitle" gorm:"type:text;not null"`
 	Body      string         `json:"body" gorm:"type:text;not null"`
+	Rendered  *content.Rendered `json:"rendered,omitempty" gorm:"-"`
+	Series    []series.Navigation `json:"series,omitempty" gorm:"-"` // previous/next in the series holding the news
+	Language    string               `json:"language" gorm:"type:varchar(10);not null;default:'en'"` // of the text, translations are separate
+	Translation *translation.Variant `json:"translation,omitempty" gorm:"-"`                         // language served and the others available
+
+	publishing.State // draft, scheduled or published
 
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
@@ -1,6 +1,9 @@
 package news
 
 import (
+	"pokemon/internal/domains/series"
+	"pokemon/internal/domains/translation"
+	"pokemon/pkg/content"
 	"pokemon/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -10,14 +13,17 @@ import (
 )
 
 type handler struct {
-	s   service
+	s          service
+	navigator  *series.Navigator
+	translator *translation.Translator
 	// viewCache viewService
 }
 
 func NewHandler(db *gorm.DB, redis *redis.Client) *handler {
 	repo := newRepo(db)
-	serv := newServ(repo, redis)
-	return &handler{s: serv}
+	renderer := content.NewRenderer(redis)
+	serv := newServ(repo, renderer, redis)
+	return &handler{s: serv, navigator: series.NewNavigator(db), translator: translation.NewTranslator(db, renderer)}
 }
 
 // POST /news
@@ -55,6 +61,17 @@ func (h *handler) get(c *fiber.Ctx) error {
 	if err != nil {
 		return fiber.ErrNotFound
 	}
+
+	viewerID, _ := utils.GetUserIDFromLocals(c)
+	if !news.VisibleTo(viewerID, news.UserID) {
+		return fiber.ErrNotFound
+	}
+	news.Series = h.navigator.Around(c.Context(), series.ContentNews, news.ID, viewerID)
+
+	news.Translation = h.translator.Negotiate(c, translation.ContentNews, news.ID, news.Language)
+	if v := news.Translation; v.Translated() {
+		news.Title, news.SubTitle, news.Body, news.Rendered = v.Title, v.Summary, v.Body, v.Rendered
+	}
 	return c.JSON(news)
 }
 
@@ -77,7 +94,9 @@ func (h *handler) listByUser(c *fiber.Ctx) error {
 	}
 	limit, offset := utils.ParsePagination(c)
 
-	news, err := h.s.listByUser(userID, limit, offset)
+	// Authors also see their own drafts and scheduled news
+	viewerID, _ := utils.GetUserIDFromLocals(c)
+	news, err := h.s.listByUser(userID, viewerID != userID, limit, offset)
 	if err != nil {
 		return err
 	}
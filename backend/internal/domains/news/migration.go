@@ -0,0 +1,12 @@
+package news
+
+import "gorm.io/gorm"
+
+type NewsMigrator struct{}
+
+func (m NewsMigrator) Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&News{},
+		&NewsView{},
+	)
+}
@@ -1,6 +1,8 @@
 package news
 
 import (
+	"pokemon/pkg/publishing"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -13,7 +15,7 @@ import (
 
 type repository interface {
 	list(limit, offset int) ([]News, error)
-	listByUser(userID uuid.UUID, limit, offset int) ([]News, error)
+	listByUser(userID uuid.UUID, publicOnly bool, limit, offset int) ([]News, error)
 	countByUser(userID uuid.UUID) (int64, error)
 
 	get(ID uuid.UUID) (News, error)
@@ -33,6 +35,7 @@ func newRepo(db *gorm.DB) repository {
 func (r *repositoryImpl) list(limit, offset int) ([]News, error) {
 	var news []News
 	err := r.db.
+		Scopes(publishing.Live("news")).
 		Limit(limit).
 		Offset(offset).
 		Order("created_at DESC").
@@ -40,9 +43,14 @@ func (r *repositoryImpl) list(limit, offset int) ([]News, error) {
 	return news, err
 }
 
-func (r *repositoryImpl) listByUser(userID uuid.UUID, limit, offset int) ([]News, error) {
+func (r *repositoryImpl) listByUser(userID uuid.UUID, publicOnly bool, limit, offset int) ([]News, error) {
+	tx := r.db
+	if publicOnly {
+		tx = tx.Scopes(publishing.Live("news"))
+	}
+
 	var news []News
-	err := r.db.
+	err := tx.
 		Where("user_id = ?", userID).
 		Limit(limit).
 		Offset(offset).
@@ -10,8 +10,8 @@ func (h *handler) RegisterRoutes(app fiber.Router) {
 	news := app.Group("/news")
 
 	news.Get("/", h.list)
-	news.Get("/:id", h.get)
-	news.Get("/user/:user_id", h.listByUser)
+	news.Get("/:id", middleware.AuthOptional(), h.get)
+	news.Get("/user/:user_id", middleware.AuthOptional(), h.listByUser)
 
 	// news.Post("/:id/view", middleware.AuthOptional(), h.createView)
 
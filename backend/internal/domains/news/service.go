@@ -4,29 +4,34 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"pokemon/pkg/content"
+	"pokemon/pkg/language"
+	"pokemon/pkg/publishing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 )
 
 type service interface {
 	create(news *News) error
 	get(id uuid.UUID) (*News, error)
 	list(limit, offset int) ([]News, error)
-	listByUser(userID uuid.UUID, limit, offset int) ([]News, error)
+	listByUser(userID uuid.UUID, publicOnly bool, limit, offset int) ([]News, error)
 	update(news *News) error
 	delete(id uuid.UUID) error
 	countByUser(userID uuid.UUID) (int64, error)
 }
 
 type serviceImpl struct {
-	repo  repository
-	redis *redis.Client
+	repo     repository
+	renderer content.Renderer
+	redis    *redis.Client
 }
 
-func newServ(repo repository, redis *redis.Client) service {
-	return &serviceImpl{repo: repo, redis: redis}
+func newServ(repo repository, renderer content.Renderer, redis *redis.Client) service {
+	return &serviceImpl{repo: repo, renderer: renderer, redis: redis}
 }
 
 const cacheTTL = time.Minute * 5
@@ -35,17 +40,23 @@ func (s *serviceImpl) create(news *News) error {
 	if err := news.Validate(); err != nil {
 		return err
 	}
+	if err := news.State.Prepare(nil, time.Now()); err != nil {
+		return err
+	}
+	lang, err := language.Resolve(news.Language, "")
+	if err != nil {
+		return err
+	}
+	news.Language = lang
 
 	if err := s.repo.create(*news); err != nil {
 		return err
 	}
+	s.render(news)
 
 	ctx := context.Background()
-	s.redis.Del(ctx,
-		fmt.Sprintf("news:list"),
-		fmt.Sprintf("news:user:%s", news.UserID),
-		fmt.Sprintf("news:count:user:%s", news.UserID),
-	)
+	clearListCaches(ctx, s.redis, news.UserID)
+	s.redis.Del(ctx, fmt.Sprintf("news:count:user:%s", news.UserID))
 
 	return nil
 }
@@ -66,6 +77,7 @@ func (s *serviceImpl) get(id uuid.UUID) (*News, error) {
 	if err != nil {
 		return nil, err
 	}
+	s.render(&n)
 
 	data, _ := json.Marshal(n)
 	s.redis.Set(ctx, cacheKey, data, cacheTTL)
@@ -89,6 +101,7 @@ func (s *serviceImpl) list(limit, offset int) ([]News, error) {
 	if err != nil {
 		return nil, err
 	}
+	s.renderAll(result)
 
 	data, _ := json.Marshal(result)
 	s.redis.Set(ctx, cacheKey, data, cacheTTL)
@@ -96,9 +109,10 @@ func (s *serviceImpl) list(limit, offset int) ([]News, error) {
 	return result, nil
 }
 
-func (s *serviceImpl) listByUser(userID uuid.UUID, limit, offset int) ([]News, error) {
+// listByUser includes drafts and scheduled news unless publicOnly is set.
+func (s *serviceImpl) listByUser(userID uuid.UUID, publicOnly bool, limit, offset int) ([]News, error) {
 	ctx := context.Background()
-	cacheKey := fmt.Sprintf("news:user:%s:%d:%d", userID, limit, offset)
+	cacheKey := fmt.Sprintf("news:user:%s:%t:%d:%d", userID, publicOnly, limit, offset)
 
 	cached, err := s.redis.Get(ctx, cacheKey).Result()
 	if err == nil {
@@ -108,10 +122,11 @@ func (s *serviceImpl) listByUser(userID uuid.UUID, limit, offset int) ([]News, e
 		}
 	}
 
-	result, err := s.repo.listByUser(userID, limit, offset)
+	result, err := s.repo.listByUser(userID, publicOnly, limit, offset)
 	if err != nil {
 		return nil, err
 	}
+	s.renderAll(result)
 
 	data, _ := json.Marshal(result)
 	s.redis.Set(ctx, cacheKey, data, cacheTTL)
@@ -124,16 +139,25 @@ func (s *serviceImpl) update(news *News) error {
 		return err
 	}
 
+	previous, err := s.repo.get(news.ID)
+	if err != nil {
+		return err
+	}
+	if err := news.State.Prepare(&previous.State, time.Now()); err != nil {
+		return err
+	}
+	if news.Language, err = language.Resolve(news.Language, previous.Language); err != nil {
+		return err
+	}
+
 	if err := s.repo.update(*news); err != nil {
 		return err
 	}
+	s.render(news)
 
 	ctx := context.Background()
-	s.redis.Del(ctx,
-		fmt.Sprintf("news:get:%s", news.ID),
-		fmt.Sprintf("news:list"),
-		fmt.Sprintf("news:user:%s", news.UserID),
-	)
+	s.redis.Del(ctx, fmt.Sprintf("news:get:%s", news.ID))
+	clearListCaches(ctx, s.redis, news.UserID)
 
 	return nil
 }
@@ -151,10 +175,9 @@ func (s *serviceImpl) delete(id uuid.UUID) error {
 	ctx := context.Background()
 	s.redis.Del(ctx,
 		fmt.Sprintf("news:get:%s", id),
-		fmt.Sprintf("news:list"),
-		fmt.Sprintf("news:user:%s", n.UserID),
 		fmt.Sprintf("news:count:user:%s", n.UserID),
 	)
+	clearListCaches(ctx, s.redis, n.UserID)
 
 	return nil
 }
@@ -181,3 +204,57 @@ func (s *serviceImpl) countByUser(userID uuid.UUID) (int64, error) {
 
 	return count, nil
 }
+
+// render attaches the sanitized HTML of the Markdown body to the news.
+func (s *serviceImpl) render(n *News) {
+	n.Rendered = s.renderer.Render(context.Background(), n.Body)
+}
+
+func (s *serviceImpl) renderAll(list []News) {
+	sources := make([]string, len(list))
+	for i := range list {
+		sources[i] = list[i].Body
+	}
+	for i, rendered := range s.renderer.RenderAll(context.Background(), sources) {
+		list[i].Rendered = rendered
+	}
+}
+
+// clearListCaches drops every cached page of the news list and of the
+// author's list, whatever the pagination.
+func clearListCaches(ctx context.Context, redis *redis.Client, userID uuid.UUID) {
+	for _, pattern := range []string{"news:list:*", fmt.Sprintf("news:user:%s:*", userID)} {
+		iter := redis.Scan(ctx, 0, pattern, 100).Iterator()
+		for iter.Next(ctx) {
+			redis.Del(ctx, iter.Val())
+		}
+	}
+}
+
+/************************
+ * SCHEDULED PUBLISHING *
+ ************************/
+
+// PublishScheduled returns the scheduler job publishing due news.
+func PublishScheduled(db *gorm.DB, redis *redis.Client) publishing.Job {
+	return func(ctx context.Context, now time.Time) error {
+		ids, err := publishing.PublishDue(ctx, db, "news", now)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		var authors []uuid.UUID
+		if err := db.WithContext(ctx).Model(&News{}).Where("id IN ?", ids).
+			Distinct().Pluck("user_id", &authors).Error; err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			redis.Del(ctx, fmt.Sprintf("news:get:%s", id))
+		}
+		for _, userID := range authors {
+			clearListCaches(ctx, redis, userID)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,156 @@
+package notification
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Types of notification, which users can turn off one by one.
+const (
+	TypeReply      = "reply"
+	TypeLike       = "like"
+	TypeMention    = "mention"
+	TypeFollow     = "follow"
+	TypeSave       = "save"
+	TypeModeration = "moderation"
+)
+
+var types = []string{TypeReply, TypeLike, TypeMention, TypeFollow, TypeSave, TypeModeration}
+
+// Kinds of subject a notification is about. Mentions are about the content
+// mentioning, the other types about the recipient's own content (or the
+// recipient, for follows).
+const (
+	SubjectShout        = "shout"
+	SubjectShoutComment = "shout_comment"
+	SubjectTopic        = "topic"
+	SubjectTopicComment = "topic_comment"
+	SubjectTeam         = "team"
+	SubjectTeamComment  = "team_comment"
+	SubjectPost         = "post"
+	SubjectSnap         = "snap"
+	SubjectSnapComment  = "snap_comment"
+	SubjectRun          = "run"
+	SubjectRunComment   = "run_comment"
+	SubjectWalkthrough  = "walkthrough"
+	SubjectGuide        = "guide"
+	SubjectGuideReport  = "guide_report"
+	SubjectUser         = "user"
+)
+
+// Notes of moderation notifications.
+const (
+	NoteReported  = "reported"
+	NoteFlagged   = "flagged"
+	NoteRemoved   = "removed"
+	NoteRestored  = "restored"
+	NoteResolved  = "resolved"
+	NoteDismissed = "dismissed"
+)
+
+var errUnknownType = errors.New("unknown notification type")
+
+/********
+ * MAIN *
+ ********/
+
+// Notification tells UserID that ActorID did something to a subject. There is
+// one per actor and group: doing it again, like liking after unliking, brings
+// it back up as unread instead of adding another.
+type Notification struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID      uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_notification_actor,priority:1;index:idx_notification_user,priority:1"`
+	GroupKey    string     `json:"group_key" gorm:"type:varchar(80);not null;uniqueIndex:idx_notification_actor,priority:2"`
+	ActorID     uuid.UUID  `json:"actor_id" gorm:"type:uuid;not null;uniqueIndex:idx_notification_actor,priority:3"`
+	Type        string     `json:"type" gorm:"type:varchar(20);not null"`
+	SubjectType string     `json:"subject_type" gorm:"type:varchar(30);not null"`
+	SubjectID   uuid.UUID  `json:"subject_id" gorm:"type:uuid;not null"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty" gorm:"type:uuid"`  // shout of a comment, guide of a report...
+	ContentID   *uuid.UUID `json:"content_id,omitempty" gorm:"type:uuid"` // the reply, when known
+	Note        string     `json:"note,omitempty" gorm:"type:varchar(20)"`
+	ReadAt      *time.Time `json:"read_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"index:idx_notification_user,priority:2,sort:desc"`
+}
+
+// Preference turns a type of notification on or off for a user. Types
+// without one are on.
+type Preference struct {
+	UserID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Type    string    `gorm:"type:varchar(20);primaryKey"`
+	Enabled bool      `gorm:"not null"`
+}
+
+func (Preference) TableName() string {
+	return "notification_preferences"
+}
+
+/**********
+ * EVENTS *
+ **********/
+
+// Event is something a user did that others hear about, sent by the domain
+// it happened in with Send.
+type Event struct {
+	Type        string
+	ActorID     uuid.UUID
+	SubjectType string
+	SubjectID   uuid.UUID
+	// ContentID is the reply, for replies
+	ContentID *uuid.UUID
+	// RecipientID defaults to the owner of the subject
+	RecipientID *uuid.UUID
+	// Note says what a moderation did
+	Note string
+}
+
+/************
+ * REQUESTS *
+ ************/
+
+type markReadRequest struct {
+	// Keys of the groups to mark read; all of them when empty
+	Keys []string `json:"keys"`
+}
+
+/*************
+ * RESPONSES *
+ *************/
+
+// Group gathers the notifications of a type about one subject: "Ash and 4
+// others liked your team".
+type Group struct {
+	Key         string     `json:"key"`
+	Type        string     `json:"type"`
+	SubjectType string     `json:"subject_type"`
+	SubjectID   uuid.UUID  `json:"subject_id"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
+	ContentID   *uuid.UUID `json:"content_id,omitempty"` // latest
+	Note        string     `json:"note,omitempty"`       // latest
+	Actors      []Actor    `json:"actors"`               // latest first, up to three
+	ActorCount  int        `json:"actor_count"`
+	Text        string     `json:"text"`
+	URL         string     `json:"url"`
+	Read        bool       `json:"read"`
+	LatestAt    time.Time  `json:"latest_at"`
+}
+
+type Actor struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+}
+
+/***************
+ * VALIDATIONS *
+ ***************/
+
+func validType(typ string) bool {
+	for _, t := range types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package notification
+
+import (
+	"errors"
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	s service
+}
+
+func NewHandler(db *gorm.DB, redis *redis.Client) *handler {
+	return &handler{s: newServ(newRepo(db), redis)}
+}
+
+// GET /notifications?unread=true
+func (h *handler) list(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+
+	limit, offset := utils.ParsePagination(c)
+	groups, err := h.s.list(c.Context(), userID, c.QueryBool("unread"), limit, offset)
+	if err != nil {
+		return notificationError(err)
+	}
+	return c.JSON(groups)
+}
+
+// GET /notifications/unread-count
+func (h *handler) unreadCount(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+
+	count, err := h.s.unread(c.Context(), userID)
+	if err != nil {
+		return notificationError(err)
+	}
+	return c.JSON(fiber.Map{"unread": count})
+}
+
+// POST /notifications/read
+func (h *handler) markRead(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+
+	var req markReadRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+		}
+	}
+
+	if err := h.s.markRead(c.Context(), userID, req.Keys); err != nil {
+		return notificationError(err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GET /notifications/preferences
+func (h *handler) preferences(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+
+	prefs, err := h.s.preferences(c.Context(), userID)
+	if err != nil {
+		return notificationError(err)
+	}
+	return c.JSON(prefs)
+}
+
+// PUT /notifications/preferences
+func (h *handler) setPreferences(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+
+	var req map[string]bool
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+
+	prefs, err := h.s.setPreferences(c.Context(), userID, req)
+	if err != nil {
+		return notificationError(err)
+	}
+	return c.JSON(prefs)
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func notificationError(err error) error {
+	if errors.Is(err, errUnknownType) {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
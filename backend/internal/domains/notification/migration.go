@@ -0,0 +1,12 @@
+package notification
+
+import "gorm.io/gorm"
+
+type NotificationMigrator struct{}
+
+func (m NotificationMigrator) Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&Notification{},
+		&Preference{},
+	)
+}
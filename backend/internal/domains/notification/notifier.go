@@ -0,0 +1,45 @@
+package notification
+
+import (
+	"context"
+	"log"
+	"pokemon/internal/domains/taglink"
+	"pokemon/pkg/events"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+var requested = events.NewTopic[Event]("notification")
+
+// Send notifies the recipient of the event once the request is over. Domains
+// call it after their change is saved; the notification domain works out who
+// the subject belongs to.
+func Send(e Event) {
+	requested.Publish(e)
+}
+
+// Listen stores the notifications sent from now on, and the ones for users
+// mentioned in content.
+func Listen(db *gorm.DB, redis *redis.Client) {
+	svc := newServ(newRepo(db), redis)
+
+	requested.Subscribe(func(ctx context.Context, e Event) {
+		if err := svc.notify(ctx, e); err != nil {
+			log.Printf("%s notification failed for %s %s: %v", e.Type, e.SubjectType, e.SubjectID, err)
+		}
+	})
+
+	taglink.Mentioned.Subscribe(func(ctx context.Context, m taglink.MentionEvent) {
+		e := Event{
+			Type:        TypeMention,
+			ActorID:     m.AuthorID,
+			SubjectType: m.ContentType,
+			SubjectID:   m.ContentID,
+			RecipientID: &m.UserID,
+		}
+		if err := svc.notify(ctx, e); err != nil {
+			log.Printf("mention notification failed for %s %s: %v", m.ContentType, m.ContentID, err)
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errSubjectNotFound = errors.New("subject not found")
+
+// groupRow is a Group as the database aggregates it.
+type groupRow struct {
+	GroupKey    string
+	Type        string
+	SubjectType string
+	SubjectID   uuid.UUID
+	ParentID    *uuid.UUID
+	ContentID   *uuid.UUID
+	Note        string
+	ActorIDs    pq.StringArray `gorm:"type:text[]"`
+	ActorCount  int
+	Read        bool
+	LatestAt    time.Time
+}
+
+type repository interface {
+	// owner finds who the subject belongs to and the page it's shown on.
+	owner(ctx context.Context, subjectType string, id uuid.UUID) (uuid.UUID, *uuid.UUID, error)
+	enabled(ctx context.Context, userID uuid.UUID, typ string) (bool, error)
+	upsert(ctx context.Context, n *Notification) error
+
+	groups(ctx context.Context, userID uuid.UUID, unread bool, limit, offset int) ([]groupRow, error)
+	unreadCount(ctx context.Context, userID uuid.UUID) (int64, error)
+	markRead(ctx context.Context, userID uuid.UUID, keys []string) error
+
+	preferences(ctx context.Context, userID uuid.UUID) ([]Preference, error)
+	savePreferences(ctx context.Context, prefs []Preference) error
+	usernames(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]string, error)
+}
+
+type repositoryImpl struct {
+	db *gorm.DB
+}
+
+func newRepo(db *gorm.DB) repository {
+	return &repositoryImpl{db: db}
+}
+
+func (r *repositoryImpl) owner(ctx context.Context, subjectType string, id uuid.UUID) (uuid.UUID, *uuid.UUID, error) {
+	sub, ok := subjects[subjectType]
+	if !ok {
+		return uuid.Nil, nil, errSubjectNotFound
+	}
+	parent := "NULL::uuid"
+	if sub.parent != "" {
+		parent = sub.parent
+	}
+
+	var row struct {
+		OwnerID  uuid.UUID
+		ParentID *uuid.UUID
+	}
+	res := r.db.WithContext(ctx).
+		Table(sub.table).
+		Select(sub.owner+" AS owner_id, "+parent+" AS parent_id").
+		Where("id = ?", id).
+		Limit(1).
+		Scan(&row)
+	if res.Error != nil {
+		return uuid.Nil, nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return uuid.Nil, nil, errSubjectNotFound
+	}
+	return row.OwnerID, row.ParentID, nil
+}
+
+func (r *repositoryImpl) enabled(ctx context.Context, userID uuid.UUID, typ string) (bool, error) {
+	var prefs []Preference
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND type = ?", userID, typ).
+		Limit(1).
+		Find(&prefs).Error
+	if err != nil || len(prefs) == 0 {
+		return err == nil, err
+	}
+	return prefs[0].Enabled, nil
+}
+
+// upsert adds the notification, or brings the one of the same actor and group
+// back up as unread.
+func (r *repositoryImpl) upsert(ctx context.Context, n *Notification) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "group_key"}, {Name: "actor_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"content_id": n.ContentID,
+			"note":       n.Note,
+			"read_at":    nil,
+			"created_at": n.CreatedAt,
+		}),
+	}).Create(n).Error
+}
+
+// groups aggregates the notifications of the user by group, latest first.
+func (r *repositoryImpl) groups(ctx context.Context, userID uuid.UUID, unread bool, limit, offset int) ([]groupRow, error) {
+	query := r.db.WithContext(ctx).
+		Model(&Notification{}).
+		Select(`group_key, type, subject_type, subject_id,
+			(array_agg(parent_id))[1] AS parent_id,
+			(array_agg(content_id ORDER BY created_at DESC))[1] AS content_id,
+			(array_agg(note ORDER BY created_at DESC))[1] AS note,
+			(array_agg(actor_id::text ORDER BY created_at DESC))[1:3] AS actor_ids,
+			COUNT(*) AS actor_count,
+			bool_and(read_at IS NOT NULL) AS read,
+			MAX(created_at) AS latest_at`).
+		Where("user_id = ?", userID).
+		Group("group_key, type, subject_type, subject_id")
+	if unread {
+		query = query.Having("bool_or(read_at IS NULL)")
+	}
+
+	var rows []groupRow
+	err := query.
+		Order("latest_at DESC, group_key").
+		Limit(limit).
+		Offset(offset).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// unreadCount counts the groups with unread notifications.
+func (r *repositoryImpl) unreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Distinct("group_key").
+		Count(&count).Error
+	return count, err
+}
+
+func (r *repositoryImpl) markRead(ctx context.Context, userID uuid.UUID, keys []string) error {
+	query := r.db.WithContext(ctx).
+		Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID)
+	if len(keys) > 0 {
+		query = query.Where("group_key IN ?", keys)
+	}
+	return query.Update("read_at", time.Now()).Error
+}
+
+func (r *repositoryImpl) preferences(ctx context.Context, userID uuid.UUID) ([]Preference, error) {
+	var prefs []Preference
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&prefs).Error
+	return prefs, err
+}
+
+func (r *repositoryImpl) savePreferences(ctx context.Context, prefs []Preference) error {
+	if len(prefs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled"}),
+	}).Create(&prefs).Error
+}
+
+func (r *repositoryImpl) usernames(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]string, error) {
+	names := map[uuid.UUID]string{}
+	if len(ids) == 0 {
+		return names, nil
+	}
+	var rows []struct {
+		ID       uuid.UUID
+		Username string
+	}
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Select("id, username").
+		Where("id IN ?", ids).
+		Scan(&rows).Error
+	for _, row := range rows {
+		names[row.ID] = row.Username
+	}
+	return names, err
+}
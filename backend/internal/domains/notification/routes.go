@@ -0,0 +1,17 @@
+package notification
+
+import (
+	"pokemon/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *handler) RegisterRoutes(router fiber.Router) {
+	group := router.Group("/notifications", middleware.AuthRequired())
+
+	group.Get("/", h.list)
+	group.Get("/unread-count", h.unreadCount)
+	group.Post("/read", h.markRead)
+	group.Get("/preferences", h.preferences)
+	group.Put("/preferences", h.setPreferences)
+}
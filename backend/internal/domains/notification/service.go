@@ -0,0 +1,245 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const unreadTTL = 10 * time.Minute
+
+func redisUnreadKey(userID uuid.UUID) string {
+	return fmt.Sprintf("notifications:unread:%s", userID.String())
+}
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type service interface {
+	notify(ctx context.Context, e Event) error
+
+	list(ctx context.Context, userID uuid.UUID, unread bool, limit, offset int) ([]Group, error)
+	unread(ctx context.Context, userID uuid.UUID) (int64, error)
+	markRead(ctx context.Context, userID uuid.UUID, keys []string) error
+
+	preferences(ctx context.Context, userID uuid.UUID) (map[string]bool, error)
+	setPreferences(ctx context.Context, userID uuid.UUID, prefs map[string]bool) (map[string]bool, error)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type serviceImpl struct {
+	repo  repository
+	redis *redis.Client
+}
+
+func newServ(repo repository, redis *redis.Client) service {
+	return &serviceImpl{repo: repo, redis: redis}
+}
+
+// notify stores the event for its recipient, unless they are the actor, the
+// subject is gone or they turned the type off.
+func (s *serviceImpl) notify(ctx context.Context, e Event) error {
+	if !validType(e.Type) {
+		return errUnknownType
+	}
+	ownerID, parentID, err := s.repo.owner(ctx, e.SubjectType, e.SubjectID)
+	if errors.Is(err, errSubjectNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	recipientID := ownerID
+	if e.RecipientID != nil {
+		recipientID = *e.RecipientID
+	}
+	if recipientID == uuid.Nil || recipientID == e.ActorID {
+		return nil
+	}
+	enabled, err := s.repo.enabled(ctx, recipientID, e.Type)
+	if err != nil || !enabled {
+		return err
+	}
+
+	n := &Notification{
+		UserID:      recipientID,
+		GroupKey:    e.Type + ":" + e.SubjectType + ":" + e.SubjectID.String(),
+		ActorID:     e.ActorID,
+		Type:        e.Type,
+		SubjectType: e.SubjectType,
+		SubjectID:   e.SubjectID,
+		ParentID:    parentID,
+		ContentID:   e.ContentID,
+		Note:        e.Note,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.repo.upsert(ctx, n); err != nil {
+		return err
+	}
+	s.redis.Del(ctx, redisUnreadKey(recipientID))
+	return nil
+}
+
+// list returns the notification groups of the user, latest first, only the
+// ones with unread notifications when unread is set.
+func (s *serviceImpl) list(ctx context.Context, userID uuid.UUID, unread bool, limit, offset int) ([]Group, error) {
+	rows, err := s.repo.groups(ctx, userID, unread, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	var actorIDs []uuid.UUID
+	for _, row := range rows {
+		for _, id := range row.ActorIDs {
+			if parsed, err := uuid.Parse(id); err == nil {
+				actorIDs = append(actorIDs, parsed)
+			}
+		}
+	}
+	names, err := s.repo.usernames(ctx, actorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]Group, 0, len(rows))
+	for _, row := range rows {
+		g := Group{
+			Key:         row.GroupKey,
+			Type:        row.Type,
+			SubjectType: row.SubjectType,
+			SubjectID:   row.SubjectID,
+			ParentID:    row.ParentID,
+			ContentID:   row.ContentID,
+			Note:        row.Note,
+			Actors:      []Actor{},
+			ActorCount:  row.ActorCount,
+			Read:        row.Read,
+			LatestAt:    row.LatestAt,
+		}
+		for _, id := range row.ActorIDs {
+			if parsed, err := uuid.Parse(id); err == nil {
+				g.Actors = append(g.Actors, Actor{ID: parsed, Username: names[parsed]})
+			}
+		}
+		sub := subjects[row.SubjectType]
+		if sub.url != nil && (sub.parent == "" || row.ParentID != nil) {
+			g.URL = sub.url(row.SubjectID, row.ParentID)
+		}
+		g.Text = text(&g, sub.noun)
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+// unread counts the groups with unread notifications, for the badge.
+func (s *serviceImpl) unread(ctx context.Context, userID uuid.UUID) (int64, error) {
+	key := redisUnreadKey(userID)
+	if val, err := s.redis.Get(ctx, key).Result(); err == nil {
+		if count, err := strconv.ParseInt(val, 10, 64); err == nil {
+			return count, nil
+		}
+	}
+
+	count, err := s.repo.unreadCount(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	s.redis.Set(ctx, key, count, unreadTTL)
+	return count, nil
+}
+
+func (s *serviceImpl) markRead(ctx context.Context, userID uuid.UUID, keys []string) error {
+	if err := s.repo.markRead(ctx, userID, keys); err != nil {
+		return err
+	}
+	s.redis.Del(ctx, redisUnreadKey(userID))
+	return nil
+}
+
+// preferences tells for every type whether the user gets it.
+func (s *serviceImpl) preferences(ctx context.Context, userID uuid.UUID) (map[string]bool, error) {
+	saved, err := s.repo.preferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	prefs := make(map[string]bool, len(types))
+	for _, t := range types {
+		prefs[t] = true
+	}
+	for _, p := range saved {
+		if validType(p.Type) {
+			prefs[p.Type] = p.Enabled
+		}
+	}
+	return prefs, nil
+}
+
+// setPreferences changes the types given and leaves the others as they are.
+func (s *serviceImpl) setPreferences(ctx context.Context, userID uuid.UUID, prefs map[string]bool) (map[string]bool, error) {
+	list := make([]Preference, 0, len(prefs))
+	for typ, enabled := range prefs {
+		if !validType(typ) {
+			return nil, errUnknownType
+		}
+		list = append(list, Preference{UserID: userID, Type: typ, Enabled: enabled})
+	}
+	if err := s.repo.savePreferences(ctx, list); err != nil {
+		return nil, err
+	}
+	return s.preferences(ctx, userID)
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// text words a group for display.
+func text(g *Group, noun string) string {
+	if noun == "" {
+		noun = "content"
+	}
+	who := "Someone"
+	if len(g.Actors) > 0 && g.Actors[0].Username != "" {
+		who = g.Actors[0].Username
+		switch {
+		case g.ActorCount == 2 && len(g.Actors) > 1 && g.Actors[1].Username != "":
+			who += " and " + g.Actors[1].Username
+		case g.ActorCount == 2:
+			who += " and 1 other"
+		case g.ActorCount > 2:
+			who += fmt.Sprintf(" and %d others", g.ActorCount-1)
+		}
+	}
+
+	switch g.Type {
+	case TypeLike:
+		return who + " liked your " + noun
+	case TypeSave:
+		return who + " saved your " + noun
+	case TypeReply:
+		return who + " replied to your " + noun
+	case TypeFollow:
+		return who + " followed you"
+	case TypeMention:
+		return who + " mentioned you in a " + noun
+	}
+
+	switch g.Note {
+	case NoteReported:
+		return who + " reported an inaccuracy in your " + noun
+	case NoteResolved, NoteDismissed:
+		return "Your " + noun + " was " + g.Note
+	default:
+		return "Your " + noun + " was " + g.Note + " by a moderator"
+	}
+}
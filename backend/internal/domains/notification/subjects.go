@@ -0,0 +1,51 @@
+package notification
+
+import (
+	"pokemon/pkg/permalink"
+
+	"github.com/google/uuid"
+)
+
+// subject describes how the rows of one table are notified about: owner is
+// the column of the user they belong to and parent the one of the page they
+// are shown on, if any.
+type subject struct {
+	table  string
+	owner  string
+	parent string
+	// noun names the subject in notification texts
+	noun string
+	url  func(id uuid.UUID, parentID *uuid.UUID) string
+}
+
+func onParent(link func(string, uuid.UUID) string) func(uuid.UUID, *uuid.UUID) string {
+	return func(_ uuid.UUID, parentID *uuid.UUID) string {
+		return link("", *parentID)
+	}
+}
+
+func onSelf(link func(string, uuid.UUID) string) func(uuid.UUID, *uuid.UUID) string {
+	return func(id uuid.UUID, _ *uuid.UUID) string {
+		return link("", id)
+	}
+}
+
+var subjects = map[string]subject{
+	SubjectShout: {table: "shouts", owner: "user_id", noun: "shout", url: onSelf(permalink.Shout)},
+	SubjectShoutComment: {table: "shout_comments", owner: "user_id", parent: "shout_id", noun: "comment",
+		url: func(id uuid.UUID, parentID *uuid.UUID) string { return permalink.ShoutComment("", *parentID, id) }},
+	SubjectTopic: {table: "topics", owner: "user_id", noun: "topic", url: onSelf(permalink.Topic)},
+	SubjectTopicComment: {table: "topic_comments", owner: "user_id", parent: "topic_id", noun: "comment",
+		url: func(id uuid.UUID, parentID *uuid.UUID) string { return permalink.TopicComment("", *parentID, id) }},
+	SubjectTeam:        {table: "teams", owner: "user_id", noun: "team", url: onSelf(permalink.Team)},
+	SubjectTeamComment: {table: "team_comments", owner: "user_id", parent: "team_id", noun: "comment", url: onParent(permalink.Team)},
+	SubjectPost:        {table: "posts", owner: "user_id", noun: "post", url: onSelf(permalink.Post)},
+	SubjectSnap:        {table: "snaps", owner: "user_id", noun: "snap", url: onSelf(permalink.Snap)},
+	SubjectSnapComment: {table: "snap_comments", owner: "user_id", parent: "snap_id", noun: "comment", url: onParent(permalink.Snap)},
+	SubjectRun:         {table: "runs", owner: "user_id", noun: "Nuzlocke run", url: onSelf(permalink.Nuzlocke)},
+	SubjectRunComment:  {table: "run_comments", owner: "user_id", parent: "run_id", noun: "comment", url: onParent(permalink.Nuzlocke)},
+	SubjectWalkthrough: {table: "walkthroughs", owner: "user_id", noun: "walkthrough", url: onSelf(permalink.Walkthrough)},
+	SubjectGuide:       {table: "game_guides", owner: "author_id", noun: "guide", url: onSelf(permalink.Guide)},
+	SubjectGuideReport: {table: "game_guide_reports", owner: "reporter_id", parent: "guide_id", noun: "report", url: onParent(permalink.Guide)},
+	SubjectUser:        {table: "users", owner: "id", noun: "profile", url: onSelf(permalink.User)},
+}
@@ -0,0 +1,218 @@
+package nuzlocke
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"pokemon/internal/domains/game"
+	"pokemon/internal/domains/user"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+/********
+ * MAIN *
+ ********/
+
+type RunStatus string
+
+const (
+	RunActive    RunStatus = "active"
+	RunWon       RunStatus = "won"
+	RunFailed    RunStatus = "failed"
+	RunAbandoned RunStatus = "abandoned"
+)
+
+type Rules struct {
+	DupesClause bool     `json:"dupes_clause"` // species already caught don't count as the route's encounter
+	ShinyClause bool     `json:"shiny_clause"` // shinies can always be caught on top of the route's encounter
+	LevelCaps   []int    `json:"level_caps"`   // cap for each badge count: index 0 before the first gym
+	Custom      []string `json:"custom"`       // free-text house rules, not enforced
+}
+
+type Run struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID      uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	User        user.User `json:"user" gorm:"foreignKey:UserID"`
+	GameID      uuid.UUID `json:"game_id" gorm:"type:uuid;not null;index"`
+	Game        game.Game `json:"game" gorm:"foreignKey:GameID"`
+	Name        string    `json:"name" gorm:"type:varchar(100);not null"`
+	Description string    `json:"description" gorm:"type:text"`
+
+	Rules    Rules     `json:"rules" gorm:"type:jsonb"`
+	Badges   int       `json:"badges" gorm:"type:int;default:0"`
+	Status   RunStatus `json:"status" gorm:"type:varchar(16);not null;default:'active';index"`
+	IsPublic bool      `json:"is_public"` // set to true by the create handler unless sent
+
+	Encounters []Encounter `json:"encounters,omitempty" gorm:"foreignKey:RunID;constraint:OnDelete:CASCADE"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+type EncounterOutcome string
+
+const (
+	OutcomeCaught  EncounterOutcome = "caught"
+	OutcomeFailed  EncounterOutcome = "failed"  // fainted or fled before being caught
+	OutcomeSkipped EncounterOutcome = "skipped" // dupe or otherwise not counted
+)
+
+type Box string
+
+const (
+	BoxParty     Box = "party"
+	BoxPC        Box = "box"
+	BoxGraveyard Box = "graveyard"
+)
+
+// Encounter is the first encounter logged on a route, plus everything that
+// happens to the Pokémon afterwards when it is caught.
+type Encounter struct {
+	ID        uuid.UUID        `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	RunID     uuid.UUID        `json:"run_id" gorm:"type:uuid;not null;index"`
+	Location  string           `json:"location" gorm:"type:varchar(100);not null"`
+	PokemonID int              `json:"pokemon_id" gorm:"type:int;not null"`
+	Nickname  string           `json:"nickname" gorm:"type:varchar(20)"`
+	Level     int              `json:"level" gorm:"type:int;default:1"`
+	IsShiny   bool             `json:"is_shiny" gorm:"default:false"`
+	Outcome   EncounterOutcome `json:"outcome" gorm:"type:varchar(16);not null"`
+
+	Box      Box `json:"box" gorm:"type:varchar(16)"` // only for caught Pokémon
+	BoxOrder int `json:"box_order" gorm:"type:int;default:0"`
+
+	DiedAt     *time.Time `json:"died_at"`
+	DeathLevel int        `json:"death_level" gorm:"type:int"`
+	DeathCause string     `json:"death_cause" gorm:"type:text"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+/****************
+ * API RESPONSE *
+ ****************/
+
+type Violation struct {
+	Rule        string     `json:"rule"`
+	Message     string     `json:"message"`
+	EncounterID *uuid.UUID `json:"encounter_id,omitempty"`
+}
+
+type RunDetail struct {
+	Run
+	Violations []Violation `json:"violations"`
+}
+
+/************
+ * REQUESTS *
+ ************/
+
+type deathRequest struct {
+	Level int    `json:"level"`
+	Cause string `json:"cause"`
+}
+
+type moveRequest struct {
+	Box   Box `json:"box"`
+	Order int `json:"order"`
+}
+
+/***************
+ * VALIDATIONS *
+ ***************/
+
+func (r *Rules) Scan(value interface{}) error {
+	return json.Unmarshal(value.([]byte), r)
+}
+
+func (r Rules) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+func (r *Run) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" || len(r.Name) > 100 {
+		return errors.New("name is required and cannot exceed 100 characters")
+	}
+	if r.GameID == uuid.Nil {
+		return errors.New("game_id is required")
+	}
+	if r.Badges < 0 {
+		return errors.New("badges cannot be negative")
+	}
+	for _, level := range r.Rules.LevelCaps {
+		if level < 1 || level > 100 {
+			return errors.New("level caps must be between 1 and 100")
+		}
+	}
+	switch r.Status {
+	case "":
+		r.Status = RunActive
+	case RunActive, RunWon, RunFailed, RunAbandoned:
+	default:
+		return errors.New("unknown run status")
+	}
+	return nil
+}
+
+func (e *Encounter) Validate() error {
+	e.Location = strings.TrimSpace(e.Location)
+	e.Nickname = strings.TrimSpace(e.Nickname)
+	if e.Location == "" {
+		return errors.New("location is required")
+	}
+	if e.PokemonID <= 0 {
+		return errors.New("pokemon_id must be a positive integer")
+	}
+	if len(e.Nickname) > 20 {
+		return errors.New("nickname cannot exceed 20 characters")
+	}
+	if e.Level < 1 || e.Level > 100 {
+		return errors.New("level must be between 1 and 100")
+	}
+
+	switch e.Outcome {
+	case OutcomeCaught:
+		if e.Box == "" {
+			e.Box = BoxParty
+		}
+		if !e.Box.valid() {
+			return errors.New("unknown box")
+		}
+	case OutcomeFailed, OutcomeSkipped:
+		e.Box = ""
+	default:
+		return errors.New("outcome must be caught, failed or skipped")
+	}
+	return nil
+}
+
+func (b Box) valid() bool {
+	return b == BoxParty || b == BoxPC || b == BoxGraveyard
+}
+
+func (e *Encounter) isDead() bool {
+	return e.DiedAt != nil
+}
+
+/****************
+ * INTERACTIONS *
+ ****************/
+
+// --- Run Comment with nesting support ---
+type RunComment struct {
+	ID        uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
+	User      user.User      `gorm:"foreignKey:UserID" json:"user"`
+	RunID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"run_id"`
+	ParentID  *uuid.UUID     `gorm:"type:uuid;index" json:"parent_id,omitempty"` // nullable, for nested comments
+	Content   string         `gorm:"type:text;not null" json:"content"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
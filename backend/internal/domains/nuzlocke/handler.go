@@ -0,0 +1,289 @@
+package nuzlocke
+
+import (
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	runSvc     runService
+	commentSvc commentService
+}
+
+func NewHandler(db *gorm.DB, redis *redis.Client) *handler {
+	runR := newRunRepo(db)
+	runS := newRunService(runR, redis)
+
+	commentR := newCommentRepo(db)
+	commentS := newCommentService(commentR)
+
+	return &handler{
+		runSvc:     runS,
+		commentSvc: commentS,
+	}
+}
+
+// GET /nuzlockes - public runs, most recently updated first
+func (h *handler) listPublic(c *fiber.Ctx) error {
+	limit, offset := utils.ParsePagination(c)
+	list, total, err := h.runSvc.listPublic(c.Context(), limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": total,
+		"items": list,
+	})
+}
+
+// GET /nuzlockes/user/:user_id
+func (h *handler) listByUser(c *fiber.Ctx) error {
+	ownerID := utils.ParseUUID(c.Params("user_id"))
+	if ownerID == uuid.Nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID")
+	}
+
+	userID, _ := utils.GetUserIDFromLocals(c)
+	limit, offset := utils.ParsePagination(c)
+
+	list, total, err := h.runSvc.listByUser(c.Context(), ownerID, ownerID != userID, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": total,
+		"items": list,
+	})
+}
+
+func (h *handler) create(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	// Runs are public unless the body says otherwise
+	run := Run{IsPublic: true}
+	if err := c.BodyParser(&run); err != nil {
+		return fiber.ErrBadRequest
+	}
+	run.ID = uuid.Nil
+	run.UserID = userID
+	run.Encounters = nil
+
+	if err := h.runSvc.create(c.Context(), &run); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(run)
+}
+
+// GET /nuzlockes/:id - the public run page, with detected rule violations
+func (h *handler) get(c *fiber.Ctx) error {
+	run, err := h.visibleRun(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(RunDetail{Run: *run, Violations: detectViolations(run)})
+}
+
+// GET /nuzlockes/:id/violations
+func (h *handler) listViolations(c *fiber.Ctx) error {
+	run, err := h.visibleRun(c)
+	if err != nil {
+		return err
+	}
+	list := detectViolations(run)
+	return c.JSON(fiber.Map{
+		"total": len(list),
+		"items": list,
+	})
+}
+
+// GET /nuzlockes/:id/graveyard
+func (h *handler) listGraveyard(c *fiber.Ctx) error {
+	run, err := h.visibleRun(c)
+	if err != nil {
+		return err
+	}
+
+	list := []Encounter{}
+	for _, e := range run.Encounters {
+		if e.isDead() {
+			list = append(list, e)
+		}
+	}
+	return c.JSON(fiber.Map{
+		"total": len(list),
+		"items": list,
+	})
+}
+
+func (h *handler) update(c *fiber.Ctx) error {
+	run, err := h.ownRun(c)
+	if err != nil {
+		return err
+	}
+
+	protected := *run
+	if err := c.BodyParser(run); err != nil {
+		return fiber.ErrBadRequest
+	}
+	run.ID = protected.ID
+	run.UserID = protected.UserID
+	run.GameID = protected.GameID
+	run.Encounters = protected.Encounters
+
+	if err := h.runSvc.update(c.Context(), run); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.JSON(RunDetail{Run: *run, Violations: detectViolations(run)})
+}
+
+func (h *handler) delete(c *fiber.Ctx) error {
+	run, err := h.ownRun(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.runSvc.delete(c.Context(), run.ID.String()); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+/**************
+ * ENCOUNTERS *
+ **************/
+
+// POST /nuzlockes/:id/encounters
+func (h *handler) addEncounter(c *fiber.Ctx) error {
+	run, err := h.ownRun(c)
+	if err != nil {
+		return err
+	}
+
+	var encounter Encounter
+	if err := c.BodyParser(&encounter); err != nil {
+		return fiber.ErrBadRequest
+	}
+	if err := h.runSvc.addEncounter(c.Context(), run, &encounter); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return h.respondWithRun(c, fiber.StatusCreated, run.ID.String())
+}
+
+// PUT /nuzlockes/:id/encounters/:encounter_id
+func (h *handler) updateEncounter(c *fiber.Ctx) error {
+	run, err := h.ownRun(c)
+	if err != nil {
+		return err
+	}
+
+	var encounter Encounter
+	if err := c.BodyParser(&encounter); err != nil {
+		return fiber.ErrBadRequest
+	}
+	encounter.ID = utils.ParseUUID(c.Params("encounter_id"))
+
+	if err := h.runSvc.updateEncounter(c.Context(), run, &encounter); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return h.respondWithRun(c, fiber.StatusOK, run.ID.String())
+}
+
+// DELETE /nuzlockes/:id/encounters/:encounter_id
+func (h *handler) deleteEncounter(c *fiber.Ctx) error {
+	run, err := h.ownRun(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.runSvc.deleteEncounter(c.Context(), run, c.Params("encounter_id")); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// POST /nuzlockes/:id/encounters/:encounter_id/death
+func (h *handler) recordDeath(c *fiber.Ctx) error {
+	run, err := h.ownRun(c)
+	if err != nil {
+		return err
+	}
+
+	var req deathRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.ErrBadRequest
+		}
+	}
+	if _, err := h.runSvc.recordDeath(c.Context(), run, c.Params("encounter_id"), req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return h.respondWithRun(c, fiber.StatusOK, run.ID.String())
+}
+
+// POST /nuzlockes/:id/encounters/:encounter_id/move
+func (h *handler) moveEncounter(c *fiber.Ctx) error {
+	run, err := h.ownRun(c)
+	if err != nil {
+		return err
+	}
+
+	var req moveRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.ErrBadRequest
+	}
+	if _, err := h.runSvc.move(c.Context(), run, c.Params("encounter_id"), req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return h.respondWithRun(c, fiber.StatusOK, run.ID.String())
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// respondWithRun reloads the run so the response carries the fresh violations.
+func (h *handler) respondWithRun(c *fiber.Ctx, status int, id string) error {
+	run, err := h.runSvc.getByID(c.Context(), id)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.Status(status).JSON(RunDetail{Run: *run, Violations: detectViolations(run)})
+}
+
+// visibleRun loads the run from :id, hiding private runs from everyone but their owner.
+func (h *handler) visibleRun(c *fiber.Ctx) (*Run, error) {
+	run, err := h.runSvc.getByID(c.Context(), c.Params("id"))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "run not found")
+	}
+
+	userID, _ := utils.GetUserIDFromLocals(c)
+	if !run.IsPublic && run.UserID != userID {
+		return nil, fiber.NewError(fiber.StatusNotFound, "run not found")
+	}
+	return run, nil
+}
+
+// ownRun loads the run from :id and makes sure it belongs to the caller.
+func (h *handler) ownRun(c *fiber.Ctx) (*Run, error) {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return nil, fiber.ErrUnauthorized
+	}
+
+	run, err := h.runSvc.getByID(c.Context(), c.Params("id"))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "run not found")
+	}
+	if run.UserID != userID {
+		return nil, fiber.ErrForbidden
+	}
+	return run, nil
+}
@@ -0,0 +1,96 @@
+package nuzlocke
+
+import (
+	"pokemon/internal/domains/notification"
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+/**************************
+ * HANDLER IMPLEMENTATION  *
+ **************************/
+
+// POST /nuzlockes/:id/comments
+func (h *handler) commentRun(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	run, err := h.visibleRun(c)
+	if err != nil {
+		return err
+	}
+
+	var body struct {
+		Content  string     `json:"content"`
+		ParentID *uuid.UUID `json:"parent_id,omitempty"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	comment := RunComment{
+		UserID:   userID,
+		RunID:    run.ID,
+		ParentID: body.ParentID,
+		Content:  body.Content,
+	}
+	if err := h.commentSvc.create(c.Context(), &comment); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	reply := notification.Event{Type: notification.TypeReply, ActorID: userID, SubjectType: notification.SubjectRun, SubjectID: run.ID, ContentID: &comment.ID}
+	if comment.ParentID != nil {
+		reply.SubjectType, reply.SubjectID = notification.SubjectRunComment, *comment.ParentID
+	}
+	notification.Send(reply)
+
+	return c.Status(fiber.StatusCreated).JSON(comment)
+}
+
+// GET /nuzlockes/:id/comments
+func (h *handler) getRunComments(c *fiber.Ctx) error {
+	run, err := h.visibleRun(c)
+	if err != nil {
+		return err
+	}
+
+	limit, offset := utils.ParsePagination(c)
+	list, total, err := h.commentSvc.listByRun(c.Context(), run.ID.String(), limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": total,
+		"items": list,
+	})
+}
+
+// DELETE /nuzlockes/:id/comments/:comment_id - by its author or the runner
+func (h *handler) deleteComment(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	run, err := h.visibleRun(c)
+	if err != nil {
+		return err
+	}
+
+	comment, err := h.commentSvc.getByID(c.Context(), c.Params("comment_id"))
+	if err != nil || comment.RunID != run.ID {
+		return fiber.NewError(fiber.StatusNotFound, "comment not found")
+	}
+	if comment.UserID != userID && run.UserID != userID {
+		return fiber.ErrForbidden
+	}
+
+	if err := h.commentSvc.delete(c.Context(), comment.ID.String()); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
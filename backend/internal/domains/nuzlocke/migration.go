@@ -0,0 +1,13 @@
+package nuzlocke
+
+import "gorm.io/gorm"
+
+type NuzlockeMigrator struct{}
+
+func (m NuzlockeMigrator) Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&Run{},
+		&Encounter{},
+		&RunComment{},
+	)
+}
@@ -0,0 +1,169 @@
+package nuzlocke
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+/******************************
+ ******************************
+ ************ MAIN ************
+ ******************************
+ ******************************/
+
+type runRepository interface {
+	listPublic(ctx context.Context, limit, offset int) ([]Run, int64, error)
+	listByUser(ctx context.Context, userID uuid.UUID, publicOnly bool, limit, offset int) ([]Run, int64, error)
+
+	create(ctx context.Context, run *Run) error
+	getByID(ctx context.Context, id string) (*Run, error)
+	update(ctx context.Context, run *Run) error
+	delete(ctx context.Context, id string) error
+
+	createEncounter(ctx context.Context, encounter *Encounter) error
+	getEncounter(ctx context.Context, runID, id string) (*Encounter, error)
+	updateEncounter(ctx context.Context, encounter *Encounter) error
+	deleteEncounter(ctx context.Context, runID, id string) error
+}
+
+type runRepoImpl struct {
+	db *gorm.DB
+}
+
+func newRunRepo(db *gorm.DB) runRepository {
+	return &runRepoImpl{db: db}
+}
+
+func (r *runRepoImpl) listPublic(ctx context.Context, limit, offset int) ([]Run, int64, error) {
+	var runs []Run
+	var count int64
+
+	tx := r.db.WithContext(ctx).Model(&Run{}).Where("is_public = ?", true)
+	if err := tx.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Preload("Game").Preload("User").Order("updated_at DESC").Limit(limit).Offset(offset).Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return runs, count, nil
+}
+
+func (r *runRepoImpl) listByUser(ctx context.Context, userID uuid.UUID, publicOnly bool, limit, offset int) ([]Run, int64, error) {
+	var runs []Run
+	var count int64
+
+	tx := r.db.WithContext(ctx).Model(&Run{}).Where("user_id = ?", userID)
+	if publicOnly {
+		tx = tx.Where("is_public = ?", true)
+	}
+	if err := tx.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Preload("Game").Order("updated_at DESC").Limit(limit).Offset(offset).Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return runs, count, nil
+}
+
+func (r *runRepoImpl) create(ctx context.Context, run *Run) error {
+	return r.db.WithContext(ctx).Omit("User", "Game", "Encounters").Create(run).Error
+}
+
+func (r *runRepoImpl) getByID(ctx context.Context, id string) (*Run, error) {
+	var run Run
+	err := r.db.WithContext(ctx).
+		Preload("Game").Preload("User").
+		Preload("Encounters", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		First(&run, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (r *runRepoImpl) update(ctx context.Context, run *Run) error {
+	return r.db.WithContext(ctx).Omit("User", "Game", "Encounters").Save(run).Error
+}
+
+func (r *runRepoImpl) delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&Run{}, "id = ?", id).Error
+}
+
+func (r *runRepoImpl) createEncounter(ctx context.Context, encounter *Encounter) error {
+	return r.db.WithContext(ctx).Create(encounter).Error
+}
+
+func (r *runRepoImpl) getEncounter(ctx context.Context, runID, id string) (*Encounter, error) {
+	var encounter Encounter
+	if err := r.db.WithContext(ctx).First(&encounter, "id = ? AND run_id = ?", id, runID).Error; err != nil {
+		return nil, err
+	}
+	return &encounter, nil
+}
+
+func (r *runRepoImpl) updateEncounter(ctx context.Context, encounter *Encounter) error {
+	return r.db.WithContext(ctx).Save(encounter).Error
+}
+
+func (r *runRepoImpl) deleteEncounter(ctx context.Context, runID, id string) error {
+	return r.db.WithContext(ctx).Delete(&Encounter{}, "id = ? AND run_id = ?", id, runID).Error
+}
+
+/**************************************
+ **************************************
+ ************ INTERACTIONS ************
+ **************************************
+ **************************************/
+
+type commentRepository interface {
+	create(ctx context.Context, comment *RunComment) error
+	getByID(ctx context.Context, id string) (*RunComment, error)
+	listByRun(ctx context.Context, runID string, limit, offset int) ([]RunComment, int64, error)
+	delete(ctx context.Context, id string) error
+}
+
+type commentRepoImpl struct {
+	db *gorm.DB
+}
+
+func newCommentRepo(db *gorm.DB) commentRepository {
+	return &commentRepoImpl{db: db}
+}
+
+func (r *commentRepoImpl) create(ctx context.Context, comment *RunComment) error {
+	return r.db.WithContext(ctx).Omit("User").Create(comment).Error
+}
+
+func (r *commentRepoImpl) getByID(ctx context.Context, id string) (*RunComment, error) {
+	var comment RunComment
+	if err := r.db.WithContext(ctx).First(&comment, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *commentRepoImpl) listByRun(ctx context.Context, runID string, limit, offset int) ([]RunComment, int64, error) {
+	var comments []RunComment
+	var count int64
+
+	tx := r.db.WithContext(ctx).Model(&RunComment{}).Where("run_id = ?", runID)
+	if err := tx.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Preload("User").Order("created_at ASC").Limit(limit).Offset(offset).Find(&comments).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return comments, count, nil
+}
+
+func (r *commentRepoImpl) delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&RunComment{}, "id = ?", id).Error
+}
@@ -0,0 +1,34 @@
+package nuzlocke
+
+import (
+	"pokemon/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *handler) RegisterRoutes(router fiber.Router) {
+	group := router.Group("/nuzlockes")
+
+	// Public run pages
+	group.Get("/", h.listPublic)
+	group.Get("/user/:user_id", middleware.AuthOptional(), h.listByUser)
+	group.Get("/:id", middleware.AuthOptional(), h.get)
+	group.Get("/:id/violations", middleware.AuthOptional(), h.listViolations)
+	group.Get("/:id/graveyard", middleware.AuthOptional(), h.listGraveyard)
+	group.Get("/:id/comments", middleware.AuthOptional(), h.getRunComments)
+
+	group.Use(middleware.AuthRequired())
+	group.Post("/", h.create)
+	group.Put("/:id", h.update)
+	group.Delete("/:id", h.delete)
+
+	group.Post("/:id/encounters", h.addEncounter)
+	group.Put("/:id/encounters/:encounter_id", h.updateEncounter)
+	group.Delete("/:id/encounters/:encounter_id", h.deleteEncounter)
+	group.Post("/:id/encounters/:encounter_id/death", h.recordDeath)
+	group.Post("/:id/encounters/:encounter_id/move", h.moveEncounter)
+
+	// Interactions
+	group.Post("/:id/comments", h.commentRun)
+	group.Delete("/:id/comments/:comment_id", h.deleteComment)
+}
@@ -0,0 +1,95 @@
+package nuzlocke
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const maxPartySize = 6
+
+// levelCap returns the cap for the run's current badge count, or 0 when the
+// run has no level caps. Past the last configured cap, the last one applies.
+func (r *Run) levelCap() int {
+	caps := r.Rules.LevelCaps
+	if len(caps) == 0 {
+		return 0
+	}
+	return caps[min(r.Badges, len(caps)-1)]
+}
+
+// detectViolations replays the run's encounters in the order they were logged
+// and reports every broken rule. Violations are reported rather than refused,
+// so runners can keep an honest log of their mistakes.
+//
+// The dupes clause works on exact species; evolution families are not known
+// to the backend.
+func detectViolations(run *Run) []Violation {
+	encounters := make([]Encounter, len(run.Encounters))
+	copy(encounters, run.Encounters)
+	sort.SliceStable(encounters, func(i, j int) bool {
+		return encounters[i].CreatedAt.Before(encounters[j].CreatedAt)
+	})
+
+	violations := []Violation{}
+	add := func(rule string, e *Encounter, format string, args ...any) {
+		v := Violation{Rule: rule, Message: fmt.Sprintf(format, args...)}
+		if e != nil {
+			id := e.ID
+			v.EncounterID = &id
+		}
+		violations = append(violations, v)
+	}
+
+	routeUsed := map[string]bool{}
+	caught := map[int]bool{}
+	party := 0
+	levelCap := run.levelCap()
+
+	for i := range encounters {
+		e := &encounters[i]
+		route := strings.ToLower(e.Location)
+		shinyExempt := run.Rules.ShinyClause && e.IsShiny
+		dupe := run.Rules.DupesClause && caught[e.PokemonID]
+
+		switch {
+		case e.Outcome == OutcomeSkipped:
+			if !dupe && !shinyExempt {
+				add("skipped_encounter", e, "%s was skipped on %s but is neither a dupe nor a clause-protected shiny", label(e), e.Location)
+			}
+		case shinyExempt:
+			// Shiny clause: never uses up the route
+		case routeUsed[route]:
+			add("one_encounter_per_route", e, "%s is a second encounter on %s", label(e), e.Location)
+		default:
+			routeUsed[route] = true
+		}
+
+		if e.Outcome != OutcomeCaught {
+			continue
+		}
+		caught[e.PokemonID] = true
+
+		if e.isDead() && e.Box != BoxGraveyard {
+			add("dead_pokemon_used", e, "%s died but is still in the %s", label(e), e.Box)
+		}
+		if e.Box == BoxParty {
+			party++
+			if levelCap > 0 && e.Level > levelCap && !e.isDead() {
+				add("level_cap", e, "%s is level %d, over the cap of %d for %d badges", label(e), e.Level, levelCap, run.Badges)
+			}
+		}
+	}
+
+	if party > maxPartySize {
+		add("party_size", nil, "the party holds %d Pokémon, more than %d", party, maxPartySize)
+	}
+	return violations
+}
+
+func label(e *Encounter) string {
+	if e.Nickname != "" {
+		return e.Nickname
+	}
+	return fmt.Sprintf("#%d", e.PokemonID)
+}
@@ -0,0 +1,256 @@
+package nuzlocke
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type runService interface {
+	listPublic(ctx context.Context, limit, offset int) ([]Run, int64, error)
+	listByUser(ctx context.Context, userID uuid.UUID, publicOnly bool, limit, offset int) ([]Run, int64, error)
+
+	create(ctx context.Context, run *Run) error
+	getByID(ctx context.Context, id string) (*Run, error)
+	update(ctx context.Context, run *Run) error
+	delete(ctx context.Context, id string) error
+
+	addEncounter(ctx context.Context, run *Run, encounter *Encounter) error
+	updateEncounter(ctx context.Context, run *Run, encounter *Encounter) error
+	deleteEncounter(ctx context.Context, run *Run, encounterID string) error
+	recordDeath(ctx context.Context, run *Run, encounterID string, req deathRequest) (*Encounter, error)
+	move(ctx context.Context, run *Run, encounterID string, req moveRequest) (*Encounter, error)
+}
+
+/********************
+ * REDIS KEY UTILS  *
+ ********************/
+
+func redisRunKey(id string) string {
+	return fmt.Sprintf("nuzlocke:%s", id)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type runServiceImpl struct {
+	db    runRepository
+	cache *redis.Client
+}
+
+func newRunService(repo runRepository, cache *redis.Client) runService {
+	return &runServiceImpl{db: repo, cache: cache}
+}
+
+func (s *runServiceImpl) listPublic(ctx context.Context, limit, offset int) ([]Run, int64, error) {
+	return s.db.listPublic(ctx, limit, offset)
+}
+
+func (s *runServiceImpl) listByUser(ctx context.Context, userID uuid.UUID, publicOnly bool, limit, offset int) ([]Run, int64, error) {
+	return s.db.listByUser(ctx, userID, publicOnly, limit, offset)
+}
+
+func (s *runServiceImpl) create(ctx context.Context, run *Run) error {
+	run.Status = RunActive
+	if err := run.Validate(); err != nil {
+		return err
+	}
+	return s.db.create(ctx, run)
+}
+
+func (s *runServiceImpl) getByID(ctx context.Context, id string) (*Run, error) {
+	cacheKey := redisRunKey(id)
+
+	val, err := s.cache.Get(ctx, cacheKey).Result()
+	if err == nil {
+		var run Run
+		if err := json.Unmarshal([]byte(val), &run); err == nil {
+			return &run, nil
+		}
+	}
+
+	run, err := s.db.getByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, _ := json.Marshal(run)
+	s.cache.Set(ctx, cacheKey, bytes, 10*time.Minute)
+
+	return run, nil
+}
+
+func (s *runServiceImpl) update(ctx context.Context, run *Run) error {
+	if err := run.Validate(); err != nil {
+		return err
+	}
+	if err := s.db.update(ctx, run); err != nil {
+		return err
+	}
+	s.cache.Del(ctx, redisRunKey(run.ID.String()))
+	return nil
+}
+
+func (s *runServiceImpl) delete(ctx context.Context, id string) error {
+	if err := s.db.delete(ctx, id); err != nil {
+		return err
+	}
+	s.cache.Del(ctx, redisRunKey(id))
+	return nil
+}
+
+func (s *runServiceImpl) addEncounter(ctx context.Context, run *Run, encounter *Encounter) error {
+	encounter.ID = uuid.Nil
+	encounter.RunID = run.ID
+	encounter.DiedAt = nil
+	if err := encounter.Validate(); err != nil {
+		return err
+	}
+	if err := s.db.createEncounter(ctx, encounter); err != nil {
+		return err
+	}
+	s.cache.Del(ctx, redisRunKey(run.ID.String()))
+	return nil
+}
+
+func (s *runServiceImpl) updateEncounter(ctx context.Context, run *Run, encounter *Encounter) error {
+	existing, err := s.db.getEncounter(ctx, run.ID.String(), encounter.ID.String())
+	if err != nil {
+		return err
+	}
+
+	// Deaths are only recorded through recordDeath
+	encounter.RunID = run.ID
+	encounter.CreatedAt = existing.CreatedAt
+	encounter.DiedAt = existing.DiedAt
+	encounter.DeathLevel = existing.DeathLevel
+	encounter.DeathCause = existing.DeathCause
+
+	if err := encounter.Validate(); err != nil {
+		return err
+	}
+	if err := s.db.updateEncounter(ctx, encounter); err != nil {
+		return err
+	}
+	s.cache.Del(ctx, redisRunKey(run.ID.String()))
+	return nil
+}
+
+func (s *runServiceImpl) deleteEncounter(ctx context.Context, run *Run, encounterID string) error {
+	if err := s.db.deleteEncounter(ctx, run.ID.String(), encounterID); err != nil {
+		return err
+	}
+	s.cache.Del(ctx, redisRunKey(run.ID.String()))
+	return nil
+}
+
+// recordDeath sends a caught Pokémon to the graveyard.
+func (s *runServiceImpl) recordDeath(ctx context.Context, run *Run, encounterID string, req deathRequest) (*Encounter, error) {
+	encounter, err := s.db.getEncounter(ctx, run.ID.String(), encounterID)
+	if err != nil {
+		return nil, err
+	}
+	if encounter.Outcome != OutcomeCaught {
+		return nil, errors.New("only caught Pokémon can die")
+	}
+	if encounter.isDead() {
+		return nil, errors.New("this Pokémon is already in the graveyard")
+	}
+
+	now := time.Now()
+	encounter.DiedAt = &now
+	encounter.DeathLevel = req.Level
+	if encounter.DeathLevel <= 0 {
+		encounter.DeathLevel = encounter.Level
+	}
+	encounter.DeathCause = strings.TrimSpace(req.Cause)
+	encounter.Box = BoxGraveyard
+
+	if err := s.db.updateEncounter(ctx, encounter); err != nil {
+		return nil, err
+	}
+	s.cache.Del(ctx, redisRunKey(run.ID.String()))
+	return encounter, nil
+}
+
+// move puts a caught Pokémon in the party or the PC. Moving a dead Pokémon out
+// of the graveyard is allowed but shows up as a violation.
+func (s *runServiceImpl) move(ctx context.Context, run *Run, encounterID string, req moveRequest) (*Encounter, error) {
+	if !req.Box.valid() {
+		return nil, errors.New("box must be party, box or graveyard")
+	}
+
+	encounter, err := s.db.getEncounter(ctx, run.ID.String(), encounterID)
+	if err != nil {
+		return nil, err
+	}
+	if encounter.Outcome != OutcomeCaught {
+		return nil, errors.New("only caught Pokémon can be moved")
+	}
+
+	encounter.Box = req.Box
+	encounter.BoxOrder = req.Order
+	if err := s.db.updateEncounter(ctx, encounter); err != nil {
+		return nil, err
+	}
+	s.cache.Del(ctx, redisRunKey(run.ID.String()))
+	return encounter, nil
+}
+
+/**************************************
+ **************************************
+ ************ INTERACTIONS ************
+ **************************************
+ **************************************/
+
+type commentService interface {
+	create(ctx context.Context, comment *RunComment) error
+	getByID(ctx context.Context, id string) (*RunComment, error)
+	listByRun(ctx context.Context, runID string, limit, offset int) ([]RunComment, int64, error)
+	delete(ctx context.Context, id string) error
+}
+
+type commentServiceImpl struct {
+	db commentRepository
+}
+
+func newCommentService(repo commentRepository) commentService {
+	return &commentServiceImpl{db: repo}
+}
+
+func (s *commentServiceImpl) create(ctx context.Context, comment *RunComment) error {
+	comment.Content = strings.TrimSpace(comment.Content)
+	if comment.Content == "" {
+		return errors.New("content is required")
+	}
+	if comment.ParentID != nil {
+		parent, err := s.db.getByID(ctx, comment.ParentID.String())
+		if err != nil || parent.RunID != comment.RunID {
+			return errors.New("parent comment not found on this run")
+		}
+	}
+	return s.db.create(ctx, comment)
+}
+
+func (s *commentServiceImpl) getByID(ctx context.Context, id string) (*RunComment, error) {
+	return s.db.getByID(ctx, id)
+}
+
+func (s *commentServiceImpl) listByRun(ctx context.Context, runID string, limit, offset int) ([]RunComment, int64, error) {
+	return s.db.listByRun(ctx, runID, limit, offset)
+}
+
+func (s *commentServiceImpl) delete(ctx context.Context, id string) error {
+	return s.db.delete(ctx, id)
+}
@@ -0,0 +1,82 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Result types, one per searchable source
+const (
+	TypePost            = "post"
+	TypeTopic           = "topic"
+	TypeTopicComment    = "topic_comment"
+	TypeGuide           = "guide"
+	TypeWalkthroughStep = "walkthrough_step"
+	TypeNews            = "news"
+	TypeShout           = "shout"
+	TypeTeam            = "team"
+)
+
+// Result is a single hit. ParentID points to the page the hit lives on when
+// it isn't a page itself (the topic of a comment, the walkthrough of a step).
+type Result struct {
+	Type      string     `json:"type"`
+	ID        uuid.UUID  `json:"id"`
+	ParentID  *uuid.UUID `json:"parent_id,omitempty"`
+	Title     string     `json:"title"`
+	Snippet   string     `json:"snippet"` // HTML escaped, matches wrapped in <mark>
+	AuthorID  uuid.UUID  `json:"author_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	Rank      float64    `json:"rank"`
+}
+
+// Facet is the number of hits of one type, ignoring the type filter.
+type Facet struct {
+	Type  string `json:"type"`
+	Count int64  `json:"count"`
+}
+
+type Page struct {
+	Total  int64    `json:"total"`
+	Items  []Result `json:"items"`
+	Facets []Facet  `json:"facets"`
+}
+
+/************
+ * REQUESTS *
+ ************/
+
+type filters struct {
+	Query  string
+	Types  []string
+	Author string // user id or username
+	From   *time.Time
+	To     *time.Time
+	Tag    string
+}
+
+/***************
+ * VALIDATIONS *
+ ***************/
+
+func (f *filters) Validate() error {
+	f.Query = strings.TrimSpace(f.Query)
+	if f.Query == "" {
+		return errors.New("q is required")
+	}
+	if len(f.Query) > 200 {
+		return errors.New("q cannot be longer than 200 characters")
+	}
+	for _, t := range f.Types {
+		if _, ok := sourceByType(t); !ok {
+			return errors.New("unknown type: " + t)
+		}
+	}
+	if f.From != nil && f.To != nil && f.To.Before(*f.From) {
+		return errors.New("to must be after from")
+	}
+	return nil
+}
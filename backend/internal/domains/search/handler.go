@@ -0,0 +1,82 @@
+package search
+
+import (
+	"pokemon/pkg/utils"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	s searchService
+}
+
+func NewHandler(db *gorm.DB, redis *redis.Client) *handler {
+	repo := newSearchRepo(db)
+	serv := newSearchService(repo, redis)
+
+	return &handler{s: serv}
+}
+
+// GET /search?q=&type=post,guide&author=&from=&to=&tag=
+func (h *handler) search(c *fiber.Ctx) error {
+	f := filters{
+		Query:  c.Query("q"),
+		Author: strings.TrimSpace(c.Query("author")),
+		Tag:    strings.TrimSpace(c.Query("tag")),
+	}
+	for _, t := range strings.Split(c.Query("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			f.Types = append(f.Types, t)
+		}
+	}
+
+	var err error
+	if f.From, err = parseDate(c.Query("from"), false); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "from must be a date (2006-01-02) or RFC 3339 time")
+	}
+	if f.To, err = parseDate(c.Query("to"), true); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "to must be a date (2006-01-02) or RFC 3339 time")
+	}
+
+	if err := f.Validate(); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	limit, offset := utils.ParsePagination(c)
+	if limit > 50 {
+		limit = 50
+	}
+
+	page, err := h.s.search(c.Context(), f, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(page)
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// parseDate accepts a plain date or an RFC 3339 time. A plain end date covers
+// the whole day.
+func parseDate(value string, end bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, err
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
@@ -0,0 +1,85 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// searchConfig is the Postgres text search configuration used everywhere.
+const searchConfig = "english"
+
+type SearchMigrator struct{}
+
+// Migrate adds a generated search_vector column and its GIN index to every
+// searchable table, so Postgres keeps the vectors up to date on its own. It
+// must run after the domain migrators; tables that don't exist yet are skipped.
+//
+// A generated column can't be altered in place, so when the document of a
+// source changes the column is dropped and added again. The expression it was
+// generated from is kept as the column comment to tell when that's needed.
+func (m SearchMigrator) Migrate(db *gorm.DB) error {
+	for _, s := range sources {
+		if !db.Migrator().HasTable(s.table) {
+			continue
+		}
+
+		current, err := vectorDocument(db, s.table)
+		if err != nil {
+			return fmt.Errorf("search vector on %s: %w", s.table, err)
+		}
+		if current == nil || *current != s.document {
+			if err := addVector(db, s); err != nil {
+				return fmt.Errorf("search vector on %s: %w", s.table, err)
+			}
+		}
+
+		index := fmt.Sprintf(
+			"CREATE INDEX IF NOT EXISTS idx_%s_search_vector ON %s USING GIN (search_vector)",
+			s.table, s.table,
+		)
+		if err := db.Exec(index).Error; err != nil {
+			return fmt.Errorf("search index on %s: %w", s.table, err)
+		}
+	}
+	return nil
+}
+
+// vectorDocument returns the expression the search_vector column of table was
+// generated from, or nil when the column is missing or predates the comment.
+func vectorDocument(db *gorm.DB, table string) (*string, error) {
+	var columns []struct{ Document *string }
+	err := db.Raw(`SELECT col_description(attrelid, attnum) AS document FROM pg_attribute
+		WHERE attrelid = ?::regclass AND attname = 'search_vector' AND NOT attisdropped`, table).
+		Scan(&columns).Error
+	if err != nil || len(columns) == 0 {
+		return nil, err
+	}
+	return columns[0].Document, nil
+}
+
+// addVector replaces the search_vector column of the source, dropping its
+// index with it, and records the document it's generated from.
+func addVector(db *gorm.DB, s source) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		statements := []string{
+			fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS search_vector", s.table),
+			fmt.Sprintf(
+				"ALTER TABLE %s ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (%s) STORED",
+				s.table, s.document,
+			),
+			// COMMENT takes no bind parameters, so the literal is quoted here
+			fmt.Sprintf(
+				"COMMENT ON COLUMN %s.search_vector IS '%s'",
+				s.table, strings.ReplaceAll(s.document, "'", "''"),
+			),
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
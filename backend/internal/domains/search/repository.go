@@ -0,0 +1,133 @@
+package search
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Markers ts_headline puts around matches. They are swapped for <mark> tags
+// once the snippet is escaped, so the source text can never inject markup.
+const (
+	markOpen  = "⟪"
+	markClose = "⟫"
+)
+
+var headlineOptions = "StartSel=" + markOpen + ", StopSel=" + markClose +
+	`, MaxFragments=2, MaxWords=30, MinWords=10, FragmentDelimiter=" … "`
+
+type searchRepository interface {
+	search(ctx context.Context, f filters, limit, offset int) ([]Result, error)
+	facets(ctx context.Context, f filters) ([]Facet, error)
+}
+
+type searchRepoImpl struct {
+	db        *gorm.DB
+	available []source
+}
+
+// newSearchRepo only keeps the sources whose table has been migrated, so a
+// missing domain shrinks the search instead of breaking it.
+func newSearchRepo(db *gorm.DB) searchRepository {
+	repo := &searchRepoImpl{db: db}
+	for _, s := range sources {
+		if db.Migrator().HasColumn(s.table, "search_vector") {
+			repo.available = append(repo.available, s)
+		}
+	}
+	return repo
+}
+
+func (r *searchRepoImpl) search(ctx context.Context, f filters, limit, offset int) ([]Result, error) {
+	union, args := r.union(f, f.Types)
+	if union == "" {
+		return []Result{}, nil
+	}
+
+	query := `WITH q AS (SELECT websearch_to_tsquery('` + searchConfig + `', ?) AS query)
+		SELECT r.type, r.id, r.parent_id, r.title, r.author_id, r.created_at, r.rank,
+			ts_headline('` + searchConfig + `', r.body, q.query, ?) AS snippet
+		FROM (
+			SELECT * FROM (` + union + `) u
+			ORDER BY u.rank DESC, u.created_at DESC
+			LIMIT ? OFFSET ?
+		) r CROSS JOIN q
+		ORDER BY r.rank DESC, r.created_at DESC`
+
+	all := append([]any{f.Query, headlineOptions}, args...)
+	all = append(all, limit, offset)
+
+	results := []Result{}
+	err := r.db.WithContext(ctx).Raw(query, all...).Scan(&results).Error
+	return results, err
+}
+
+// facets counts the hits of every type, whatever types were asked for.
+func (r *searchRepoImpl) facets(ctx context.Context, f filters) ([]Facet, error) {
+	union, args := r.union(f, nil)
+	if union == "" {
+		return []Facet{}, nil
+	}
+
+	query := `WITH q AS (SELECT websearch_to_tsquery('` + searchConfig + `', ?) AS query)
+		SELECT u.type, COUNT(*) AS count FROM (` + union + `) u
+		GROUP BY u.type ORDER BY count DESC`
+
+	facets := []Facet{}
+	err := r.db.WithContext(ctx).Raw(query, append([]any{f.Query}, args...)...).Scan(&facets).Error
+	return facets, err
+}
+
+// union builds one SELECT per source, all returning the same columns. When
+// types is empty every available source is used.
+func (r *searchRepoImpl) union(f filters, types []string) (string, []any) {
+	var parts []string
+	var args []any
+
+	for _, s := range r.available {
+		if len(types) > 0 && !contains(types, s.typ) {
+			continue
+		}
+		if f.Tag != "" && s.tagged == "" {
+			continue
+		}
+
+		var where []string
+		where = append(where, "t.search_vector @@ q.query", s.visible)
+
+		if f.Author != "" {
+			where = append(where, s.authorID+" IN (SELECT id FROM users WHERE id::text = ? OR username = ?)")
+			args = append(args, f.Author, f.Author)
+		}
+		if f.From != nil {
+			where = append(where, "t.created_at >= ?")
+			args = append(args, *f.From)
+		}
+		if f.To != nil {
+			where = append(where, "t.created_at < ?")
+			args = append(args, *f.To)
+		}
+		if f.Tag != "" {
+			where = append(where, s.tagged)
+			for i := 0; i < strings.Count(s.tagged, "?"); i++ {
+				args = append(args, f.Tag)
+			}
+		}
+
+		parts = append(parts, "SELECT '"+s.typ+"' AS type, t.id AS id, "+s.parentID+" AS parent_id, "+
+			s.title+" AS title, "+s.body+" AS body, "+s.authorID+" AS author_id, t.created_at AS created_at, "+
+			"ts_rank_cd(t.search_vector, q.query) AS rank "+
+			"FROM "+s.from+" CROSS JOIN q WHERE "+strings.Join(where, " AND "))
+	}
+	return strings.Join(parts, " UNION ALL "), args
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,7 @@
+package search
+
+import "github.com/gofiber/fiber/v2"
+
+func (h *handler) RegisterRoutes(router fiber.Router) {
+	router.Get("/search", h.search)
+}
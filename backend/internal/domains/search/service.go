@@ -0,0 +1,100 @@
+package search
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type searchService interface {
+	search(ctx context.Context, f filters, limit, offset int) (*Page, error)
+}
+
+/********************
+ * REDIS KEY UTILS  *
+ ********************/
+
+// Popular queries are hammered by search-as-you-type, so pages are cached
+// briefly under a hash of the query and every filter.
+func redisSearchKey(f filters, limit, offset int) string {
+	raw, _ := json.Marshal(struct {
+		F      filters
+		Limit  int
+		Offset int
+	}{f, limit, offset})
+	sum := sha1.Sum(raw)
+	return fmt.Sprintf("search:%s", hex.EncodeToString(sum[:]))
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type searchServiceImpl struct {
+	db    searchRepository
+	cache *redis.Client
+}
+
+func newSearchService(repo searchRepository, cache *redis.Client) searchService {
+	return &searchServiceImpl{db: repo, cache: cache}
+}
+
+func (s *searchServiceImpl) search(ctx context.Context, f filters, limit, offset int) (*Page, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	cacheKey := redisSearchKey(f, limit, offset)
+	if val, err := s.cache.Get(ctx, cacheKey).Result(); err == nil {
+		var page Page
+		if err := json.Unmarshal([]byte(val), &page); err == nil {
+			return &page, nil
+		}
+	}
+
+	facets, err := s.db.facets(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	results, err := s.db.search(ctx, f, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{Items: results, Facets: facets}
+	for _, facet := range facets {
+		if len(f.Types) == 0 || contains(f.Types, facet.Type) {
+			page.Total += facet.Count
+		}
+	}
+	for i := range page.Items {
+		page.Items[i].Snippet = highlight(page.Items[i].Snippet)
+	}
+
+	bytes, _ := json.Marshal(page)
+	s.cache.Set(ctx, cacheKey, bytes, time.Minute)
+
+	return page, nil
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// highlight escapes the snippet and turns the ts_headline markers into <mark>.
+func highlight(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, markOpen, "<mark>")
+	return strings.ReplaceAll(escaped, markClose, "</mark>")
+}
@@ -0,0 +1,129 @@
+package search
+
+import "pokemon/pkg/publishing"
+
+// source describes how one table takes part in the search: the weighted
+// document stored in its search_vector column, and how a hit maps to a Result.
+// Visible holds the soft delete and visibility rules of the source.
+type source struct {
+	typ      string
+	table    string
+	document string // tsvector expression over the table's own columns
+
+	from     string // FROM clause, the searched table aliased as t
+	parentID string
+	title    string
+	body     string
+	authorID string
+	visible  string
+	tagged   string // condition where every ? is the tag; empty if the source has no tags
+}
+
+var sources = []source{
+	{
+		typ:      TypePost,
+		table:    "posts",
+		document: weighted("title", "A") + " || " + weighted("content", "B"),
+		from:     "posts t",
+		parentID: "NULL::uuid",
+		title:    "t.title",
+		body:     "t.content",
+		authorID: "t.user_id",
+		visible:  "t.deleted_at IS NULL AND " + publishing.LiveCondition("t"),
+		tagged: `EXISTS (SELECT 1 FROM post_tags r JOIN tags g ON g.id = r.tag_id
+			WHERE r.post_id = t.id AND (g.name = ? OR g.slug = ?))`,
+	},
+	{
+		typ:      TypeTopic,
+		table:    "topics",
+		document: weighted("title", "A") + " || " + weighted("content", "B"),
+		from:     "topics t",
+		parentID: "NULL::uuid",
+		title:    "t.title",
+		body:     "t.content",
+		authorID: "t.user_id",
+		visible:  "t.deleted_at IS NULL",
+	},
+	{
+		typ:      TypeTopicComment,
+		table:    "topic_comments",
+		document: weighted("content", "B"),
+		from:     "topic_comments t JOIN topics p ON p.id = t.topic_id",
+		parentID: "t.topic_id",
+		title:    "p.title",
+		body:     "t.content",
+		authorID: "t.user_id",
+		visible:  "t.deleted_at IS NULL AND p.deleted_at IS NULL",
+	},
+	{
+		typ:      TypeGuide,
+		table:    "game_guides",
+		document: weighted("title", "A") + " || " + weighted("summary", "B") + " || " + weighted("content", "C"),
+		from:     "game_guides t",
+		parentID: "NULL::uuid",
+		title:    "t.title",
+		body:     "t.content",
+		authorID: "t.author_id",
+		visible:  publishing.LiveCondition("t"),
+		tagged: `EXISTS (SELECT 1 FROM game_guide_tags_relation r JOIN game_guide_tags g ON g.id = r.game_guide_tag_id
+			WHERE r.game_guide_id = t.id AND (g.name = ? OR g.slug = ?))`,
+	},
+	{
+		typ:      TypeWalkthroughStep,
+		table:    "walkthrough_steps",
+		document: weighted("title", "A") + " || " + weighted("content", "B"),
+		from:     "walkthrough_steps t JOIN walkthroughs p ON p.id = t.walkthrough_id",
+		parentID: "t.walkthrough_id",
+		title:    "p.title || ' – ' || t.title",
+		body:     "t.content",
+		authorID: "p.user_id",
+		visible:  "TRUE",
+		tagged:   "(? = ANY(t.tags) OR ? = ANY(p.tags))",
+	},
+	{
+		typ:      TypeNews,
+		table:    "news",
+		document: weighted("title", "A") + " || " + weighted("sub_title", "B") + " || " + weighted("body", "C"),
+		from:     "news t",
+		parentID: "NULL::uuid",
+		title:    "t.title",
+		body:     "t.body",
+		authorID: "t.user_id",
+		visible:  "t.deleted_at IS NULL AND " + publishing.LiveCondition("t"),
+	},
+	{
+		typ:      TypeShout,
+		table:    "shouts",
+		document: weighted("content", "B"),
+		from:     "shouts t",
+		parentID: "NULL::uuid",
+		title:    "''",
+		body:     "t.content",
+		authorID: "t.user_id",
+		visible:  "t.deleted_at IS NULL AND NOT t.is_flagged",
+	},
+	{
+		typ:      TypeTeam,
+		table:    "teams",
+		document: weighted("name", "A") + " || " + weighted("description", "B"),
+		from:     "teams t",
+		parentID: "NULL::uuid",
+		title:    "t.name",
+		body:     "coalesce(t.description, '')",
+		authorID: "t.user_id",
+		visible:  "t.deleted_at IS NULL AND t.public",
+	},
+}
+
+func sourceByType(typ string) (source, bool) {
+	for _, s := range sources {
+		if s.typ == typ {
+			return s, true
+		}
+	}
+	return source{}, false
+}
+
+func weighted(column, weight string) string {
+	return "setweight(to_tsvector('" + searchConfig + "', coalesce(" + column + ", '')), '" + weight + "')"
+}
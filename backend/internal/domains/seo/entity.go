@@ -0,0 +1,76 @@
+package seo
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+/*************
+ * SITEMAPS *
+ *************/
+
+// shard is one sitemap file of a section.
+type shard struct {
+	Shard        int       `json:"shard"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// location is one URL of a sitemap.
+type location struct {
+	ID        uuid.UUID `json:"id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+/************
+ * METADATA *
+ ************/
+
+// page is what the metadata of a content page is built from.
+type page struct {
+	Kind      string         `json:"kind"`
+	ID        uuid.UUID      `json:"id"`
+	Title     string         `json:"title"`
+	Summary   string         `json:"summary"`
+	Body      string         `json:"body"`
+	Image     string         `json:"image"`
+	Game      string         `json:"game"`
+	AuthorID  uuid.UUID      `json:"author_id"`
+	Author    string         `json:"author"`
+	Tags      pq.StringArray `json:"tags" gorm:"type:text[]"`
+	Comments  int64          `json:"comments"`
+	Likes     int64          `json:"likes"`
+	Views     int64          `json:"views"`
+	Published time.Time      `json:"published"`
+	Updated   time.Time      `json:"updated"`
+	Steps     []howToStep    `json:"steps" gorm:"-"`
+}
+
+// howToStep is a walkthrough step as listed in HowTo data.
+type howToStep struct {
+	StepNumber int    `json:"step_number"`
+	Title      string `json:"title"`
+	Content    string `json:"content"`
+}
+
+/*************
+ * RESPONSES *
+ *************/
+
+// Metadata holds everything a page needs in its <head> to be shared and
+// indexed: OpenGraph and Twitter card tags, and schema.org JSON-LD.
+type Metadata struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Canonical   string         `json:"canonical"`
+	OpenGraph   []MetaTag      `json:"open_graph"` // <meta property="..." content="...">
+	Twitter     []MetaTag      `json:"twitter"`    // <meta name="..." content="...">
+	JSONLD      map[string]any `json:"json_ld"`    // <script type="application/ld+json">
+}
+
+// MetaTag is a <meta> element; OpenGraph tags may repeat, like article:tag.
+type MetaTag struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
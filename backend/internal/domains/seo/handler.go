@@ -0,0 +1,75 @@
+package seo
+
+import (
+	"errors"
+	"pokemon/pkg/content"
+	"pokemon/pkg/permalink"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	s       service
+	siteURL string
+}
+
+// NewHandler links to siteURL, or to the address the request came in on
+// when it is empty.
+func NewHandler(db *gorm.DB, redis *redis.Client, siteURL string) *handler {
+	repo := newRepo(db)
+	serv := newServ(repo, content.NewRenderer(redis), redis)
+	return &handler{s: serv, siteURL: siteURL}
+}
+
+// GET /sitemap.xml
+func (h *handler) sitemapIndex(c *fiber.Ctx) error {
+	body, err := h.s.index(c.Context(), permalink.Base(c, h.siteURL))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return sendXML(c, body)
+}
+
+// GET /sitemaps/:section-:page.xml
+func (h *handler) sitemap(c *fiber.Ctx) error {
+	number, err := c.ParamsInt("page")
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, errUnknownSitemap.Error())
+	}
+
+	body, err := h.s.sitemap(c.Context(), permalink.Base(c, h.siteURL), c.Params("section"), number)
+	if errors.Is(err, errUnknownSitemap) {
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return sendXML(c, body)
+}
+
+// GET /seo/metadata?url=
+func (h *handler) metadata(c *fiber.Ctx) error {
+	rawURL := c.Query("url")
+	if rawURL == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "url is required")
+	}
+
+	meta, err := h.s.metadata(c.Context(), permalink.Base(c, h.siteURL), rawURL)
+	switch {
+	case errors.Is(err, errNotContentURL):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "page not found")
+	case err != nil:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(meta)
+}
+
+func sendXML(c *fiber.Ctx, body []byte) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationXMLCharsetUTF8)
+	c.Set(fiber.HeaderCacheControl, "public, max-age=3600")
+	return c.Send(body)
+}
@@ -0,0 +1,174 @@
+package seo
+
+import (
+	"context"
+	"fmt"
+	"pokemon/pkg/permalink"
+	"strings"
+	"time"
+)
+
+// descriptionLength keeps descriptions within what search results show.
+const descriptionLength = 160
+
+// build describes the page for crawlers and link previews.
+func (s *serviceImpl) build(ctx context.Context, base string, p *page) *Metadata {
+	canonical := pageURL(base, p)
+	description := strings.TrimSpace(p.Summary)
+	if description == "" && p.Body != "" {
+		description = s.renderer.Render(ctx, p.Body).Excerpt(descriptionLength)
+	}
+	if description == "" && len(p.Steps) > 0 {
+		description = fmt.Sprintf("A %d-step walkthrough of %s by %s.", len(p.Steps), p.Game, p.Author)
+	}
+
+	meta := &Metadata{
+		Title:       p.Title + " – " + permalink.SiteName,
+		Description: description,
+		Canonical:   canonical,
+	}
+
+	ogType := "article"
+	if p.Kind == permalink.KindTeam {
+		ogType = "website"
+	}
+	meta.OpenGraph = []MetaTag{
+		{Name: "og:site_name", Content: permalink.SiteName},
+		{Name: "og:type", Content: ogType},
+		{Name: "og:title", Content: p.Title},
+		{Name: "og:description", Content: description},
+		{Name: "og:url", Content: canonical},
+	}
+	if p.Image != "" {
+		meta.OpenGraph = append(meta.OpenGraph, MetaTag{Name: "og:image", Content: p.Image})
+	}
+	if ogType == "article" {
+		meta.OpenGraph = append(meta.OpenGraph,
+			MetaTag{Name: "article:published_time", Content: isoTime(p.Published)},
+			MetaTag{Name: "article:modified_time", Content: isoTime(p.Updated)},
+			MetaTag{Name: "article:author", Content: permalink.User(base, p.AuthorID)},
+		)
+		for _, tag := range p.Tags {
+			meta.OpenGraph = append(meta.OpenGraph, MetaTag{Name: "article:tag", Content: tag})
+		}
+	}
+
+	card := "summary"
+	if p.Image != "" {
+		card = "summary_large_image"
+	}
+	meta.Twitter = []MetaTag{
+		{Name: "twitter:card", Content: card},
+		{Name: "twitter:title", Content: p.Title},
+		{Name: "twitter:description", Content: description},
+	}
+	if p.Image != "" {
+		meta.Twitter = append(meta.Twitter, MetaTag{Name: "twitter:image", Content: p.Image})
+	}
+
+	meta.JSONLD = s.structuredData(ctx, base, p, canonical, description)
+	return meta
+}
+
+// structuredData is the schema.org description of the page: an Article for
+// posts, news and guides, a HowTo for walkthroughs and a
+// DiscussionForumPosting for forum topics.
+func (s *serviceImpl) structuredData(ctx context.Context, base string, p *page, canonical, description string) map[string]any {
+	author := map[string]any{
+		"@type": "Person",
+		"name":  p.Author,
+		"url":   permalink.User(base, p.AuthorID),
+	}
+	data := map[string]any{
+		"@context":      "https://schema.org",
+		"url":           canonical,
+		"description":   description,
+		"author":        author,
+		"datePublished": isoTime(p.Published),
+		"dateModified":  isoTime(p.Updated),
+	}
+	if len(p.Tags) > 0 {
+		data["keywords"] = strings.Join(p.Tags, ", ")
+	}
+
+	switch p.Kind {
+	case permalink.KindPost, permalink.KindNews, permalink.KindGuide:
+		data["@type"] = "Article"
+		data["headline"] = p.Title
+		data["mainEntityOfPage"] = canonical
+		data["publisher"] = map[string]any{"@type": "Organization", "name": permalink.SiteName, "url": base}
+		data["wordCount"] = s.renderer.Render(ctx, p.Body).WordCount
+		if p.Image != "" {
+			data["image"] = p.Image
+		}
+
+	case permalink.KindWalkthrough:
+		data["@type"] = "HowTo"
+		data["name"] = p.Title
+		if p.Game != "" {
+			data["about"] = map[string]any{"@type": "VideoGame", "name": p.Game}
+		}
+		sources := make([]string, len(p.Steps))
+		for i, step := range p.Steps {
+			sources[i] = step.Content
+		}
+		renders := s.renderer.RenderAll(ctx, sources)
+		steps := make([]map[string]any, 0, len(p.Steps))
+		for i, step := range p.Steps {
+			steps = append(steps, map[string]any{
+				"@type":    "HowToStep",
+				"position": step.StepNumber,
+				"name":     step.Title,
+				"text":     renders[i].Excerpt(500),
+				"url":      fmt.Sprintf("%s#step-%d", canonical, step.StepNumber),
+			})
+		}
+		data["step"] = steps
+
+	case permalink.KindTopic:
+		data["@type"] = "DiscussionForumPosting"
+		data["headline"] = p.Title
+		data["text"] = s.renderer.Render(ctx, p.Body).Excerpt(500)
+		data["commentCount"] = p.Comments
+		data["interactionStatistic"] = []map[string]any{
+			interaction("CommentAction", p.Comments),
+			interaction("LikeAction", p.Likes),
+			interaction("ViewAction", p.Views),
+		}
+
+	default:
+		data["@type"] = "CreativeWork"
+		data["name"] = p.Title
+	}
+	return data
+}
+
+func interaction(action string, count int64) map[string]any {
+	return map[string]any{
+		"@type":                "InteractionCounter",
+		"interactionType":      "https://schema.org/" + action,
+		"userInteractionCount": count,
+	}
+}
+
+func pageURL(base string, p *page) string {
+	switch p.Kind {
+	case permalink.KindPost:
+		return permalink.Post(base, p.ID)
+	case permalink.KindNews:
+		return permalink.News(base, p.ID)
+	case permalink.KindGuide:
+		return permalink.Guide(base, p.ID)
+	case permalink.KindWalkthrough:
+		return permalink.Walkthrough(base, p.ID)
+	case permalink.KindTopic:
+		return permalink.Topic(base, p.ID)
+	case permalink.KindTeam:
+		return permalink.Team(base, p.ID)
+	}
+	return base
+}
+
+func isoTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,118 @@
+package seo
+
+import (
+	"context"
+	"database/sql"
+	"pokemon/pkg/permalink"
+	"pokemon/pkg/publishing"
+
+	"gorm.io/gorm"
+)
+
+type repository interface {
+	shards(ctx context.Context, sec section) ([]shard, error)
+	locations(ctx context.Context, sec section, shard int) ([]location, error)
+	page(ctx context.Context, kind, ref string) (*page, error)
+}
+
+type repositoryImpl struct {
+	db *gorm.DB
+}
+
+func newRepo(db *gorm.DB) repository {
+	return &repositoryImpl{db: db}
+}
+
+// Rows are sharded in creation order, so new content fills the last shard
+// and the lastmod of older ones only moves when their own rows change.
+const shardOrder = "t.created_at, t.id"
+
+func (r *repositoryImpl) shards(ctx context.Context, sec section) ([]shard, error) {
+	var shards []shard
+	err := r.db.WithContext(ctx).
+		Raw(`SELECT s.shard, MAX(s.updated_at) AS last_modified
+			FROM (SELECT (ROW_NUMBER() OVER (ORDER BY `+shardOrder+`) - 1) / ? AS shard, t.updated_at
+				FROM `+sec.from+` WHERE `+sec.visible+`) s
+			GROUP BY s.shard ORDER BY s.shard`, shardSize).
+		Scan(&shards).Error
+	return shards, err
+}
+
+func (r *repositoryImpl) locations(ctx context.Context, sec section, shard int) ([]location, error) {
+	var locations []location
+	err := r.db.WithContext(ctx).
+		Raw(`SELECT t.id, t.updated_at FROM `+sec.from+` WHERE `+sec.visible+`
+			ORDER BY `+shardOrder+` LIMIT ? OFFSET ?`, shardSize, shard*shardSize).
+		Scan(&locations).Error
+	return locations, err
+}
+
+// pageQueries select a live page by ID; guides match their slug too.
+var pageQueries = map[string]string{
+	permalink.KindPost: `SELECT 'post' AS kind, t.id, t.title, '' AS summary, t.content AS body,
+			t.user_id AS author_id, u.username AS author,
+			ARRAY(SELECT g.name FROM post_tags r JOIN tags g ON g.id = r.tag_id WHERE r.post_id = t.id ORDER BY g.name) AS tags,
+			COALESCE(t.published_at, t.created_at) AS published, t.updated_at AS updated
+		FROM posts t JOIN users u ON u.id = t.user_id
+		WHERE t.id::text = @ref AND t.deleted_at IS NULL AND ` + publishing.LiveCondition("t"),
+
+	permalink.KindNews: `SELECT 'news' AS kind, t.id, t.title, t.sub_title AS summary, t.body,
+			t.user_id AS author_id, u.username AS author,
+			COALESCE(t.published_at, t.created_at) AS published, t.updated_at AS updated
+		FROM news t JOIN users u ON u.id = t.user_id
+		WHERE t.id::text = @ref AND t.deleted_at IS NULL AND ` + publishing.LiveCondition("t"),
+
+	permalink.KindGuide: `SELECT 'guide' AS kind, t.id, t.title, t.summary, t.content AS body, t.cover_image_url AS image,
+			t.author_id, u.username AS author,
+			ARRAY(SELECT g.name FROM game_guide_tags_relation r JOIN game_guide_tags g ON g.id = r.game_guide_tag_id
+				WHERE r.game_guide_id = t.id ORDER BY g.name) AS tags,
+			COALESCE(t.published_at, t.created_at) AS published, t.updated_at AS updated
+		FROM game_guides t JOIN users u ON u.id = t.author_id
+		WHERE (t.id::text = @ref OR t.slug = @ref) AND ` + publishing.LiveCondition("t"),
+
+	permalink.KindWalkthrough: `SELECT 'walkthrough' AS kind, t.id, t.title, '' AS summary, '' AS body, t.game,
+			t.user_id AS author_id, u.username AS author, t.tags, t.created_at AS published, t.updated_at AS updated
+		FROM walkthroughs t JOIN users u ON u.id = t.user_id
+		WHERE t.id::text = @ref`,
+
+	permalink.KindTopic: `SELECT 'topic' AS kind, t.id, t.title, '' AS summary, t.content AS body,
+			t.user_id AS author_id, u.username AS author,
+			t.comment_count AS comments, t.like_count AS likes, t.view_count AS views,
+			t.created_at AS published, t.updated_at AS updated
+		FROM topics t JOIN users u ON u.id = t.user_id
+		WHERE t.id::text = @ref AND t.deleted_at IS NULL`,
+
+	permalink.KindTeam: `SELECT 'team' AS kind, t.id, t.name AS title, COALESCE(t.description, '') AS summary, '' AS body,
+			t.user_id AS author_id, u.username AS author, t.created_at AS published, t.updated_at AS updated
+		FROM teams t JOIN users u ON u.id = t.user_id
+		WHERE t.id::text = @ref AND t.deleted_at IS NULL AND t.public`,
+}
+
+func (r *repositoryImpl) page(ctx context.Context, kind, ref string) (*page, error) {
+	query, ok := pageQueries[kind]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var p page
+	result := r.db.WithContext(ctx).Raw(query, sql.Named("ref", ref)).Scan(&p)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if kind == permalink.KindWalkthrough {
+		err := r.db.WithContext(ctx).
+			Table("walkthrough_steps").
+			Select("step_number, title, content").
+			Where("walkthrough_id = ?", p.ID).
+			Order("step_number ASC").
+			Scan(&p.Steps).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &p, nil
+}
@@ -0,0 +1,15 @@
+package seo
+
+import "github.com/gofiber/fiber/v2"
+
+func (h *handler) RegisterRoutes(app fiber.Router) {
+	app.Get("/seo/metadata", h.metadata)
+}
+
+// RegisterSitemapRoutes serves the sitemaps at the root of the site, where
+// crawlers look for them; the site's proxy forwards /sitemap.xml and
+// /sitemaps/ here.
+func (h *handler) RegisterSitemapRoutes(app fiber.Router) {
+	app.Get("/sitemap.xml", h.sitemapIndex)
+	app.Get("/sitemaps/:section-:page.xml", h.sitemap)
+}
@@ -0,0 +1,143 @@
+package seo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"pokemon/pkg/content"
+	"pokemon/pkg/permalink"
+	"pokemon/pkg/syndication"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	sitemapCacheTTL = time.Hour
+	pageCacheTTL    = 15 * time.Minute
+)
+
+var (
+	errUnknownSitemap = errors.New("sitemap not found")
+	errNotContentURL  = errors.New("url is not a content page")
+)
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type service interface {
+	index(ctx context.Context, base string) ([]byte, error)
+	sitemap(ctx context.Context, base, name string, number int) ([]byte, error)
+	metadata(ctx context.Context, base, rawURL string) (*Metadata, error)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type serviceImpl struct {
+	repo     repository
+	renderer content.Renderer
+	redis    *redis.Client
+}
+
+func newServ(repo repository, renderer content.Renderer, redis *redis.Client) service {
+	return &serviceImpl{repo: repo, renderer: renderer, redis: redis}
+}
+
+// index lists one sitemap per shard of every section.
+func (s *serviceImpl) index(ctx context.Context, base string) ([]byte, error) {
+	doc := sitemapIndex{XMLNS: sitemapNamespace, Sitemaps: []sitemapEntry{}}
+	for _, sec := range sections {
+		var shards []shard
+		err := s.cached(ctx, "sitemap:shards:"+sec.name, sitemapCacheTTL, &shards, func() (any, error) {
+			return s.repo.shards(ctx, sec)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sh := range shards {
+			doc.Sitemaps = append(doc.Sitemaps, sitemapEntry{
+				Loc:     sitemapURL(base, sec.name, sh.Shard+1),
+				LastMod: lastMod(sh.LastModified),
+			})
+		}
+	}
+	return syndication.MarshalXML(doc)
+}
+
+// sitemap lists the URLs of one shard; numbers start at 1.
+func (s *serviceImpl) sitemap(ctx context.Context, base, name string, number int) ([]byte, error) {
+	sec, ok := sectionByName(name)
+	if !ok || number < 1 {
+		return nil, errUnknownSitemap
+	}
+
+	var locations []location
+	key := fmt.Sprintf("sitemap:%s:%d", sec.name, number)
+	err := s.cached(ctx, key, sitemapCacheTTL, &locations, func() (any, error) {
+		return s.repo.locations(ctx, sec, number-1)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(locations) == 0 && number > 1 {
+		return nil, errUnknownSitemap
+	}
+
+	doc := urlSet{XMLNS: sitemapNamespace, URLs: make([]sitemapEntry, 0, len(locations))}
+	for _, loc := range locations {
+		doc.URLs = append(doc.URLs, sitemapEntry{
+			Loc:     sec.link(base, loc.ID),
+			LastMod: lastMod(loc.UpdatedAt),
+		})
+	}
+	return syndication.MarshalXML(doc)
+}
+
+func (s *serviceImpl) metadata(ctx context.Context, base, rawURL string) (*Metadata, error) {
+	kind, ref, ok := permalink.Parse(rawURL)
+	if !ok {
+		return nil, errNotContentURL
+	}
+
+	var p page
+	err := s.cached(ctx, "seo:page:"+kind+":"+ref, pageCacheTTL, &p, func() (any, error) {
+		return s.repo.page(ctx, kind, ref)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.build(ctx, base, &p), nil
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// cached reads key into dest, loading and caching it on a miss.
+func (s *serviceImpl) cached(ctx context.Context, key string, ttl time.Duration, dest any, load func() (any, error)) error {
+	if data, err := s.redis.Get(ctx, key).Bytes(); err == nil {
+		if json.Unmarshal(data, dest) == nil {
+			return nil
+		}
+	}
+
+	value, err := load()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.redis.Set(ctx, key, data, ttl)
+	return json.Unmarshal(data, dest)
+}
+
+func sitemapURL(base, section string, number int) string {
+	return fmt.Sprintf("%s/sitemaps/%s-%d.xml", base, section, number)
+}
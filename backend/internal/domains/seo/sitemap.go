@@ -0,0 +1,102 @@
+package seo
+
+import (
+	"encoding/xml"
+	"pokemon/pkg/permalink"
+	"pokemon/pkg/publishing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// shardSize is the number of URLs per sitemap file, well below the 50,000
+// the protocol allows.
+const shardSize = 10000
+
+// section is one kind of content listed in the sitemaps, like a search
+// source: its rows in from are aliased as t and filtered by visible.
+type section struct {
+	name    string
+	from    string
+	visible string
+	link    func(base string, id uuid.UUID) string
+}
+
+// Section names end up in file names (posts-1.xml), so they hold no hyphens.
+var sections = []section{
+	{
+		name:    "posts",
+		from:    "posts t",
+		visible: "t.deleted_at IS NULL AND " + publishing.LiveCondition("t"),
+		link:    permalink.Post,
+	},
+	{
+		name:    "guides",
+		from:    "game_guides t",
+		visible: publishing.LiveCondition("t"),
+		link:    permalink.Guide,
+	},
+	{
+		name:    "walkthroughs",
+		from:    "walkthroughs t",
+		visible: "TRUE",
+		link:    permalink.Walkthrough,
+	},
+	{
+		name:    "news",
+		from:    "news t",
+		visible: "t.deleted_at IS NULL AND " + publishing.LiveCondition("t"),
+		link:    permalink.News,
+	},
+	{
+		name:    "teams",
+		from:    "teams t",
+		visible: "t.deleted_at IS NULL AND t.public",
+		link:    permalink.Team,
+	},
+	{
+		name:    "topics",
+		from:    "topics t",
+		visible: "t.deleted_at IS NULL",
+		link:    permalink.Topic,
+	},
+}
+
+func sectionByName(name string) (section, bool) {
+	for _, s := range sections {
+		if s.name == name {
+			return s, true
+		}
+	}
+	return section{}, false
+}
+
+/*******
+ * XML *
+ *******/
+
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+type sitemapIndex struct {
+	XMLName  xml.Name       `xml:"sitemapindex"`
+	XMLNS    string         `xml:"xmlns,attr"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type urlSet struct {
+	XMLName xml.Name       `xml:"urlset"`
+	XMLNS   string         `xml:"xmlns,attr"`
+	URLs    []sitemapEntry `xml:"url"`
+}
+
+func lastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,160 @@
+package series
+
+import (
+	"errors"
+	"pokemon/internal/domains/user"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Kinds of content a series can hold, mixed in any order.
+const (
+	ContentGuide       = "guide"
+	ContentPost        = "post"
+	ContentNews        = "news"
+	ContentWalkthrough = "walkthrough"
+)
+
+/********
+ * MAIN *
+ ********/
+
+// Series is an ordered collection of guides, posts, news and walkthroughs,
+// like a guide split in parts. Its owner curates it, alone or with
+// collaborators who may add, remove and reorder items.
+type Series struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OwnerID     uuid.UUID `json:"owner_id" gorm:"type:uuid;not null;index"`
+	Owner       user.User `json:"owner" gorm:"foreignKey:OwnerID"`
+	Title       string    `json:"title" gorm:"type:varchar(255);not null"`
+	Description string    `json:"description" gorm:"type:text"`
+
+	Items         []SeriesItem         `json:"items" gorm:"foreignKey:SeriesID;constraint:OnDelete:CASCADE"`
+	Collaborators []SeriesCollaborator `json:"collaborators" gorm:"foreignKey:SeriesID;constraint:OnDelete:CASCADE"`
+	Progress      *Progress            `json:"progress,omitempty" gorm:"-"` // logged-in readers only
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// SeriesItem points at one piece of content, which stays in its own domain.
+type SeriesItem struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	SeriesID    uuid.UUID `json:"series_id" gorm:"type:uuid;not null;uniqueIndex:idx_series_item_content"`
+	Position    int       `json:"position" gorm:"not null"`
+	ContentType string    `json:"content_type" gorm:"type:varchar(20);not null;uniqueIndex:idx_series_item_content"`
+	ContentID   uuid.UUID `json:"content_id" gorm:"type:uuid;not null;uniqueIndex:idx_series_item_content;index"`
+	AddedBy     uuid.UUID `json:"added_by" gorm:"type:uuid;not null"`
+
+	Title    string    `json:"title" gorm:"-"`          // title of the content
+	Live     bool      `json:"live" gorm:"-"`           // false for drafts, shown to their author only
+	Read     bool      `json:"read,omitempty" gorm:"-"` // read by the current reader
+	AuthorID uuid.UUID `json:"-" gorm:"-"`              // author of the content
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SeriesCollaborator struct {
+	SeriesID  uuid.UUID `json:"series_id" gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey;index"`
+	User      user.User `json:"user" gorm:"foreignKey:UserID"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SeriesRead records that a reader got to an item of a series.
+type SeriesRead struct {
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;primaryKey"`
+	ItemID    uuid.UUID  `json:"item_id" gorm:"type:uuid;primaryKey"`
+	Item      SeriesItem `json:"-" gorm:"foreignKey:ItemID;constraint:OnDelete:CASCADE"`
+	SeriesID  uuid.UUID  `json:"series_id" gorm:"type:uuid;not null;index"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+/*************
+ * RESPONSES *
+ *************/
+
+// Progress counts the live items of a series a reader has read.
+type Progress struct {
+	Read    int     `json:"read"`
+	Total   int     `json:"total"`
+	Percent float64 `json:"percent"`
+}
+
+// Navigation places a piece of content in one of the series it belongs to.
+// Content responses carry one per series; readers mark the item read with
+// POST /series/:series_id/items/:item_id/read.
+type Navigation struct {
+	SeriesID uuid.UUID `json:"series_id"`
+	ItemID   uuid.UUID `json:"item_id"`
+	Title    string    `json:"title"`
+	Position int       `json:"position"` // 1-based, among the items the viewer sees
+	Total    int       `json:"total"`
+	Previous *ItemRef  `json:"previous,omitempty"`
+	Next     *ItemRef  `json:"next,omitempty"`
+	Progress *Progress `json:"progress,omitempty"`
+}
+
+type ItemRef struct {
+	ContentType string    `json:"content_type"`
+	ContentID   uuid.UUID `json:"content_id"`
+	Title       string    `json:"title"`
+}
+
+/************
+ * REQUESTS *
+ ************/
+
+type addItemRequest struct {
+	ContentType string    `json:"content_type"`
+	ContentID   uuid.UUID `json:"content_id"`
+	Position    int       `json:"position"` // 1-based; 0 appends
+}
+
+type reorderItemsRequest struct {
+	ItemIDs []uuid.UUID `json:"item_ids"`
+}
+
+type collaboratorRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+/***************
+ * VALIDATIONS *
+ ***************/
+
+func (s *Series) Validate() error {
+	s.Title = strings.TrimSpace(s.Title)
+	s.Description = strings.TrimSpace(s.Description)
+
+	if s.OwnerID == uuid.Nil {
+		return errors.New("owner_id is required")
+	}
+	if s.Title == "" {
+		return errors.New("title is required")
+	}
+	if len(s.Title) > 255 {
+		return errors.New("title cannot be longer than 255 characters")
+	}
+	if len(s.Description) > 5000 {
+		return errors.New("description cannot be longer than 5000 characters")
+	}
+	return nil
+}
+
+func (r *addItemRequest) Validate() error {
+	if _, ok := contentSources[r.ContentType]; !ok {
+		return errors.New("content_type must be guide, post, news or walkthrough")
+	}
+	if r.ContentID == uuid.Nil {
+		return errors.New("content_id is required")
+	}
+	if r.Position < 0 {
+		return errors.New("position cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,329 @@
+package series
+
+import (
+	"context"
+	"errors"
+	"pokemon/internal/domains/user"
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	s service
+}
+
+func NewHandler(db *gorm.DB, redis *redis.Client) *handler {
+	return &handler{s: newServ(newRepo(db))}
+}
+
+// POST /series
+func (h *handler) create(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	var series Series
+	if err := c.BodyParser(&series); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+	series.ID, series.OwnerID = uuid.Nil, userID
+
+	if err := h.s.create(c.Context(), &series); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(series)
+}
+
+// GET /series
+func (h *handler) list(c *fiber.Ctx) error {
+	limit, offset := utils.ParsePagination(c)
+	list, total, err := h.s.list(c.Context(), nil, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{"total": total, "items": list})
+}
+
+// GET /series/user/:user_id
+func (h *handler) listByOwner(c *fiber.Ctx) error {
+	ownerID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user id")
+	}
+
+	limit, offset := utils.ParsePagination(c)
+	list, total, err := h.s.list(c.Context(), &ownerID, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{"total": total, "items": list})
+}
+
+// GET /series/:id
+func (h *handler) get(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	viewerID, _ := utils.GetUserIDFromLocals(c)
+	series, err := h.s.get(c.Context(), id, viewerID)
+	if err != nil {
+		return seriesError(err)
+	}
+	return c.JSON(series)
+}
+
+// PUT /series/:id
+func (h *handler) update(c *fiber.Ctx) error {
+	series, err := h.owned(c)
+	if err != nil {
+		return err
+	}
+
+	var req Series
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+	series.Title, series.Description = req.Title, req.Description
+
+	if err := h.s.update(c.Context(), series); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.JSON(series)
+}
+
+// DELETE /series/:id
+func (h *handler) delete(c *fiber.Ctx) error {
+	series, err := h.owned(c)
+	if err != nil {
+		return err
+	}
+	if err := h.s.delete(c.Context(), series.ID); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// POST /series/:id/collaborators
+func (h *handler) addCollaborator(c *fiber.Ctx) error {
+	series, err := h.owned(c)
+	if err != nil {
+		return err
+	}
+
+	var req collaboratorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+	if req.UserID == series.OwnerID {
+		return fiber.NewError(fiber.StatusBadRequest, "the owner already curates the series")
+	}
+
+	if err := h.s.addCollaborator(c.Context(), series.ID, req.UserID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// DELETE /series/:id/collaborators/:user_id
+// The owner removes collaborators; collaborators may leave on their own.
+func (h *handler) removeCollaborator(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+	series, err := h.find(c)
+	if err != nil {
+		return err
+	}
+	collaboratorID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user id")
+	}
+	if collaboratorID != userID && !h.isOwner(c, series, userID) {
+		return fiber.ErrForbidden
+	}
+
+	if err := h.s.removeCollaborator(c.Context(), series.ID, collaboratorID); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// POST /series/:id/items
+func (h *handler) addItem(c *fiber.Ctx) error {
+	series, userID, err := h.curated(c)
+	if err != nil {
+		return err
+	}
+
+	var req addItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+
+	item, err := h.s.addItem(c.Context(), series.ID, userID, req)
+	if err != nil {
+		return seriesError(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(item)
+}
+
+// DELETE /series/:id/items/:item_id
+func (h *handler) removeItem(c *fiber.Ctx) error {
+	series, _, err := h.curated(c)
+	if err != nil {
+		return err
+	}
+	itemID, err := uuid.Parse(c.Params("item_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid item id")
+	}
+
+	if err := h.s.removeItem(c.Context(), series.ID, itemID); err != nil {
+		return seriesError(err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// PUT /series/:id/items/order
+func (h *handler) reorderItems(c *fiber.Ctx) error {
+	series, userID, err := h.curated(c)
+	if err != nil {
+		return err
+	}
+
+	var req reorderItemsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.s.reorderItems(c.Context(), series.ID, userID, req.ItemIDs); err != nil {
+		return seriesError(err)
+	}
+
+	updated, err := h.s.get(c.Context(), series.ID, userID)
+	if err != nil {
+		return seriesError(err)
+	}
+	return c.JSON(updated)
+}
+
+// POST /series/:id/items/:item_id/read
+func (h *handler) markRead(c *fiber.Ctx) error {
+	return h.read(c, h.s.markRead)
+}
+
+// DELETE /series/:id/items/:item_id/read
+func (h *handler) unmarkRead(c *fiber.Ctx) error {
+	return h.read(c, h.s.unmarkRead)
+}
+
+type readFunc func(ctx context.Context, seriesID, itemID, userID uuid.UUID) error
+
+func (h *handler) read(c *fiber.Ctx, apply readFunc) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+	seriesID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+	itemID, err := uuid.Parse(c.Params("item_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid item id")
+	}
+
+	if err := apply(c.Context(), seriesID, itemID, userID); err != nil {
+		return seriesError(err)
+	}
+
+	series, err := h.s.get(c.Context(), seriesID, userID)
+	if err != nil {
+		return seriesError(err)
+	}
+	return c.JSON(series.Progress)
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func (h *handler) find(c *fiber.Ctx) (*Series, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+	series, err := h.s.getByID(c.Context(), id)
+	if err != nil {
+		return nil, seriesError(err)
+	}
+	return series, nil
+}
+
+// owned loads the series for a change only its owner (or a moderator) may make.
+func (h *handler) owned(c *fiber.Ctx) (*Series, error) {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return nil, fiber.ErrUnauthorized
+	}
+	series, err := h.find(c)
+	if err != nil {
+		return nil, err
+	}
+	if !h.isOwner(c, series, userID) {
+		return nil, fiber.ErrForbidden
+	}
+	return series, nil
+}
+
+// curated loads the series for a change to its items.
+func (h *handler) curated(c *fiber.Ctx) (*Series, uuid.UUID, error) {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return nil, uuid.Nil, fiber.ErrUnauthorized
+	}
+	series, err := h.find(c)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+	ok, err := h.canCurate(c, series, userID)
+	if err != nil {
+		return nil, uuid.Nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	if !ok {
+		return nil, uuid.Nil, fiber.ErrForbidden
+	}
+	return series, userID, nil
+}
+
+func (h *handler) isOwner(c *fiber.Ctx, series *Series, userID uuid.UUID) bool {
+	return series.OwnerID == userID || utils.HasRole(c, user.RoleModerator, user.RoleAdmin)
+}
+
+func (h *handler) canCurate(c *fiber.Ctx, series *Series, userID uuid.UUID) (bool, error) {
+	if h.isOwner(c, series, userID) {
+		return true, nil
+	}
+	return h.s.isCurator(c.Context(), series, userID)
+}
+
+func seriesError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "series or item not found")
+	case errors.Is(err, errContentNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, errItemExists):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, errInvalidItemOrder):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return fiber.NewError(fiber.StatusBadRequest, err.Error())
+}
@@ -0,0 +1,14 @@
+package series
+
+import "gorm.io/gorm"
+
+type SeriesMigrator struct{}
+
+func (m SeriesMigrator) Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&Series{},
+		&SeriesItem{},
+		&SeriesCollaborator{},
+		&SeriesRead{},
+	)
+}
@@ -0,0 +1,31 @@
+package series
+
+import (
+	"context"
+	"log"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Navigator lets other domains place their content in its series, for the
+// previous/next links of their responses.
+type Navigator struct {
+	s service
+}
+
+func NewNavigator(db *gorm.DB) *Navigator {
+	return &Navigator{s: newServ(newRepo(db))}
+}
+
+// Around returns the navigation of the content in every series holding it,
+// with the progress of logged-in viewers (uuid.Nil for anonymous ones).
+// Navigation is an extra: failures are logged and leave it out.
+func (n *Navigator) Around(ctx context.Context, contentType string, contentID, viewerID uuid.UUID) []Navigation {
+	navigation, err := n.s.navigation(ctx, contentType, contentID, viewerID)
+	if err != nil {
+		log.Printf("series navigation failed for %s %s: %v", contentType, contentID, err)
+		return nil
+	}
+	return navigation
+}
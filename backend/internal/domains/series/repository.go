@@ -0,0 +1,350 @@
+package series
+
+import (
+	"context"
+	"pokemon/pkg/publishing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// contentSource tells where the content of a type lives. Its rows in from are
+// aliased as t; exists leaves out deleted rows and live unpublished ones.
+type contentSource struct {
+	from   string
+	exists string
+	live   string
+	author string
+}
+
+var contentSources = map[string]contentSource{
+	ContentGuide: {
+		from:   "game_guides t",
+		exists: "TRUE",
+		live:   publishing.LiveCondition("t"),
+		author: "t.author_id",
+	},
+	ContentPost: {
+		from:   "posts t",
+		exists: "t.deleted_at IS NULL",
+		live:   publishing.LiveCondition("t"),
+		author: "t.user_id",
+	},
+	ContentNews: {
+		from:   "news t",
+		exists: "t.deleted_at IS NULL",
+		live:   publishing.LiveCondition("t"),
+		author: "t.user_id",
+	},
+	ContentWalkthrough: {
+		from:   "walkthroughs t",
+		exists: "TRUE",
+		live:   "TRUE",
+		author: "t.user_id",
+	},
+}
+
+type repository interface {
+	create(ctx context.Context, s *Series) error
+	getByID(ctx context.Context, id uuid.UUID) (*Series, error)
+	list(ctx context.Context, ownerID *uuid.UUID, limit, offset int) ([]Series, int64, error)
+	update(ctx context.Context, s *Series) error
+	delete(ctx context.Context, id uuid.UUID) error
+	containing(ctx context.Context, contentType string, contentID uuid.UUID) ([]Series, error)
+
+	isCollaborator(ctx context.Context, seriesID, userID uuid.UUID) (bool, error)
+	addCollaborator(ctx context.Context, collaborator *SeriesCollaborator) error
+	removeCollaborator(ctx context.Context, seriesID, userID uuid.UUID) error
+
+	addItem(ctx context.Context, item *SeriesItem) error
+	removeItem(ctx context.Context, seriesID, itemID uuid.UUID) error
+	reorderItems(ctx context.Context, seriesID uuid.UUID, itemIDs []uuid.UUID) error
+	getItem(ctx context.Context, seriesID, itemID uuid.UUID) (*SeriesItem, error)
+
+	resolve(ctx context.Context, items []SeriesItem) error
+
+	markRead(ctx context.Context, read *SeriesRead) error
+	unmarkRead(ctx context.Context, userID, itemID uuid.UUID) error
+	readItems(ctx context.Context, userID uuid.UUID, seriesIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+}
+
+type repositoryImpl struct {
+	db *gorm.DB
+}
+
+func newRepo(db *gorm.DB) repository {
+	return &repositoryImpl{db: db}
+}
+
+func (r *repositoryImpl) create(ctx context.Context, s *Series) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(s).Error
+}
+
+func (r *repositoryImpl) getByID(ctx context.Context, id uuid.UUID) (*Series, error) {
+	var s Series
+	err := r.db.WithContext(ctx).
+		Preload("Owner").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Preload("Collaborators.User").
+		First(&s, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *repositoryImpl) list(ctx context.Context, ownerID *uuid.UUID, limit, offset int) ([]Series, int64, error) {
+	query := r.db.WithContext(ctx).Model(&Series{})
+	if ownerID != nil {
+		query = query.Where("owner_id = ?", *ownerID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var list []Series
+	err := query.
+		Preload("Owner").
+		Order("updated_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&list).Error
+	return list, total, err
+}
+
+func (r *repositoryImpl) update(ctx context.Context, s *Series) error {
+	return r.db.WithContext(ctx).
+		Model(&Series{}).
+		Where("id = ?", s.ID).
+		Updates(map[string]any{"title": s.Title, "description": s.Description}).Error
+}
+
+func (r *repositoryImpl) delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&Series{}, "id = ?", id).Error
+}
+
+// containing lists the series holding the content, with their items in order.
+func (r *repositoryImpl) containing(ctx context.Context, contentType string, contentID uuid.UUID) ([]Series, error) {
+	var list []Series
+	err := r.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Where("id IN (?)", r.db.Model(&SeriesItem{}).
+			Select("series_id").
+			Where("content_type = ? AND content_id = ?", contentType, contentID)).
+		Order("created_at ASC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *repositoryImpl) isCollaborator(ctx context.Context, seriesID, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&SeriesCollaborator{}).
+		Where("series_id = ? AND user_id = ?", seriesID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *repositoryImpl) addCollaborator(ctx context.Context, collaborator *SeriesCollaborator) error {
+	return r.db.WithContext(ctx).
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(collaborator).Error
+}
+
+func (r *repositoryImpl) removeCollaborator(ctx context.Context, seriesID, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Delete(&SeriesCollaborator{}, "series_id = ? AND user_id = ?", seriesID, userID).Error
+}
+
+// addItem inserts the item at its position, 1-based, moving the following
+// items down. Positions past the end append it.
+func (r *repositoryImpl) addItem(ctx context.Context, item *SeriesItem) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockSeries(tx, item.SeriesID); err != nil {
+			return err
+		}
+
+		var exists int64
+		err := tx.Model(&SeriesItem{}).
+			Where("series_id = ? AND content_type = ? AND content_id = ?", item.SeriesID, item.ContentType, item.ContentID).
+			Count(&exists).Error
+		if err != nil {
+			return err
+		}
+		if exists > 0 {
+			return errItemExists
+		}
+
+		var count int64
+		if err := tx.Model(&SeriesItem{}).Where("series_id = ?", item.SeriesID).Count(&count).Error; err != nil {
+			return err
+		}
+		if item.Position < 1 || item.Position > int(count) {
+			item.Position = int(count) + 1
+		}
+
+		err = tx.Model(&SeriesItem{}).
+			Where("series_id = ? AND position >= ?", item.SeriesID, item.Position).
+			UpdateColumn("position", gorm.Expr("position + 1")).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+		return touchSeries(tx, item.SeriesID)
+	})
+}
+
+// removeItem deletes the item and closes the gap it leaves.
+func (r *repositoryImpl) removeItem(ctx context.Context, seriesID, itemID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockSeries(tx, seriesID); err != nil {
+			return err
+		}
+
+		var item SeriesItem
+		if err := tx.First(&item, "id = ? AND series_id = ?", itemID, seriesID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&item).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&SeriesItem{}).
+			Where("series_id = ? AND position > ?", seriesID, item.Position).
+			UpdateColumn("position", gorm.Expr("position - 1")).Error
+		if err != nil {
+			return err
+		}
+		return touchSeries(tx, seriesID)
+	})
+}
+
+func (r *repositoryImpl) reorderItems(ctx context.Context, seriesID uuid.UUID, itemIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockSeries(tx, seriesID); err != nil {
+			return err
+		}
+
+		var count int64
+		err := tx.Model(&SeriesItem{}).
+			Where("series_id = ? AND id IN ?", seriesID, itemIDs).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		var total int64
+		if err := tx.Model(&SeriesItem{}).Where("series_id = ?", seriesID).Count(&total).Error; err != nil {
+			return err
+		}
+		if count != int64(len(itemIDs)) || count != total {
+			return errInvalidItemOrder
+		}
+
+		for i, id := range itemIDs {
+			err := tx.Model(&SeriesItem{}).Where("id = ?", id).
+				UpdateColumn("position", i+1).Error
+			if err != nil {
+				return err
+			}
+		}
+		return touchSeries(tx, seriesID)
+	})
+}
+
+func (r *repositoryImpl) getItem(ctx context.Context, seriesID, itemID uuid.UUID) (*SeriesItem, error) {
+	var item SeriesItem
+	err := r.db.WithContext(ctx).First(&item, "id = ? AND series_id = ?", itemID, seriesID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// resolve fills in the title and author of every item and whether its
+// content is live. Items whose content is gone are left untitled and not live.
+func (r *repositoryImpl) resolve(ctx context.Context, items []SeriesItem) error {
+	ids := make(map[string][]uuid.UUID)
+	for _, item := range items {
+		ids[item.ContentType] = append(ids[item.ContentType], item.ContentID)
+	}
+
+	type resolved struct {
+		ID       uuid.UUID
+		Title    string
+		Live     bool
+		AuthorID uuid.UUID
+	}
+	found := make(map[string]map[uuid.UUID]resolved, len(ids))
+	for contentType, contentIDs := range ids {
+		src, ok := contentSources[contentType]
+		if !ok {
+			continue
+		}
+
+		var rows []resolved
+		err := r.db.WithContext(ctx).
+			Raw("SELECT t.id, t.title, "+src.live+" AS live, "+src.author+" AS author_id FROM "+src.from+
+				" WHERE t.id IN ? AND "+src.exists, contentIDs).
+			Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		found[contentType] = make(map[uuid.UUID]resolved, len(rows))
+		for _, row := range rows {
+			found[contentType][row.ID] = row
+		}
+	}
+
+	for i := range items {
+		row := found[items[i].ContentType][items[i].ContentID]
+		items[i].Title, items[i].Live, items[i].AuthorID = row.Title, row.Live, row.AuthorID
+	}
+	return nil
+}
+
+func (r *repositoryImpl) markRead(ctx context.Context, read *SeriesRead) error {
+	return r.db.WithContext(ctx).
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(read).Error
+}
+
+func (r *repositoryImpl) unmarkRead(ctx context.Context, userID, itemID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Delete(&SeriesRead{}, "user_id = ? AND item_id = ?", userID, itemID).Error
+}
+
+func (r *repositoryImpl) readItems(ctx context.Context, userID uuid.UUID, seriesIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	var itemIDs []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Model(&SeriesRead{}).
+		Where("user_id = ? AND series_id IN ?", userID, seriesIDs).
+		Pluck("item_id", &itemIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	read := make(map[uuid.UUID]bool, len(itemIDs))
+	for _, id := range itemIDs {
+		read[id] = true
+	}
+	return read, nil
+}
+
+// lockSeries serializes changes to the items of a series.
+func lockSeries(tx *gorm.DB, seriesID uuid.UUID) error {
+	var s Series
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").First(&s, "id = ?", seriesID).Error
+}
+
+func touchSeries(tx *gorm.DB, seriesID uuid.UUID) error {
+	return tx.Model(&Series{}).Where("id = ?", seriesID).UpdateColumn("updated_at", gorm.Expr("NOW()")).Error
+}
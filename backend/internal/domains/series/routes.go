@@ -0,0 +1,29 @@
+package series
+
+import (
+	"pokemon/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *handler) RegisterRoutes(router fiber.Router) {
+	group := router.Group("/series")
+	auth := middleware.AuthRequired()
+
+	group.Get("/", h.list)
+	group.Get("/user/:user_id", h.listByOwner)
+	group.Get("/:id", middleware.AuthOptional(), h.get)
+
+	group.Post("/", auth, h.create)
+	group.Put("/:id", auth, h.update)
+	group.Delete("/:id", auth, h.delete)
+
+	group.Post("/:id/collaborators", auth, h.addCollaborator)
+	group.Delete("/:id/collaborators/:user_id", auth, h.removeCollaborator)
+
+	group.Post("/:id/items", auth, h.addItem)
+	group.Put("/:id/items/order", auth, h.reorderItems)
+	group.Delete("/:id/items/:item_id", auth, h.removeItem)
+	group.Post("/:id/items/:item_id/read", auth, h.markRead)
+	group.Delete("/:id/items/:item_id/read", auth, h.unmarkRead)
+}
@@ -0,0 +1,324 @@
+package series
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errItemExists       = errors.New("this content is already in the series")
+	errContentNotFound  = errors.New("content not found")
+	errInvalidItemOrder = errors.New("item_ids must list every item of the series exactly once")
+)
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type service interface {
+	create(ctx context.Context, s *Series) error
+	get(ctx context.Context, id, viewerID uuid.UUID) (*Series, error)
+	getByID(ctx context.Context, id uuid.UUID) (*Series, error)
+	list(ctx context.Context, ownerID *uuid.UUID, limit, offset int) ([]Series, int64, error)
+	update(ctx context.Context, s *Series) error
+	delete(ctx context.Context, id uuid.UUID) error
+	isCurator(ctx context.Context, s *Series, userID uuid.UUID) (bool, error)
+
+	addCollaborator(ctx context.Context, seriesID, userID uuid.UUID) error
+	removeCollaborator(ctx context.Context, seriesID, userID uuid.UUID) error
+
+	addItem(ctx context.Context, seriesID, userID uuid.UUID, req addItemRequest) (*SeriesItem, error)
+	removeItem(ctx context.Context, seriesID, itemID uuid.UUID) error
+	reorderItems(ctx context.Context, seriesID, viewerID uuid.UUID, itemIDs []uuid.UUID) error
+
+	markRead(ctx context.Context, seriesID, itemID, userID uuid.UUID) error
+	unmarkRead(ctx context.Context, seriesID, itemID, userID uuid.UUID) error
+
+	navigation(ctx context.Context, contentType string, contentID, viewerID uuid.UUID) ([]Navigation, error)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type serviceImpl struct {
+	repo repository
+}
+
+func newServ(repo repository) service {
+	return &serviceImpl{repo: repo}
+}
+
+func (s *serviceImpl) create(ctx context.Context, series *Series) error {
+	if err := series.Validate(); err != nil {
+		return err
+	}
+	series.Items, series.Collaborators = nil, nil
+	return s.repo.create(ctx, series)
+}
+
+// get returns the series as the viewer sees it: live items, their own drafts
+// and, when logged in, what they read. Curators get no more than that: drafts
+// stay with their author until published.
+func (s *serviceImpl) get(ctx context.Context, id, viewerID uuid.UUID) (*Series, error) {
+	series, err := s.repo.getByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.resolve(ctx, series.Items); err != nil {
+		return nil, err
+	}
+	series.Items = visibleItems(series.Items, viewerID)
+
+	if viewerID != uuid.Nil {
+		read, err := s.repo.readItems(ctx, viewerID, []uuid.UUID{series.ID})
+		if err != nil {
+			return nil, err
+		}
+		for i := range series.Items {
+			series.Items[i].Read = read[series.Items[i].ID]
+		}
+		series.Progress = progressOf(liveItems(series.Items), read)
+	}
+	return series, nil
+}
+
+func (s *serviceImpl) getByID(ctx context.Context, id uuid.UUID) (*Series, error) {
+	return s.repo.getByID(ctx, id)
+}
+
+func (s *serviceImpl) list(ctx context.Context, ownerID *uuid.UUID, limit, offset int) ([]Series, int64, error) {
+	return s.repo.list(ctx, ownerID, limit, offset)
+}
+
+func (s *serviceImpl) update(ctx context.Context, series *Series) error {
+	if err := series.Validate(); err != nil {
+		return err
+	}
+	return s.repo.update(ctx, series)
+}
+
+func (s *serviceImpl) delete(ctx context.Context, id uuid.UUID) error {
+	return s.repo.delete(ctx, id)
+}
+
+// isCurator tells whether the user may change the items of the series.
+func (s *serviceImpl) isCurator(ctx context.Context, series *Series, userID uuid.UUID) (bool, error) {
+	if userID == uuid.Nil {
+		return false, nil
+	}
+	if series.OwnerID == userID {
+		return true, nil
+	}
+	return s.repo.isCollaborator(ctx, series.ID, userID)
+}
+
+func (s *serviceImpl) addCollaborator(ctx context.Context, seriesID, userID uuid.UUID) error {
+	if userID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	return s.repo.addCollaborator(ctx, &SeriesCollaborator{SeriesID: seriesID, UserID: userID})
+}
+
+func (s *serviceImpl) removeCollaborator(ctx context.Context, seriesID, userID uuid.UUID) error {
+	return s.repo.removeCollaborator(ctx, seriesID, userID)
+}
+
+// addItem adds content the curator can see: live content, or their own draft.
+func (s *serviceImpl) addItem(ctx context.Context, seriesID, userID uuid.UUID, req addItemRequest) (*SeriesItem, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	items := []SeriesItem{{
+		SeriesID:    seriesID,
+		Position:    req.Position,
+		ContentType: req.ContentType,
+		ContentID:   req.ContentID,
+		AddedBy:     userID,
+	}}
+	if err := s.repo.resolve(ctx, items); err != nil {
+		return nil, err
+	}
+	item := &items[0]
+	if !item.visibleTo(userID) {
+		return nil, errContentNotFound
+	}
+	if err := s.repo.addItem(ctx, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *serviceImpl) removeItem(ctx context.Context, seriesID, itemID uuid.UUID) error {
+	return s.repo.removeItem(ctx, seriesID, itemID)
+}
+
+// reorderItems numbers the items in the given order, which must list every
+// item of the series the viewer sees once. The drafts of others keep their
+// places, so curators can reorder around items they don't see.
+func (s *serviceImpl) reorderItems(ctx context.Context, seriesID, viewerID uuid.UUID, itemIDs []uuid.UUID) error {
+	series, err := s.repo.getByID(ctx, seriesID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.resolve(ctx, series.Items); err != nil {
+		return err
+	}
+
+	visible := make(map[uuid.UUID]bool, len(series.Items))
+	for _, item := range visibleItems(series.Items, viewerID) {
+		visible[item.ID] = true
+	}
+	seen := make(map[uuid.UUID]bool, len(itemIDs))
+	for _, id := range itemIDs {
+		if seen[id] || !visible[id] {
+			return errInvalidItemOrder
+		}
+		seen[id] = true
+	}
+	if len(seen) != len(visible) {
+		return errInvalidItemOrder
+	}
+
+	order := make([]uuid.UUID, 0, len(series.Items))
+	next := 0
+	for _, item := range series.Items {
+		if visible[item.ID] {
+			order = append(order, itemIDs[next])
+			next++
+		} else {
+			order = append(order, item.ID)
+		}
+	}
+	return s.repo.reorderItems(ctx, seriesID, order)
+}
+
+func (s *serviceImpl) markRead(ctx context.Context, seriesID, itemID, userID uuid.UUID) error {
+	if _, err := s.repo.getItem(ctx, seriesID, itemID); err != nil {
+		return err
+	}
+	return s.repo.markRead(ctx, &SeriesRead{UserID: userID, ItemID: itemID, SeriesID: seriesID})
+}
+
+func (s *serviceImpl) unmarkRead(ctx context.Context, seriesID, itemID, userID uuid.UUID) error {
+	if _, err := s.repo.getItem(ctx, seriesID, itemID); err != nil {
+		return err
+	}
+	return s.repo.unmarkRead(ctx, userID, itemID)
+}
+
+// navigation places the content in every series holding it, among the items
+// the viewer sees. Reading is only recorded by markRead: this runs on GETs.
+func (s *serviceImpl) navigation(ctx context.Context, contentType string, contentID, viewerID uuid.UUID) ([]Navigation, error) {
+	list, err := s.repo.containing(ctx, contentType, contentID)
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+
+	var all []SeriesItem
+	for _, series := range list {
+		all = append(all, series.Items...)
+	}
+	if err := s.repo.resolve(ctx, all); err != nil {
+		return nil, err
+	}
+
+	var read map[uuid.UUID]bool
+	if viewerID != uuid.Nil {
+		seriesIDs := make([]uuid.UUID, 0, len(list))
+		for _, series := range list {
+			seriesIDs = append(seriesIDs, series.ID)
+		}
+		if read, err = s.repo.readItems(ctx, viewerID, seriesIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	navigation := make([]Navigation, 0, len(list))
+	offset := 0
+	for _, series := range list {
+		items := visibleItems(all[offset:offset+len(series.Items)], viewerID)
+		offset += len(series.Items)
+
+		at := -1
+		for i, item := range items {
+			if item.ContentType == contentType && item.ContentID == contentID {
+				at = i
+			}
+		}
+		if at < 0 {
+			continue
+		}
+
+		nav := Navigation{
+			SeriesID: series.ID,
+			ItemID:   items[at].ID,
+			Title:    series.Title,
+			Position: at + 1,
+			Total:    len(items),
+		}
+		if at > 0 {
+			nav.Previous = refOf(items[at-1])
+		}
+		if at < len(items)-1 {
+			nav.Next = refOf(items[at+1])
+		}
+
+		if read != nil {
+			nav.Progress = progressOf(liveItems(items), read)
+		}
+		navigation = append(navigation, nav)
+	}
+	return navigation, nil
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// visibleTo tells whether the viewer may see the item: its content is live or
+// theirs.
+func (item *SeriesItem) visibleTo(viewerID uuid.UUID) bool {
+	return item.Live || (viewerID != uuid.Nil && item.AuthorID == viewerID)
+}
+
+func visibleItems(items []SeriesItem, viewerID uuid.UUID) []SeriesItem {
+	visible := make([]SeriesItem, 0, len(items))
+	for _, item := range items {
+		if item.visibleTo(viewerID) {
+			visible = append(visible, item)
+		}
+	}
+	return visible
+}
+
+func liveItems(items []SeriesItem) []SeriesItem {
+	live := make([]SeriesItem, 0, len(items))
+	for _, item := range items {
+		if item.Live {
+			live = append(live, item)
+		}
+	}
+	return live
+}
+
+func progressOf(items []SeriesItem, read map[uuid.UUID]bool) *Progress {
+	progress := &Progress{Total: len(items)}
+	for _, item := range items {
+		if read[item.ID] {
+			progress.Read++
+		}
+	}
+	if progress.Total > 0 {
+		progress.Percent = math.Round(float64(progress.Read)*1000/float64(progress.Total)) / 10
+	}
+	return progress
+}
+
+func refOf(item SeriesItem) *ItemRef {
+	return &ItemRef{ContentType: item.ContentType, ContentID: item.ContentID, Title: item.Title}
+}
@@ -2,6 +2,8 @@ package shout
 
 import (
 	"errors"
+	"pokemon/internal/domains/dexlink"
+	"pokemon/internal/domains/taglink"
 	"pokemon/internal/domains/user"
 	"pokemon/pkg/validation"
 	"time"
@@ -35,6 +37,11 @@ type Shout struct {
 	LikeCount    int            `gorm:"default:0" json:"like_count"`
 	CommentCount int            `gorm:"default:0" json:"comment_count"`
 	IsFlagged    bool           `gorm:"default:false" json:"is_flagged"`
+
+	// Species, moves, abilities and items named in the content, for the client to link
+	DexLinks     []dexlink.Match `gorm:"-" json:"dex_links,omitempty"`
+	// Hashtags and mentions in the content, likewise
+	Entities     *taglink.Entities `gorm:"-" json:"entities,omitempty"`
 }
 
 /****************
@@ -56,6 +63,9 @@ type ShoutComment struct {
 
 	Likes     []ShoutCommentLike `gorm:"constraint:OnDelete:CASCADE" json:"likes"`
 	LikeCount int                `gorm:"default:0" json:"like_count"`
+
+	// Hashtags and mentions in the content, for the client to link
+	Entities *taglink.Entities `gorm:"-" json:"entities,omitempty"`
 }
 
 type ShoutLike struct {
@@ -92,6 +102,44 @@ type ShoutView struct {
 	Shout Shout     `gorm:"foreignKey:ShoutID" json:"shout"`
 }
 
+/***********
+ * FOLLOWS *
+ ***********/
+
+// Follow is FollowerID following FolloweeID: their shouts and reshouts show
+// on the home timeline of the follower.
+type Follow struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	FollowerID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_follower_followee" json:"follower_id"`
+	FolloweeID uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_follower_followee" json:"followee_id"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	Follower user.User `gorm:"foreignKey:FollowerID;constraint:OnDelete:CASCADE" json:"-"`
+	Followee user.User `gorm:"foreignKey:FolloweeID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+/*************
+ * RESPONSES *
+ *************/
+
+type followUser struct {
+	ID         uuid.UUID `json:"id"`
+	Username   string    `json:"username"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+type followCounts struct {
+	Followers int64 `json:"followers"`
+	Following int64 `json:"following"`
+	// Whether the viewer follows the user, when signed in
+	IsFollowing *bool `json:"is_following,omitempty"`
+}
+
+type timelinePage struct {
+	Shouts     []Shout `json:"shouts"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
 /***************
  * VALIDATIONS *
  ***************/
@@ -155,6 +203,19 @@ func (s *ShoutSave) Validate() error {
 	return nil
 }
 
+func (f *Follow) Validate() error {
+	if err := validation.UUIDRequired(f.FollowerID, "follower_id"); err != nil {
+		return err
+	}
+	if err := validation.UUIDRequired(f.FolloweeID, "followee_id"); err != nil {
+		return err
+	}
+	if f.FollowerID == f.FolloweeID {
+		return errFollowSelf
+	}
+	return nil
+}
+
 func (v *ShoutView) Validate() error {
 	if err := validation.UUIDRequired(v.ShoutID, "shout_id"); err != nil {
 		return err
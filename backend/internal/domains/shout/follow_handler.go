@@ -0,0 +1,149 @@
+package shout
+
+import (
+	"errors"
+	"log"
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+/***********
+ * FOLLOWS *
+ ***********/
+
+// POST /follows/:userID
+func (h *handler) follow(c *fiber.Ctx) error {
+	followeeID, err := uuid.Parse(c.Params("userID"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID")
+	}
+
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+
+	if err := h.fs.follow(userID, followeeID); err != nil {
+		return followError(err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// DELETE /follows/:userID
+func (h *handler) unfollow(c *fiber.Ctx) error {
+	followeeID, err := uuid.Parse(c.Params("userID"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID")
+	}
+
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+
+	if err := h.fs.unfollow(userID, followeeID); err != nil {
+		return followError(err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GET /follows/:userID/followers
+func (h *handler) listFollowers(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("userID"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID")
+	}
+
+	limit, offset := utils.ParsePagination(c)
+	users, err := h.fs.followers(userID, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(users)
+}
+
+// GET /follows/:userID/following
+func (h *handler) listFollowing(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("userID"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID")
+	}
+
+	limit, offset := utils.ParsePagination(c)
+	users, err := h.fs.following(userID, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(users)
+}
+
+// GET /follows/:userID/counts
+func (h *handler) followCounts(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("userID"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID")
+	}
+
+	var viewerID *uuid.UUID
+	if id, err := utils.GetUserIDFromLocals(c); err == nil {
+		viewerID = &id
+	}
+
+	counts, err := h.fs.counts(userID, viewerID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(counts)
+}
+
+/************
+ * TIMELINE *
+ ************/
+
+// GET /shouts/timeline?cursor=
+func (h *handler) homeTimeline(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+
+	limit, _ := utils.ParsePagination(c)
+	limit = min(limit, 100)
+
+	page, err := h.ts.home(userID, c.Query("cursor"), limit)
+	if errors.Is(err, errInvalidCursor) {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	for i := range page.Shouts {
+		h.annotate(c, &page.Shouts[i])
+	}
+	return c.JSON(page)
+}
+
+// publish places a new shout on the timelines. The shout is saved already, so
+// failing here only logs: the timelines catch up when they are rebuilt.
+func (h *handler) publish(shout *Shout) {
+	if err := h.ts.publish(shout); err != nil {
+		log.Printf("timeline fan-out failed for shout %s: %v", shout.ID, err)
+	}
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func followError(err error) error {
+	switch {
+	case errors.Is(err, errFollowSelf):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, errUserNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,174 @@
+package shout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pokemon/internal/domains/notification"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	errFollowSelf   = errors.New("you cannot follow yourself")
+	errUserNotFound = errors.New("user not found")
+)
+
+/********************
+ * REDIS KEY UTILS  *
+ ********************/
+
+// The follow graph is mirrored in Redis: the friends leaderboard of the game
+// domain reads the following sets.
+func redisFollowersKey(userID uuid.UUID) string {
+	return fmt.Sprintf("user:%s:followers", userID.String())
+}
+
+func redisFollowingKey(userID uuid.UUID) string {
+	return fmt.Sprintf("user:%s:following", userID.String())
+}
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type followService interface {
+	follow(followerID, followeeID uuid.UUID) error
+	unfollow(followerID, followeeID uuid.UUID) error
+
+	followers(userID uuid.UUID, limit, offset int) ([]followUser, error)
+	following(userID uuid.UUID, limit, offset int) ([]followUser, error)
+	counts(userID uuid.UUID, viewerID *uuid.UUID) (*followCounts, error)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type follows struct {
+	repo     followRepository
+	timeline timelineService
+	redis    *redis.Client
+}
+
+func newFollowService(repo followRepository, timeline timelineService, redis *redis.Client) followService {
+	return &follows{repo: repo, timeline: timeline, redis: redis}
+}
+
+// follow makes followerID follow followeeID, and brings the recent shouts of
+// the followee onto the timeline of the follower. Following twice is a no-op.
+func (s *follows) follow(followerID, followeeID uuid.UUID) error {
+	follow := &Follow{FollowerID: followerID, FolloweeID: followeeID}
+	if err := follow.Validate(); err != nil {
+		return err
+	}
+	exists, err := s.repo.userExists(followeeID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errUserNotFound
+	}
+
+	created, err := s.repo.create(follow)
+	if err != nil || !created {
+		return err
+	}
+	notification.Send(notification.Event{Type: notification.TypeFollow, ActorID: followerID, SubjectType: notification.SubjectUser, SubjectID: followeeID})
+
+	if err := s.mirror(followerID, followeeID, true); err != nil {
+		return err
+	}
+	return s.timeline.followed(followerID, followeeID)
+}
+
+// unfollow undoes follow, taking the shouts of the followee off the timeline
+// of the follower.
+func (s *follows) unfollow(followerID, followeeID uuid.UUID) error {
+	deleted, err := s.repo.delete(followerID, followeeID)
+	if err != nil || !deleted {
+		return err
+	}
+	if err := s.mirror(followerID, followeeID, false); err != nil {
+		return err
+	}
+	return s.timeline.unfollowed(followerID, followeeID)
+}
+
+func (s *follows) followers(userID uuid.UUID, limit, offset int) ([]followUser, error) {
+	return s.repo.followers(userID, limit, offset)
+}
+
+func (s *follows) following(userID uuid.UUID, limit, offset int) ([]followUser, error) {
+	return s.repo.following(userID, limit, offset)
+}
+
+func (s *follows) counts(userID uuid.UUID, viewerID *uuid.UUID) (*followCounts, error) {
+	var counts followCounts
+	var err error
+	if counts.Followers, err = s.repo.countFollowers(userID); err != nil {
+		return nil, err
+	}
+	if counts.Following, err = s.repo.countFollowing(userID); err != nil {
+		return nil, err
+	}
+	if viewerID != nil && *viewerID != userID {
+		following, err := s.repo.exists(*viewerID, userID)
+		if err != nil {
+			return nil, err
+		}
+		counts.IsFollowing = &following
+	}
+	return &counts, nil
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// mirror applies a follow, or an unfollow, to the sets of both users.
+func (s *follows) mirror(followerID, followeeID uuid.UUID, add bool) error {
+	ctx := context.Background()
+	sets := []struct {
+		key    string
+		member uuid.UUID
+		load   func() ([]uuid.UUID, error)
+	}{
+		{redisFollowingKey(followerID), followeeID, func() ([]uuid.UUID, error) { return s.repo.followingIDs(followerID) }},
+		{redisFollowersKey(followeeID), followerID, func() ([]uuid.UUID, error) { return s.repo.followerIDs(followeeID) }},
+	}
+
+	for _, set := range sets {
+		n, err := s.redis.Exists(ctx, set.key).Result()
+		if err != nil {
+			return err
+		}
+		switch {
+		case n == 0:
+			// A set Redis lost would be left with this change only: rebuild it
+			err = fillSet(ctx, s.redis, set.key, set.load)
+		case add:
+			err = s.redis.SAdd(ctx, set.key, set.member.String()).Err()
+		default:
+			err = s.redis.SRem(ctx, set.key, set.member.String()).Err()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillSet stores the ids load returns in the set at key.
+func fillSet(ctx context.Context, client *redis.Client, key string, load func() ([]uuid.UUID, error)) error {
+	ids, err := load()
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	members := make([]any, len(ids))
+	for i, id := range ids {
+		members[i] = id.String()
+	}
+	return client.SAdd(ctx, key, members...).Err()
+}
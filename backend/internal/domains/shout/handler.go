@@ -1,6 +1,10 @@
 package shout
 
 import (
+	"errors"
+	"pokemon/internal/domains/dexlink"
+	"pokemon/internal/domains/notification"
+	"pokemon/internal/domains/taglink"
 	"pokemon/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -13,6 +17,10 @@ import (
 type handler struct {
 	s shoutService
 	is interactionService
+	fs followService
+	ts timelineService
+	matcher *dexlink.Matcher
+	tagger *taglink.Tagger
 }
 
 func NewHandler(db *gorm.DB, redis *redis.Client) *handler {
@@ -21,7 +29,11 @@ func NewHandler(db *gorm.DB, redis *redis.Client) *handler {
 
 	iRepo := newInteractionRepo(db)
 	iService := newInteractionService(iRepo, redis)
-	return &handler{s: service, is: iService}
+
+	fRepo := newFollowRepo(db)
+	tService := newTimelineService(newTimelineRepo(db), fRepo, redis)
+	fService := newFollowService(fRepo, tService, redis)
+	return &handler{s: service, is: iService, fs: fService, ts: tService, matcher: dexlink.NewMatcher(db), tagger: taglink.NewTagger(db, redis)}
 }
 
 // POST /shouts
@@ -42,6 +54,8 @@ func (h *handler) createShout(c *fiber.Ctx) error {
 	if err := h.s.createShout(&shout); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
+	h.tag(c, &shout)
+	h.publish(&shout)
 	return c.Status(fiber.StatusCreated).JSON(shout)
 }
 
@@ -55,6 +69,7 @@ func (h *handler) getShout(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "shout not found"})
 	}
+	h.annotate(c, shout)
 	return c.JSON(shout)
 }
 
@@ -66,6 +81,9 @@ func (h *handler) listShouts(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	for i := range shouts {
+		h.annotate(c, &shouts[i])
+	}
 	return c.JSON(shouts)
 }
 
@@ -82,6 +100,9 @@ func (h *handler) listShoutsByUser(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	for i := range shouts {
+		h.annotate(c, &shouts[i])
+	}
 	return c.JSON(shouts)
 }
 
@@ -98,6 +119,9 @@ func (h *handler) listShoutsByParent(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	for i := range shouts {
+		h.annotate(c, &shouts[i])
+	}
 	return c.JSON(shouts)
 }
 
@@ -125,6 +149,7 @@ func (h *handler) updateShout(c *fiber.Ctx) error {
 	if err := h.s.updateShout(&shout); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	h.tag(c, &shout)
 	return c.JSON(shout)
 }
 
@@ -138,6 +163,37 @@ func (h *handler) deleteShout(c *fiber.Ctx) error {
 	if err := h.s.deleteShout(id); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	h.tagger.Remove(c.Context(), taglink.ContentShout, id)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// PUT /shouts/:id/flag and DELETE /shouts/:id/flag, for moderators
+func (h *handler) flagShout(c *fiber.Ctx) error {
+	return h.setFlagged(c, true)
+}
+
+func (h *handler) unflagShout(c *fiber.Ctx) error {
+	return h.setFlagged(c, false)
+}
+
+func (h *handler) setFlagged(c *fiber.Ctx, flagged bool) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid shout ID"})
+	}
+
+	if err := h.s.setFlagged(id, flagged); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "shout not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// Tell the author their shout was flagged, not that it is back
+	if flagged {
+		moderatorID, _ := utils.GetUserIDFromLocals(c)
+		notification.Send(notification.Event{Type: notification.TypeModeration, ActorID: moderatorID, SubjectType: notification.SubjectShout, SubjectID: id, Note: notification.NoteFlagged})
+	}
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
@@ -162,6 +218,25 @@ func (h *handler) retweetShout(c *fiber.Ctx) error {
 	if err := h.s.createShout(newShout); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	h.publish(newShout)
 
 	return c.Status(fiber.StatusCreated).JSON(newShout)
 }
+
+// annotate locates the Pokédex entities, hashtags and mentions in the shout
+// and in the shout it quotes or reshouts.
+func (h *handler) annotate(c *fiber.Ctx, shout *Shout) {
+	shout.DexLinks = h.matcher.Find(c.Context(), shout.Content)
+	shout.Entities = h.tagger.Find(c.Context(), shout.Content)
+	if shout.ReshoutOf != nil {
+		shout.ReshoutOf.DexLinks = h.matcher.Find(c.Context(), shout.ReshoutOf.Content)
+		shout.ReshoutOf.Entities = h.tagger.Find(c.Context(), shout.ReshoutOf.Content)
+	}
+}
+
+// tag stores the hashtags and mentions of a saved shout.
+func (h *handler) tag(c *fiber.Ctx, shout *Shout) {
+	shout.Entities = h.tagger.Sync(c.Context(), taglink.Ref{
+		Type: taglink.ContentShout, ID: shout.ID, AuthorID: shout.UserID,
+	}, shout.Content)
+}
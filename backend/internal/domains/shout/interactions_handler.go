@@ -1,6 +1,8 @@
 package shout
 
 import (
+	"pokemon/internal/domains/notification"
+	"pokemon/internal/domains/taglink"
 	"pokemon/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -38,6 +40,7 @@ func (h *handler) createLike(c *fiber.Ctx) error {
 	if err := h.is.createLike(&like); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
+	notification.Send(notification.Event{Type: notification.TypeLike, ActorID: userID, SubjectType: notification.SubjectShout, SubjectID: shoutID})
 
 	return c.SendStatus(fiber.StatusCreated)
 }
@@ -84,10 +87,27 @@ func (h *handler) createComment(c *fiber.Ctx) error {
 	if err := h.is.createComment(&comment); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
+	h.tagComment(c, &comment)
+	h.notifyReply(&comment)
 
 	return c.Status(fiber.StatusCreated).JSON(comment)
 }
 
+// notifyReply tells the author of the shout, or of the comment replied to.
+func (h *handler) notifyReply(comment *ShoutComment) {
+	e := notification.Event{
+		Type:        notification.TypeReply,
+		ActorID:     comment.UserID,
+		SubjectType: notification.SubjectShout,
+		SubjectID:   comment.ShoutID,
+		ContentID:   &comment.ID,
+	}
+	if comment.ParentCommentID != nil {
+		e.SubjectType, e.SubjectID = notification.SubjectShoutComment, *comment.ParentCommentID
+	}
+	notification.Send(e)
+}
+
 func (h *handler) updateComment(c *fiber.Ctx) error {
 	userID, err := utils.GetUserIDFromLocals(c)
 	if err != nil {
@@ -108,6 +128,7 @@ func (h *handler) updateComment(c *fiber.Ctx) error {
 	if err := h.is.updateComment(&comment); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
+	h.tagComment(c, &comment)
 
 	return c.JSON(comment)
 }
@@ -121,6 +142,7 @@ func (h *handler) deleteComment(c *fiber.Ctx) error {
 	if err := h.is.deleteComment(commentID); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
+	h.tagger.Remove(c.Context(), taglink.ContentShoutComment, commentID)
 
 	return c.SendStatus(fiber.StatusNoContent)
 }
@@ -180,6 +202,7 @@ func (h *handler) createSave(c *fiber.Ctx) error {
 	if err := h.is.createSave(&save); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
+	notification.Send(notification.Event{Type: notification.TypeSave, ActorID: userID, SubjectType: notification.SubjectShout, SubjectID: shoutID})
 
 	return c.SendStatus(fiber.StatusCreated)
 }
@@ -219,3 +242,10 @@ func (h *handler) getInteractions(c *fiber.Ctx) error {
 
 	return c.JSON(result)
 }
+
+// tagComment stores the hashtags and mentions of a saved comment.
+func (h *handler) tagComment(c *fiber.Ctx, comment *ShoutComment) {
+	comment.Entities = h.tagger.Sync(c.Context(), taglink.Ref{
+		Type: taglink.ContentShoutComment, ID: comment.ID, ParentID: &comment.ShoutID, AuthorID: comment.UserID,
+	}, comment.Content)
+}
@@ -0,0 +1,20 @@
+package shout
+
+import (
+	"gorm.io/gorm"
+)
+
+type ShoutMigrator struct{}
+
+func (m ShoutMigrator) Migrate(db *gorm.DB) error {
+	// ShoutView stays out: anonymous views have no user for the foreign key
+	// AutoMigrate would add
+	return db.AutoMigrate(
+		&Shout{},
+		&ShoutComment{},
+		&ShoutLike{},
+		&ShoutCommentLike{},
+		&ShoutSave{},
+		&Follow{},
+	)
+}
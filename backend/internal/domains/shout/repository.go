@@ -1,8 +1,12 @@
 package shout
 
 import (
+	"pokemon/internal/domains/user"
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 /******************************
@@ -20,6 +24,7 @@ type shoutRepository interface {
 	create(shout *Shout) error
 	getById(id uuid.UUID) (*Shout, error)
 	update(shout *Shout) error
+	setFlagged(id uuid.UUID, flagged bool) error
 	delete(id uuid.UUID) error
 }
 
@@ -47,8 +52,20 @@ func (r *shoutRepo) getById(id uuid.UUID) (*Shout, error) {
 	return &shout, err
 }
 
+// update leaves the flag alone, only moderators set it.
 func (r *shoutRepo) update(shout *Shout) error {
-	return r.db.Save(shout).Error
+	return r.db.Omit("IsFlagged").Save(shout).Error
+}
+
+func (r *shoutRepo) setFlagged(id uuid.UUID, flagged bool) error {
+	res := r.db.Model(&Shout{}).Where("id = ?", id).Update("is_flagged", flagged)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
 
 func (r *shoutRepo) delete(id uuid.UUID) error {
@@ -225,3 +242,164 @@ func (r *iRepo) createSave(save *ShoutSave) error {
 func (r *iRepo) deleteSave(shoutID, userID uuid.UUID) error {
 	return r.db.Delete(&ShoutSave{}, "shout_id = ? AND user_id", shoutID, userID).Error
 }
+
+/*********************************
+ *********************************
+ ************ FOLLOWS ************
+ *********************************
+ *********************************/
+
+type followRepository interface {
+	create(follow *Follow) (bool, error)
+	delete(followerID, followeeID uuid.UUID) (bool, error)
+	exists(followerID, followeeID uuid.UUID) (bool, error)
+	userExists(userID uuid.UUID) (bool, error)
+
+	followers(userID uuid.UUID, limit, offset int) ([]followUser, error)
+	following(userID uuid.UUID, limit, offset int) ([]followUser, error)
+	followerIDs(userID uuid.UUID) ([]uuid.UUID, error)
+	followingIDs(userID uuid.UUID) ([]uuid.UUID, error)
+	countFollowers(userID uuid.UUID) (int64, error)
+	countFollowing(userID uuid.UUID) (int64, error)
+}
+
+type followRepo struct {
+	db *gorm.DB
+}
+
+func newFollowRepo(db *gorm.DB) followRepository {
+	return &followRepo{db: db}
+}
+
+// create reports whether the follow is new.
+func (r *followRepo) create(follow *Follow) (bool, error) {
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(follow)
+	return res.RowsAffected > 0, res.Error
+}
+
+// delete reports whether there was a follow to delete.
+func (r *followRepo) delete(followerID, followeeID uuid.UUID) (bool, error) {
+	res := r.db.Delete(&Follow{}, "follower_id = ? AND followee_id = ?", followerID, followeeID)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *followRepo) exists(followerID, followeeID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&Follow{}).
+		Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *followRepo) userExists(userID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&user.User{}).Where("id = ?", userID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *followRepo) followers(userID uuid.UUID, limit, offset int) ([]followUser, error) {
+	return r.users("follower_id", "followee_id", userID, limit, offset)
+}
+
+func (r *followRepo) following(userID uuid.UUID, limit, offset int) ([]followUser, error) {
+	return r.users("followee_id", "follower_id", userID, limit, offset)
+}
+
+// users lists the users on the other side of the follows of userID, most
+// recent first.
+func (r *followRepo) users(other, side string, userID uuid.UUID, limit, offset int) ([]followUser, error) {
+	var users []followUser
+	err := r.db.Model(&Follow{}).
+		Select("users.id, users.username, follows.created_at AS followed_at").
+		Joins("JOIN users ON users.id = follows."+other+" AND users.deleted_at IS NULL").
+		Where("follows."+side+" = ?", userID).
+		Order("follows.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&users).Error
+	return users, err
+}
+
+func (r *followRepo) followerIDs(userID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&Follow{}).Where("followee_id = ?", userID).Pluck("follower_id", &ids).Error
+	return ids, err
+}
+
+func (r *followRepo) followingIDs(userID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&Follow{}).Where("follower_id = ?", userID).Pluck("followee_id", &ids).Error
+	return ids, err
+}
+
+func (r *followRepo) countFollowers(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&Follow{}).Where("followee_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *followRepo) countFollowing(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&Follow{}).Where("follower_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+/**********************************
+ **********************************
+ ************ TIMELINE ************
+ **********************************
+ **********************************/
+
+// timelineEntry is a shout as it's placed on a timeline.
+type timelineEntry struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+}
+
+type timelineRepository interface {
+	// recent lists the latest shouts of the authors before the cursor, when
+	// there is one.
+	recent(authorIDs []uuid.UUID, before *timelineEntry, limit int) ([]timelineEntry, error)
+	getByIDs(ids []uuid.UUID) ([]Shout, error)
+}
+
+type timelineRepo struct {
+	db *gorm.DB
+}
+
+func newTimelineRepo(db *gorm.DB) timelineRepository {
+	return &timelineRepo{db: db}
+}
+
+func (r *timelineRepo) recent(authorIDs []uuid.UUID, before *timelineEntry, limit int) ([]timelineEntry, error) {
+	var entries []timelineEntry
+	if len(authorIDs) == 0 {
+		return entries, nil
+	}
+	query := r.db.Model(&Shout{}).
+		Select("id, created_at").
+		Where("user_id IN ?", authorIDs)
+	if before != nil {
+		// Timelines order shouts to the millisecond, as Redis scores them
+		query = query.Where("(date_trunc('milliseconds', created_at), id) < (?, ?)", before.CreatedAt, before.ID)
+	}
+	err := query.
+		Order("date_trunc('milliseconds', created_at) DESC, id DESC").
+		Limit(limit).
+		Scan(&entries).Error
+	return entries, err
+}
+
+func (r *timelineRepo) getByIDs(ids []uuid.UUID) ([]Shout, error) {
+	var shouts []Shout
+	if len(ids) == 0 {
+		return shouts, nil
+	}
+	err := r.db.
+		Preload("User").
+		Preload("ReshoutOf").
+		Preload("ReshoutOf.User").
+		Where("id IN ?", ids).
+		Find(&shouts).Error
+	return shouts, err
+}
@@ -1,7 +1,9 @@
 package shout
 
 import (
+	"pokemon/internal/domains/user"
 	"pokemon/internal/middleware"
+	"pokemon/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -9,6 +11,9 @@ import (
 func (h *handler) RegisterRoutes(app fiber.Router) {
 	shouts := app.Group("/shouts")
 
+	// Home timeline, ahead of /:id
+	shouts.Get("/timeline", middleware.AuthRequired(), h.homeTimeline)
+
 	// Public routes
 	shouts.Get("/", h.listShouts)
 	shouts.Get("/:id", h.getShout)
@@ -27,6 +32,11 @@ func (h *handler) RegisterRoutes(app fiber.Router) {
 	shouts.Put("/:id", h.updateShout)
 	shouts.Delete("/:id", h.deleteShout)
 
+	// Moderation
+	moderators := utils.RoleMiddleware(user.RoleModerator, user.RoleAdmin)
+	shouts.Put("/:id/flag", moderators, h.flagShout)
+	shouts.Delete("/:id/flag", moderators, h.unflagShout)
+
 	// Likes
 	shouts.Post("/:shout_id/likes", h.createLike)
 	shouts.Delete("/:shout_id/likes", h.deleteLike)
@@ -39,4 +49,12 @@ func (h *handler) RegisterRoutes(app fiber.Router) {
 	// Saves
 	shouts.Post("/:shout_id/saves", h.createSave)
 	shouts.Delete("/:shout_id/saves", h.deleteSave)
+
+	// Follows
+	follows := app.Group("/follows")
+	follows.Get("/:userID/followers", h.listFollowers)
+	follows.Get("/:userID/following", h.listFollowing)
+	follows.Get("/:userID/counts", middleware.AuthOptional(), h.followCounts)
+	follows.Post("/:userID", middleware.AuthRequired(), h.follow)
+	follows.Delete("/:userID", middleware.AuthRequired(), h.unfollow)
 }
@@ -18,6 +18,7 @@ type shoutService interface {
 	listShoutsByUser(userID uuid.UUID, limit, offset int) ([]Shout, error)
 	listShoutsByParent(reshoutID uuid.UUID, limit, offset int) ([]Shout, error)
 	updateShout(shout *Shout) error
+	setFlagged(id uuid.UUID, flagged bool) error
 	deleteShout(id uuid.UUID) error
 	countShoutsByUser(userID uuid.UUID) (int64, error)
 }
@@ -112,6 +113,15 @@ func (s *service) updateShout(shout *Shout) error {
 	return nil
 }
 
+// setFlagged hides the shout from search and tag pages, or shows it again.
+func (s *service) setFlagged(id uuid.UUID, flagged bool) error {
+	if err := s.repo.setFlagged(id, flagged); err != nil {
+		return err
+	}
+	s.redis.Del(context.Background(), redisShoutKey(id))
+	return nil
+}
+
 func (s *service) deleteShout(id uuid.UUID) error {
 	if err := s.repo.delete(id); err != nil {
 		return err
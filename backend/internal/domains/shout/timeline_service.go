@@ -0,0 +1,400 @@
+package shout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Home timelines are built when shouts are posted: they are added to the
+// timeline of every follower of their author (fan-out on write). Authors with
+// wideFollowers followers or more would make posting too slow, so their shouts
+// are looked up when timelines are read instead (fan-out on read).
+const (
+	// wideFollowers is the follower count from which an author is wide
+	wideFollowers = 10000
+	// timelineSize is how many shouts a timeline keeps, the latest ones
+	timelineSize = 800
+	// timelineTTL lets the timelines of inactive users lapse; they are built
+	// anew from the database on their next read
+	timelineTTL = 7 * 24 * time.Hour
+	// backfillSize is how many recent shouts of a followee a follow adds
+	backfillSize = 100
+	// fanOutBatch is how many timelines a pipeline writes at once
+	fanOutBatch = 500
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+/********************
+ * REDIS KEY UTILS  *
+ ********************/
+
+// Sorted set of shout IDs scored by their creation time in milliseconds.
+func redisTimelineKey(userID uuid.UUID) string {
+	return fmt.Sprintf("timeline:%s", userID.String())
+}
+
+// Set of the IDs of wide authors.
+const redisWideAuthorsKey = "timeline:wide_authors"
+
+// timelineAddScript adds shouts to a timeline that exists, trimming it to
+// ARGV[1] entries. Missing timelines are left alone: they are built in full
+// when read, and a partial one would be taken for complete.
+var timelineAddScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+for i = 2, #ARGV, 2 do
+	redis.call("ZADD", KEYS[1], ARGV[i], ARGV[i + 1])
+end
+redis.call("ZREMRANGEBYRANK", KEYS[1], 0, -tonumber(ARGV[1]) - 1)
+return 1
+`)
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type timelineService interface {
+	// publish places a new shout or reshout on the timelines.
+	publish(shout *Shout) error
+	followed(followerID, followeeID uuid.UUID) error
+	unfollowed(followerID, followeeID uuid.UUID) error
+
+	home(userID uuid.UUID, cursor string, limit int) (*timelinePage, error)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type timeline struct {
+	repo    timelineRepository
+	follows followRepository
+	redis   *redis.Client
+}
+
+func newTimelineService(repo timelineRepository, follows followRepository, redis *redis.Client) timelineService {
+	return &timeline{repo: repo, follows: follows, redis: redis}
+}
+
+func (s *timeline) publish(shout *Shout) error {
+	ctx := context.Background()
+	entry := []timelineEntry{{ID: shout.ID, CreatedAt: shout.CreatedAt}}
+
+	wide, err := s.wide(ctx, shout.UserID)
+	if err != nil {
+		return err
+	}
+	if wide {
+		// Only the author's own timeline holds it; followers merge it on read
+		return s.add(ctx, []uuid.UUID{shout.UserID}, entry)
+	}
+
+	followerIDs, err := s.follows.followerIDs(shout.UserID)
+	if err != nil {
+		return err
+	}
+	return s.add(ctx, append(followerIDs, shout.UserID), entry)
+}
+
+// followed backfills the timeline of the follower with the recent shouts of
+// the followee.
+func (s *timeline) followed(followerID, followeeID uuid.UUID) error {
+	ctx := context.Background()
+	wide, err := s.wide(ctx, followeeID)
+	if err != nil || wide {
+		return err
+	}
+	entries, err := s.repo.recent([]uuid.UUID{followeeID}, nil, backfillSize)
+	if err != nil {
+		return err
+	}
+	return s.add(ctx, []uuid.UUID{followerID}, entries)
+}
+
+func (s *timeline) unfollowed(followerID, followeeID uuid.UUID) error {
+	ctx := context.Background()
+	entries, err := s.repo.recent([]uuid.UUID{followeeID}, nil, timelineSize)
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+	members := make([]any, len(entries))
+	for i, e := range entries {
+		members[i] = e.ID.String()
+	}
+	return s.redis.ZRem(ctx, redisTimelineKey(followerID), members...).Err()
+}
+
+// home pages through the timeline of the user, from the latest shouts, or
+// from before the cursor the previous page returned.
+func (s *timeline) home(userID uuid.UUID, cursor string, limit int) (*timelinePage, error) {
+	ctx := context.Background()
+	before, err := parseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensure(ctx, userID); err != nil {
+		return nil, err
+	}
+	// One more than the page tells whether there is a next one
+	entries, err := s.stored(ctx, userID, before, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	wideIDs, err := s.wideFollowees(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := s.repo.recent(wideIDs, before, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	entries = merge(entries, merged)
+
+	page := &timelinePage{Shouts: []Shout{}}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		page.NextCursor = formatCursor(entries[limit-1])
+	}
+
+	ids := make([]uuid.UUID, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	shouts, err := s.repo.getByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]Shout, len(shouts))
+	for _, shout := range shouts {
+		byID[shout.ID] = shout
+	}
+
+	var gone []any
+	for _, id := range ids {
+		shout, ok := byID[id]
+		if !ok {
+			gone = append(gone, id.String())
+			continue
+		}
+		page.Shouts = append(page.Shouts, shout)
+	}
+	if len(gone) > 0 {
+		// Deleted shouts leave the timeline as it's read
+		s.redis.ZRem(ctx, redisTimelineKey(userID), gone...)
+	}
+	return page, nil
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// wide reports whether the author is wide, marking them once they reach
+// wideFollowers. Authors stay wide after that: the shouts they posted while
+// wide were never fanned out, so fanning out again would leave a gap.
+func (s *timeline) wide(ctx context.Context, authorID uuid.UUID) (bool, error) {
+	wide, err := s.redis.SIsMember(ctx, redisWideAuthorsKey, authorID.String()).Result()
+	if err != nil || wide {
+		return wide, err
+	}
+	count, err := s.follows.countFollowers(authorID)
+	if err != nil || count < wideFollowers {
+		return false, err
+	}
+	return true, s.redis.SAdd(ctx, redisWideAuthorsKey, authorID.String()).Err()
+}
+
+// wideFollowees lists the wide authors the user follows.
+func (s *timeline) wideFollowees(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	key := redisFollowingKey(userID)
+	n, err := s.redis.Exists(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		if err := fillSet(ctx, s.redis, key, func() ([]uuid.UUID, error) { return s.follows.followingIDs(userID) }); err != nil {
+			return nil, err
+		}
+	}
+
+	members, err := s.redis.SInter(ctx, key, redisWideAuthorsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, 0, len(members))
+	for _, m := range members {
+		if id, err := uuid.Parse(m); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// ensure builds the timeline of the user from the database when Redis has
+// none, and keeps it from lapsing while it's read.
+func (s *timeline) ensure(ctx context.Context, userID uuid.UUID) error {
+	key := redisTimelineKey(userID)
+	if ok, err := s.redis.Expire(ctx, key, timelineTTL).Result(); err != nil || ok {
+		return err
+	}
+
+	followingIDs, err := s.follows.followingIDs(userID)
+	if err != nil {
+		return err
+	}
+	authorIDs := []uuid.UUID{userID}
+	for _, id := range followingIDs {
+		wide, err := s.wide(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !wide {
+			authorIDs = append(authorIDs, id)
+		}
+	}
+	entries, err := s.repo.recent(authorIDs, nil, timelineSize)
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+
+	members := make([]redis.Z, len(entries))
+	for i, e := range entries {
+		members[i] = redis.Z{Score: float64(e.CreatedAt.UnixMilli()), Member: e.ID.String()}
+	}
+	_, err = s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, key, members...)
+		pipe.Expire(ctx, key, timelineTTL)
+		return nil
+	})
+	return err
+}
+
+// stored reads up to limit entries of the timeline of the user from Redis.
+func (s *timeline) stored(ctx context.Context, userID uuid.UUID, before *timelineEntry, limit int) ([]timelineEntry, error) {
+	max := "+inf"
+	if before != nil {
+		max = strconv.FormatInt(before.CreatedAt.UnixMilli(), 10)
+	}
+
+	var entries []timelineEntry
+	for offset := int64(0); len(entries) < limit; {
+		batch, err := s.redis.ZRevRangeByScoreWithScores(ctx, redisTimelineKey(userID), &redis.ZRangeBy{
+			Min: "-inf", Max: max, Offset: offset, Count: int64(limit),
+		}).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, z := range batch {
+			id, err := uuid.Parse(z.Member.(string))
+			if err != nil {
+				continue
+			}
+			e := timelineEntry{ID: id, CreatedAt: time.UnixMilli(int64(z.Score))}
+			// The max is inclusive: skip the cursor and what came before it
+			// in the same millisecond
+			if before == nil || after(*before, e) {
+				entries = append(entries, e)
+			}
+		}
+		if len(batch) < limit {
+			break
+		}
+		offset += int64(len(batch))
+	}
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// add places the entries on the timelines of the users, in batches.
+func (s *timeline) add(ctx context.Context, userIDs []uuid.UUID, entries []timelineEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	args := []any{timelineSize}
+	for _, e := range entries {
+		args = append(args, e.CreatedAt.UnixMilli(), e.ID.String())
+	}
+
+	for start := 0; start < len(userIDs); start += fanOutBatch {
+		batch := userIDs[start:min(start+fanOutBatch, len(userIDs))]
+		pipe := s.redis.Pipeline()
+		for _, id := range batch {
+			timelineAddScript.Eval(ctx, pipe, []string{redisTimelineKey(id)}, args...)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merge combines two lists of entries sorted latest first, without
+// duplicates, into one sorted the same way.
+func merge(a, b []timelineEntry) []timelineEntry {
+	seen := make(map[uuid.UUID]bool, len(a)+len(b))
+	merged := make([]timelineEntry, 0, len(a)+len(b))
+	for _, e := range append(a, b...) {
+		e.CreatedAt = time.UnixMilli(e.CreatedAt.UnixMilli())
+		if !seen[e.ID] {
+			seen[e.ID] = true
+			merged = append(merged, e)
+		}
+	}
+	slices.SortFunc(merged, func(x, y timelineEntry) int {
+		if after(x, y) {
+			return -1
+		}
+		if after(y, x) {
+			return 1
+		}
+		return 0
+	})
+	return merged
+}
+
+// after reports whether e comes after prev on a timeline: it's older, or as
+// old with a lower ID, as Redis orders equal scores.
+func after(prev, e timelineEntry) bool {
+	if !e.CreatedAt.Equal(prev.CreatedAt) {
+		return e.CreatedAt.Before(prev.CreatedAt)
+	}
+	return strings.Compare(e.ID.String(), prev.ID.String()) < 0
+}
+
+// Cursors are the creation time in milliseconds and the ID of the last shout
+// of a page.
+func formatCursor(e timelineEntry) string {
+	return fmt.Sprintf("%d_%s", e.CreatedAt.UnixMilli(), e.ID)
+}
+
+func parseCursor(cursor string) (*timelineEntry, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	ms, id, ok := strings.Cut(cursor, "_")
+	if !ok {
+		return nil, errInvalidCursor
+	}
+	millis, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	return &timelineEntry{ID: parsed, CreatedAt: time.UnixMilli(millis)}, nil
+}
@@ -2,6 +2,7 @@ package snapdex
 
 import (
 	"errors"
+	"pokemon/internal/domains/taglink"
 	"strings"
 	"time"
 
@@ -28,6 +29,9 @@ type Snap struct {
 	Status        string         `json:"status" gorm:"type:text;default:'active'"` // active, flagged, removed
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
+
+	// Hashtags and mentions in the caption, for the client to link
+	Entities *taglink.Entities `json:"entities,omitempty" gorm:"-"`
 }
 
 /****************
@@ -1,6 +1,7 @@
 package snapdex
 
 import (
+	"pokemon/internal/domains/taglink"
 	"pokemon/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -14,6 +15,7 @@ type handler struct {
 	iLikeS snapLikeService
 	iCommentS snapCommentService
 	iCommentLikeS snapCommentLikeService
+	tagger *taglink.Tagger
 }
 
 func NewHandler(db *gorm.DB, redis *redis.Client) handler {
@@ -35,6 +37,7 @@ func NewHandler(db *gorm.DB, redis *redis.Client) handler {
 		iLikeS: likeServ,
 		iCommentS: commentServ,
 		iCommentLikeS: likeCommentServ,
+		tagger: taglink.NewTagger(db, redis),
 	}
 }
 
@@ -58,6 +61,7 @@ func (h *handler) createSnap(c *fiber.Ctx) error {
 	if err := h.s.create(&snap); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
+	h.tag(c, &snap)
 
 	return c.Status(fiber.StatusCreated).JSON(snap)
 }
@@ -89,6 +93,7 @@ func (h *handler) updateSnap(c *fiber.Ctx) error {
 	if err := h.s.update(&snap); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
+	h.tag(c, &snap)
 
 	return c.JSON(snap)
 }
@@ -108,6 +113,7 @@ func (h *handler) deleteSnap(c *fiber.Ctx) error {
 	if err := h.s.delete(id); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
+	h.tagger.Remove(c.Context(), taglink.ContentSnap, id)
 
 	return c.SendStatus(fiber.StatusNoContent)
 }
@@ -168,3 +174,10 @@ func (h *handler) countSnapsByUser(c *fiber.Ctx) error {
 
 	return c.JSON(fiber.Map{"count": count})
 }
+
+// tag stores the hashtags and mentions of the caption of a saved snap.
+func (h *handler) tag(c *fiber.Ctx, snap *Snap) {
+	snap.Entities = h.tagger.Sync(c.Context(), taglink.Ref{
+		Type: taglink.ContentSnap, ID: snap.ID, AuthorID: snap.UserID,
+	}, snap.Caption)
+}
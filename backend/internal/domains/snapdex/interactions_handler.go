@@ -1,6 +1,7 @@
 package snapdex
 
 import (
+	"pokemon/internal/domains/notification"
 	"pokemon/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -26,6 +27,7 @@ func (h *handler) createComment(c *fiber.Ctx) error {
 	if err := h.iCommentS.create(&comment); err != nil {
 		return err
 	}
+	notification.Send(notification.Event{Type: notification.TypeReply, ActorID: userID, SubjectType: notification.SubjectSnap, SubjectID: comment.SnapID, ContentID: &comment.ID})
 
 	return c.Status(fiber.StatusCreated).JSON(comment)
 }
@@ -74,6 +76,12 @@ func (h *handler) updateCommentStatus(c *fiber.Ctx) error {
 		return err
 	}
 
+	// Tell the author their comment was taken down, not that it is back
+	if status != SnapStatus.Active {
+		moderatorID, _ := utils.GetUserIDFromLocals(c)
+		notification.Send(notification.Event{Type: notification.TypeModeration, ActorID: moderatorID, SubjectType: notification.SubjectSnapComment, SubjectID: id, Note: status})
+	}
+
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
@@ -127,6 +135,7 @@ func (h *handler) likeSnap(c *fiber.Ctx) error {
 	if err := h.iLikeS.like(like); err != nil {
 		return err
 	}
+	notification.Send(notification.Event{Type: notification.TypeLike, ActorID: userID, SubjectType: notification.SubjectSnap, SubjectID: snapID})
 
 	return c.SendStatus(fiber.StatusCreated)
 }
@@ -218,6 +227,7 @@ func (h *handler) likeComment(c *fiber.Ctx) error {
 	if err := h.iCommentLikeS.like(like); err != nil {
 		return err
 	}
+	notification.Send(notification.Event{Type: notification.TypeLike, ActorID: userID, SubjectType: notification.SubjectSnapComment, SubjectID: commentID})
 
 	return c.SendStatus(fiber.StatusCreated)
 }
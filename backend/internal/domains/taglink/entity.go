@@ -0,0 +1,115 @@
+package taglink
+
+import (
+	"errors"
+	"pokemon/pkg/events"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of content parsed for hashtags and mentions.
+const (
+	ContentShout        = "shout"
+	ContentShoutComment = "shout_comment"
+	ContentTopicComment = "topic_comment"
+	ContentSnap         = "snap"
+)
+
+var errInvalidTag = errors.New("invalid hashtag")
+
+/********
+ * MAIN *
+ ********/
+
+// ContentHashtag records that a piece of content carries a hashtag, for the
+// hashtag pages. Tags are stored folded to lower case.
+type ContentHashtag struct {
+	ContentType string     `json:"content_type" gorm:"type:varchar(20);primaryKey"`
+	ContentID   uuid.UUID  `json:"content_id" gorm:"type:uuid;primaryKey"`
+	Tag         string     `json:"tag" gorm:"type:varchar(100);primaryKey;index:idx_content_hashtag_tag,priority:1"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty" gorm:"type:uuid"` // shout of a comment, topic of a forum comment
+	AuthorID    uuid.UUID  `json:"author_id" gorm:"type:uuid;not null"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"index:idx_content_hashtag_tag,priority:2,sort:desc"`
+}
+
+// UserMention records that a piece of content mentions a user.
+type UserMention struct {
+	ContentType string     `json:"content_type" gorm:"type:varchar(20);primaryKey"`
+	ContentID   uuid.UUID  `json:"content_id" gorm:"type:uuid;primaryKey"`
+	UserID      uuid.UUID  `json:"user_id" gorm:"type:uuid;primaryKey;index"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty" gorm:"type:uuid"`
+	AuthorID    uuid.UUID  `json:"author_id" gorm:"type:uuid;not null"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Ref identifies a piece of content and its author. ParentID is the shout of
+// a shout comment and the topic of a forum comment.
+type Ref struct {
+	Type     string
+	ID       uuid.UUID
+	ParentID *uuid.UUID
+	AuthorID uuid.UUID
+}
+
+/**********
+ * EVENTS *
+ **********/
+
+// MentionEvent is published when content starts mentioning a user, once per
+// user and content: editing the content doesn't mention them again.
+type MentionEvent struct {
+	UserID      uuid.UUID
+	AuthorID    uuid.UUID
+	ContentType string
+	ContentID   uuid.UUID
+	ParentID    *uuid.UUID
+}
+
+var Mentioned = events.NewTopic[MentionEvent]("mention")
+
+/*************
+ * RESPONSES *
+ *************/
+
+// Entities locates the hashtags and mentions of a text for clients to link
+// them. Start and End count characters (code points) from the start of the
+// text, as dexlink.Match does.
+type Entities struct {
+	Hashtags []HashtagEntity `json:"hashtags"`
+	Mentions []MentionEntity `json:"mentions"`
+}
+
+type HashtagEntity struct {
+	Tag   string `json:"tag"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// MentionEntity is a mention of an existing user; mentions of unknown
+// usernames are left out.
+type MentionEntity struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Start    int       `json:"start"`
+	End      int       `json:"end"`
+}
+
+// Tagged is a visible piece of content carrying a hashtag.
+type Tagged struct {
+	ContentType string     `json:"content_type"`
+	ContentID   uuid.UUID  `json:"content_id"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
+	Text        string     `json:"text"`
+	AuthorID    uuid.UUID  `json:"author_id"`
+	Username    string     `json:"username"`
+	URL         string     `json:"url"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type Trend struct {
+	Tag   string  `json:"tag"`
+	Score float64 `json:"score"`
+}
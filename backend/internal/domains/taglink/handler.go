@@ -0,0 +1,59 @@
+package taglink
+
+import (
+	"errors"
+	"net/url"
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	s service
+}
+
+func NewHandler(db *gorm.DB, redis *redis.Client) *handler {
+	return &handler{s: newServ(newRepo(db), redis)}
+}
+
+// GET /hashtags/trending
+func (h *handler) trending(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 10)
+	if limit <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid limit")
+	}
+
+	trends, err := h.s.trending(c.Context(), limit)
+	if err != nil {
+		return tagError(err)
+	}
+	return c.JSON(trends)
+}
+
+// GET /hashtags/:tag
+func (h *handler) hashtag(c *fiber.Ctx) error {
+	tag, err := url.PathUnescape(c.Params("tag"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid hashtag")
+	}
+
+	limit, offset := utils.ParsePagination(c)
+	list, total, err := h.s.hashtag(c.Context(), tag, limit, offset)
+	if err != nil {
+		return tagError(err)
+	}
+	return c.JSON(fiber.Map{"total": total, "items": list})
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func tagError(err error) error {
+	if errors.Is(err, errInvalidTag) {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
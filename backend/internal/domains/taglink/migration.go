@@ -0,0 +1,12 @@
+package taglink
+
+import "gorm.io/gorm"
+
+type TagLinkMigrator struct{}
+
+func (m TagLinkMigrator) Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&ContentHashtag{},
+		&UserMention{},
+	)
+}
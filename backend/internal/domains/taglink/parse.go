@@ -0,0 +1,99 @@
+package taglink
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	maxTagLength      = 100
+	maxUsernameLength = 20
+)
+
+// token is a hashtag or mention found in text, without its # or @. Start and
+// End count code points and include the sign.
+type token struct {
+	text       string
+	start, end int
+}
+
+// parse finds the hashtags and mentions of a text. A sign only starts one at
+// the start of the text or after a character that can't be part of a word, so
+// e-mail addresses, URL fragments and HTML entities aren't taken for them.
+func parse(text string) (tags, mentions []token) {
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		sign := runes[i]
+		if sign != '#' && sign != '@' {
+			continue
+		}
+		if i > 0 && !opens(runes[i-1]) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isTagRune(runes[end], sign) {
+			end++
+		}
+		if sign == '@' {
+			// Sentence punctuation isn't part of the username
+			for end > i+1 && strings.ContainsRune(".-", runes[end-1]) {
+				end--
+			}
+		}
+		name := string(runes[i+1 : end])
+
+		switch {
+		case sign == '#' && validTag(name):
+			tags = append(tags, token{text: name, start: i, end: end})
+		case sign == '@' && end-i-1 >= 3 && end-i-1 <= maxUsernameLength:
+			mentions = append(mentions, token{text: name, start: i, end: end})
+		}
+		i = end - 1
+	}
+	return tags, mentions
+}
+
+// opens reports whether a hashtag or mention can follow r.
+func opens(r rune) bool {
+	if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) {
+		return false
+	}
+	return !strings.ContainsRune("_&/#@.-", r)
+}
+
+func isTagRune(r, sign rune) bool {
+	if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_' {
+		return true
+	}
+	return sign == '@' && (r == '.' || r == '-')
+}
+
+// validTag accepts tags of up to maxTagLength characters with a letter in
+// them, so #1 or #2024 aren't tags.
+func validTag(tag string) bool {
+	runes := []rune(tag)
+	if len(runes) == 0 || len(runes) > maxTagLength {
+		return false
+	}
+	for _, r := range runes {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeTag folds a tag as it's stored and looked up.
+func normalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	if !validTag(tag) {
+		return "", errInvalidTag
+	}
+	for _, r := range tag {
+		if !isTagRune(r, '#') {
+			return "", errInvalidTag
+		}
+	}
+	return tag, nil
+}
@@ -0,0 +1,144 @@
+package taglink
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		tags     []token
+		mentions []token
+	}{
+		{
+			name: "hashtag",
+			text: "#shiny hunt",
+			tags: []token{{text: "shiny", start: 0, end: 6}},
+		},
+		{
+			name:     "offsets count code points",
+			text:     "Pokémon #ピカチュウ! @ash_k",
+			tags:     []token{{text: "ピカチュウ", start: 8, end: 14}},
+			mentions: []token{{text: "ash_k", start: 16, end: 22}},
+		},
+		{
+			name: "combining marks",
+			text: "#cafe\u0301 time",
+			tags: []token{{text: "cafe\u0301", start: 0, end: 6}},
+		},
+		{
+			name: "non-ASCII tag",
+			text: "a #Pokémon run",
+			tags: []token{{text: "Pokémon", start: 2, end: 10}},
+		},
+		{
+			name:     "after punctuation",
+			text:     "(#shiny), \"@misty\"",
+			tags:     []token{{text: "shiny", start: 1, end: 7}},
+			mentions: []token{{text: "misty", start: 11, end: 17}},
+		},
+		{
+			name: "e-mail address",
+			text: "write to ash@example.com",
+		},
+		{
+			name: "html entities",
+			text: "it&#39;s &#shiny; &#x27;",
+		},
+		{
+			name: "url fragments",
+			text: "see example.com/guide#route-1 or /#top",
+		},
+		{
+			name: "after a word character",
+			text: "a_#shiny b-#shiny c.#shiny 1#shiny",
+		},
+		{
+			name: "doubled signs",
+			text: "##shiny @@misty #@brock",
+		},
+		{
+			name: "numbers only",
+			text: "#1 and #2024 but #gen9",
+			tags: []token{{text: "gen9", start: 17, end: 22}},
+		},
+		{
+			name: "tag stops at punctuation",
+			text: "#shiny-hunt #a.b",
+			tags: []token{{text: "shiny", start: 0, end: 6}, {text: "a", start: 12, end: 14}},
+		},
+		{
+			name:     "mention keeps inner dots and hyphens",
+			text:     "@ash.ketchum-1 hi",
+			mentions: []token{{text: "ash.ketchum-1", start: 0, end: 14}},
+		},
+		{
+			name:     "mention drops trailing dots and hyphens",
+			text:     "thanks @misty. and @brock-- and @gary.-",
+			mentions: []token{{text: "misty", start: 7, end: 13}, {text: "brock", start: 19, end: 25}, {text: "gary", start: 32, end: 37}},
+		},
+		{
+			name: "mention too short",
+			text: "@ab @a.. @x-",
+		},
+		{
+			name:     "mention length limits",
+			text:     "@abc @" + strings.Repeat("a", maxUsernameLength) + " @" + strings.Repeat("b", maxUsernameLength+1),
+			mentions: []token{{text: "abc", start: 0, end: 4}, {text: strings.Repeat("a", maxUsernameLength), start: 5, end: 6 + maxUsernameLength}},
+		},
+		{
+			name: "tag length limit",
+			text: "#" + strings.Repeat("a", maxTagLength) + " #" + strings.Repeat("b", maxTagLength+1),
+			tags: []token{{text: strings.Repeat("a", maxTagLength), start: 0, end: maxTagLength + 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, mentions := parse(tt.text)
+			if !reflect.DeepEqual(tags, tt.tags) {
+				t.Errorf("tags of %q\n got %+v\nwant %+v", tt.text, tags, tt.tags)
+			}
+			if !reflect.DeepEqual(mentions, tt.mentions) {
+				t.Errorf("mentions of %q\n got %+v\nwant %+v", tt.text, mentions, tt.mentions)
+			}
+		})
+	}
+}
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+		ok   bool
+	}{
+		{"shiny", "shiny", true},
+		{" #Shiny ", "shiny", true},
+		{"Gen_9", "gen_9", true},
+		{"Pokémon", "pokémon", true},
+		{"ÉCLAIR", "éclair", true},
+		{"ピカチュウ", "ピカチュウ", true},
+		{strings.Repeat("a", maxTagLength), strings.Repeat("a", maxTagLength), true},
+		{"", "", false},
+		{"#", "", false},
+		{"2024", "", false},
+		{"two words", "", false},
+		{"shiny-hunt", "", false},
+		{"shiny!", "", false},
+		{"##shiny", "", false},
+		{strings.Repeat("a", maxTagLength+1), "", false},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizeTag(tt.tag)
+		if tt.ok && (err != nil || got != tt.want) {
+			t.Errorf("normalizeTag(%q) = %q, %v, want %q", tt.tag, got, err, tt.want)
+		}
+		if !tt.ok && err != errInvalidTag {
+			t.Errorf("normalizeTag(%q) = %q, %v, want errInvalidTag", tt.tag, got, err)
+		}
+	}
+}
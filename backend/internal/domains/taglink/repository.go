@@ -0,0 +1,163 @@
+package taglink
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// account is a user a mention resolves to.
+type account struct {
+	ID       uuid.UUID
+	Username string
+}
+
+type repository interface {
+	// accounts finds the users by username, ignoring case, keyed by the
+	// lower case username.
+	accounts(ctx context.Context, usernames []string) (map[string]account, error)
+	// replace makes tags and userIDs the hashtags and mentions of the content,
+	// returning the ones it didn't have.
+	replace(ctx context.Context, ref Ref, tags []string, userIDs []uuid.UUID) ([]string, []uuid.UUID, error)
+	remove(ctx context.Context, contentType string, id uuid.UUID) error
+	tagged(ctx context.Context, tag string, limit, offset int) ([]Tagged, int64, error)
+}
+
+type repositoryImpl struct {
+	db *gorm.DB
+}
+
+func newRepo(db *gorm.DB) repository {
+	return &repositoryImpl{db: db}
+}
+
+func (r *repositoryImpl) accounts(ctx context.Context, usernames []string) (map[string]account, error) {
+	found := map[string]account{}
+	if len(usernames) == 0 {
+		return found, nil
+	}
+	lower := make([]string, len(usernames))
+	for i, name := range usernames {
+		lower[i] = strings.ToLower(name)
+	}
+
+	var list []account
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Select("id, username").
+		Where("lower(username) IN ? AND deleted_at IS NULL", lower).
+		Scan(&list).Error
+	for _, a := range list {
+		found[strings.ToLower(a.Username)] = a
+	}
+	return found, err
+}
+
+func (r *repositoryImpl) replace(ctx context.Context, ref Ref, tags []string, userIDs []uuid.UUID) ([]string, []uuid.UUID, error) {
+	var addedTags []string
+	var addedUsers []uuid.UUID
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var oldTags []string
+		if err := tx.Model(&ContentHashtag{}).
+			Where("content_type = ? AND content_id = ?", ref.Type, ref.ID).
+			Pluck("tag", &oldTags).Error; err != nil {
+			return err
+		}
+		var oldUsers []uuid.UUID
+		if err := tx.Model(&UserMention{}).
+			Where("content_type = ? AND content_id = ?", ref.Type, ref.ID).
+			Pluck("user_id", &oldUsers).Error; err != nil {
+			return err
+		}
+
+		// Rows that stay keep their creation time, which orders hashtag pages
+		q := tx.Where("content_type = ? AND content_id = ?", ref.Type, ref.ID)
+		if len(tags) > 0 {
+			q = q.Where("tag NOT IN ?", tags)
+		}
+		if err := q.Delete(&ContentHashtag{}).Error; err != nil {
+			return err
+		}
+		q = tx.Where("content_type = ? AND content_id = ?", ref.Type, ref.ID)
+		if len(userIDs) > 0 {
+			q = q.Where("user_id NOT IN ?", userIDs)
+		}
+		if err := q.Delete(&UserMention{}).Error; err != nil {
+			return err
+		}
+
+		var hashtags []ContentHashtag
+		for _, tag := range tags {
+			if !slices.Contains(oldTags, tag) {
+				addedTags = append(addedTags, tag)
+				hashtags = append(hashtags, ContentHashtag{
+					ContentType: ref.Type, ContentID: ref.ID, Tag: tag, ParentID: ref.ParentID, AuthorID: ref.AuthorID,
+				})
+			}
+		}
+		var mentions []UserMention
+		for _, id := range userIDs {
+			if !slices.Contains(oldUsers, id) {
+				addedUsers = append(addedUsers, id)
+				mentions = append(mentions, UserMention{
+					ContentType: ref.Type, ContentID: ref.ID, UserID: id, ParentID: ref.ParentID, AuthorID: ref.AuthorID,
+				})
+			}
+		}
+
+		if len(hashtags) > 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&hashtags).Error; err != nil {
+				return err
+			}
+		}
+		if len(mentions) > 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&mentions).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return addedTags, addedUsers, err
+}
+
+func (r *repositoryImpl) remove(ctx context.Context, contentType string, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&ContentHashtag{}, "content_type = ? AND content_id = ?", contentType, id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&UserMention{}, "content_type = ? AND content_id = ?", contentType, id).Error
+	})
+}
+
+// tagged lists the visible content carrying the tag, latest tagged first.
+func (r *repositoryImpl) tagged(ctx context.Context, tag string, limit, offset int) ([]Tagged, int64, error) {
+	parts := make([]string, 0, len(sources))
+	for _, src := range sources {
+		parts = append(parts, "SELECT '"+src.typ+"' AS content_type, t.id AS content_id, h.parent_id, "+
+			src.text+" AS text, h.author_id, u.username, h.created_at"+
+			" FROM "+src.from+
+			" JOIN content_hashtags h ON h.content_id = t.id AND h.content_type = '"+src.typ+"'"+
+			" JOIN users u ON u.id = h.author_id"+
+			" WHERE h.tag = @tag AND "+src.visible)
+	}
+	union := strings.Join(parts, " UNION ALL ")
+	args := []any{sql.Named("tag", tag)}
+
+	db := r.db.WithContext(ctx)
+	var total int64
+	if err := db.Raw("SELECT COUNT(*) FROM ("+union+") u", args...).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var list []Tagged
+	err := db.Raw("SELECT * FROM ("+union+") u ORDER BY u.created_at DESC, u.content_id LIMIT @limit OFFSET @offset",
+		append(args, sql.Named("limit", limit), sql.Named("offset", offset))...).
+		Scan(&list).Error
+	return list, total, err
+}
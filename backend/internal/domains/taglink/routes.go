@@ -0,0 +1,10 @@
+package taglink
+
+import "github.com/gofiber/fiber/v2"
+
+func (h *handler) RegisterRoutes(router fiber.Router) {
+	group := router.Group("/hashtags")
+
+	group.Get("/trending", h.trending)
+	group.Get("/:tag", h.hashtag)
+}
@@ -0,0 +1,189 @@
+package taglink
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Trending ranks the tags used over the last trendingWindow hours, counting
+// each piece of content once. Recent hours weigh more, so a tag fades out of
+// the ranking as its hour slides back.
+const (
+	trendingWindow = 24
+	trendingSize   = 50
+	trendingTTL    = 5 * time.Minute
+)
+
+const redisTrendingKey = "hashtags:trending"
+
+// Sorted set of the tags used in an hour, by pieces of content.
+func redisTrendingHourKey(hour time.Time) string {
+	return redisTrendingKey + ":" + hour.UTC().Format("2006010215")
+}
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type service interface {
+	find(ctx context.Context, text string) (*Entities, error)
+	sync(ctx context.Context, ref Ref, text string) (*Entities, error)
+	remove(ctx context.Context, contentType string, id uuid.UUID) error
+
+	hashtag(ctx context.Context, tag string, limit, offset int) ([]Tagged, int64, error)
+	trending(ctx context.Context, limit int) ([]Trend, error)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type serviceImpl struct {
+	repo  repository
+	redis *redis.Client
+}
+
+func newServ(repo repository, redis *redis.Client) service {
+	return &serviceImpl{repo: repo, redis: redis}
+}
+
+// find locates the hashtags of the text and its mentions of existing users.
+func (s *serviceImpl) find(ctx context.Context, text string) (*Entities, error) {
+	tags, mentions := parse(text)
+	entities := &Entities{Hashtags: []HashtagEntity{}, Mentions: []MentionEntity{}}
+	for _, t := range tags {
+		entities.Hashtags = append(entities.Hashtags, HashtagEntity{Tag: strings.ToLower(t.text), Start: t.start, End: t.end})
+	}
+	if len(mentions) == 0 {
+		return entities, nil
+	}
+
+	names := make([]string, len(mentions))
+	for i, m := range mentions {
+		names[i] = m.text
+	}
+	accounts, err := s.repo.accounts(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mentions {
+		if a, ok := accounts[strings.ToLower(m.text)]; ok {
+			entities.Mentions = append(entities.Mentions, MentionEntity{UserID: a.ID, Username: a.Username, Start: m.start, End: m.end})
+		}
+	}
+	return entities, nil
+}
+
+// sync stores the hashtags and mentions of the content as its text now has
+// them. Newly used tags count towards trending, and newly mentioned users
+// other than the author are told with a MentionEvent.
+func (s *serviceImpl) sync(ctx context.Context, ref Ref, text string) (*Entities, error) {
+	entities, err := s.find(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	for _, h := range entities.Hashtags {
+		if !slices.Contains(tags, h.Tag) {
+			tags = append(tags, h.Tag)
+		}
+	}
+	var userIDs []uuid.UUID
+	for _, m := range entities.Mentions {
+		if !slices.Contains(userIDs, m.UserID) {
+			userIDs = append(userIDs, m.UserID)
+		}
+	}
+
+	addedTags, addedUsers, err := s.repo.replace(ctx, ref, tags, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(addedTags) > 0 {
+		key := redisTrendingHourKey(time.Now())
+		_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, tag := range addedTags {
+				pipe.ZIncrBy(ctx, key, 1, tag)
+			}
+			pipe.Expire(ctx, key, (trendingWindow+1)*time.Hour)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range addedUsers {
+		if id != ref.AuthorID {
+			Mentioned.Publish(MentionEvent{
+				UserID: id, AuthorID: ref.AuthorID, ContentType: ref.Type, ContentID: ref.ID, ParentID: ref.ParentID,
+			})
+		}
+	}
+	return entities, nil
+}
+
+func (s *serviceImpl) remove(ctx context.Context, contentType string, id uuid.UUID) error {
+	return s.repo.remove(ctx, contentType, id)
+}
+
+func (s *serviceImpl) hashtag(ctx context.Context, tag string, limit, offset int) ([]Tagged, int64, error) {
+	tag, err := normalizeTag(tag)
+	if err != nil {
+		return nil, 0, err
+	}
+	list, total, err := s.repo.tagged(ctx, tag, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range list {
+		src, _ := sourceByType(list[i].ContentType)
+		list[i].URL = src.url(list[i].ContentID, list[i].ParentID)
+	}
+	return list, total, nil
+}
+
+func (s *serviceImpl) trending(ctx context.Context, limit int) ([]Trend, error) {
+	limit = min(limit, trendingSize)
+
+	var trends []Trend
+	if cached, err := s.redis.Get(ctx, redisTrendingKey).Bytes(); err == nil && json.Unmarshal(cached, &trends) == nil {
+		return trends[:min(limit, len(trends))], nil
+	}
+
+	now := time.Now()
+	store := redis.ZStore{Aggregate: "SUM"}
+	for age := 0; age < trendingWindow; age++ {
+		store.Keys = append(store.Keys, redisTrendingHourKey(now.Add(-time.Duration(age)*time.Hour)))
+		store.Weights = append(store.Weights, float64(trendingWindow-age)/trendingWindow)
+	}
+	scores, err := s.redis.ZUnionWithScores(ctx, store).Result()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(scores, func(a, b redis.Z) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Member.(string), b.Member.(string))
+	})
+
+	trends = make([]Trend, 0, trendingSize)
+	for _, z := range scores[:min(trendingSize, len(scores))] {
+		trends = append(trends, Trend{Tag: z.Member.(string), Score: z.Score})
+	}
+	if data, err := json.Marshal(trends); err == nil {
+		s.redis.Set(ctx, redisTrendingKey, data, trendingTTL)
+	}
+	return trends[:min(limit, len(trends))], nil
+}
@@ -0,0 +1,66 @@
+package taglink
+
+import (
+	"pokemon/pkg/permalink"
+
+	"github.com/google/uuid"
+)
+
+// source describes how the content of one type is listed on hashtag pages.
+// Its rows in from are aliased as t; visible is the rule they must pass to be
+// listed.
+type source struct {
+	typ     string
+	from    string
+	text    string
+	visible string
+	url     func(id uuid.UUID, parentID *uuid.UUID) string
+}
+
+var sources = []source{
+	{
+		typ:     ContentShout,
+		from:    "shouts t",
+		text:    "t.content",
+		visible: "t.deleted_at IS NULL AND NOT t.is_flagged",
+		url: func(id uuid.UUID, _ *uuid.UUID) string {
+			return permalink.Shout("", id)
+		},
+	},
+	{
+		typ:     ContentShoutComment,
+		from:    "shout_comments t JOIN shouts p ON p.id = t.shout_id",
+		text:    "t.content",
+		visible: "NOT t.is_flagged AND p.deleted_at IS NULL AND NOT p.is_flagged",
+		url: func(id uuid.UUID, parentID *uuid.UUID) string {
+			return permalink.ShoutComment("", *parentID, id)
+		},
+	},
+	{
+		typ:     ContentTopicComment,
+		from:    "topic_comments t JOIN topics p ON p.id = t.topic_id",
+		text:    "t.content",
+		visible: "t.deleted_at IS NULL AND p.deleted_at IS NULL",
+		url: func(id uuid.UUID, parentID *uuid.UUID) string {
+			return permalink.TopicComment("", *parentID, id)
+		},
+	},
+	{
+		typ:     ContentSnap,
+		from:    "snaps t",
+		text:    "t.caption",
+		visible: "t.status = 'active'",
+		url: func(id uuid.UUID, _ *uuid.UUID) string {
+			return permalink.Snap("", id)
+		},
+	},
+}
+
+func sourceByType(typ string) (source, bool) {
+	for _, s := range sources {
+		if s.typ == typ {
+			return s, true
+		}
+	}
+	return source{}, false
+}
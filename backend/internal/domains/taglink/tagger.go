@@ -0,0 +1,49 @@
+package taglink
+
+import (
+	"context"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Tagger keeps the hashtags and mentions of the content of other domains:
+// they call Sync when the content is created or edited and Remove when it's
+// deleted. The content is saved by then, so failures only log; editing the
+// content again retries.
+type Tagger struct {
+	s service
+}
+
+func NewTagger(db *gorm.DB, redis *redis.Client) *Tagger {
+	return &Tagger{s: newServ(newRepo(db), redis)}
+}
+
+// Sync stores the hashtags and mentions of the content and returns them, or
+// nil when that failed.
+func (t *Tagger) Sync(ctx context.Context, ref Ref, text string) *Entities {
+	entities, err := t.s.sync(ctx, ref, text)
+	if err != nil {
+		log.Printf("hashtag and mention indexing failed for %s %s: %v", ref.Type, ref.ID, err)
+	}
+	return entities
+}
+
+// Remove forgets the hashtags and mentions of deleted content.
+func (t *Tagger) Remove(ctx context.Context, contentType string, id uuid.UUID) {
+	if err := t.s.remove(ctx, contentType, id); err != nil {
+		log.Printf("hashtag and mention removal failed for %s %s: %v", contentType, id, err)
+	}
+}
+
+// Find returns the hashtags and mentions of the text without storing them,
+// for content being shown, or nil when they couldn't be resolved.
+func (t *Tagger) Find(ctx context.Context, text string) *Entities {
+	entities, err := t.s.find(ctx, text)
+	if err != nil {
+		log.Printf("mention lookup failed: %v", err)
+	}
+	return entities
+}
@@ -1,6 +1,7 @@
 package team
 
 import (
+	"pokemon/internal/domains/notification"
 	"pokemon/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -26,6 +27,7 @@ func (h *handler) saveTeam(c *fiber.Ctx) error {
 	if err := h.i.saveTeam(userID, teamID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	notification.Send(notification.Event{Type: notification.TypeSave, ActorID: userID, SubjectType: notification.SubjectTeam, SubjectID: teamID})
 
 	return c.SendStatus(fiber.StatusCreated)
 }
@@ -119,6 +121,12 @@ func (h *handler) commentTeam(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	reply := notification.Event{Type: notification.TypeReply, ActorID: userID, SubjectType: notification.SubjectTeam, SubjectID: teamID}
+	if body.ParentID != nil {
+		reply.SubjectType, reply.SubjectID = notification.SubjectTeamComment, *body.ParentID
+	}
+	notification.Send(reply)
+
 	return c.SendStatus(fiber.StatusCreated)
 }
 
@@ -225,6 +233,7 @@ func (h *handler) likeTeam(c *fiber.Ctx) error {
 	if err := h.i.likeTeam(userID, teamID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	notification.Send(notification.Event{Type: notification.TypeLike, ActorID: userID, SubjectType: notification.SubjectTeam, SubjectID: teamID})
 
 	return c.SendStatus(fiber.StatusCreated)
 }
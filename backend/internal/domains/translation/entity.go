@@ -0,0 +1,183 @@
+package translation
+
+import (
+	"errors"
+	"pokemon/internal/domains/user"
+	"pokemon/pkg/content"
+	"pokemon/pkg/language"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of content that can be translated.
+const (
+	ContentGuide = "guide"
+	ContentNews  = "news"
+	ContentPost  = "post"
+)
+
+// Translation statuses. Drafts are only shown to the translator and the
+// reviewers of the content (its author and moderators).
+const (
+	StatusDraft     = "draft"
+	StatusPublished = "published"
+)
+
+var (
+	errUnknownType     = errors.New("content_type must be guide, news or post")
+	errContentNotFound = errors.New("content not found")
+	errSourceLanguage  = errors.New("the content is already written in this language")
+	errNotAllowed      = errors.New("only the translator, the author or a moderator can change this translation")
+	errBadRevision     = errors.New("source_revision must be a revision of the content")
+)
+
+/********
+ * MAIN *
+ ********/
+
+// Translation is a language variant of a guide, news or post. It records the
+// source revision it was translated from: once the source changes, it is
+// outdated until a translator brings it up to date.
+type Translation struct {
+	ID           uuid.UUID         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ContentType  string            `gorm:"type:varchar(20);not null;uniqueIndex:idx_translation_variant" json:"content_type"`
+	ContentID    uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex:idx_translation_variant" json:"content_id"`
+	Language     string            `gorm:"type:varchar(10);not null;uniqueIndex:idx_translation_variant;index" json:"language"`
+	Title        string            `gorm:"type:text;not null" json:"title"`
+	Summary      string            `gorm:"type:text" json:"summary"` // guide summary or news subtitle
+	Body         string            `gorm:"type:text;not null" json:"body"`
+	Rendered     *content.Rendered `gorm:"-" json:"rendered,omitempty"`
+	Status       string            `gorm:"type:varchar(20);not null;default:'draft'" json:"status"`
+	TranslatorID uuid.UUID         `gorm:"type:uuid;not null;index" json:"translator_id"`
+	Translator   user.User         `gorm:"foreignKey:TranslatorID" json:"translator"`
+
+	SourceRevision  int  `gorm:"not null" json:"source_revision"` // revision of the source it translates
+	CurrentRevision int  `gorm:"-" json:"current_revision"`       // latest revision of the source
+	Outdated        bool `gorm:"-" json:"outdated"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SourceRevision is a snapshot of the translatable text of a content item,
+// numbered from 1 every time the text changes, so translators can see what
+// changed since their translation. Only news and posts get them: guides keep
+// revisions of their own, and their translations use those numbers.
+type SourceRevision struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ContentType string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_translation_source_revision" json:"content_type"`
+	ContentID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_translation_source_revision" json:"content_id"`
+	Number      int       `gorm:"not null;uniqueIndex:idx_translation_source_revision" json:"number"`
+	Hash        string    `gorm:"type:varchar(32);not null" json:"-"` // md5 of the text
+	Title       string    `gorm:"type:text;not null" json:"title"`
+	Summary     string    `gorm:"type:text" json:"summary"`
+	Body        string    `gorm:"type:text;not null" json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+/************
+ * REQUESTS *
+ ************/
+
+type saveRequest struct {
+	Title          string `json:"title"`
+	Summary        string `json:"summary"`
+	Body           string `json:"body"`
+	Status         string `json:"status"`          // draft unless a reviewer publishes
+	SourceRevision int    `json:"source_revision"` // defaults to the latest revision
+}
+
+type queueFilter struct {
+	Language       string
+	ContentType    string
+	IncludeMissing bool // also list live content with no translation in Language
+	Limit, Offset  int
+}
+
+/*************
+ * RESPONSES *
+ *************/
+
+// Variant tells readers which language they got and which others exist.
+// Its text is set when a translation is served instead of the source.
+type Variant struct {
+	Language       string   `json:"language"`
+	SourceLanguage string   `json:"source_language"`
+	Available      []string `json:"available"`          // source language first
+	Outdated       bool     `json:"outdated,omitempty"` // the source changed since it was translated
+
+	Title    string            `json:"-"`
+	Summary  string            `json:"-"`
+	Body     string            `json:"-"`
+	Rendered *content.Rendered `json:"-"`
+}
+
+// Translated reports whether a translation is served.
+func (v *Variant) Translated() bool {
+	return v.Language != v.SourceLanguage
+}
+
+// Overview lists the translations of one content item.
+type Overview struct {
+	ContentType     string        `json:"content_type"`
+	ContentID       uuid.UUID     `json:"content_id"`
+	SourceLanguage  string        `json:"source_language"`
+	CurrentRevision int           `json:"current_revision"`
+	Missing         []string      `json:"missing"` // supported languages with no translation
+	Translations    []Translation `json:"translations"`
+}
+
+// Queue reasons.
+const (
+	ReasonOutdated = "outdated"
+	ReasonMissing  = "missing"
+)
+
+// QueueItem is a translation that needs work, most behind first.
+type QueueItem struct {
+	ContentType        string     `json:"content_type"`
+	ContentID          uuid.UUID  `json:"content_id"`
+	Title              string     `json:"title"` // of the source
+	SourceLanguage     string     `json:"source_language"`
+	Language           string     `json:"language"`
+	Reason             string     `json:"reason"`
+	TranslationID      *uuid.UUID `json:"translation_id,omitempty"`
+	TranslatorID       *uuid.UUID `json:"translator_id,omitempty"`
+	TranslatedRevision int        `json:"translated_revision"`
+	CurrentRevision    int        `json:"current_revision"`
+	SourceUpdatedAt    time.Time  `json:"source_updated_at"`
+}
+
+/***************
+ * VALIDATIONS *
+ ***************/
+
+func (r *saveRequest) validate() error {
+	r.Title, r.Summary, r.Body = strings.TrimSpace(r.Title), strings.TrimSpace(r.Summary), strings.TrimSpace(r.Body)
+	if r.Title == "" {
+		return errors.New("title is required")
+	}
+	if r.Body == "" {
+		return errors.New("body is required")
+	}
+	switch r.Status {
+	case "":
+		r.Status = StatusDraft
+	case StatusDraft, StatusPublished:
+	default:
+		return errors.New("status must be draft or published")
+	}
+	if r.SourceRevision < 0 {
+		return errBadRevision
+	}
+	return nil
+}
+
+func validLanguage(lang string) (string, error) {
+	if l := language.Normalize(lang); l != "" {
+		return l, nil
+	}
+	return "", language.ErrUnsupported
+}
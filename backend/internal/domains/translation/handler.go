@@ -0,0 +1,150 @@
+package translation
+
+import (
+	"errors"
+	"pokemon/internal/domains/dexlink"
+	"pokemon/internal/domains/user"
+	"pokemon/pkg/language"
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	s service
+}
+
+func NewHandler(db *gorm.DB, redis *redis.Client) *handler {
+	return &handler{s: newServ(newRepo(db), dexlink.NewRenderer(db, redis))}
+}
+
+// GET /translations/:content_type/:id
+func (h *handler) overview(c *fiber.Ctx) error {
+	contentType, contentID, err := parseContent(c)
+	if err != nil {
+		return err
+	}
+	o, err := h.s.overview(c.Context(), contentType, contentID, currentViewer(c))
+	if err != nil {
+		return translationError(err)
+	}
+	return c.JSON(o)
+}
+
+// GET /translations/:content_type/:id/:lang
+func (h *handler) get(c *fiber.Ctx) error {
+	contentType, contentID, err := parseContent(c)
+	if err != nil {
+		return err
+	}
+	t, err := h.s.get(c.Context(), contentType, contentID, c.Params("lang"), currentViewer(c))
+	if err != nil {
+		return translationError(err)
+	}
+	return c.JSON(t)
+}
+
+// PUT /translations/:content_type/:id/:lang
+func (h *handler) save(c *fiber.Ctx) error {
+	contentType, contentID, err := parseContent(c)
+	if err != nil {
+		return err
+	}
+	var req saveRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+	if err := req.validate(); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	t, err := h.s.save(c.Context(), contentType, contentID, c.Params("lang"), req, currentViewer(c))
+	if err != nil {
+		return translationError(err)
+	}
+	return c.JSON(t)
+}
+
+// DELETE /translations/:content_type/:id/:lang
+func (h *handler) delete(c *fiber.Ctx) error {
+	contentType, contentID, err := parseContent(c)
+	if err != nil {
+		return err
+	}
+	if err := h.s.delete(c.Context(), contentType, contentID, c.Params("lang"), currentViewer(c)); err != nil {
+		return translationError(err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GET /translations/:content_type/:id/revisions/:number
+func (h *handler) revision(c *fiber.Ctx) error {
+	contentType, contentID, err := parseContent(c)
+	if err != nil {
+		return err
+	}
+	number, err := c.ParamsInt("number")
+	if err != nil || number < 1 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid revision number")
+	}
+	rev, err := h.s.revision(c.Context(), contentType, contentID, number, currentViewer(c))
+	if err != nil {
+		return translationError(err)
+	}
+	return c.JSON(rev)
+}
+
+// GET /translations/queue?lang=es&content_type=guide&include_missing=true
+func (h *handler) queue(c *fiber.Ctx) error {
+	limit, offset := utils.ParsePagination(c)
+	items, total, err := h.s.queue(c.Context(), queueFilter{
+		Language:       c.Query("lang"),
+		ContentType:    c.Query("content_type"),
+		IncludeMissing: c.QueryBool("include_missing"),
+		Limit:          limit,
+		Offset:         offset,
+	})
+	if err != nil {
+		return translationError(err)
+	}
+	return c.JSON(fiber.Map{"total": total, "items": items})
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func parseContent(c *fiber.Ctx) (string, uuid.UUID, error) {
+	contentType := c.Params("content_type")
+	if _, ok := contentSources[contentType]; !ok {
+		return "", uuid.Nil, fiber.NewError(fiber.StatusBadRequest, errUnknownType.Error())
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return "", uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+	return contentType, id, nil
+}
+
+func currentViewer(c *fiber.Ctx) viewer {
+	id, _ := utils.GetUserIDFromLocals(c)
+	return viewer{id: id, moderator: id != uuid.Nil && utils.HasRole(c, user.RoleModerator, user.RoleAdmin)}
+}
+
+func translationError(err error) error {
+	switch {
+	case isNotFound(err):
+		return fiber.NewError(fiber.StatusNotFound, "translation not found")
+	case errors.Is(err, errContentNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, errNotAllowed):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, errSourceLanguage):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, errUnknownType), errors.Is(err, errBadRevision), errors.Is(err, language.ErrUnsupported):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
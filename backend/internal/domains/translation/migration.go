@@ -0,0 +1,52 @@
+package translation
+
+import (
+	"database/sql"
+
+	"gorm.io/gorm"
+)
+
+type TranslationMigrator struct{}
+
+func (m TranslationMigrator) Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(
+		&Translation{},
+		&SourceRevision{},
+	); err != nil {
+		return err
+	}
+	return moveGuideRevisions(db)
+}
+
+// moveGuideRevisions points guide translations made before they used guide
+// revisions at the guide revision with the text they were translated from,
+// then drops the source revisions recorded for guides. A guide never edited
+// since revisions exist is at revision 1; translations whose text matches no
+// revision are left at 0, so they show as outdated.
+func moveGuideRevisions(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			UPDATE translations tr SET source_revision = COALESCE(
+				(
+					SELECT MAX(g.number) FROM source_revisions s
+					JOIN game_guide_revisions g ON g.guide_id = s.content_id
+						AND g.title = s.title AND g.summary = s.summary AND g.content = s.body
+					WHERE s.content_type = @type AND s.content_id = tr.content_id AND s.number = tr.source_revision
+				),
+				(
+					SELECT 1 FROM source_revisions s
+					JOIN game_guides t ON t.id = s.content_id
+						AND t.title = s.title AND coalesce(t.summary, '') = s.summary AND t.content = s.body
+					WHERE s.content_type = @type AND s.content_id = tr.content_id AND s.number = tr.source_revision
+						AND NOT EXISTS (SELECT 1 FROM game_guide_revisions g WHERE g.guide_id = t.id)
+				),
+				0)
+			WHERE tr.content_type = @type AND EXISTS (
+				SELECT 1 FROM source_revisions s WHERE s.content_type = @type AND s.content_id = tr.content_id
+			)`, sql.Named("type", ContentGuide)).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("content_type = ?", ContentGuide).Delete(&SourceRevision{}).Error
+	})
+}
@@ -0,0 +1,338 @@
+package translation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"pokemon/pkg/publishing"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// contentSource tells where the content of a type lives and what of it is
+// translated. Its rows in from are aliased as t; exists leaves out deleted
+// rows and live unpublished ones. Types with revisions of their own set
+// revisions; the others get source revisions recorded for them.
+type contentSource struct {
+	from      string
+	title     string
+	summary   string
+	body      string
+	author    string
+	exists    string
+	live      string
+	revisions *revisionTable
+}
+
+// revisionTable is where a type keeps its own numbered revisions, with the
+// translated text in its title, summary and body columns.
+type revisionTable struct {
+	table     string
+	contentID string
+	body      string
+}
+
+var contentSources = map[string]contentSource{
+	ContentGuide: {
+		from:    "game_guides t",
+		title:   "t.title",
+		summary: "coalesce(t.summary, '')",
+		body:    "t.content",
+		author:  "t.author_id",
+		exists:  "TRUE",
+		live:    publishing.LiveCondition("t"),
+		revisions: &revisionTable{
+			table:     "game_guide_revisions",
+			contentID: "guide_id",
+			body:      "content",
+		},
+	},
+	ContentNews: {
+		from:    "news t",
+		title:   "t.title",
+		summary: "t.sub_title",
+		body:    "t.body",
+		author:  "t.user_id",
+		exists:  "t.deleted_at IS NULL",
+		live:    publishing.LiveCondition("t"),
+	},
+	ContentPost: {
+		from:    "posts t",
+		title:   "t.title",
+		summary: "''",
+		body:    "t.content",
+		author:  "t.user_id",
+		exists:  "t.deleted_at IS NULL",
+		live:    publishing.LiveCondition("t"),
+	},
+}
+
+// hash identifies the version of the text of a source row.
+func (src contentSource) hash() string {
+	return "md5(" + src.title + " || E'\\n' || " + src.summary + " || E'\\n' || " + src.body + ")"
+}
+
+// latestRevision is an SQL expression for the current revision of the row
+// with id contentID. For types with their own revisions, that is the last one
+// that changed the text: edits of anything else don't outdate translations.
+// Guides edited before revisions existed are at revision 1, the number their
+// original text gets on their next edit.
+func (src contentSource) latestRevision(contentType, contentID string) string {
+	if rt := src.revisions; rt != nil {
+		text := func(alias string) string {
+			return "(" + alias + ".title, " + alias + ".summary, " + alias + "." + rt.body + ")"
+		}
+		return `COALESCE((
+			SELECT MAX(r.number) FROM ` + rt.table + ` r
+			LEFT JOIN ` + rt.table + ` p ON p.` + rt.contentID + ` = r.` + rt.contentID + ` AND p.number = r.number - 1
+			WHERE r.` + rt.contentID + ` = ` + contentID + ` AND (p.id IS NULL OR ` + text("p") + ` IS DISTINCT FROM ` + text("r") + `)
+		), 1)`
+	}
+	return `(
+			SELECT MAX(number) FROM source_revisions r
+			WHERE r.content_type = '` + contentType + `' AND r.content_id = ` + contentID + `
+		)`
+}
+
+// sourceInfo is what translations need to know about their source.
+type sourceInfo struct {
+	ID        uuid.UUID
+	AuthorID  uuid.UUID
+	Language  string
+	Title     string
+	Live      bool
+	UpdatedAt time.Time
+}
+
+type repository interface {
+	source(ctx context.Context, contentType string, contentID uuid.UUID) (*sourceInfo, error)
+	sync(ctx context.Context, contentType string, contentID *uuid.UUID) error
+	currentRevision(ctx context.Context, contentType string, contentID uuid.UUID) (int, error)
+	revision(ctx context.Context, contentType string, contentID uuid.UUID, number int) (*SourceRevision, error)
+
+	list(ctx context.Context, contentType string, contentID uuid.UUID, publishedOnly bool) ([]Translation, error)
+	get(ctx context.Context, contentType string, contentID uuid.UUID, lang string) (*Translation, error)
+	save(ctx context.Context, t *Translation) error
+	delete(ctx context.Context, id uuid.UUID) error
+
+	queue(ctx context.Context, f queueFilter) ([]QueueItem, int64, error)
+}
+
+type repositoryImpl struct {
+	db *gorm.DB
+}
+
+func newRepo(db *gorm.DB) repository {
+	return &repositoryImpl{db: db}
+}
+
+func (r *repositoryImpl) source(ctx context.Context, contentType string, contentID uuid.UUID) (*sourceInfo, error) {
+	src, ok := contentSources[contentType]
+	if !ok {
+		return nil, errUnknownType
+	}
+
+	var rows []sourceInfo
+	err := r.db.WithContext(ctx).
+		Raw("SELECT t.id, "+src.author+" AS author_id, t.language, t.title, "+src.live+" AS live, t.updated_at"+
+			" FROM "+src.from+" WHERE t.id = ? AND "+src.exists, contentID).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errContentNotFound
+	}
+	return &rows[0], nil
+}
+
+// sync records a new source revision for every row whose text changed since
+// its last one: the row contentID, or every translated row of the type when
+// it is nil. Untranslated content and types with their own revisions aren't
+// tracked.
+func (r *repositoryImpl) sync(ctx context.Context, contentType string, contentID *uuid.UUID) error {
+	src, ok := contentSources[contentType]
+	if !ok {
+		return errUnknownType
+	}
+	if src.revisions != nil {
+		return nil
+	}
+
+	scope := "t.id IN (SELECT content_id FROM translations WHERE content_type = @type)"
+	args := []any{sql.Named("type", contentType)}
+	if contentID != nil {
+		scope = "t.id = @id"
+		args = append(args, sql.Named("id", *contentID))
+	}
+	return r.db.WithContext(ctx).Exec(`
+		INSERT INTO source_revisions
+			(id, content_type, content_id, number, hash, title, summary, body, created_at)
+		SELECT gen_random_uuid(), @type, t.id, coalesce(r.number, 0) + 1, `+src.hash()+`,
+			`+src.title+`, `+src.summary+`, `+src.body+`, now()
+		FROM `+src.from+`
+		LEFT JOIN LATERAL (
+			SELECT number, hash FROM source_revisions r
+			WHERE r.content_type = @type AND r.content_id = t.id
+			ORDER BY number DESC LIMIT 1
+		) r ON TRUE
+		WHERE `+scope+` AND (r.hash IS NULL OR r.hash <> `+src.hash()+`)
+		ON CONFLICT DO NOTHING`, args...).Error
+}
+
+func (r *repositoryImpl) currentRevision(ctx context.Context, contentType string, contentID uuid.UUID) (int, error) {
+	src, ok := contentSources[contentType]
+	if !ok {
+		return 0, errUnknownType
+	}
+
+	var number int
+	err := r.db.WithContext(ctx).
+		Raw("SELECT COALESCE("+src.latestRevision(contentType, "?")+", 0)", contentID).
+		Scan(&number).Error
+	return number, err
+}
+
+// revision returns the text of a revision of the content. Types with their
+// own revisions read it from there; revision 1 of a guide never edited since
+// revisions exist is its current text.
+func (r *repositoryImpl) revision(ctx context.Context, contentType string, contentID uuid.UUID, number int) (*SourceRevision, error) {
+	src, ok := contentSources[contentType]
+	if !ok {
+		return nil, errUnknownType
+	}
+
+	var rev SourceRevision
+	rt := src.revisions
+	if rt == nil {
+		err := r.db.WithContext(ctx).
+			Where("content_type = ? AND content_id = ? AND number = ?", contentType, contentID, number).
+			First(&rev).Error
+		return &rev, err
+	}
+
+	var rows []SourceRevision
+	err := r.db.WithContext(ctx).
+		Raw("SELECT r.id, r.number, r.title, r.summary, r."+rt.body+" AS body, r.created_at"+
+			" FROM "+rt.table+" r WHERE r."+rt.contentID+" = ? AND r.number = ?", contentID, number).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 && number == 1 {
+		err = r.db.WithContext(ctx).
+			Raw("SELECT 1 AS number, "+src.title+" AS title, "+src.summary+" AS summary, "+src.body+" AS body, t.updated_at AS created_at"+
+				" FROM "+src.from+" WHERE t.id = ? AND "+src.exists+
+				" AND NOT EXISTS (SELECT 1 FROM "+rt.table+" r WHERE r."+rt.contentID+" = t.id)", contentID).
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(rows) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	rev = rows[0]
+	rev.ContentType, rev.ContentID = contentType, contentID
+	return &rev, nil
+}
+
+func (r *repositoryImpl) list(ctx context.Context, contentType string, contentID uuid.UUID, publishedOnly bool) ([]Translation, error) {
+	var list []Translation
+	q := r.db.WithContext(ctx).Preload("Translator").
+		Where("content_type = ? AND content_id = ?", contentType, contentID)
+	if publishedOnly {
+		q = q.Where("status = ?", StatusPublished)
+	}
+	err := q.Order("language").Find(&list).Error
+	return list, err
+}
+
+func (r *repositoryImpl) get(ctx context.Context, contentType string, contentID uuid.UUID, lang string) (*Translation, error) {
+	var t Translation
+	err := r.db.WithContext(ctx).Preload("Translator").
+		Where("content_type = ? AND content_id = ? AND language = ?", contentType, contentID, lang).
+		First(&t).Error
+	return &t, err
+}
+
+func (r *repositoryImpl) save(ctx context.Context, t *Translation) error {
+	if t.ID == uuid.Nil {
+		return r.db.WithContext(ctx).Omit("Translator").Create(t).Error
+	}
+	return r.db.WithContext(ctx).Omit("Translator").Save(t).Error
+}
+
+func (r *repositoryImpl) delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&Translation{}, "id = ?", id).Error
+}
+
+// queue lists the translations behind their source, most revisions behind
+// first, then with f.IncludeMissing the live content not yet translated in
+// f.Language, most recently updated first.
+func (r *repositoryImpl) queue(ctx context.Context, f queueFilter) ([]QueueItem, int64, error) {
+	var parts []string
+	for _, contentType := range []string{ContentGuide, ContentNews, ContentPost} {
+		if f.ContentType != "" && f.ContentType != contentType {
+			continue
+		}
+		src := contentSources[contentType]
+		typ := "'" + contentType + "'"
+		latest := `LEFT JOIN LATERAL (
+				SELECT ` + src.latestRevision(contentType, "t.id") + ` AS number
+			) r ON TRUE`
+
+		outdated := `
+			SELECT ` + typ + ` AS content_type, t.id AS content_id, t.title, t.language AS source_language,
+				tr.language, '` + ReasonOutdated + `' AS reason, tr.id AS translation_id, tr.translator_id,
+				tr.source_revision AS translated_revision, r.number AS current_revision, t.updated_at AS source_updated_at
+			FROM translations tr
+			JOIN ` + src.from + ` ON t.id = tr.content_id
+			` + latest + `
+			WHERE tr.content_type = ` + typ + ` AND ` + src.exists + ` AND tr.source_revision < r.number`
+		if f.Language != "" {
+			outdated += " AND tr.language = @lang"
+		}
+		parts = append(parts, outdated)
+
+		if f.IncludeMissing && f.Language != "" {
+			parts = append(parts, `
+			SELECT `+typ+`, t.id, t.title, t.language, @lang, '`+ReasonMissing+`', NULL::uuid, NULL::uuid,
+				0, coalesce(r.number, 0), t.updated_at
+			FROM `+src.from+`
+			`+latest+`
+			WHERE `+src.exists+` AND `+src.live+` AND t.language <> @lang
+				AND NOT EXISTS (
+					SELECT 1 FROM translations tr
+					WHERE tr.content_type = `+typ+` AND tr.content_id = t.id AND tr.language = @lang
+				)`)
+		}
+	}
+	if len(parts) == 0 {
+		return nil, 0, errUnknownType
+	}
+	union := strings.Join(parts, "\nUNION ALL\n")
+	lang := sql.Named("lang", f.Language)
+
+	var total int64
+	if err := r.db.WithContext(ctx).Raw("SELECT COUNT(*) FROM ("+union+") q", lang).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []QueueItem
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT * FROM (`+union+`) q
+		ORDER BY q.reason = '`+ReasonMissing+`', q.current_revision - q.translated_revision DESC,
+			q.source_updated_at DESC, q.content_id, q.language
+		LIMIT @limit OFFSET @offset`,
+		lang, sql.Named("limit", f.Limit), sql.Named("offset", f.Offset),
+	).Scan(&items).Error
+	return items, total, err
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound)
+}
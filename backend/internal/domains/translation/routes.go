@@ -0,0 +1,21 @@
+package translation
+
+import (
+	"pokemon/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *handler) RegisterRoutes(router fiber.Router) {
+	group := router.Group("/translations")
+	auth := middleware.AuthRequired()
+	optional := middleware.AuthOptional()
+
+	group.Get("/queue", auth, h.queue)
+
+	group.Get("/:content_type/:id", optional, h.overview)
+	group.Get("/:content_type/:id/revisions/:number", optional, h.revision)
+	group.Get("/:content_type/:id/:lang", optional, h.get)
+	group.Put("/:content_type/:id/:lang", auth, h.save)
+	group.Delete("/:content_type/:id/:lang", auth, h.delete)
+}
@@ -0,0 +1,281 @@
+package translation
+
+import (
+	"context"
+	"pokemon/pkg/content"
+	"pokemon/pkg/language"
+
+	"github.com/google/uuid"
+)
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+// viewer is who asks: reviewers (the author of the content and moderators)
+// see and publish every translation of it.
+type viewer struct {
+	id        uuid.UUID
+	moderator bool
+}
+
+type service interface {
+	overview(ctx context.Context, contentType string, contentID uuid.UUID, v viewer) (*Overview, error)
+	get(ctx context.Context, contentType string, contentID uuid.UUID, lang string, v viewer) (*Translation, error)
+	save(ctx context.Context, contentType string, contentID uuid.UUID, lang string, req saveRequest, v viewer) (*Translation, error)
+	delete(ctx context.Context, contentType string, contentID uuid.UUID, lang string, v viewer) error
+	revision(ctx context.Context, contentType string, contentID uuid.UUID, number int, v viewer) (*SourceRevision, error)
+	queue(ctx context.Context, f queueFilter) ([]QueueItem, int64, error)
+
+	variant(ctx context.Context, contentType string, contentID uuid.UUID, sourceLanguage string, preferred []string) (*Variant, error)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type serviceImpl struct {
+	repo     repository
+	renderer content.Renderer
+}
+
+func newServ(repo repository, renderer content.Renderer) service {
+	return &serviceImpl{repo: repo, renderer: renderer}
+}
+
+func (s *serviceImpl) overview(ctx context.Context, contentType string, contentID uuid.UUID, v viewer) (*Overview, error) {
+	src, err := s.visibleSource(ctx, contentType, contentID, v)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.currentRevision(ctx, contentType, contentID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.repo.list(ctx, contentType, contentID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &Overview{
+		ContentType:     contentType,
+		ContentID:       contentID,
+		SourceLanguage:  src.Language,
+		CurrentRevision: current,
+		Translations:    []Translation{},
+	}
+	translated := map[string]bool{src.Language: true}
+	for _, t := range list {
+		translated[t.Language] = true
+		if t.Status != StatusPublished && !s.canSeeDraft(src, &t, v) {
+			continue
+		}
+		t.CurrentRevision, t.Outdated = current, t.SourceRevision < current
+		o.Translations = append(o.Translations, t)
+	}
+	for _, lang := range language.Supported {
+		if !translated[lang] {
+			o.Missing = append(o.Missing, lang)
+		}
+	}
+	return o, nil
+}
+
+func (s *serviceImpl) get(ctx context.Context, contentType string, contentID uuid.UUID, lang string, v viewer) (*Translation, error) {
+	src, err := s.visibleSource(ctx, contentType, contentID, v)
+	if err != nil {
+		return nil, err
+	}
+	if lang, err = validLanguage(lang); err != nil {
+		return nil, err
+	}
+	t, err := s.repo.get(ctx, contentType, contentID, lang)
+	if err != nil {
+		return nil, err
+	}
+	if t.Status != StatusPublished && !s.canSeeDraft(src, t, v) {
+		return nil, errContentNotFound
+	}
+	if t.CurrentRevision, err = s.currentRevision(ctx, contentType, contentID); err != nil {
+		return nil, err
+	}
+	t.Outdated = t.SourceRevision < t.CurrentRevision
+	t.Rendered = s.renderer.Render(ctx, t.Body)
+	return t, nil
+}
+
+// save creates or updates the translation of the content in lang from a
+// validated request. Anyone may start a translation; after that only its
+// translator and the reviewers may change it. Only reviewers publish: an edit
+// by anyone else goes back to draft until a reviewer publishes it again.
+func (s *serviceImpl) save(ctx context.Context, contentType string, contentID uuid.UUID, lang string, req saveRequest, v viewer) (*Translation, error) {
+	src, err := s.visibleSource(ctx, contentType, contentID, v)
+	if err != nil {
+		return nil, err
+	}
+	if lang, err = validLanguage(lang); err != nil {
+		return nil, err
+	}
+	if lang == src.Language {
+		return nil, errSourceLanguage
+	}
+	current, err := s.currentRevision(ctx, contentType, contentID)
+	if err != nil {
+		return nil, err
+	}
+	if req.SourceRevision == 0 {
+		req.SourceRevision = current
+	}
+	if req.SourceRevision > current {
+		return nil, errBadRevision
+	}
+
+	t, err := s.repo.get(ctx, contentType, contentID, lang)
+	switch {
+	case isNotFound(err):
+		t = &Translation{ContentType: contentType, ContentID: contentID, Language: lang, TranslatorID: v.id}
+	case err != nil:
+		return nil, err
+	case t.TranslatorID != v.id && !s.isReviewer(src, v):
+		return nil, errNotAllowed
+	}
+
+	t.Title, t.Summary, t.Body = req.Title, req.Summary, req.Body
+	t.SourceRevision, t.Status = req.SourceRevision, req.Status
+	if !s.isReviewer(src, v) {
+		t.Status = StatusDraft
+	}
+	if err := s.repo.save(ctx, t); err != nil {
+		return nil, err
+	}
+
+	t.CurrentRevision, t.Outdated = current, t.SourceRevision < current
+	t.Rendered = s.renderer.Render(ctx, t.Body)
+	return t, nil
+}
+
+func (s *serviceImpl) delete(ctx context.Context, contentType string, contentID uuid.UUID, lang string, v viewer) error {
+	src, err := s.repo.source(ctx, contentType, contentID)
+	if err != nil {
+		return err
+	}
+	if lang, err = validLanguage(lang); err != nil {
+		return err
+	}
+	t, err := s.repo.get(ctx, contentType, contentID, lang)
+	if err != nil {
+		return err
+	}
+	if t.TranslatorID != v.id && !s.isReviewer(src, v) {
+		return errNotAllowed
+	}
+	return s.repo.delete(ctx, t.ID)
+}
+
+func (s *serviceImpl) revision(ctx context.Context, contentType string, contentID uuid.UUID, number int, v viewer) (*SourceRevision, error) {
+	if _, err := s.visibleSource(ctx, contentType, contentID, v); err != nil {
+		return nil, err
+	}
+	return s.repo.revision(ctx, contentType, contentID, number)
+}
+
+func (s *serviceImpl) queue(ctx context.Context, f queueFilter) ([]QueueItem, int64, error) {
+	if f.ContentType != "" {
+		if _, ok := contentSources[f.ContentType]; !ok {
+			return nil, 0, errUnknownType
+		}
+	}
+	if f.Language != "" {
+		lang, err := validLanguage(f.Language)
+		if err != nil {
+			return nil, 0, err
+		}
+		f.Language = lang
+	}
+
+	for contentType := range contentSources {
+		if f.ContentType == "" || f.ContentType == contentType {
+			if err := s.repo.sync(ctx, contentType, nil); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	items, total, err := s.repo.queue(ctx, f)
+	if items == nil {
+		items = []QueueItem{}
+	}
+	return items, total, err
+}
+
+// variant picks what a reader gets: the first of their preferred languages
+// that is either the source language or has a published translation, and
+// the source when none is. Outdated translations are still served, flagged.
+func (s *serviceImpl) variant(ctx context.Context, contentType string, contentID uuid.UUID, sourceLanguage string, preferred []string) (*Variant, error) {
+	if sourceLanguage == "" {
+		sourceLanguage = language.Default
+	}
+	v := &Variant{Language: sourceLanguage, SourceLanguage: sourceLanguage, Available: []string{sourceLanguage}}
+
+	list, err := s.repo.list(ctx, contentType, contentID, true)
+	if err != nil || len(list) == 0 {
+		return v, err
+	}
+	byLanguage := make(map[string]*Translation, len(list))
+	for i := range list {
+		if list[i].Language != sourceLanguage {
+			byLanguage[list[i].Language] = &list[i]
+			v.Available = append(v.Available, list[i].Language)
+		}
+	}
+
+	for _, lang := range preferred {
+		if lang == sourceLanguage {
+			return v, nil
+		}
+		t, ok := byLanguage[lang]
+		if !ok {
+			continue
+		}
+		current, err := s.currentRevision(ctx, contentType, contentID)
+		if err != nil {
+			return v, err
+		}
+		v.Language, v.Outdated = lang, t.SourceRevision < current
+		v.Title, v.Summary, v.Body = t.Title, t.Summary, t.Body
+		v.Rendered = s.renderer.Render(ctx, t.Body)
+		return v, nil
+	}
+	return v, nil
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// currentRevision records the source text first when it changed.
+func (s *serviceImpl) currentRevision(ctx context.Context, contentType string, contentID uuid.UUID) (int, error) {
+	if err := s.repo.sync(ctx, contentType, &contentID); err != nil {
+		return 0, err
+	}
+	return s.repo.currentRevision(ctx, contentType, contentID)
+}
+
+// visibleSource hides unpublished content from everyone but its reviewers.
+func (s *serviceImpl) visibleSource(ctx context.Context, contentType string, contentID uuid.UUID, v viewer) (*sourceInfo, error) {
+	src, err := s.repo.source(ctx, contentType, contentID)
+	if err != nil {
+		return nil, err
+	}
+	if !src.Live && !s.isReviewer(src, v) {
+		return nil, errContentNotFound
+	}
+	return src, nil
+}
+
+func (s *serviceImpl) isReviewer(src *sourceInfo, v viewer) bool {
+	return v.moderator || (v.id != uuid.Nil && src.AuthorID == v.id)
+}
+
+func (s *serviceImpl) canSeeDraft(src *sourceInfo, t *Translation, v viewer) bool {
+	return (v.id != uuid.Nil && t.TranslatorID == v.id) || s.isReviewer(src, v)
+}
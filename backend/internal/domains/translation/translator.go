@@ -0,0 +1,37 @@
+package translation
+
+import (
+	"log"
+	"pokemon/pkg/content"
+	"pokemon/pkg/language"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Translator lets other domains serve their content in the language a reader
+// asks for. renderer should be the one the domain renders its content with.
+type Translator struct {
+	s service
+}
+
+func NewTranslator(db *gorm.DB, renderer content.Renderer) *Translator {
+	return &Translator{s: newServ(newRepo(db), renderer)}
+}
+
+// Negotiate picks the variant of the content for the request, from its ?lang=
+// parameter and Accept-Language header, and sets Content-Language. The
+// caller swaps in the text of the variant when it is Translated. Translations
+// are an extra: failures are logged and the source is served.
+func (t *Translator) Negotiate(c *fiber.Ctx, contentType string, contentID uuid.UUID, sourceLanguage string) *Variant {
+	preferred := language.Preferred(c.Query("lang"), c.Get(fiber.HeaderAcceptLanguage))
+	v, err := t.s.variant(c.Context(), contentType, contentID, sourceLanguage, preferred)
+	if err != nil {
+		log.Printf("translation lookup failed for %s %s: %v", contentType, contentID, err)
+	}
+
+	c.Set(fiber.HeaderContentLanguage, v.Language)
+	c.Vary(fiber.HeaderAcceptLanguage)
+	return v
+}
@@ -15,11 +15,19 @@ type User struct {
     FirstName string         `json:"first_name"`
     LastName  string         `json:"last_name"`
     Active    bool           `json:"active" gorm:"default:true"`
+    Role      string         `json:"role" gorm:"type:varchar(20);not null;default:'user'"`
     CreatedAt time.Time      `json:"created_at"`
     UpdatedAt time.Time      `json:"updated_at"`
     DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
 
+// Roles carried in the JWT and checked with utils.RoleMiddleware
+const (
+    RoleUser      = "user"
+    RoleModerator = "moderator"
+    RoleAdmin     = "admin"
+)
+
 /************
  * REQUESTS *
  ************/
@@ -101,7 +101,7 @@ func (s *service) login(ctx context.Context, req *loginRequest) (string, error)
         return "", errors.New("account is deactivated")
     }
     
-    token, err := utils.GenerateJWT(user.ID, user.Email)
+    token, err := utils.GenerateJWT(user.ID, user.Email, user.Role)
     if err != nil {
         return "", err
     }
@@ -1,7 +1,10 @@
 package walkthrough
 
 import (
+	"pokemon/internal/domains/game"
+	"pokemon/internal/domains/series"
 	"pokemon/internal/domains/user"
+	"pokemon/pkg/content"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,12 +14,21 @@ import (
 type Walkthrough struct {
 	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
 	Title     string         `gorm:"not null" json:"title"`                          // e.g. "Pokémon Scarlet Full Walkthrough"
+	Slug      string         `gorm:"type:varchar(255);not null;default:'';uniqueIndex:idx_walkthrough_slug,where:slug <> ''" json:"slug,omitempty"` // Set by the Markdown library, empty for walkthroughs written in the app
 	Game      string         `gorm:"not null" json:"game"`                          // e.g. "Scarlet", "Violet", etc.
 	UserID    uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`           // FK to users
 	User      user.User      `gorm:"foreignKey:UserID" json:"user"`
 	Tags      pq.StringArray `gorm:"type:text[]" json:"tags"`                       // Optional: ["Story", "Guide", "Exploration"]
 
+	GameID         *uuid.UUID         `gorm:"type:uuid;index" json:"game_id"`                  // Optional link to the game
+	LinkedGame     *game.Game         `gorm:"foreignKey:GameID" json:"linked_game,omitempty"`
+	VersionGroupID *uuid.UUID         `gorm:"type:uuid;index" json:"version_group_id"`         // e.g. Scarlet/Violet, set from the game when missing
+	VersionGroup   *game.VersionGroup `gorm:"foreignKey:VersionGroupID" json:"version_group,omitempty"`
+	Version        string             `gorm:"-" json:"version,omitempty"`                      // Version the steps were filtered for
+
 	Steps     []WalkthroughStep `gorm:"foreignKey:WalkthroughID" json:"steps"`     // Walkthrough content sections
+	Contributors []Contributor  `gorm:"-" json:"contributors,omitempty"`           // Step editors besides the author
+	Series    []series.Navigation `gorm:"-" json:"series,omitempty"`               // Previous/next in the series holding the walkthrough
 
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
@@ -27,15 +39,206 @@ type WalkthroughStep struct {
 	WalkthroughID  uuid.UUID `gorm:"type:uuid;not null" json:"walkthrough_id"`         // FK to Walkthrough
 	Title          string    `gorm:"not null" json:"title"`                            // e.g. "Route 1 and First Battle"
 	Content        string    `gorm:"type:text;not null" json:"content"`                // Markdown or HTML
+	Rendered       *content.Rendered `gorm:"-" json:"rendered,omitempty"`          // Sanitized HTML of Content
 	StepNumber     int       `gorm:"not null" json:"step_number"`                      // Order in the walkthrough
 
 	MediaURLs      pq.StringArray `gorm:"type:text[]" json:"media_urls"`              // Optional: image/video links
 	Tags           pq.StringArray `gorm:"type:text[]" json:"tags"`                    // e.g. ["Wild Pokémon", "Catching", "Battle Tips"]
+	Versions       pq.StringArray `gorm:"type:text[]" json:"versions"`                // Game IDs the step is for, empty for every version
+	EditSummary    string    `gorm:"-" json:"edit_summary,omitempty"`                  // Input only, stored on the revision
+	Checklist      []StepChecklistItem `gorm:"foreignKey:StepID;constraint:OnDelete:CASCADE" json:"checklist,omitempty"`
 
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 }
 
+// Checklist item kinds
+const (
+	ChecklistItem    = "item"    // An item to pick up
+	ChecklistTrainer = "trainer" // A trainer to battle
+	ChecklistTask    = "task"    // Anything else
+)
+
+// StepChecklistItem is one thing to do in a step that readers tick off
+// individually.
+type StepChecklistItem struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	StepID    uuid.UUID `gorm:"type:uuid;not null;index" json:"step_id"`
+	Position  int       `gorm:"not null" json:"position"`
+	Kind      string    `gorm:"type:varchar(20);not null;default:'task'" json:"kind"`
+	Label     string    `gorm:"not null" json:"label"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WalkthroughProgress is where a reader is in a walkthrough. Completed steps
+// and ticked checklist items are stored separately.
+type WalkthroughProgress struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID        uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_user_walkthrough_progress" json:"user_id"`
+	WalkthroughID uuid.UUID  `gorm:"type:uuid;not null;index;uniqueIndex:idx_user_walkthrough_progress" json:"walkthrough_id"`
+	LastStepID    *uuid.UUID `gorm:"type:uuid" json:"last_step_id"`                   // Step to resume at
+
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+type StepCompletion struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_step_completion" json:"user_id"`
+	StepID        uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_user_step_completion" json:"step_id"`
+	WalkthroughID uuid.UUID `gorm:"type:uuid;not null;index" json:"walkthrough_id"`
+
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type ChecklistTick struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_checklist_tick" json:"user_id"`
+	ItemID        uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_user_checklist_tick" json:"item_id"`
+	WalkthroughID uuid.UUID `gorm:"type:uuid;not null;index" json:"walkthrough_id"`
+
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Progress is a reader's progress through one walkthrough. Completed and
+// Checked are left out of listings.
+type Progress struct {
+	WalkthroughID  uuid.UUID   `json:"walkthrough_id"`
+	Title          string      `json:"title,omitempty"`
+	Game           string      `json:"game,omitempty"`
+	TotalSteps     int64       `json:"total_steps"`
+	CompletedSteps int64       `json:"completed_steps"`
+	Percent        float64     `json:"percent"`
+	LastStepID     *uuid.UUID  `json:"last_step_id"`
+	ResumeStepID   *uuid.UUID  `json:"resume_step_id"`                                  // Last step, or the first one not completed
+	Completed      []uuid.UUID `json:"completed,omitempty"`
+	Checked        []uuid.UUID `json:"checked,omitempty"`
+	StartedAt      time.Time   `json:"started_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// FunnelStep tells how many readers who started a walkthrough completed a
+// step, and how many were lost since the previous one.
+type FunnelStep struct {
+	StepID     uuid.UUID `json:"step_id"`
+	StepNumber int       `json:"step_number"`
+	Title      string    `json:"title"`
+	Completed  int64     `json:"completed"`
+	Percent    float64   `json:"percent"`                                               // Of the readers who started
+	DropOff    int64     `json:"drop_off"`                                              // Completed the previous step but not this one
+	Resting    int64     `json:"resting"`                                               // Readers whose last step is this one
+}
+
+type Funnel struct {
+	WalkthroughID uuid.UUID    `json:"walkthrough_id"`
+	Started       int64        `json:"started"`
+	Finished      int64        `json:"finished"`
+	Steps         []FunnelStep `json:"steps"`
+}
+
+// StepRevision is a snapshot of a step after one edit. Numbers start at 1 for
+// every step; the highest one matches the current step.
+type StepRevision struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	StepID        uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_step_revision" json:"step_id"`
+	WalkthroughID uuid.UUID `gorm:"type:uuid;not null;index" json:"walkthrough_id"`
+	Number        int       `gorm:"not null;uniqueIndex:idx_step_revision" json:"number"`
+	Title         string    `gorm:"not null" json:"title"`
+	Content       string    `gorm:"type:text;not null" json:"content,omitempty"`
+	AuthorID      uuid.UUID `gorm:"type:uuid;not null;index" json:"author_id"`
+	EditSummary   string    `gorm:"type:varchar(255)" json:"edit_summary"`
+
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Changes between two versions of a step's text.
+type Changes struct {
+	Title   *content.Change   `json:"title,omitempty"`
+	Content *content.LineDiff `json:"content"`
+}
+
+// RevisionDiff compares two revisions of a step.
+type RevisionDiff struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+	Changes
+}
+
+// Suggestion statuses. Only pending suggestions can be reviewed.
+const (
+	SuggestionPending  = "pending"
+	SuggestionAccepted = "accepted"
+	SuggestionRejected = "rejected"
+)
+
+// StepSuggestion is an edit of a step proposed by a reader. Accepting it
+// records a revision credited to the proposer.
+type StepSuggestion struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	StepID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"step_id"`
+	WalkthroughID uuid.UUID  `gorm:"type:uuid;not null;index" json:"walkthrough_id"`
+	UserID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`          // Proposer
+	BaseRevision  int        `gorm:"not null" json:"base_revision"`                    // Revision the edit was made against
+	Title         string     `gorm:"not null" json:"title"`
+	Content       string     `gorm:"type:text;not null" json:"content,omitempty"`
+	Message       string     `gorm:"type:varchar(255)" json:"message"`                 // Why the edit is needed
+	Status        string     `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	ReviewerID    *uuid.UUID `gorm:"type:uuid" json:"reviewer_id,omitempty"`
+	ReviewNote    string     `gorm:"type:text" json:"review_note,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+	Revision      *int       `json:"revision,omitempty"`                               // Revision created when accepted
+
+	Comments      []StepSuggestionComment `gorm:"foreignKey:SuggestionID;constraint:OnDelete:CASCADE" json:"comments,omitempty"`
+	Changes       *Changes   `gorm:"-" json:"changes,omitempty"`                       // Diff against the current step
+
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+type StepSuggestionComment struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SuggestionID uuid.UUID `gorm:"type:uuid;not null;index" json:"suggestion_id"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	User         user.User `gorm:"foreignKey:UserID" json:"user"`
+	Content      string    `gorm:"type:text;not null" json:"content"`
+
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Contributor is someone other than the author who edited steps of a
+// walkthrough, mostly through accepted suggestions.
+type Contributor struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Edits    int64     `json:"edits"`
+}
+
+// suggestEditRequest leaves out the fields the suggestion doesn't change.
+type suggestEditRequest struct {
+	Title   *string `json:"title"`
+	Content *string `json:"content"`
+	Message string  `json:"message"`
+}
+
+type reviewSuggestionRequest struct {
+	Note string `json:"note"`
+}
+
+type reorderStepsRequest struct {
+	StepIDs []uuid.UUID `json:"step_ids"`
+}
+
+type lastStepRequest struct {
+	StepID uuid.UUID `json:"step_id"`
+}
+
+// checklistRequest replaces a step's checklist. Items keep their ID, and the
+// ticks readers gave them, when it is sent back.
+type checklistRequest struct {
+	Items []StepChecklistItem `json:"items"`
+}
+
 type WalkthroughComment struct {
 	ID            uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
 	WalkthroughID uuid.UUID `gorm:"type:uuid;not null" json:"walkthrough_id"`
@@ -1,6 +1,10 @@
 package walkthrough
 
 import (
+	"errors"
+	"pokemon/internal/domains/dexlink"
+	"pokemon/internal/domains/notification"
+	"pokemon/internal/domains/series"
 	"pokemon/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -10,14 +14,24 @@ import (
 )
 
 type handler struct {
-	s service
+	s           service
+	revisions   revisionService
+	suggestions suggestionService
+	progress    progressService
+	navigator   *series.Navigator
 }
 
 func NewHandler(db *gorm.DB, redis *redis.Client) *handler {
 	repo := newRepo(db)
-	serv := newServ(repo, redis)
+	serv := newServ(repo, dexlink.NewRenderer(db, redis), redis)
 
-	return &handler{s: serv}
+	return &handler{
+		s:           serv,
+		revisions:   newRevisionService(repo, serv),
+		suggestions: newSuggestionService(repo, serv),
+		progress:    newProgressService(newProgressRepo(db), repo),
+		navigator:   series.NewNavigator(db),
+	}
 }
 
 func (h *handler) create(c *fiber.Ctx) error {
@@ -45,6 +59,16 @@ func (h *handler) get(c *fiber.Ctx) error {
 	if err != nil {
 		return fiber.NewError(fiber.StatusNotFound, "Walkthrough not found")
 	}
+
+	// Readers may narrow the walkthrough down to their version of the game
+	if version := c.Query("version"); version != "" {
+		if err := h.s.filterVersion(c.Context(), wt, version); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	}
+
+	viewerID, _ := utils.GetUserIDFromLocals(c)
+	wt.Series = h.navigator.Around(c.Context(), series.ContentWalkthrough, wt.ID, viewerID)
 	return c.JSON(wt)
 }
 
@@ -109,40 +133,110 @@ func (h *handler) delete(c *fiber.Ctx) error {
 }
 
 func (h *handler) addStep(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
 	var step WalkthroughStep
 	if err := c.BodyParser(&step); err != nil {
 		return fiber.ErrBadRequest
 	}
 
-	step.WalkthroughID = uuid.MustParse(c.Params("id"))
+	step.WalkthroughID, err = uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+	if err := h.checkEditor(c, step.WalkthroughID, userID); err != nil {
+		return err
+	}
 
-	if err := h.s.addStep(c.Context(), &step); err != nil {
+	if err := h.s.addStep(c.Context(), &step, userID); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 	return c.Status(fiber.StatusCreated).JSON(step)
 }
 
 func (h *handler) updateStep(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	existing, err := h.s.getStep(c.Context(), c.Params("step_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Step not found")
+	}
+	if err := h.checkEditor(c, existing.WalkthroughID, userID); err != nil {
+		return err
+	}
+
 	var step WalkthroughStep
 	if err := c.BodyParser(&step); err != nil {
 		return fiber.ErrBadRequest
 	}
-	step.ID = uuid.MustParse(c.Params("step_id"))
+	step.ID = existing.ID
 
-	if err := h.s.updateStep(c.Context(), &step); err != nil {
+	if err := h.s.updateStep(c.Context(), &step, userID); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 	return c.JSON(step)
 }
 
 func (h *handler) deleteStep(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
 	stepID := c.Params("step_id")
+	existing, err := h.s.getStep(c.Context(), stepID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Step not found")
+	}
+	if err := h.checkEditor(c, existing.WalkthroughID, userID); err != nil {
+		return err
+	}
+
 	if err := h.s.deleteStep(c.Context(), stepID); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
+// PUT /walkthroughs/:id/steps/order
+func (h *handler) reorderSteps(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+	walkthroughID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+	if err := h.checkEditor(c, walkthroughID, userID); err != nil {
+		return err
+	}
+
+	var req reorderStepsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	if err := h.s.reorderSteps(c.Context(), walkthroughID, req.StepIDs); err != nil {
+		if errors.Is(err, errInvalidStepOrder) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	wt, err := h.s.getWalkthrough(c.Context(), walkthroughID.String())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(wt)
+}
+
 func (h *handler) addComment(c *fiber.Ctx) error {
 	userID, err := utils.GetUserIDFromLocals(c)
 	if err != nil {
@@ -160,6 +254,7 @@ func (h *handler) addComment(c *fiber.Ctx) error {
 	if err := h.s.addComment(c.Context(), &comment); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
+	notification.Send(notification.Event{Type: notification.TypeReply, ActorID: userID, SubjectType: notification.SubjectWalkthrough, SubjectID: comment.WalkthroughID, ContentID: &comment.ID})
 	return c.Status(fiber.StatusCreated).JSON(comment)
 }
 
@@ -0,0 +1,157 @@
+package walkthrough
+
+import (
+	"context"
+	"errors"
+	"pokemon/internal/domains/game"
+	"pokemon/pkg/content"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Library gives bulk tools, like the Markdown import, access to walkthroughs
+// under the same rules as the API: game checks and step revisions.
+type Library struct {
+	s    service
+	repo repository
+}
+
+func NewLibrary(db *gorm.DB, redis *redis.Client) *Library {
+	repo := newRepo(db)
+	return &Library{s: newServ(repo, content.NewRenderer(redis), redis), repo: repo}
+}
+
+// BySlug returns the walkthrough with its steps, or nil when there is none.
+func (l *Library) BySlug(ctx context.Context, slug string) (*Walkthrough, error) {
+	wt, err := l.repo.getBySlug(ctx, slug)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return wt, err
+}
+
+// ByID returns the walkthrough with its steps, or nil when there is none.
+func (l *Library) ByID(ctx context.Context, id uuid.UUID) (*Walkthrough, error) {
+	wt, err := l.repo.getByID(ctx, id.String())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return wt, err
+}
+
+// All returns every walkthrough with its steps.
+func (l *Library) All(ctx context.Context) ([]Walkthrough, error) {
+	return l.repo.all(ctx)
+}
+
+// FilterVersion narrows the walkthrough down to one version of the game, as
+// the version query parameter of the API does.
+func (l *Library) FilterVersion(ctx context.Context, wt *Walkthrough, version string) error {
+	return l.s.filterVersion(ctx, wt, version)
+}
+
+// StepVersions names the versions a step is for after their games, as
+// writers refer to them.
+func (l *Library) StepVersions(wt *Walkthrough, step *WalkthroughStep) []string {
+	games := versionGamesOf(wt)
+	names := make([]string, 0, len(step.Versions))
+	for _, id := range step.Versions {
+		if g, ok := findVersion(games, id); ok {
+			names = append(names, g.Name)
+		} else {
+			names = append(names, id)
+		}
+	}
+	return names
+}
+
+// Save creates the walkthrough, or updates it when it has an ID, and makes
+// steps its steps in that order. Steps carrying the ID of one of its steps
+// update it, as a revision credited to editorID when their text changed;
+// the others are added, and the steps left out are deleted.
+func (l *Library) Save(ctx context.Context, wt *Walkthrough, steps []WalkthroughStep, editorID uuid.UUID) error {
+	previous := map[uuid.UUID]WalkthroughStep{}
+	wt.Steps = nil
+
+	if wt.ID == uuid.Nil {
+		if err := l.s.createWalkthrough(ctx, wt); err != nil {
+			return err
+		}
+	} else {
+		existing, err := l.repo.getByID(ctx, wt.ID.String())
+		if err != nil {
+			return err
+		}
+		for _, step := range existing.Steps {
+			previous[step.ID] = step
+		}
+		if err := l.s.updateWalkthrough(ctx, wt); err != nil {
+			return err
+		}
+	}
+
+	games, err := l.repo.versionGames(ctx, wt.ID)
+	if err != nil {
+		return err
+	}
+
+	kept := map[uuid.UUID]bool{}
+	for i := range steps {
+		step := &steps[i]
+		step.WalkthroughID, step.StepNumber = wt.ID, i+1
+		if step.Versions, err = resolveVersions(games, step.Versions); err != nil {
+			return err
+		}
+
+		prev, ok := previous[step.ID]
+		if !ok {
+			step.ID = uuid.Nil
+			if err := l.s.addStep(ctx, step, editorID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		kept[step.ID] = true
+		if sameStep(&prev, step) {
+			*step = prev
+			continue
+		}
+		if err := l.s.updateStep(ctx, step, editorID); err != nil {
+			return err
+		}
+	}
+
+	for id := range previous {
+		if !kept[id] {
+			if err := l.s.deleteStep(ctx, id.String()); err != nil {
+				return err
+			}
+		}
+	}
+	wt.Steps = steps
+	return nil
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func versionGamesOf(wt *Walkthrough) []game.Game {
+	if wt.VersionGroup != nil {
+		return wt.VersionGroup.Games
+	}
+	if wt.LinkedGame != nil {
+		return []game.Game{*wt.LinkedGame}
+	}
+	return nil
+}
+
+func sameStep(a, b *WalkthroughStep) bool {
+	return a.Title == b.Title && a.Content == b.Content && a.StepNumber == b.StepNumber &&
+		slices.Equal(a.Tags, b.Tags) && slices.Equal(a.MediaURLs, b.MediaURLs) &&
+		slices.Equal(a.Versions, b.Versions)
+}
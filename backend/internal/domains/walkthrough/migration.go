@@ -0,0 +1,20 @@
+package walkthrough
+
+import "gorm.io/gorm"
+
+type WalkthroughMigrator struct{}
+
+func (m WalkthroughMigrator) Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&Walkthrough{},
+		&WalkthroughStep{},
+		&StepRevision{},
+		&StepSuggestion{},
+		&StepSuggestionComment{},
+		&StepChecklistItem{},
+		&WalkthroughProgress{},
+		&StepCompletion{},
+		&ChecklistTick{},
+		&WalkthroughComment{},
+	)
+}
@@ -0,0 +1,230 @@
+package walkthrough
+
+import (
+	"context"
+	"errors"
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GET /walkthroughs/progress
+func (h *handler) listProgress(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	limit, offset := utils.ParsePagination(c)
+	list, total, err := h.progress.list(c.Context(), userID, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": total,
+		"items": list,
+	})
+}
+
+// GET /walkthroughs/:id/progress
+func (h *handler) getProgress(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+	walkthroughID, err := h.walkthroughID(c)
+	if err != nil {
+		return err
+	}
+
+	progress, err := h.progress.get(c.Context(), userID, walkthroughID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(progress)
+}
+
+// PUT /walkthroughs/:id/progress
+func (h *handler) setLastStep(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+	walkthroughID, err := h.walkthroughID(c)
+	if err != nil {
+		return err
+	}
+
+	var req lastStepRequest
+	if err := c.BodyParser(&req); err != nil || req.StepID == uuid.Nil {
+		return fiber.NewError(fiber.StatusBadRequest, "step_id is required")
+	}
+	step, err := h.s.getStep(c.Context(), req.StepID.String())
+	if err != nil || step.WalkthroughID != walkthroughID {
+		return fiber.NewError(fiber.StatusNotFound, "Step not found")
+	}
+
+	if err := h.progress.setLastStep(c.Context(), userID, step); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return h.respondProgress(c, userID, walkthroughID)
+}
+
+// DELETE /walkthroughs/:id/progress
+func (h *handler) resetProgress(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+	walkthroughID, err := h.walkthroughID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.progress.reset(c.Context(), userID, walkthroughID); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// POST /walkthroughs/steps/:step_id/complete
+func (h *handler) completeStep(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+	step, err := h.s.getStep(c.Context(), c.Params("step_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Step not found")
+	}
+
+	if err := h.progress.complete(c.Context(), userID, step); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return h.respondProgress(c, userID, step.WalkthroughID)
+}
+
+// DELETE /walkthroughs/steps/:step_id/complete
+func (h *handler) uncompleteStep(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+	step, err := h.s.getStep(c.Context(), c.Params("step_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Step not found")
+	}
+
+	if err := h.progress.uncomplete(c.Context(), userID, step); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return h.respondProgress(c, userID, step.WalkthroughID)
+}
+
+// POST /walkthroughs/checklist/:item_id/check
+func (h *handler) checkItem(c *fiber.Ctx) error {
+	return h.tick(c, h.progress.check)
+}
+
+// DELETE /walkthroughs/checklist/:item_id/check
+func (h *handler) uncheckItem(c *fiber.Ctx) error {
+	return h.tick(c, h.progress.uncheck)
+}
+
+// PUT /walkthroughs/steps/:step_id/checklist
+func (h *handler) replaceChecklist(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+	step, err := h.s.getStep(c.Context(), c.Params("step_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Step not found")
+	}
+	if err := h.checkEditor(c, step.WalkthroughID, userID); err != nil {
+		return err
+	}
+
+	var req checklistRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	if err := h.s.replaceChecklist(c.Context(), step.ID, req.Items); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Checklist item not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	updated, err := h.s.getStep(c.Context(), step.ID.String())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(updated)
+}
+
+// GET /walkthroughs/:id/funnel
+func (h *handler) funnel(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+	walkthroughID, err := h.walkthroughID(c)
+	if err != nil {
+		return err
+	}
+	if err := h.checkEditor(c, walkthroughID, userID); err != nil {
+		return err
+	}
+
+	funnel, err := h.progress.funnel(c.Context(), walkthroughID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(funnel)
+}
+
+type tickFunc func(ctx context.Context, userID uuid.UUID, step *WalkthroughStep, itemID uuid.UUID) error
+
+func (h *handler) tick(c *fiber.Ctx, apply tickFunc) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+	item, err := h.s.getChecklistItem(c.Context(), c.Params("item_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Checklist item not found")
+	}
+	step, err := h.s.getStep(c.Context(), item.StepID.String())
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Step not found")
+	}
+
+	if err := apply(c.Context(), userID, step, item.ID); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return h.respondProgress(c, userID, step.WalkthroughID)
+}
+
+// walkthroughID parses the :id param of a walkthrough that exists.
+func (h *handler) walkthroughID(c *fiber.Ctx) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "Invalid walkthrough ID")
+	}
+	if _, err := h.s.ownerOf(c.Context(), id); err != nil {
+		return uuid.Nil, fiber.NewError(fiber.StatusNotFound, "Walkthrough not found")
+	}
+	return id, nil
+}
+
+func (h *handler) respondProgress(c *fiber.Ctx, userID, walkthroughID uuid.UUID) error {
+	progress, err := h.progress.get(c.Context(), userID, walkthroughID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(progress)
+}
@@ -0,0 +1,203 @@
+package walkthrough
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type progressService interface {
+	get(ctx context.Context, userID, walkthroughID uuid.UUID) (*Progress, error)
+	list(ctx context.Context, userID uuid.UUID, limit, offset int) ([]Progress, int64, error)
+	setLastStep(ctx context.Context, userID uuid.UUID, step *WalkthroughStep) error
+	reset(ctx context.Context, userID, walkthroughID uuid.UUID) error
+
+	complete(ctx context.Context, userID uuid.UUID, step *WalkthroughStep) error
+	uncomplete(ctx context.Context, userID uuid.UUID, step *WalkthroughStep) error
+	check(ctx context.Context, userID uuid.UUID, step *WalkthroughStep, itemID uuid.UUID) error
+	uncheck(ctx context.Context, userID uuid.UUID, step *WalkthroughStep, itemID uuid.UUID) error
+
+	funnel(ctx context.Context, walkthroughID uuid.UUID) (*Funnel, error)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type progressServiceImpl struct {
+	repo  progressRepository
+	steps repository
+}
+
+func newProgressService(repo progressRepository, steps repository) progressService {
+	return &progressServiceImpl{repo: repo, steps: steps}
+}
+
+// get works for walkthroughs the reader hasn't started, which have no
+// progress yet.
+func (s *progressServiceImpl) get(ctx context.Context, userID, walkthroughID uuid.UUID) (*Progress, error) {
+	progress := &Progress{WalkthroughID: walkthroughID, Completed: []uuid.UUID{}, Checked: []uuid.UUID{}}
+
+	saved, err := s.repo.get(ctx, userID, walkthroughID)
+	switch {
+	case err == nil:
+		progress.LastStepID = saved.LastStepID
+		progress.StartedAt, progress.UpdatedAt = saved.CreatedAt, saved.UpdatedAt
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	order, err := s.steps.stepOrder(ctx, walkthroughID)
+	if err != nil {
+		return nil, err
+	}
+	completed, err := s.repo.completed(ctx, userID, walkthroughID)
+	if err != nil {
+		return nil, err
+	}
+	checked, err := s.repo.checked(ctx, userID, walkthroughID)
+	if err != nil {
+		return nil, err
+	}
+	progress.Completed = append(progress.Completed, completed...)
+	progress.Checked = append(progress.Checked, checked...)
+
+	progress.TotalSteps, progress.CompletedSteps = int64(len(order)), int64(len(completed))
+	progress.Percent = percent(progress.CompletedSteps, progress.TotalSteps)
+	progress.ResumeStepID = resumeStep(order, completed, progress.LastStepID)
+	return progress, nil
+}
+
+func (s *progressServiceImpl) list(ctx context.Context, userID uuid.UUID, limit, offset int) ([]Progress, int64, error) {
+	list, total, err := s.repo.list(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range list {
+		list[i].Percent = percent(list[i].CompletedSteps, list[i].TotalSteps)
+	}
+	return list, total, nil
+}
+
+func (s *progressServiceImpl) setLastStep(ctx context.Context, userID uuid.UUID, step *WalkthroughStep) error {
+	return s.repo.touch(ctx, userID, step.WalkthroughID, &step.ID)
+}
+
+func (s *progressServiceImpl) reset(ctx context.Context, userID, walkthroughID uuid.UUID) error {
+	return s.repo.reset(ctx, userID, walkthroughID)
+}
+
+// complete also moves the resume point to the step.
+func (s *progressServiceImpl) complete(ctx context.Context, userID uuid.UUID, step *WalkthroughStep) error {
+	completion := &StepCompletion{UserID: userID, StepID: step.ID, WalkthroughID: step.WalkthroughID}
+	if err := s.repo.complete(ctx, completion); err != nil {
+		return err
+	}
+	return s.repo.touch(ctx, userID, step.WalkthroughID, &step.ID)
+}
+
+func (s *progressServiceImpl) uncomplete(ctx context.Context, userID uuid.UUID, step *WalkthroughStep) error {
+	if err := s.repo.uncomplete(ctx, userID, step.ID); err != nil {
+		return err
+	}
+	return s.repo.touch(ctx, userID, step.WalkthroughID, nil)
+}
+
+func (s *progressServiceImpl) check(ctx context.Context, userID uuid.UUID, step *WalkthroughStep, itemID uuid.UUID) error {
+	tick := &ChecklistTick{UserID: userID, ItemID: itemID, WalkthroughID: step.WalkthroughID}
+	if err := s.repo.check(ctx, tick); err != nil {
+		return err
+	}
+	return s.repo.touch(ctx, userID, step.WalkthroughID, &step.ID)
+}
+
+func (s *progressServiceImpl) uncheck(ctx context.Context, userID uuid.UUID, step *WalkthroughStep, itemID uuid.UUID) error {
+	if err := s.repo.uncheck(ctx, userID, itemID); err != nil {
+		return err
+	}
+	return s.repo.touch(ctx, userID, step.WalkthroughID, nil)
+}
+
+// funnel shows how far the readers who started the walkthrough got, step by
+// step, to spot where they give up.
+func (s *progressServiceImpl) funnel(ctx context.Context, walkthroughID uuid.UUID) (*Funnel, error) {
+	steps, err := s.repo.funnelSteps(ctx, walkthroughID)
+	if err != nil {
+		return nil, err
+	}
+	resting, err := s.repo.resting(ctx, walkthroughID)
+	if err != nil {
+		return nil, err
+	}
+	started, err := s.repo.started(ctx, walkthroughID)
+	if err != nil {
+		return nil, err
+	}
+	finished, err := s.repo.finished(ctx, walkthroughID, len(steps))
+	if err != nil {
+		return nil, err
+	}
+
+	previous := started
+	for i := range steps {
+		steps[i].Percent = percent(steps[i].Completed, started)
+		steps[i].DropOff = max(previous-steps[i].Completed, 0)
+		steps[i].Resting = resting[steps[i].StepID]
+		previous = steps[i].Completed
+	}
+	if steps == nil {
+		steps = []FunnelStep{}
+	}
+
+	return &Funnel{
+		WalkthroughID: walkthroughID,
+		Started:       started,
+		Finished:      finished,
+		Steps:         steps,
+	}, nil
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// percent is rounded to one decimal.
+func percent(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)*1000/float64(total)) / 10
+}
+
+// resumeStep picks the first step not completed yet, starting from the last
+// step the reader was at. Nil means the walkthrough is done.
+func resumeStep(order, completed []uuid.UUID, last *uuid.UUID) *uuid.UUID {
+	done := make(map[uuid.UUID]bool, len(completed))
+	for _, id := range completed {
+		done[id] = true
+	}
+
+	start := 0
+	if last != nil {
+		for i, id := range order {
+			if id == *last {
+				start = i
+				break
+			}
+		}
+	}
+	for i := range order {
+		id := order[(start+i)%len(order)]
+		if !done[id] {
+			return &id
+		}
+	}
+	return nil
+}
@@ -2,8 +2,13 @@ package walkthrough
 
 import (
 	"context"
+	"errors"
+	"pokemon/internal/domains/game"
+	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 /******************************
@@ -17,12 +22,39 @@ type repository interface {
 
 	create(ctx context.Context, wt *Walkthrough) error
 	getByID(ctx context.Context, id string) (*Walkthrough, error)
+	getBySlug(ctx context.Context, slug string) (*Walkthrough, error)
+	all(ctx context.Context) ([]Walkthrough, error)
 	update(ctx context.Context, wt *Walkthrough) error
 	delete(ctx context.Context, id string) error
 
-	addStep(ctx context.Context, step *WalkthroughStep) error
-	updateStep(ctx context.Context, step *WalkthroughStep) error
+	ownerOf(ctx context.Context, walkthroughID uuid.UUID) (uuid.UUID, error)
+
+	getStep(ctx context.Context, stepID string) (*WalkthroughStep, error)
+	addStep(ctx context.Context, step *WalkthroughStep, rev *StepRevision) error
+	updateStep(ctx context.Context, step *WalkthroughStep, previous *WalkthroughStep, rev *StepRevision, base *int) error
 	deleteStep(ctx context.Context, stepID string) error
+	stepOrder(ctx context.Context, walkthroughID uuid.UUID) ([]uuid.UUID, error)
+	reorderSteps(ctx context.Context, walkthroughID uuid.UUID, stepIDs []uuid.UUID) error
+
+	getGame(ctx context.Context, id uuid.UUID) (*game.Game, error)
+	getVersionGroup(ctx context.Context, id uuid.UUID) (*game.VersionGroup, error)
+	versionGames(ctx context.Context, walkthroughID uuid.UUID) ([]game.Game, error)
+
+	getChecklistItem(ctx context.Context, itemID string) (*StepChecklistItem, error)
+	replaceChecklist(ctx context.Context, stepID uuid.UUID, items []StepChecklistItem) error
+
+	listRevisions(ctx context.Context, stepID string, limit, offset int) ([]StepRevision, int64, error)
+	getRevision(ctx context.Context, stepID string, number int) (*StepRevision, error)
+	latestRevision(ctx context.Context, stepID uuid.UUID) (int, error)
+	contributors(ctx context.Context, walkthroughID, ownerID uuid.UUID) ([]Contributor, error)
+
+	createSuggestion(ctx context.Context, suggestion *StepSuggestion) error
+	getSuggestion(ctx context.Context, stepID, id string) (*StepSuggestion, error)
+	listSuggestions(ctx context.Context, stepID, status string, limit, offset int) ([]StepSuggestion, int64, error)
+	reviewSuggestion(ctx context.Context, id uuid.UUID, status string, reviewerID uuid.UUID, note string) error
+	reopenSuggestion(ctx context.Context, id uuid.UUID) error
+	setSuggestionRevision(ctx context.Context, id uuid.UUID, number int) error
+	addSuggestionComment(ctx context.Context, comment *StepSuggestionComment) error
 
 	addComment(ctx context.Context, comment *WalkthroughComment) error
 	listComments(ctx context.Context, walkthroughID string, limit, offset int) ([]WalkthroughComment, int64, error)
@@ -40,20 +72,46 @@ func (r *repositoryImpl) create(ctx context.Context, wt *Walkthrough) error {
 	return r.db.WithContext(ctx).Create(wt).Error
 }
 
-func (r *repositoryImpl) getByID(ctx context.Context, id string) (*Walkthrough, error) {
-	var wt Walkthrough
-	err := r.db.WithContext(ctx).
+// withSteps loads everything a walkthrough is shown with.
+func withSteps(tx *gorm.DB) *gorm.DB {
+	return tx.
 		Preload("User").
+		Preload("LinkedGame", omitPokedex).
+		Preload("VersionGroup").
+		Preload("VersionGroup.Games", func(tx *gorm.DB) *gorm.DB {
+			return omitPokedex(tx).Order("released_at ASC")
+		}).
 		Preload("Steps", func(tx *gorm.DB) *gorm.DB {
 			return tx.Order("step_number ASC")
 		}).
-		First(&wt, "id = ?", id).Error
-	if err != nil {
+		Preload("Steps.Checklist", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("position ASC")
+		})
+}
+
+func (r *repositoryImpl) getByID(ctx context.Context, id string) (*Walkthrough, error) {
+	var wt Walkthrough
+	if err := withSteps(r.db.WithContext(ctx)).First(&wt, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &wt, nil
+}
+
+func (r *repositoryImpl) getBySlug(ctx context.Context, slug string) (*Walkthrough, error) {
+	var wt Walkthrough
+	if err := withSteps(r.db.WithContext(ctx)).First(&wt, "slug = ?", slug).Error; err != nil {
 		return nil, err
 	}
 	return &wt, nil
 }
 
+// all returns every walkthrough with its steps, for exports.
+func (r *repositoryImpl) all(ctx context.Context) ([]Walkthrough, error) {
+	var list []Walkthrough
+	err := withSteps(r.db.WithContext(ctx)).Order("slug ASC, created_at ASC").Find(&list).Error
+	return list, err
+}
+
 func (r *repositoryImpl) list(ctx context.Context, limit, offset int) ([]Walkthrough, int64, error) {
 	var list []Walkthrough
 	var count int64
@@ -72,26 +130,381 @@ func (r *repositoryImpl) list(ctx context.Context, limit, offset int) ([]Walkthr
 	return list, count, nil
 }
 
+// update only saves the walkthrough itself; steps have their own endpoints.
 func (r *repositoryImpl) update(ctx context.Context, wt *Walkthrough) error {
-	return r.db.WithContext(ctx).Save(wt).Error
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(wt).Error
 }
 
 func (r *repositoryImpl) delete(ctx context.Context, id string) error {
 	return r.db.WithContext(ctx).Delete(&Walkthrough{}, "id = ?", id).Error
 }
 
-func (r *repositoryImpl) addStep(ctx context.Context, step *WalkthroughStep) error {
-	return r.db.WithContext(ctx).Create(step).Error
+func (r *repositoryImpl) ownerOf(ctx context.Context, walkthroughID uuid.UUID) (uuid.UUID, error) {
+	var wt Walkthrough
+	if err := r.db.WithContext(ctx).Select("user_id").First(&wt, "id = ?", walkthroughID).Error; err != nil {
+		return uuid.Nil, err
+	}
+	return wt.UserID, nil
+}
+
+func (r *repositoryImpl) getStep(ctx context.Context, stepID string) (*WalkthroughStep, error) {
+	var step WalkthroughStep
+	err := r.db.WithContext(ctx).
+		Preload("Checklist", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("position ASC")
+		}).
+		First(&step, "id = ?", stepID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &step, nil
+}
+
+// addStep saves the step along with its first revision.
+func (r *repositoryImpl) addStep(ctx context.Context, step *WalkthroughStep, rev *StepRevision) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(step).Error; err != nil {
+			return err
+		}
+		rev.StepID, rev.WalkthroughID, rev.Number = step.ID, step.WalkthroughID, 1
+		return tx.Create(rev).Error
+	})
 }
 
-func (r *repositoryImpl) updateStep(ctx context.Context, step *WalkthroughStep) error {
-	return r.db.WithContext(ctx).Save(step).Error
+// updateStep saves the step and records rev as its next revision. Steps
+// written before revisions existed get their previous state recorded first.
+// When base is set, the edit was made against that revision and fails with
+// errSuggestionStale if the step's text changed since. The step is locked so
+// edits are numbered one after the other.
+func (r *repositoryImpl) updateStep(ctx context.Context, step *WalkthroughStep, previous *WalkthroughStep, rev *StepRevision, base *int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current WalkthroughStep
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", step.ID).Error; err != nil {
+			return err
+		}
+
+		var last int
+		err := tx.Model(&StepRevision{}).Where("step_id = ?", step.ID).
+			Select("COALESCE(MAX(number), 0)").Scan(&last).Error
+		if err != nil {
+			return err
+		}
+		if base != nil && *base != last {
+			// Revision 1 holds the text of steps edited before revisions existed
+			var since StepRevision
+			err := tx.First(&since, "step_id = ? AND number = ?", step.ID, max(*base, 1)).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if err != nil || since.Title != current.Title || since.Content != current.Content {
+				return errSuggestionStale
+			}
+		}
+
+		// The checklist has its own endpoint and keeps readers' ticks
+		if err := tx.Omit("Checklist").Save(step).Error; err != nil {
+			return err
+		}
+		if last == 0 {
+			owner, err := (&repositoryImpl{db: tx}).ownerOf(ctx, previous.WalkthroughID)
+			if err != nil {
+				return err
+			}
+			original := revisionOf(previous, owner, "Original version")
+			original.Number, original.CreatedAt = 1, previous.UpdatedAt
+			if err := tx.Create(original).Error; err != nil {
+				return err
+			}
+			last = 1
+		}
+
+		rev.StepID, rev.WalkthroughID, rev.Number = step.ID, step.WalkthroughID, last+1
+		return tx.Create(rev).Error
+	})
 }
 
 func (r *repositoryImpl) deleteStep(ctx context.Context, stepID string) error {
 	return r.db.WithContext(ctx).Delete(&WalkthroughStep{}, "id = ?", stepID).Error
 }
 
+// stepOrder returns the IDs of the walkthrough's steps in reading order.
+func (r *repositoryImpl) stepOrder(ctx context.Context, walkthroughID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&WalkthroughStep{}).
+		Where("walkthrough_id = ?", walkthroughID).
+		Order("step_number ASC, created_at ASC").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// reorderSteps renumbers every step of the walkthrough at once. The
+// walkthrough row is locked so concurrent reorders can't interleave.
+func (r *repositoryImpl) reorderSteps(ctx context.Context, walkthroughID uuid.UUID, stepIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var wt Walkthrough
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").First(&wt, "id = ?", walkthroughID).Error
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		err = tx.Model(&WalkthroughStep{}).
+			Where("walkthrough_id = ? AND id IN ?", walkthroughID, stepIDs).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		var total int64
+		if err := tx.Model(&WalkthroughStep{}).Where("walkthrough_id = ?", walkthroughID).Count(&total).Error; err != nil {
+			return err
+		}
+		if count != int64(len(stepIDs)) || count != total {
+			return errInvalidStepOrder
+		}
+
+		for i, id := range stepIDs {
+			err := tx.Model(&WalkthroughStep{}).Where("id = ?", id).
+				UpdateColumn("step_number", i+1).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *repositoryImpl) getGame(ctx context.Context, id uuid.UUID) (*game.Game, error) {
+	var g game.Game
+	if err := omitPokedex(r.db.WithContext(ctx)).First(&g, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func (r *repositoryImpl) getVersionGroup(ctx context.Context, id uuid.UUID) (*game.VersionGroup, error) {
+	var group game.VersionGroup
+	if err := r.db.WithContext(ctx).First(&group, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// versionGames returns the games a walkthrough covers: those of its version
+// group, or the single game it links to.
+func (r *repositoryImpl) versionGames(ctx context.Context, walkthroughID uuid.UUID) ([]game.Game, error) {
+	var wt Walkthrough
+	err := r.db.WithContext(ctx).Select("game_id", "version_group_id").First(&wt, "id = ?", walkthroughID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var games []game.Game
+	tx := omitPokedex(r.db.WithContext(ctx))
+	switch {
+	case wt.VersionGroupID != nil:
+		err = tx.Where("version_group_id = ?", *wt.VersionGroupID).Order("released_at ASC").Find(&games).Error
+	case wt.GameID != nil:
+		err = tx.Where("id = ?", *wt.GameID).Find(&games).Error
+	}
+	return games, err
+}
+
+// omitPokedex leaves out the Pokédex bitmask of games, which walkthroughs
+// don't need.
+func omitPokedex(tx *gorm.DB) *gorm.DB {
+	return tx.Omit("pokedex")
+}
+
+func (r *repositoryImpl) getChecklistItem(ctx context.Context, itemID string) (*StepChecklistItem, error) {
+	var item StepChecklistItem
+	if err := r.db.WithContext(ctx).First(&item, "id = ?", itemID).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// replaceChecklist saves items as the step's whole checklist. Items dropped
+// from the list are deleted along with their ticks.
+func (r *repositoryImpl) replaceChecklist(ctx context.Context, stepID uuid.UUID, items []StepChecklistItem) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var kept []uuid.UUID
+		for _, item := range items {
+			if item.ID != uuid.Nil {
+				kept = append(kept, item.ID)
+			}
+		}
+
+		stale := tx.Model(&StepChecklistItem{}).Select("id").Where("step_id = ?", stepID)
+		if len(kept) > 0 {
+			stale = stale.Where("id NOT IN ?", kept)
+		}
+		if err := tx.Where("item_id IN (?)", stale).Delete(&ChecklistTick{}).Error; err != nil {
+			return err
+		}
+		del := tx.Where("step_id = ?", stepID)
+		if len(kept) > 0 {
+			del = del.Where("id NOT IN ?", kept)
+		}
+		if err := del.Delete(&StepChecklistItem{}).Error; err != nil {
+			return err
+		}
+
+		for i := range items {
+			items[i].StepID = stepID
+			if items[i].ID == uuid.Nil {
+				if err := tx.Create(&items[i]).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			// Only items of this step can be kept
+			res := tx.Model(&StepChecklistItem{}).
+				Where("id = ? AND step_id = ?", items[i].ID, stepID).
+				Updates(map[string]interface{}{
+					"position": items[i].Position,
+					"kind":     items[i].Kind,
+					"label":    items[i].Label,
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+		}
+		return nil
+	})
+}
+
+func (r *repositoryImpl) listRevisions(ctx context.Context, stepID string, limit, offset int) ([]StepRevision, int64, error) {
+	var list []StepRevision
+	var count int64
+
+	tx := r.db.WithContext(ctx).Model(&StepRevision{}).Where("step_id = ?", stepID)
+	if err := tx.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err := tx.Omit("content").
+		Order("number DESC").
+		Limit(limit).Offset(offset).
+		Find(&list).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return list, count, nil
+}
+
+func (r *repositoryImpl) getRevision(ctx context.Context, stepID string, number int) (*StepRevision, error) {
+	var rev StepRevision
+	err := r.db.WithContext(ctx).First(&rev, "step_id = ? AND number = ?", stepID, number).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// latestRevision returns 0 for steps without any recorded revision.
+func (r *repositoryImpl) latestRevision(ctx context.Context, stepID uuid.UUID) (int, error) {
+	var last int
+	err := r.db.WithContext(ctx).Model(&StepRevision{}).Where("step_id = ?", stepID).
+		Select("COALESCE(MAX(number), 0)").Scan(&last).Error
+	return last, err
+}
+
+// contributors lists the editors of the walkthrough's steps other than its
+// owner, most active first.
+func (r *repositoryImpl) contributors(ctx context.Context, walkthroughID, ownerID uuid.UUID) ([]Contributor, error) {
+	var list []Contributor
+	err := r.db.WithContext(ctx).Table("step_revisions AS r").
+		Select("r.author_id AS user_id, COALESCE(u.username, '') AS username, COUNT(*) AS edits").
+		Joins("LEFT JOIN users u ON u.id = r.author_id").
+		Where("r.walkthrough_id = ? AND r.author_id <> ?", walkthroughID, ownerID).
+		Group("r.author_id, u.username").
+		Order("edits DESC, username ASC").
+		Scan(&list).Error
+	return list, err
+}
+
+func (r *repositoryImpl) createSuggestion(ctx context.Context, suggestion *StepSuggestion) error {
+	return r.db.WithContext(ctx).Create(suggestion).Error
+}
+
+func (r *repositoryImpl) getSuggestion(ctx context.Context, stepID, id string) (*StepSuggestion, error) {
+	var suggestion StepSuggestion
+	err := r.db.WithContext(ctx).
+		Preload("Comments", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("created_at ASC")
+		}).
+		Preload("Comments.User").
+		First(&suggestion, "id = ? AND step_id = ?", id, stepID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &suggestion, nil
+}
+
+// listSuggestions leaves out the proposed content; an empty status lists
+// every suggestion.
+func (r *repositoryImpl) listSuggestions(ctx context.Context, stepID, status string, limit, offset int) ([]StepSuggestion, int64, error) {
+	var list []StepSuggestion
+	var count int64
+
+	tx := r.db.WithContext(ctx).Model(&StepSuggestion{}).Where("step_id = ?", stepID)
+	if status != "" {
+		tx = tx.Where("status = ?", status)
+	}
+	if err := tx.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err := tx.Omit("content").
+		Order("created_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&list).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return list, count, nil
+}
+
+// reviewSuggestion closes a pending suggestion. It fails with
+// errSuggestionReviewed when someone else reviewed it first.
+func (r *repositoryImpl) reviewSuggestion(ctx context.Context, id uuid.UUID, status string, reviewerID uuid.UUID, note string) error {
+	res := r.db.WithContext(ctx).Model(&StepSuggestion{}).
+		Where("id = ? AND status = ?", id, SuggestionPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewer_id": reviewerID,
+			"review_note": note,
+			"reviewed_at": time.Now(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errSuggestionReviewed
+	}
+	return nil
+}
+
+func (r *repositoryImpl) reopenSuggestion(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&StepSuggestion{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":      SuggestionPending,
+			"reviewer_id": nil,
+			"review_note": "",
+			"reviewed_at": nil,
+		}).Error
+}
+
+func (r *repositoryImpl) setSuggestionRevision(ctx context.Context, id uuid.UUID, number int) error {
+	return r.db.WithContext(ctx).Model(&StepSuggestion{}).Where("id = ?", id).Update("revision", number).Error
+}
+
+func (r *repositoryImpl) addSuggestionComment(ctx context.Context, comment *StepSuggestionComment) error {
+	return r.db.WithContext(ctx).Create(comment).Error
+}
+
 func (r *repositoryImpl) addComment(ctx context.Context, comment *WalkthroughComment) error {
 	return r.db.WithContext(ctx).Create(comment).Error
 }
@@ -119,3 +532,193 @@ func (r *repositoryImpl) listComments(ctx context.Context, walkthroughID string,
  ************ INTERACTIONS ************
  **************************************
  **************************************/
+
+/* PROGRESS */
+
+type progressRepository interface {
+	get(ctx context.Context, userID, walkthroughID uuid.UUID) (*WalkthroughProgress, error)
+	touch(ctx context.Context, userID, walkthroughID uuid.UUID, lastStepID *uuid.UUID) error
+	reset(ctx context.Context, userID, walkthroughID uuid.UUID) error
+	list(ctx context.Context, userID uuid.UUID, limit, offset int) ([]Progress, int64, error)
+
+	complete(ctx context.Context, completion *StepCompletion) error
+	uncomplete(ctx context.Context, userID, stepID uuid.UUID) error
+	completed(ctx context.Context, userID, walkthroughID uuid.UUID) ([]uuid.UUID, error)
+
+	check(ctx context.Context, tick *ChecklistTick) error
+	uncheck(ctx context.Context, userID, itemID uuid.UUID) error
+	checked(ctx context.Context, userID, walkthroughID uuid.UUID) ([]uuid.UUID, error)
+
+	funnelSteps(ctx context.Context, walkthroughID uuid.UUID) ([]FunnelStep, error)
+	resting(ctx context.Context, walkthroughID uuid.UUID) (map[uuid.UUID]int64, error)
+	started(ctx context.Context, walkthroughID uuid.UUID) (int64, error)
+	finished(ctx context.Context, walkthroughID uuid.UUID, totalSteps int) (int64, error)
+}
+
+type progressRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func newProgressRepo(db *gorm.DB) progressRepository {
+	return &progressRepositoryImpl{db: db}
+}
+
+func (r *progressRepositoryImpl) get(ctx context.Context, userID, walkthroughID uuid.UUID) (*WalkthroughProgress, error) {
+	var p WalkthroughProgress
+	err := r.db.WithContext(ctx).First(&p, "user_id = ? AND walkthrough_id = ?", userID, walkthroughID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// touch starts the reader's progress or bumps it, moving the resume point
+// when lastStepID is given.
+func (r *progressRepositoryImpl) touch(ctx context.Context, userID, walkthroughID uuid.UUID, lastStepID *uuid.UUID) error {
+	p := WalkthroughProgress{UserID: userID, WalkthroughID: walkthroughID, LastStepID: lastStepID}
+	set := map[string]interface{}{"updated_at": time.Now()}
+	if lastStepID != nil {
+		set["last_step_id"] = *lastStepID
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "walkthrough_id"}},
+			DoUpdates: clause.Assignments(set),
+		}).
+		Create(&p).Error
+}
+
+// reset forgets everything the reader did in the walkthrough.
+func (r *progressRepositoryImpl) reset(ctx context.Context, userID, walkthroughID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		where := "user_id = ? AND walkthrough_id = ?"
+		if err := tx.Where(where, userID, walkthroughID).Delete(&ChecklistTick{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where(where, userID, walkthroughID).Delete(&StepCompletion{}).Error; err != nil {
+			return err
+		}
+		return tx.Where(where, userID, walkthroughID).Delete(&WalkthroughProgress{}).Error
+	})
+}
+
+// list returns the reader's walkthroughs, most recently read first.
+func (r *progressRepositoryImpl) list(ctx context.Context, userID uuid.UUID, limit, offset int) ([]Progress, int64, error) {
+	var list []Progress
+	var count int64
+
+	tx := r.db.WithContext(ctx).Model(&WalkthroughProgress{}).Where("user_id = ?", userID)
+	if err := tx.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err := r.db.WithContext(ctx).Table("walkthrough_progresses AS p").
+		Select(`p.walkthrough_id, w.title, w.game, p.last_step_id,
+			p.created_at AS started_at, p.updated_at,
+			(SELECT COUNT(*) FROM walkthrough_steps s WHERE s.walkthrough_id = p.walkthrough_id) AS total_steps,
+			(SELECT COUNT(*) FROM step_completions c JOIN walkthrough_steps s ON s.id = c.step_id
+				WHERE c.user_id = p.user_id AND s.walkthrough_id = p.walkthrough_id) AS completed_steps`).
+		Joins("JOIN walkthroughs w ON w.id = p.walkthrough_id").
+		Where("p.user_id = ?", userID).
+		Order("p.updated_at DESC").
+		Limit(limit).Offset(offset).
+		Scan(&list).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return list, count, nil
+}
+
+func (r *progressRepositoryImpl) complete(ctx context.Context, completion *StepCompletion) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(completion).Error
+}
+
+func (r *progressRepositoryImpl) uncomplete(ctx context.Context, userID, stepID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("user_id = ? AND step_id = ?", userID, stepID).Delete(&StepCompletion{}).Error
+}
+
+// completed only returns steps that still exist.
+func (r *progressRepositoryImpl) completed(ctx context.Context, userID, walkthroughID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Table("step_completions AS c").
+		Joins("JOIN walkthrough_steps s ON s.id = c.step_id").
+		Where("c.user_id = ? AND s.walkthrough_id = ?", userID, walkthroughID).
+		Pluck("c.step_id", &ids).Error
+	return ids, err
+}
+
+func (r *progressRepositoryImpl) check(ctx context.Context, tick *ChecklistTick) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(tick).Error
+}
+
+func (r *progressRepositoryImpl) uncheck(ctx context.Context, userID, itemID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("user_id = ? AND item_id = ?", userID, itemID).Delete(&ChecklistTick{}).Error
+}
+
+func (r *progressRepositoryImpl) checked(ctx context.Context, userID, walkthroughID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&ChecklistTick{}).
+		Where("user_id = ? AND walkthrough_id = ?", userID, walkthroughID).
+		Pluck("item_id", &ids).Error
+	return ids, err
+}
+
+// funnelSteps counts the readers who completed each step, in reading order.
+func (r *progressRepositoryImpl) funnelSteps(ctx context.Context, walkthroughID uuid.UUID) ([]FunnelStep, error) {
+	var steps []FunnelStep
+	err := r.db.WithContext(ctx).Table("walkthrough_steps AS s").
+		Select("s.id AS step_id, s.step_number, s.title, COUNT(c.user_id) AS completed").
+		Joins("LEFT JOIN step_completions c ON c.step_id = s.id").
+		Where("s.walkthrough_id = ?", walkthroughID).
+		Group("s.id, s.step_number, s.title, s.created_at").
+		Order("s.step_number ASC, s.created_at ASC").
+		Scan(&steps).Error
+	return steps, err
+}
+
+// resting counts readers by the step they last stopped at.
+func (r *progressRepositoryImpl) resting(ctx context.Context, walkthroughID uuid.UUID) (map[uuid.UUID]int64, error) {
+	var rows []struct {
+		LastStepID uuid.UUID
+		Readers    int64
+	}
+	err := r.db.WithContext(ctx).Model(&WalkthroughProgress{}).
+		Select("last_step_id, COUNT(*) AS readers").
+		Where("walkthrough_id = ? AND last_step_id IS NOT NULL", walkthroughID).
+		Group("last_step_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uuid.UUID]int64, len(rows))
+	for _, row := range rows {
+		counts[row.LastStepID] = row.Readers
+	}
+	return counts, nil
+}
+
+func (r *progressRepositoryImpl) started(ctx context.Context, walkthroughID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&WalkthroughProgress{}).
+		Where("walkthrough_id = ?", walkthroughID).
+		Count(&count).Error
+	return count, err
+}
+
+// finished counts the readers who completed every current step.
+func (r *progressRepositoryImpl) finished(ctx context.Context, walkthroughID uuid.UUID, totalSteps int) (int64, error) {
+	if totalSteps == 0 {
+		return 0, nil
+	}
+	var count int64
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT COUNT(*) FROM (
+			SELECT c.user_id FROM step_completions c
+			JOIN walkthrough_steps s ON s.id = c.step_id
+			WHERE s.walkthrough_id = ?
+			GROUP BY c.user_id
+			HAVING COUNT(*) = ?
+		) AS done`, walkthroughID, totalSteps).
+		Scan(&count).Error
+	return count, err
+}
@@ -0,0 +1,98 @@
+package walkthrough
+
+import (
+	"errors"
+	"pokemon/internal/domains/user"
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GET /walkthroughs/steps/:step_id/revisions
+func (h *handler) listRevisions(c *fiber.Ctx) error {
+	limit, offset := utils.ParsePagination(c)
+	list, total, err := h.revisions.list(c.Context(), c.Params("step_id"), limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": total,
+		"items": list,
+	})
+}
+
+// GET /walkthroughs/steps/:step_id/revisions/:number
+func (h *handler) getRevision(c *fiber.Ctx) error {
+	number, err := c.ParamsInt("number")
+	if err != nil || number < 1 {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid revision number")
+	}
+
+	rev, err := h.revisions.get(c.Context(), c.Params("step_id"), number)
+	if err != nil {
+		return revisionError(err)
+	}
+	return c.JSON(rev)
+}
+
+// GET /walkthroughs/steps/:step_id/diff?from=&to=
+func (h *handler) diffRevisions(c *fiber.Ctx) error {
+	from, to := c.QueryInt("from"), c.QueryInt("to")
+	if from < 1 || to < 1 {
+		return fiber.NewError(fiber.StatusBadRequest, "from and to revision numbers are required")
+	}
+
+	diff, err := h.revisions.diff(c.Context(), c.Params("step_id"), from, to)
+	if err != nil {
+		return revisionError(err)
+	}
+	return c.JSON(diff)
+}
+
+// POST /walkthroughs/steps/:step_id/revisions/:number/rollback
+func (h *handler) rollback(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+	number, err := c.ParamsInt("number")
+	if err != nil || number < 1 {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid revision number")
+	}
+
+	stepID := c.Params("step_id")
+	existing, err := h.s.getStep(c.Context(), stepID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Step not found")
+	}
+	if err := h.checkEditor(c, existing.WalkthroughID, userID); err != nil {
+		return err
+	}
+
+	step, err := h.revisions.rollback(c.Context(), stepID, number, userID)
+	if err != nil {
+		return revisionError(err)
+	}
+	return c.JSON(step)
+}
+
+// checkEditor only lets the walkthrough's author or a moderator edit its steps.
+func (h *handler) checkEditor(c *fiber.Ctx, walkthroughID, userID uuid.UUID) error {
+	owner, err := h.s.ownerOf(c.Context(), walkthroughID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Walkthrough not found")
+	}
+	if owner != userID && !utils.HasRole(c, user.RoleModerator, user.RoleAdmin) {
+		return fiber.ErrForbidden
+	}
+	return nil
+}
+
+func revisionError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, "Revision not found")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
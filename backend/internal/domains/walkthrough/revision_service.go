@@ -0,0 +1,95 @@
+package walkthrough
+
+import (
+	"context"
+	"fmt"
+	"pokemon/pkg/content"
+
+	"github.com/google/uuid"
+)
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type revisionService interface {
+	list(ctx context.Context, stepID string, limit, offset int) ([]StepRevision, int64, error)
+	get(ctx context.Context, stepID string, number int) (*StepRevision, error)
+	diff(ctx context.Context, stepID string, from, to int) (*RevisionDiff, error)
+	rollback(ctx context.Context, stepID string, number int, editorID uuid.UUID) (*WalkthroughStep, error)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type revisionServiceImpl struct {
+	repo  repository
+	steps service
+}
+
+func newRevisionService(repo repository, steps service) revisionService {
+	return &revisionServiceImpl{repo: repo, steps: steps}
+}
+
+func (s *revisionServiceImpl) list(ctx context.Context, stepID string, limit, offset int) ([]StepRevision, int64, error) {
+	return s.repo.listRevisions(ctx, stepID, limit, offset)
+}
+
+func (s *revisionServiceImpl) get(ctx context.Context, stepID string, number int) (*StepRevision, error) {
+	return s.repo.getRevision(ctx, stepID, number)
+}
+
+func (s *revisionServiceImpl) diff(ctx context.Context, stepID string, from, to int) (*RevisionDiff, error) {
+	older, err := s.repo.getRevision(ctx, stepID, from)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := s.repo.getRevision(ctx, stepID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RevisionDiff{
+		From: from,
+		To:   to,
+		Changes: Changes{
+			Title:   content.ChangeOf(older.Title, newer.Title),
+			Content: content.Diff(older.Content, newer.Content),
+		},
+	}, nil
+}
+
+// rollback restores the text of an old revision as a new revision.
+func (s *revisionServiceImpl) rollback(ctx context.Context, stepID string, number int, editorID uuid.UUID) (*WalkthroughStep, error) {
+	rev, err := s.repo.getRevision(ctx, stepID, number)
+	if err != nil {
+		return nil, err
+	}
+	step, err := s.repo.getStep(ctx, stepID)
+	if err != nil {
+		return nil, err
+	}
+
+	step.Title, step.Content = rev.Title, rev.Content
+	step.EditSummary = fmt.Sprintf("Rolled back to revision %d", number)
+	if err := s.steps.updateStep(ctx, step, editorID); err != nil {
+		return nil, err
+	}
+	return step, nil
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func revisionOf(step *WalkthroughStep, authorID uuid.UUID, summary string) *StepRevision {
+	return &StepRevision{
+		StepID:        step.ID,
+		WalkthroughID: step.WalkthroughID,
+		Title:         step.Title,
+		Content:       step.Content,
+		AuthorID:      authorID,
+		EditSummary:   summary,
+	}
+}
@@ -1,20 +1,50 @@
 package walkthrough
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"pokemon/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
 
 func (h *handler) RegisterRoutes(router fiber.Router) {
 	group := router.Group("/walkthroughs")
+	auth := middleware.AuthRequired()
 
-	group.Post("/", h.create)
+	group.Post("/", auth, h.create)
 	group.Get("/", h.list)
-	group.Get("/:id", h.get)
-	group.Put("/:id", h.update)
-	group.Delete("/:id", h.delete)
+	group.Get("/progress", auth, h.listProgress)
+	group.Get("/:id", middleware.AuthOptional(), h.get)
+	group.Put("/:id", auth, h.update)
+	group.Delete("/:id", auth, h.delete)
+
+	group.Post("/:id/steps", auth, h.addStep)
+	group.Put("/:id/steps/order", auth, h.reorderSteps)
+	group.Put("/steps/:step_id", auth, h.updateStep)
+	group.Delete("/steps/:step_id", auth, h.deleteStep)
+
+	group.Get("/steps/:step_id/revisions", h.listRevisions)
+	group.Get("/steps/:step_id/revisions/:number", h.getRevision)
+	group.Get("/steps/:step_id/diff", h.diffRevisions)
+	group.Post("/steps/:step_id/revisions/:number/rollback", auth, h.rollback)
+
+	group.Get("/steps/:step_id/suggestions", h.listSuggestions)
+	group.Get("/steps/:step_id/suggestions/:suggestion_id", h.getSuggestion)
+	group.Post("/steps/:step_id/suggestions", auth, h.suggestEdit)
+	group.Post("/steps/:step_id/suggestions/:suggestion_id/comments", auth, h.commentSuggestion)
+	group.Post("/steps/:step_id/suggestions/:suggestion_id/accept", auth, h.acceptSuggestion)
+	group.Post("/steps/:step_id/suggestions/:suggestion_id/reject", auth, h.rejectSuggestion)
+
+	group.Put("/steps/:step_id/checklist", auth, h.replaceChecklist)
+	group.Post("/steps/:step_id/complete", auth, h.completeStep)
+	group.Delete("/steps/:step_id/complete", auth, h.uncompleteStep)
+	group.Post("/checklist/:item_id/check", auth, h.checkItem)
+	group.Delete("/checklist/:item_id/check", auth, h.uncheckItem)
 
-	group.Post("/:id/steps", h.addStep)
-	group.Put("/steps/:step_id", h.updateStep)
-	group.Delete("/steps/:step_id", h.deleteStep)
+	group.Get("/:id/progress", auth, h.getProgress)
+	group.Put("/:id/progress", auth, h.setLastStep)
+	group.Delete("/:id/progress", auth, h.resetProgress)
+	group.Get("/:id/funnel", auth, h.funnel)
 
-	group.Post("/:id/comments", h.addComment)
+	group.Post("/:id/comments", auth, h.addComment)
 	group.Get("/:id/comments", h.listComments)
 }
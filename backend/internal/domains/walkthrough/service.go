@@ -3,21 +3,34 @@ package walkthrough
 import (
 	"context"
 	"errors"
+	"pokemon/internal/domains/game"
+	"pokemon/pkg/content"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
+var errInvalidStepOrder = errors.New("step_ids must list every step of the walkthrough exactly once")
+
 type service interface {
 	createWalkthrough(ctx context.Context, wt *Walkthrough) error
 	getWalkthrough(ctx context.Context, id string) (*Walkthrough, error)
 	listWalkthroughs(ctx context.Context, limit, offset int) ([]Walkthrough, int64, error)
 	updateWalkthrough(ctx context.Context, wt *Walkthrough) error
 	deleteWalkthrough(ctx context.Context, id string) error
+	filterVersion(ctx context.Context, wt *Walkthrough, version string) error
 
-	addStep(ctx context.Context, step *WalkthroughStep) error
-	updateStep(ctx context.Context, step *WalkthroughStep) error
+	ownerOf(ctx context.Context, walkthroughID uuid.UUID) (uuid.UUID, error)
+	getStep(ctx context.Context, stepID string) (*WalkthroughStep, error)
+	addStep(ctx context.Context, step *WalkthroughStep, authorID uuid.UUID) error
+	updateStep(ctx context.Context, step *WalkthroughStep, editorID uuid.UUID) error
+	reviseStep(ctx context.Context, step *WalkthroughStep, editorID uuid.UUID, base int) (int, error)
 	deleteStep(ctx context.Context, stepID string) error
+	reorderSteps(ctx context.Context, walkthroughID uuid.UUID, stepIDs []uuid.UUID) error
+
+	getChecklistItem(ctx context.Context, itemID string) (*StepChecklistItem, error)
+	replaceChecklist(ctx context.Context, stepID uuid.UUID, items []StepChecklistItem) error
 
 	addComment(ctx context.Context, comment *WalkthroughComment) error
 	listComments(ctx context.Context, walkthroughID string, limit, offset int) ([]WalkthroughComment, int64, error)
@@ -25,22 +38,38 @@ type service interface {
 
 type serviceImpl struct {
 	repo repository
+	renderer content.Renderer
 	redis *redis.Client
 }
 
-func newServ(repo repository, redis *redis.Client) service {
-	return &serviceImpl{repo: repo, redis: redis}
+func newServ(repo repository, renderer content.Renderer, redis *redis.Client) service {
+	return &serviceImpl{repo: repo, renderer: renderer, redis: redis}
 }
 
 func (s *serviceImpl) createWalkthrough(ctx context.Context, wt *Walkthrough) error {
+	if err := s.resolveGame(ctx, wt); err != nil {
+		return err
+	}
 	if wt.Title == "" || wt.Game == "" {
 		return errors.New("title and game are required")
 	}
-	return s.repo.create(ctx, wt)
+	if err := s.repo.create(ctx, wt); err != nil {
+		return err
+	}
+	s.renderSteps(ctx, wt)
+	return nil
 }
 
 func (s *serviceImpl) getWalkthrough(ctx context.Context, id string) (*Walkthrough, error) {
-	return s.repo.getByID(ctx, id)
+	wt, err := s.repo.getByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if wt.Contributors, err = s.repo.contributors(ctx, wt.ID, wt.UserID); err != nil {
+		return nil, err
+	}
+	s.renderSteps(ctx, wt)
+	return wt, nil
 }
 
 func (s *serviceImpl) listWalkthroughs(ctx context.Context, limit, offset int) ([]Walkthrough, int64, error) {
@@ -51,25 +80,161 @@ func (s *serviceImpl) updateWalkthrough(ctx context.Context, wt *Walkthrough) er
 	if wt.ID == uuid.Nil {
 		return errors.New("invalid walkthrough ID")
 	}
+	if err := s.resolveGame(ctx, wt); err != nil {
+		return err
+	}
 	return s.repo.update(ctx, wt)
 }
 
+// resolveGame checks the game and version group the walkthrough links to.
+// Linking a game links its version group too, and names the game when the
+// walkthrough doesn't.
+func (s *serviceImpl) resolveGame(ctx context.Context, wt *Walkthrough) error {
+	wt.LinkedGame, wt.VersionGroup = nil, nil
+
+	if wt.GameID != nil {
+		g, err := s.repo.getGame(ctx, *wt.GameID)
+		if err != nil {
+			return errors.New("game not found")
+		}
+		if wt.VersionGroupID == nil {
+			wt.VersionGroupID = g.VersionGroupID
+		} else if g.VersionGroupID == nil || *g.VersionGroupID != *wt.VersionGroupID {
+			return errors.New("game doesn't belong to the version group")
+		}
+		if wt.Game == "" {
+			wt.Game = g.Name
+		}
+		return nil
+	}
+
+	if wt.VersionGroupID != nil {
+		group, err := s.repo.getVersionGroup(ctx, *wt.VersionGroupID)
+		if err != nil {
+			return errors.New("version group not found")
+		}
+		if wt.Game == "" {
+			wt.Game = group.Name
+		}
+	}
+	return nil
+}
+
+// filterVersion narrows the walkthrough down to what readers of one version
+// of the game need: other versions' steps and sections are dropped.
+func (s *serviceImpl) filterVersion(ctx context.Context, wt *Walkthrough, version string) error {
+	var games []game.Game
+	if wt.VersionGroup != nil {
+		games = wt.VersionGroup.Games
+	} else if wt.LinkedGame != nil {
+		games = []game.Game{*wt.LinkedGame}
+	}
+	g, ok := findVersion(games, version)
+	if !ok {
+		return errUnknownVersion
+	}
+
+	steps := make([]WalkthroughStep, 0, len(wt.Steps))
+	sources := make([]string, 0, len(wt.Steps))
+	for _, step := range wt.Steps {
+		if !step.appliesTo(g) {
+			continue
+		}
+		step.Content = filterSections(step.Content, g)
+		steps = append(steps, step)
+		sources = append(sources, step.Content)
+	}
+	for i, rendered := range s.renderer.RenderAll(ctx, sources) {
+		steps[i].Rendered = rendered
+	}
+	wt.Steps, wt.Version = steps, g.Name
+	return nil
+}
+
 func (s *serviceImpl) deleteWalkthrough(ctx context.Context, id string) error {
 	return s.repo.delete(ctx, id)
 }
 
-func (s *serviceImpl) addStep(ctx context.Context, step *WalkthroughStep) error {
+func (s *serviceImpl) ownerOf(ctx context.Context, walkthroughID uuid.UUID) (uuid.UUID, error) {
+	return s.repo.ownerOf(ctx, walkthroughID)
+}
+
+func (s *serviceImpl) getStep(ctx context.Context, stepID string) (*WalkthroughStep, error) {
+	step, err := s.repo.getStep(ctx, stepID)
+	if err != nil {
+		return nil, err
+	}
+	s.renderStep(ctx, step)
+	return step, nil
+}
+
+func (s *serviceImpl) addStep(ctx context.Context, step *WalkthroughStep, authorID uuid.UUID) error {
 	if step.WalkthroughID == uuid.Nil || step.Title == "" || step.Content == "" {
 		return errors.New("invalid walkthrough step")
 	}
-	return s.repo.addStep(ctx, step)
+	if len(step.EditSummary) > 255 {
+		return errors.New("edit_summary cannot be longer than 255 characters")
+	}
+	if err := normalizeChecklist(step.Checklist); err != nil {
+		return err
+	}
+	if err := s.resolveVersions(ctx, step); err != nil {
+		return err
+	}
+	summary := step.EditSummary
+	if summary == "" {
+		summary = "Created"
+	}
+	if err := s.repo.addStep(ctx, step, revisionOf(step, authorID, summary)); err != nil {
+		return err
+	}
+	s.renderStep(ctx, step)
+	return nil
+}
+
+// updateStep saves the step as a new revision credited to editorID. The step
+// stays in its walkthrough and keeps its position unless a new one is given.
+func (s *serviceImpl) updateStep(ctx context.Context, step *WalkthroughStep, editorID uuid.UUID) error {
+	_, err := s.saveStep(ctx, step, editorID, nil)
+	return err
 }
 
-func (s *serviceImpl) updateStep(ctx context.Context, step *WalkthroughStep) error {
+// reviseStep is updateStep for an edit made against revision base of the step.
+// It fails with errSuggestionStale when the step's text changed since, and
+// returns the number of the new revision.
+func (s *serviceImpl) reviseStep(ctx context.Context, step *WalkthroughStep, editorID uuid.UUID, base int) (int, error) {
+	return s.saveStep(ctx, step, editorID, &base)
+}
+
+func (s *serviceImpl) saveStep(ctx context.Context, step *WalkthroughStep, editorID uuid.UUID, base *int) (int, error) {
 	if step.ID == uuid.Nil {
-		return errors.New("invalid step ID")
+		return 0, errors.New("invalid step ID")
+	}
+	if step.Title == "" || step.Content == "" {
+		return 0, errors.New("invalid walkthrough step")
+	}
+	if len(step.EditSummary) > 255 {
+		return 0, errors.New("edit_summary cannot be longer than 255 characters")
+	}
+
+	previous, err := s.repo.getStep(ctx, step.ID.String())
+	if err != nil {
+		return 0, err
+	}
+	step.WalkthroughID, step.CreatedAt = previous.WalkthroughID, previous.CreatedAt
+	if step.StepNumber == 0 {
+		step.StepNumber = previous.StepNumber
+	}
+	if err := s.resolveVersions(ctx, step); err != nil {
+		return 0, err
 	}
-	return s.repo.updateStep(ctx, step)
+
+	rev := revisionOf(step, editorID, step.EditSummary)
+	if err := s.repo.updateStep(ctx, step, previous, rev, base); err != nil {
+		return 0, err
+	}
+	s.renderStep(ctx, step)
+	return rev.Number, nil
 }
 
 func (s *serviceImpl) deleteStep(ctx context.Context, stepID string) error {
@@ -79,6 +244,48 @@ func (s *serviceImpl) deleteStep(ctx context.Context, stepID string) error {
 	return s.repo.deleteStep(ctx, stepID)
 }
 
+// reorderSteps numbers the steps in the given order, which must list every
+// step of the walkthrough once.
+func (s *serviceImpl) reorderSteps(ctx context.Context, walkthroughID uuid.UUID, stepIDs []uuid.UUID) error {
+	seen := make(map[uuid.UUID]bool, len(stepIDs))
+	for _, id := range stepIDs {
+		if seen[id] {
+			return errInvalidStepOrder
+		}
+		seen[id] = true
+	}
+	return s.repo.reorderSteps(ctx, walkthroughID, stepIDs)
+}
+
+// resolveVersions stores the versions of a step as IDs of the walkthrough's
+// games.
+func (s *serviceImpl) resolveVersions(ctx context.Context, step *WalkthroughStep) error {
+	if len(step.Versions) == 0 {
+		return nil
+	}
+	games, err := s.repo.versionGames(ctx, step.WalkthroughID)
+	if err != nil {
+		return err
+	}
+	ids, err := resolveVersions(games, step.Versions)
+	if err != nil {
+		return err
+	}
+	step.Versions = ids
+	return nil
+}
+
+func (s *serviceImpl) getChecklistItem(ctx context.Context, itemID string) (*StepChecklistItem, error) {
+	return s.repo.getChecklistItem(ctx, itemID)
+}
+
+func (s *serviceImpl) replaceChecklist(ctx context.Context, stepID uuid.UUID, items []StepChecklistItem) error {
+	if err := normalizeChecklist(items); err != nil {
+		return err
+	}
+	return s.repo.replaceChecklist(ctx, stepID, items)
+}
+
 func (s *serviceImpl) addComment(ctx context.Context, comment *WalkthroughComment) error {
 	if comment.WalkthroughID == uuid.Nil || comment.UserID == uuid.Nil || comment.Content == "" {
 		return errors.New("invalid comment")
@@ -92,3 +299,41 @@ func (s *serviceImpl) listComments(ctx context.Context, walkthroughID string, li
 	}
 	return s.repo.listComments(ctx, walkthroughID, limit, offset)
 }
+
+// renderSteps attaches the sanitized HTML of every step's content.
+func (s *serviceImpl) renderSteps(ctx context.Context, wt *Walkthrough) {
+	sources := make([]string, len(wt.Steps))
+	for i := range wt.Steps {
+		sources[i] = labelSections(wt.Steps[i].Content)
+	}
+	for i, rendered := range s.renderer.RenderAll(ctx, sources) {
+		wt.Steps[i].Rendered = rendered
+	}
+}
+
+// renderStep shows every version's sections, each labeled with its versions.
+func (s *serviceImpl) renderStep(ctx context.Context, step *WalkthroughStep) {
+	step.Rendered = s.renderer.Render(ctx, labelSections(step.Content))
+}
+
+// normalizeChecklist validates the items and numbers them in the given order.
+func normalizeChecklist(items []StepChecklistItem) error {
+	for i := range items {
+		items[i].Label = strings.TrimSpace(items[i].Label)
+		if items[i].Label == "" {
+			return errors.New("checklist items need a label")
+		}
+		if len(items[i].Label) > 255 {
+			return errors.New("checklist labels cannot be longer than 255 characters")
+		}
+		switch items[i].Kind {
+		case "":
+			items[i].Kind = ChecklistTask
+		case ChecklistItem, ChecklistTrainer, ChecklistTask:
+		default:
+			return errors.New("checklist kind must be item, trainer or task")
+		}
+		items[i].Position = i + 1
+	}
+	return nil
+}
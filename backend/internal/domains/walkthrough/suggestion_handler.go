@@ -0,0 +1,168 @@
+package walkthrough
+
+import (
+	"context"
+	"errors"
+	"pokemon/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// POST /walkthroughs/steps/:step_id/suggestions
+func (h *handler) suggestEdit(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+	step, err := h.s.getStep(c.Context(), c.Params("step_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Step not found")
+	}
+
+	var req suggestEditRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	// Fields left out keep the current text
+	suggestion := StepSuggestion{
+		UserID:  userID,
+		Title:   step.Title,
+		Content: step.Content,
+		Message: req.Message,
+	}
+	if req.Title != nil {
+		suggestion.Title = *req.Title
+	}
+	if req.Content != nil {
+		suggestion.Content = *req.Content
+	}
+
+	if err := h.suggestions.propose(c.Context(), step, &suggestion); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(suggestion)
+}
+
+// GET /walkthroughs/steps/:step_id/suggestions?status=
+func (h *handler) listSuggestions(c *fiber.Ctx) error {
+	status := c.Query("status")
+	switch status {
+	case "", SuggestionPending, SuggestionAccepted, SuggestionRejected:
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid status")
+	}
+
+	limit, offset := utils.ParsePagination(c)
+	list, total, err := h.suggestions.list(c.Context(), c.Params("step_id"), status, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"total": total,
+		"items": list,
+	})
+}
+
+// GET /walkthroughs/steps/:step_id/suggestions/:suggestion_id
+func (h *handler) getSuggestion(c *fiber.Ctx) error {
+	step, err := h.s.getStep(c.Context(), c.Params("step_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Step not found")
+	}
+
+	suggestion, err := h.suggestions.get(c.Context(), step, c.Params("suggestion_id"))
+	if err != nil {
+		return suggestionError(err)
+	}
+	return c.JSON(suggestion)
+}
+
+// POST /walkthroughs/steps/:step_id/suggestions/:suggestion_id/comments
+func (h *handler) commentSuggestion(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+	step, err := h.s.getStep(c.Context(), c.Params("step_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Step not found")
+	}
+	suggestion, err := h.suggestions.get(c.Context(), step, c.Params("suggestion_id"))
+	if err != nil {
+		return suggestionError(err)
+	}
+
+	// The review is a conversation between the proposer and the editors
+	if suggestion.UserID != userID {
+		if err := h.checkEditor(c, step.WalkthroughID, userID); err != nil {
+			return err
+		}
+	}
+
+	var comment StepSuggestionComment
+	if err := c.BodyParser(&comment); err != nil {
+		return fiber.ErrBadRequest
+	}
+	comment.ID = uuid.Nil
+	comment.SuggestionID = suggestion.ID
+	comment.UserID = userID
+
+	if err := h.suggestions.comment(c.Context(), &comment); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(comment)
+}
+
+// POST /walkthroughs/steps/:step_id/suggestions/:suggestion_id/accept
+func (h *handler) acceptSuggestion(c *fiber.Ctx) error {
+	return h.reviewSuggestion(c, h.suggestions.accept)
+}
+
+// POST /walkthroughs/steps/:step_id/suggestions/:suggestion_id/reject
+func (h *handler) rejectSuggestion(c *fiber.Ctx) error {
+	return h.reviewSuggestion(c, h.suggestions.reject)
+}
+
+type reviewFunc func(ctx context.Context, step *WalkthroughStep, id string, reviewerID uuid.UUID, note string) (*StepSuggestion, error)
+
+// reviewSuggestion lets the walkthrough's author or a moderator close a
+// suggestion with an optional note.
+func (h *handler) reviewSuggestion(c *fiber.Ctx, review reviewFunc) error {
+	userID, err := utils.GetUserIDFromLocals(c)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+	step, err := h.s.getStep(c.Context(), c.Params("step_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Step not found")
+	}
+	if err := h.checkEditor(c, step.WalkthroughID, userID); err != nil {
+		return err
+	}
+
+	var req reviewSuggestionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.ErrBadRequest
+		}
+	}
+
+	suggestion, err := review(c.Context(), step, c.Params("suggestion_id"), userID, req.Note)
+	if err != nil {
+		return suggestionError(err)
+	}
+	return c.JSON(suggestion)
+}
+
+func suggestionError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Suggestion not found")
+	case errors.Is(err, errSuggestionReviewed), errors.Is(err, errSuggestionStale):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
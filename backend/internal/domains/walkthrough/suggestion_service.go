@@ -0,0 +1,151 @@
+package walkthrough
+
+import (
+	"context"
+	"errors"
+	"pokemon/pkg/content"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errSuggestionReviewed  = errors.New("suggestion has already been reviewed")
+	errSuggestionUnchanged = errors.New("suggestion doesn't change the step")
+	errSuggestionStale     = errors.New("the step changed since the suggestion was made, it has to be proposed again against the current text")
+)
+
+/*********************
+ * SERVICE INTERFACE *
+ *********************/
+
+type suggestionService interface {
+	propose(ctx context.Context, step *WalkthroughStep, suggestion *StepSuggestion) error
+	list(ctx context.Context, stepID, status string, limit, offset int) ([]StepSuggestion, int64, error)
+	get(ctx context.Context, step *WalkthroughStep, id string) (*StepSuggestion, error)
+	comment(ctx context.Context, comment *StepSuggestionComment) error
+	accept(ctx context.Context, step *WalkthroughStep, id string, reviewerID uuid.UUID, note string) (*StepSuggestion, error)
+	reject(ctx context.Context, step *WalkthroughStep, id string, reviewerID uuid.UUID, note string) (*StepSuggestion, error)
+}
+
+/**************************
+ * SERVICE IMPLEMENTATION *
+ **************************/
+
+type suggestionServiceImpl struct {
+	repo  repository
+	steps service
+}
+
+func newSuggestionService(repo repository, steps service) suggestionService {
+	return &suggestionServiceImpl{repo: repo, steps: steps}
+}
+
+// propose records a pending suggestion against the current revision of the
+// step.
+func (s *suggestionServiceImpl) propose(ctx context.Context, step *WalkthroughStep, suggestion *StepSuggestion) error {
+	if suggestion.Title == "" || suggestion.Content == "" {
+		return errors.New("title and content are required")
+	}
+	if len(suggestion.Message) > 255 {
+		return errors.New("message cannot be longer than 255 characters")
+	}
+	if changesOf(step, suggestion) == nil {
+		return errSuggestionUnchanged
+	}
+
+	base, err := s.repo.latestRevision(ctx, step.ID)
+	if err != nil {
+		return err
+	}
+	suggestion.StepID, suggestion.WalkthroughID = step.ID, step.WalkthroughID
+	suggestion.BaseRevision = base
+	suggestion.Status = SuggestionPending
+	if err := s.repo.createSuggestion(ctx, suggestion); err != nil {
+		return err
+	}
+	suggestion.Changes = changesOf(step, suggestion)
+	return nil
+}
+
+func (s *suggestionServiceImpl) list(ctx context.Context, stepID, status string, limit, offset int) ([]StepSuggestion, int64, error) {
+	return s.repo.listSuggestions(ctx, stepID, status, limit, offset)
+}
+
+// get attaches the diff against the current step to pending suggestions.
+func (s *suggestionServiceImpl) get(ctx context.Context, step *WalkthroughStep, id string) (*StepSuggestion, error) {
+	suggestion, err := s.repo.getSuggestion(ctx, step.ID.String(), id)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion.Status == SuggestionPending {
+		suggestion.Changes = changesOf(step, suggestion)
+	}
+	return suggestion, nil
+}
+
+func (s *suggestionServiceImpl) comment(ctx context.Context, comment *StepSuggestionComment) error {
+	if comment.Content == "" {
+		return errors.New("content is required")
+	}
+	return s.repo.addSuggestionComment(ctx, comment)
+}
+
+// accept applies the suggestion as a new revision credited to its proposer.
+// The suggestion is claimed first so it can't be applied twice, and is only
+// applied if the step's text is still the one it was proposed against.
+func (s *suggestionServiceImpl) accept(ctx context.Context, step *WalkthroughStep, id string, reviewerID uuid.UUID, note string) (*StepSuggestion, error) {
+	suggestion, err := s.repo.getSuggestion(ctx, step.ID.String(), id)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion.Status != SuggestionPending {
+		return nil, errSuggestionReviewed
+	}
+	if err := s.repo.reviewSuggestion(ctx, suggestion.ID, SuggestionAccepted, reviewerID, note); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.getStep(ctx, step.ID.String())
+	if err != nil {
+		s.repo.reopenSuggestion(ctx, suggestion.ID)
+		return nil, err
+	}
+	updated.Title, updated.Content = suggestion.Title, suggestion.Content
+	updated.EditSummary = suggestion.Message
+	if updated.EditSummary == "" {
+		updated.EditSummary = "Suggested edit"
+	}
+	number, err := s.steps.reviseStep(ctx, updated, suggestion.UserID, suggestion.BaseRevision)
+	if err != nil {
+		s.repo.reopenSuggestion(ctx, suggestion.ID)
+		return nil, err
+	}
+	if err := s.repo.setSuggestionRevision(ctx, suggestion.ID, number); err != nil {
+		return nil, err
+	}
+	return s.repo.getSuggestion(ctx, step.ID.String(), id)
+}
+
+func (s *suggestionServiceImpl) reject(ctx context.Context, step *WalkthroughStep, id string, reviewerID uuid.UUID, note string) (*StepSuggestion, error) {
+	suggestion, err := s.repo.getSuggestion(ctx, step.ID.String(), id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.reviewSuggestion(ctx, suggestion.ID, SuggestionRejected, reviewerID, note); err != nil {
+		return nil, err
+	}
+	return s.repo.getSuggestion(ctx, step.ID.String(), id)
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// changesOf returns nil when the suggestion matches the step.
+func changesOf(step *WalkthroughStep, suggestion *StepSuggestion) *Changes {
+	title := content.ChangeOf(step.Title, suggestion.Title)
+	if title == nil && step.Content == suggestion.Content {
+		return nil
+	}
+	return &Changes{Title: title, Content: content.Diff(step.Content, suggestion.Content)}
+}
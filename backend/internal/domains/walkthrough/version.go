@@ -0,0 +1,136 @@
+package walkthrough
+
+import (
+	"errors"
+	"pokemon/internal/domains/game"
+	"strings"
+)
+
+// Parts of a step that only apply to some versions of a game are fenced in
+// its content:
+//
+//	:::version scarlet, violet
+//	Only readers of these versions see this.
+//	:::
+//
+// Versions are named after their game, with or without the "Pokémon" prefix.
+const (
+	versionFence    = ":::version"
+	versionFenceEnd = ":::"
+)
+
+var errUnknownVersion = errors.New("unknown version for this walkthrough")
+
+// versionKey turns a game or version name into a comparable key:
+// "Pokémon Scarlet" and "scarlet" both give "scarlet".
+func versionKey(name string) string {
+	key := strings.ToLower(strings.TrimSpace(name))
+	for _, prefix := range []string{"pokémon ", "pokemon "} {
+		key = strings.TrimPrefix(key, prefix)
+	}
+	return strings.Join(strings.Fields(key), "-")
+}
+
+// findVersion matches a game ID or name among the games of a walkthrough.
+func findVersion(games []game.Game, ref string) (*game.Game, bool) {
+	key := versionKey(ref)
+	for i := range games {
+		if games[i].ID.String() == ref || versionKey(games[i].Name) == key {
+			return &games[i], true
+		}
+	}
+	return nil, false
+}
+
+// resolveVersions turns the version refs of a step into game IDs.
+func resolveVersions(games []game.Game, refs []string) ([]string, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	if len(games) == 0 {
+		return nil, errors.New("link the walkthrough to a game or version group before marking steps version-specific")
+	}
+
+	ids := make([]string, 0, len(refs))
+	seen := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		g, ok := findVersion(games, ref)
+		if !ok {
+			return nil, errors.New("unknown version " + ref)
+		}
+		if id := g.ID.String(); !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// appliesTo tells whether a step is shown to readers of the version.
+func (step *WalkthroughStep) appliesTo(g *game.Game) bool {
+	if len(step.Versions) == 0 {
+		return true
+	}
+	for _, id := range step.Versions {
+		if id == g.ID.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// filterSections drops the fenced sections of other versions and the fences
+// themselves.
+func filterSections(text string, g *game.Game) string {
+	var out []string
+	inside, keep := false, true
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case !inside && strings.HasPrefix(trimmed, versionFence):
+			inside, keep = true, sectionFor(trimmed, g)
+		case inside && trimmed == versionFenceEnd:
+			inside, keep = false, true
+		case keep:
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// labelSections keeps every section, replacing fences with a label naming
+// the versions, for readers who haven't picked one.
+func labelSections(text string) string {
+	if !strings.Contains(text, versionFence) {
+		return text
+	}
+
+	var out []string
+	inside := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case !inside && strings.HasPrefix(trimmed, versionFence):
+			inside = true
+			names := strings.TrimSpace(strings.TrimPrefix(trimmed, versionFence))
+			out = append(out, "", "**Only in "+names+":**", "")
+		case inside && trimmed == versionFenceEnd:
+			inside = false
+			out = append(out, "")
+		default:
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+func sectionFor(fence string, g *game.Game) bool {
+	names := strings.TrimPrefix(fence, versionFence)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == g.ID.String() || versionKey(name) == versionKey(g.Name) {
+			return true
+		}
+	}
+	return false
+}
@@ -22,6 +22,7 @@ func AuthRequired() fiber.Handler {
         
         c.Locals("userID", claims.UserID)
         c.Locals("email", claims.Email)
+        c.Locals("role", claims.Role)
         
         return c.Next()
     }
@@ -42,6 +43,7 @@ func AuthOptional() fiber.Handler {
         
         c.Locals("userID", claims.UserID)
         c.Locals("email", claims.Email)
+        c.Locals("role", claims.Role)
         
         return c.Next()
     }
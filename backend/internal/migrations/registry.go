@@ -1,10 +1,25 @@
 package migrations
 
 import (
+	"pokemon/internal/domains/blog"
+	"pokemon/internal/domains/collection"
+	"pokemon/internal/domains/dexlink"
 	favoritepokemon "pokemon/internal/domains/favorite-pokemon"
 	"pokemon/internal/domains/forum"
+	"pokemon/internal/domains/game"
+	"pokemon/internal/domains/guide"
+	"pokemon/internal/domains/hunt"
+	"pokemon/internal/domains/news"
+	"pokemon/internal/domains/notification"
+	"pokemon/internal/domains/nuzlocke"
+	"pokemon/internal/domains/search"
+	"pokemon/internal/domains/series"
+	"pokemon/internal/domains/shout"
+	"pokemon/internal/domains/taglink"
 	"pokemon/internal/domains/team"
+	"pokemon/internal/domains/translation"
 	"pokemon/internal/domains/user"
+	"pokemon/internal/domains/walkthrough"
 )
 
 func GetAllMigrators() []Migrator {
@@ -13,5 +28,22 @@ func GetAllMigrators() []Migrator {
 		forum.ForumMigrator{},
 		team.TeamMigrator{},
 		favoritepokemon.FavoritePokemonMigrator{},
+		game.GameMigrator{},
+		hunt.HuntMigrator{},
+		nuzlocke.NuzlockeMigrator{},
+		collection.CollectionMigrator{},
+		blog.BlogMigrator{},
+		news.NewsMigrator{},
+		guide.GuideMigrator{},
+		walkthrough.WalkthroughMigrator{},
+		series.SeriesMigrator{},
+		dexlink.DexLinkMigrator{},
+		translation.TranslationMigrator{},
+		shout.ShoutMigrator{},
+		taglink.TagLinkMigrator{},
+		notification.NotificationMigrator{},
+
+		// Must stay last: it indexes the tables created above
+		search.SearchMigrator{},
 	}
 }
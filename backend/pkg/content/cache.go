@@ -0,0 +1,96 @@
+package content
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// rendererVersion is part of every cache key; bump it whenever the parser or
+// the allow-list changes so stale HTML is never served.
+const rendererVersion = "v2"
+
+const renderTTL = 24 * time.Hour
+
+// Renderer renders content through a Redis cache. RenderAll renders a list
+// in one round trip to the cache, for list pages.
+type Renderer interface {
+	Render(ctx context.Context, source string) *Rendered
+	RenderAll(ctx context.Context, sources []string) []*Rendered
+}
+
+type cachedRenderer struct {
+	cache *redis.Client
+}
+
+func NewRenderer(cache *redis.Client) Renderer {
+	return &cachedRenderer{cache: cache}
+}
+
+// The cache is keyed by a hash of the source, so every revision of a post or
+// guide gets its own entry and an edit never needs an explicit invalidation.
+func redisRenderKey(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return "content:render:" + rendererVersion + ":" + hex.EncodeToString(sum[:])
+}
+
+func (r *cachedRenderer) Render(ctx context.Context, source string) *Rendered {
+	key := redisRenderKey(source)
+
+	if val, err := r.cache.Get(ctx, key).Result(); err == nil {
+		var rendered Rendered
+		if err := json.Unmarshal([]byte(val), &rendered); err == nil {
+			return &rendered
+		}
+	}
+
+	rendered := Render(source)
+	if bytes, err := json.Marshal(rendered); err == nil {
+		r.cache.Set(ctx, key, bytes, renderTTL)
+	}
+	return rendered
+}
+
+// RenderAll reads every source from the cache with a single MGET, renders the
+// misses and stores them in one pipeline.
+func (r *cachedRenderer) RenderAll(ctx context.Context, sources []string) []*Rendered {
+	all := make([]*Rendered, len(sources))
+	if len(sources) == 0 {
+		return all
+	}
+
+	keys := make([]string, len(sources))
+	for i, source := range sources {
+		keys[i] = redisRenderKey(source)
+	}
+	cached, err := r.cache.MGet(ctx, keys...).Result()
+	if err != nil {
+		cached = nil
+	}
+
+	pipe := r.cache.Pipeline()
+	for i, source := range sources {
+		if i < len(cached) {
+			if val, ok := cached[i].(string); ok {
+				var rendered Rendered
+				if err := json.Unmarshal([]byte(val), &rendered); err == nil {
+					all[i] = &rendered
+					continue
+				}
+			}
+		}
+
+		all[i] = Render(source)
+		if bytes, err := json.Marshal(all[i]); err == nil {
+			pipe.Set(ctx, keys[i], bytes, renderTTL)
+		}
+	}
+	if pipe.Len() > 0 {
+		pipe.Exec(ctx)
+	}
+	return all
+}
@@ -0,0 +1,180 @@
+package content
+
+import "strings"
+
+// Diff operations of a line.
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// maxDiffEdits bounds the work of a diff. Past that many changed lines the two
+// texts have little in common and are shown as a full replacement.
+const maxDiffEdits = 1000
+
+// DiffLine is one line of a line-level diff. Old and New are 1-based line
+// numbers in each text, 0 when the line isn't part of that side.
+type DiffLine struct {
+	Op   string `json:"op"`
+	Old  int    `json:"old,omitempty"`
+	New  int    `json:"new,omitempty"`
+	Text string `json:"text"`
+}
+
+// LineDiff is the line-level difference between two texts.
+type LineDiff struct {
+	Added   int        `json:"added"`
+	Removed int        `json:"removed"`
+	Lines   []DiffLine `json:"lines"`
+}
+
+// Change is the before and after of a short field, such as a title.
+type Change struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// ChangeOf returns nil when the field didn't change.
+func ChangeOf(oldValue, newValue string) *Change {
+	if oldValue == newValue {
+		return nil
+	}
+	return &Change{Old: oldValue, New: newValue}
+}
+
+// Diff computes the shortest line-level edit script turning oldText into
+// newText (Myers' algorithm). Common leading and trailing lines are matched up
+// front so typical small edits of long documents stay cheap.
+func Diff(oldText, newText string) *LineDiff {
+	a, b := splitLines(oldText), splitLines(newText)
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	d := &LineDiff{Lines: []DiffLine{}}
+	for i := 0; i < prefix; i++ {
+		d.Lines = append(d.Lines, DiffLine{Op: DiffEqual, Old: i + 1, New: i + 1, Text: a[i]})
+	}
+
+	middle := myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	for _, line := range middle {
+		if line.Old > 0 {
+			line.Old += prefix
+		}
+		if line.New > 0 {
+			line.New += prefix
+		}
+		switch line.Op {
+		case DiffInsert:
+			d.Added++
+		case DiffDelete:
+			d.Removed++
+		}
+		d.Lines = append(d.Lines, line)
+	}
+
+	for i := 0; i < suffix; i++ {
+		oldLine, newLine := len(a)-suffix+i, len(b)-suffix+i
+		d.Lines = append(d.Lines, DiffLine{Op: DiffEqual, Old: oldLine + 1, New: newLine + 1, Text: a[oldLine]})
+	}
+	return d
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// myers returns the edit script between a and b, with 1-based line numbers
+// relative to the slices.
+func myers(a, b []string) []DiffLine {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxDiffEdits)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+
+	var trace [][]int
+	found := false
+search:
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break search
+			}
+		}
+	}
+	if !found {
+		return replaceAll(a, b)
+	}
+
+	// Walk the trace back from the end, collecting the script in reverse.
+	var script []DiffLine
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			script = append(script, DiffLine{Op: DiffEqual, Old: x, New: y, Text: a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				script = append(script, DiffLine{Op: DiffInsert, New: y, Text: b[y-1]})
+			} else {
+				script = append(script, DiffLine{Op: DiffDelete, Old: x, Text: a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(script)-1; i < j; i, j = i+1, j-1 {
+		script[i], script[j] = script[j], script[i]
+	}
+	return script
+}
+
+func replaceAll(a, b []string) []DiffLine {
+	script := make([]DiffLine, 0, len(a)+len(b))
+	for i, line := range a {
+		script = append(script, DiffLine{Op: DiffDelete, Old: i + 1, Text: line})
+	}
+	for i, line := range b {
+		script = append(script, DiffLine{Op: DiffInsert, New: i + 1, Text: line})
+	}
+	return script
+}
@@ -0,0 +1,127 @@
+package content
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name             string
+		oldText, newText string
+		added, removed   int
+	}{
+		{"both empty", "", "", 0, 0},
+		{"from empty", "", "a\nb", 2, 0},
+		{"to empty", "a\nb", "", 0, 2},
+		{"unchanged", "a\nb\nc", "a\nb\nc", 0, 0},
+		{"insert in the middle", "a\nc", "a\nb\nc", 1, 0},
+		{"delete in the middle", "a\nb\nc", "a\nc", 0, 1},
+		{"replace a line", "a\nb\nc", "a\nx\nc", 1, 1},
+		{"swap", "a\nb", "b\na", 1, 1},
+		{"trailing newline", "a\nb\n", "a\nb", 0, 0},
+		{"crlf", "a\r\nb", "a\nb", 0, 0},
+		{"repeated lines", "a\nb\na\nb\na", "b\na\nb", 0, 2},
+		{"unrelated", "a\nb\nc", "x\ny", 2, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Diff(tt.oldText, tt.newText)
+			if d.Added != tt.added || d.Removed != tt.removed {
+				t.Errorf("got +%d -%d, want +%d -%d", d.Added, d.Removed, tt.added, tt.removed)
+			}
+			checkRoundTrip(t, d, tt.oldText, tt.newText)
+		})
+	}
+}
+
+func TestDiffRoundTrip(t *testing.T) {
+	// Pseudo-random edits of a long document, deterministic so failures repeat
+	var lines []string
+	for i := 0; i < 300; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i%37))
+	}
+	oldText := strings.Join(lines, "\n")
+
+	seed := uint32(1)
+	next := func(n int) int {
+		seed = seed*1664525 + 1013904223
+		return int(seed>>8) % n
+	}
+	for round := 0; round < 50; round++ {
+		edited := append([]string(nil), lines...)
+		for edits := next(20); edits > 0; edits-- {
+			at := next(len(edited) + 1)
+			switch next(3) {
+			case 0:
+				edited = append(edited[:at], append([]string{fmt.Sprintf("new %d", next(10))}, edited[at:]...)...)
+			case 1:
+				if at < len(edited) {
+					edited = append(edited[:at], edited[at+1:]...)
+				}
+			case 2:
+				if at < len(edited) {
+					edited[at] = "changed"
+				}
+			}
+		}
+		newText := strings.Join(edited, "\n")
+		checkRoundTrip(t, Diff(oldText, newText), oldText, newText)
+	}
+}
+
+func TestDiffTooManyEdits(t *testing.T) {
+	var a, b []string
+	for i := 0; i < maxDiffEdits+10; i++ {
+		a = append(a, fmt.Sprintf("old %d", i))
+		b = append(b, fmt.Sprintf("new %d", i))
+	}
+	oldText, newText := strings.Join(a, "\n"), strings.Join(b, "\n")
+
+	d := Diff(oldText, newText)
+	if d.Added != len(b) || d.Removed != len(a) {
+		t.Errorf("got +%d -%d, want a full replacement", d.Added, d.Removed)
+	}
+	checkRoundTrip(t, d, oldText, newText)
+}
+
+// checkRoundTrip rebuilds both texts from the diff and checks the line
+// numbers of each side run 1, 2, 3...
+func checkRoundTrip(t *testing.T, d *LineDiff, oldText, newText string) {
+	t.Helper()
+
+	var oldLines, newLines []string
+	added, removed := 0, 0
+	for _, line := range d.Lines {
+		if line.Op != DiffInsert {
+			oldLines = append(oldLines, line.Text)
+			if line.Old != len(oldLines) {
+				t.Fatalf("old line %d numbered %d", len(oldLines), line.Old)
+			}
+		}
+		if line.Op != DiffDelete {
+			newLines = append(newLines, line.Text)
+			if line.New != len(newLines) {
+				t.Fatalf("new line %d numbered %d", len(newLines), line.New)
+			}
+		}
+		switch line.Op {
+		case DiffInsert:
+			added++
+		case DiffDelete:
+			removed++
+		}
+	}
+
+	if got, want := strings.Join(oldLines, "\n"), strings.Join(splitLines(oldText), "\n"); got != want {
+		t.Errorf("old side\n got %q\nwant %q", got, want)
+	}
+	if got, want := strings.Join(newLines, "\n"), strings.Join(splitLines(newText), "\n"); got != want {
+		t.Errorf("new side\n got %q\nwant %q", got, want)
+	}
+	if added != d.Added || removed != d.Removed {
+		t.Errorf("counts +%d -%d, lines +%d -%d", d.Added, d.Removed, added, removed)
+	}
+}
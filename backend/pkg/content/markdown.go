@@ -0,0 +1,880 @@
+package content
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"pokemon/pkg/utils"
+)
+
+// The parser covers the Markdown people actually write in guides and posts:
+// ATX and setext headings, paragraphs, emphasis, code spans and fences,
+// blockquotes, nested lists, GFM tables, links, images, autolinks and
+// horizontal rules. Two extensions exist for story content:
+//
+//	:::spoiler Optional summary     ||inline spoiler||
+//	hidden blocks
+//	:::
+//
+// Raw HTML is passed through untouched and cleaned up by the sanitizer.
+
+var (
+	atxHeadingRe = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	hrRe         = regexp.MustCompile(`^ {0,3}((\*[ \t]*){3,}|(-[ \t]*){3,}|(_[ \t]*){3,})$`)
+	fenceRe      = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`]*)$")
+	listItemRe   = regexp.MustCompile(`^( {0,3})([-*+]|\d{1,9}[.)])([ \t]+|$)`)
+	spoilerRe    = regexp.MustCompile(`^ {0,3}:::[ \t]*spoiler\b[ \t]*(.*)$`)
+	closeBlockRe = regexp.MustCompile(`^ {0,3}:::[ \t]*$`)
+	htmlBlockRe  = regexp.MustCompile(`^ {0,3}</?[a-zA-Z][a-zA-Z0-9-]*(\s|/?>|$)|^ {0,3}<!--`)
+	tableDelimRe = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	setextRe     = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	entityRe     = regexp.MustCompile(`^&(#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+	inlineTagRe  = regexp.MustCompile(`^</?[a-zA-Z][a-zA-Z0-9-]*(\s[^<>]*)?/?>$`)
+	autolinkRe   = regexp.MustCompile(`^<((?:https?|mailto):[^\s<>]+)>`)
+	bareURLRe    = regexp.MustCompile(`^https?://[^\s<]+`)
+)
+
+const (
+	// maxNesting bounds how deep spoilers, blockquotes and lists, and links
+	// and emphasis, can nest. Every level scans its content again, so deeper
+	// markup is left as text rather than letting a post cost quadratic time.
+	maxNesting = 8
+
+	// maxSourceLength is the longest source parsed as Markdown; anything
+	// longer is shown as plain text.
+	maxSourceLength = 512 << 10
+
+	// maxDestinationLength bounds the scan for the end of a link destination.
+	maxDestinationLength = 2048
+)
+
+// parser holds what has to be shared across nested blocks: the table of
+// contents, the heading ids already handed out and how deep it is.
+type parser struct {
+	toc         []Heading
+	ids         map[string]int
+	inLink      bool
+	depth       int
+	inlineDepth int
+}
+
+func newParser() *parser {
+	return &parser{toc: []Heading{}, ids: map[string]int{}}
+}
+
+// markdownToHTML converts the source to (unsanitized) HTML.
+func (p *parser) markdownToHTML(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+	source = strings.ReplaceAll(source, "\t", "    ")
+
+	var b strings.Builder
+	if len(source) > maxSourceLength {
+		plainParagraphs(source, &b)
+		return b.String()
+	}
+	p.blocks(strings.Split(source, "\n"), &b, false)
+	return b.String()
+}
+
+// plainParagraphs writes the source as escaped text, one paragraph per run
+// of non-blank lines.
+func plainParagraphs(source string, b *strings.Builder) {
+	for _, para := range strings.Split(source, "\n\n") {
+		if para = strings.TrimSpace(para); para != "" {
+			b.WriteString("<p>")
+			b.WriteString(html.EscapeString(para))
+			b.WriteString("</p>\n")
+		}
+	}
+}
+
+/**********
+ * BLOCKS *
+ **********/
+
+// blocks renders a sequence of lines. Inside tight list items paragraphs are
+// written without their <p> wrapper. Past maxNesting, spoilers, blockquotes
+// and lists are no longer recognized and end up in paragraphs.
+func (p *parser) blocks(lines []string, b *strings.Builder, tight bool) {
+	nest := p.depth < maxNesting
+	p.depth++
+	defer func() { p.depth-- }()
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case isBlank(line):
+			i++
+		case fenceRe.MatchString(line):
+			i = p.fencedCode(lines, i, b)
+		case nest && spoilerRe.MatchString(line):
+			i = p.spoiler(lines, i, b)
+		case atxHeadingRe.MatchString(line):
+			m := atxHeadingRe.FindStringSubmatch(line)
+			p.heading(len(m[1]), m[2], b)
+			i++
+		case hrRe.MatchString(line):
+			b.WriteString("<hr>\n")
+			i++
+		case nest && isBlockquote(line):
+			i = p.blockquote(lines, i, b)
+		case nest && listItemRe.MatchString(line):
+			i = p.list(lines, i, b)
+		case isTableStart(lines, i):
+			i = p.table(lines, i, b)
+		case htmlBlockRe.MatchString(line):
+			i = p.htmlBlock(lines, i, b)
+		case indentOf(line) >= 4:
+			i = p.indentedCode(lines, i, b)
+		default:
+			i = p.paragraph(lines, i, b, tight)
+		}
+	}
+}
+
+func (p *parser) heading(level int, text string, b *strings.Builder) {
+	inner := p.inline(strings.TrimSpace(text))
+	plain := plainText(inner)
+	id := p.headingID(plain)
+
+	p.toc = append(p.toc, Heading{Level: level, ID: id, Text: plain})
+	fmt.Fprintf(b, `<h%d id="%s">%s <a class="heading-anchor" href="#%s">#</a></h%d>`+"\n", level, id, inner, id, level)
+}
+
+// headingID slugs the heading text, suffixing duplicates like GitHub does.
+func (p *parser) headingID(text string) string {
+	base := utils.Slugify(text)
+	if base == "" {
+		base = "section"
+	}
+	id := base
+	if n := p.ids[base]; n > 0 {
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	p.ids[base]++
+	return id
+}
+
+func (p *parser) fencedCode(lines []string, start int, b *strings.Builder) int {
+	m := fenceRe.FindStringSubmatch(lines[start])
+	indent, fence, info := len(m[1]), m[2], strings.TrimSpace(m[3])
+
+	var code []string
+	i := start + 1
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, fence[:1]) && strings.Trim(trimmed, fence[:1]) == "" && len(trimmed) >= len(fence) {
+			i++
+			break
+		}
+		code = append(code, stripIndent(lines[i], indent))
+	}
+
+	b.WriteString("<pre><code")
+	if lang := strings.Fields(info); len(lang) > 0 {
+		fmt.Fprintf(b, ` class="language-%s"`, html.EscapeString(lang[0]))
+	}
+	b.WriteString(">")
+	for _, l := range code {
+		b.WriteString(html.EscapeString(l))
+		b.WriteString("\n")
+	}
+	b.WriteString("</code></pre>\n")
+	return i
+}
+
+func (p *parser) indentedCode(lines []string, start int, b *strings.Builder) int {
+	var code []string
+	i := start
+	for ; i < len(lines); i++ {
+		if isBlank(lines[i]) {
+			code = append(code, "")
+			continue
+		}
+		if indentOf(lines[i]) < 4 {
+			break
+		}
+		code = append(code, lines[i][4:])
+	}
+	for len(code) > 0 && code[len(code)-1] == "" {
+		code = code[:len(code)-1]
+	}
+
+	b.WriteString("<pre><code>")
+	for _, l := range code {
+		b.WriteString(html.EscapeString(l))
+		b.WriteString("\n")
+	}
+	b.WriteString("</code></pre>\n")
+	return i
+}
+
+// spoiler renders a :::spoiler block as a collapsed <details>. Spoilers can
+// be nested, so the closing ::: has to be matched by depth.
+func (p *parser) spoiler(lines []string, start int, b *strings.Builder) int {
+	summary := strings.TrimSpace(spoilerRe.FindStringSubmatch(lines[start])[1])
+	if summary == "" {
+		summary = "Spoiler"
+	}
+
+	depth := 1
+	i := start + 1
+	for ; i < len(lines); i++ {
+		if spoilerRe.MatchString(lines[i]) {
+			depth++
+		} else if closeBlockRe.MatchString(lines[i]) {
+			depth--
+			if depth == 0 {
+				break
+			}
+		}
+	}
+
+	fmt.Fprintf(b, "<details class=\"spoiler\"><summary>%s</summary>\n", p.inline(summary))
+	p.blocks(lines[start+1:min(i, len(lines))], b, false)
+	b.WriteString("</details>\n")
+	return i + 1
+}
+
+func (p *parser) blockquote(lines []string, start int, b *strings.Builder) int {
+	var inner []string
+	i := start
+	for ; i < len(lines) && isBlockquote(lines[i]); i++ {
+		l := strings.TrimLeft(lines[i], " ")[1:]
+		inner = append(inner, strings.TrimPrefix(l, " "))
+	}
+
+	b.WriteString("<blockquote>\n")
+	p.blocks(inner, b, false)
+	b.WriteString("</blockquote>\n")
+	return i
+}
+
+// list collects the items of one list. A line belongs to the current item if
+// it is indented past the marker, or is a lazy continuation of its paragraph.
+// Any blank line between items makes the whole list loose.
+func (p *parser) list(lines []string, start int, b *strings.Builder) int {
+	first := listItemRe.FindStringSubmatch(lines[start])
+	ordered := !strings.ContainsAny(first[2], "-*+")
+	marker := first[2][len(first[2])-1:]
+
+	var items [][]string
+	loose := false
+	i := start
+	for i < len(lines) {
+		m := listItemRe.FindStringSubmatch(lines[i])
+		if m == nil || len(m[1]) != len(first[1]) || !sameListType(m[2], ordered, marker) {
+			break
+		}
+
+		// Content starts after the marker's spaces, unless there are none or so
+		// many that the item opens with indented code.
+		content := len(m[0])
+		if m[3] == "" || len(m[3]) > 4 {
+			content = len(m[1]) + len(m[2]) + 1
+		}
+		item := []string{""}
+		if content < len(lines[i]) {
+			item[0] = lines[i][content:]
+		}
+		i++
+
+		for i < len(lines) {
+			line := lines[i]
+			if isBlank(line) {
+				next := i + 1
+				for next < len(lines) && isBlank(lines[next]) {
+					next++
+				}
+				if next < len(lines) && indentOf(lines[next]) >= content {
+					item = append(item, "")
+					i++
+					loose = true
+					continue
+				}
+				if next < len(lines) {
+					if n := listItemRe.FindStringSubmatch(lines[next]); n != nil && len(n[1]) == len(first[1]) && sameListType(n[2], ordered, marker) {
+						loose = true
+					}
+				}
+				i = next
+				break
+			}
+			if indentOf(line) >= content {
+				item = append(item, line[content:])
+				i++
+				continue
+			}
+			if listItemRe.MatchString(line) || startsBlock(line) || isBlank(lines[i-1]) {
+				break
+			}
+			item = append(item, strings.TrimLeft(line, " "))
+			i++
+		}
+		items = append(items, item)
+	}
+
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+		if n, _ := strconv.Atoi(strings.TrimRight(first[2], ".)")); n != 1 {
+			fmt.Fprintf(b, "<ol start=\"%d\">\n", n)
+		} else {
+			b.WriteString("<ol>\n")
+		}
+	} else {
+		b.WriteString("<ul>\n")
+	}
+	for _, item := range items {
+		b.WriteString("<li>")
+		p.blocks(item, b, !loose)
+		b.WriteString("</li>\n")
+	}
+	fmt.Fprintf(b, "</%s>\n", tag)
+	return i
+}
+
+func (p *parser) table(lines []string, start int, b *strings.Builder) int {
+	header := splitRow(lines[start])
+	aligns := []string{}
+	for _, cell := range splitRow(lines[start+1]) {
+		left, right := strings.HasPrefix(cell, ":"), strings.HasSuffix(cell, ":")
+		switch {
+		case left && right:
+			aligns = append(aligns, "center")
+		case right:
+			aligns = append(aligns, "right")
+		case left:
+			aligns = append(aligns, "left")
+		default:
+			aligns = append(aligns, "")
+		}
+	}
+
+	cell := func(tag string, col int, text string) {
+		if col < len(aligns) && aligns[col] != "" {
+			fmt.Fprintf(b, `<%s align="%s">`, tag, aligns[col])
+		} else {
+			fmt.Fprintf(b, "<%s>", tag)
+		}
+		b.WriteString(p.inline(text))
+		fmt.Fprintf(b, "</%s>", tag)
+	}
+
+	b.WriteString("<table>\n<thead>\n<tr>")
+	for col, text := range header {
+		cell("th", col, text)
+	}
+	b.WriteString("</tr>\n</thead>\n")
+
+	i := start + 2
+	if i < len(lines) && !isBlank(lines[i]) && strings.Contains(lines[i], "|") {
+		b.WriteString("<tbody>\n")
+		for ; i < len(lines) && !isBlank(lines[i]) && strings.Contains(lines[i], "|"); i++ {
+			row := splitRow(lines[i])
+			b.WriteString("<tr>")
+			for col := range header {
+				text := ""
+				if col < len(row) {
+					text = row[col]
+				}
+				cell("td", col, text)
+			}
+			b.WriteString("</tr>\n")
+		}
+		b.WriteString("</tbody>\n")
+	}
+	b.WriteString("</table>\n")
+	return i
+}
+
+// htmlBlock copies raw HTML up to the next blank line.
+func (p *parser) htmlBlock(lines []string, start int, b *strings.Builder) int {
+	i := start
+	for ; i < len(lines) && !isBlank(lines[i]); i++ {
+		b.WriteString(lines[i])
+		b.WriteString("\n")
+	}
+	return i
+}
+
+// paragraph gathers lines until a blank line or another block starts. A
+// paragraph underlined with === or --- is a setext heading instead.
+func (p *parser) paragraph(lines []string, start int, b *strings.Builder, tight bool) int {
+	var text []string
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) {
+			break
+		}
+		if i > start {
+			if m := setextRe.FindStringSubmatch(line); m != nil {
+				level := 2
+				if m[1][0] == '=' {
+					level = 1
+				}
+				p.heading(level, strings.Join(text, "\n"), b)
+				return i + 1
+			}
+			if startsBlock(line) || listItemRe.MatchString(line) {
+				break
+			}
+		}
+		text = append(text, strings.TrimLeft(line, " "))
+	}
+
+	inner := p.inline(strings.TrimRight(strings.Join(text, "\n"), " "))
+	if tight {
+		b.WriteString(inner)
+		b.WriteString("\n")
+	} else {
+		b.WriteString("<p>")
+		b.WriteString(inner)
+		b.WriteString("</p>\n")
+	}
+	return i
+}
+
+/***********
+ * INLINES *
+ ***********/
+
+// inline renders emphasis, code spans, links and the like. Delimiters are
+// matched greedily to the nearest valid closer rather than with the full
+// CommonMark delimiter algorithm, which is plenty for user content. Past
+// maxNesting the text is only escaped.
+func (p *parser) inline(s string) string {
+	if p.inlineDepth >= maxNesting {
+		return html.EscapeString(s)
+	}
+	p.inlineDepth++
+	defer func() { p.inlineDepth-- }()
+
+	var b strings.Builder
+	var closers []int32          // filled on the first [
+	unclosed := map[string]int{} // emphasis delimiter -> start of a scan that found no closer
+	tagEnd := forward{s: s, sep: ">"}
+	commentEnd := forward{s: s, sep: "-->"}
+	closerOf := func(open int) int { // closing ] of the [ at open, relative to it
+		if closers == nil {
+			closers = bracketClosers(s)
+		}
+		if closers[open] < 0 {
+			return -1
+		}
+		return int(closers[open]) - open
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		rest := s[i:]
+
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			b.WriteString("<br>\n")
+			i += 2
+			continue
+		case c == '\\' && i+1 < len(s) && isPunct(s[i+1]):
+			b.WriteString(html.EscapeString(s[i+1 : i+2]))
+			i += 2
+			continue
+		case c == '`':
+			if n, ok := p.codeSpan(rest, &b); ok {
+				i += n
+				continue
+			}
+			run := runLength(rest, '`')
+			b.WriteString(rest[:run])
+			i += run
+			continue
+		case c == '!' && strings.HasPrefix(rest, "!["):
+			if n, ok := p.link(rest[1:], closerOf(i+1), &b, true); ok {
+				i += n + 1
+				continue
+			}
+		case c == '[':
+			if n, ok := p.link(rest, closerOf(i), &b, false); ok {
+				i += n
+				continue
+			}
+		case c == '<':
+			if m := autolinkRe.FindStringSubmatch(rest); m != nil {
+				fmt.Fprintf(&b, `<a href="%s">%s</a>`, html.EscapeString(m[1]), html.EscapeString(m[1]))
+				i += len(m[0])
+				continue
+			}
+			if strings.HasPrefix(rest, "<!--") {
+				if end := commentEnd.from(i + 4); end >= 0 {
+					b.WriteString(s[i : end+3])
+					i = end + 3
+					continue
+				}
+			} else if end := tagEnd.from(i); end >= 0 && inlineTagRe.MatchString(s[i:end+1]) {
+				b.WriteString(s[i : end+1])
+				i = end + 1
+				continue
+			}
+			b.WriteString("&lt;")
+			i++
+			continue
+		case c == '&':
+			if m := entityRe.FindString(rest); m != "" {
+				b.WriteString(m)
+				i += len(m)
+				continue
+			}
+			b.WriteString("&amp;")
+			i++
+			continue
+		case c == 'h' && !p.inLink && (i == 0 || strings.ContainsRune(" \n(", rune(s[i-1]))):
+			if m := bareURLRe.FindString(rest); m != "" {
+				url := strings.TrimRight(m, ".,;:!?)'\"")
+				fmt.Fprintf(&b, `<a href="%s">%s</a>`, html.EscapeString(url), html.EscapeString(url))
+				i += len(url)
+				continue
+			}
+		case c == '*' || c == '_' || c == '~' || c == '|':
+			if n, ok := p.emphasis(s, i, unclosed, &b); ok {
+				i += n
+				continue
+			}
+			run := runLength(rest, c)
+			b.WriteString(rest[:run])
+			i += run
+			continue
+		case c == '\n':
+			if i >= 2 && s[i-2:i] == "  " {
+				b.WriteString("<br>")
+			}
+			b.WriteString("\n")
+			i++
+			continue
+		}
+
+		b.WriteString(html.EscapeString(s[i : i+1]))
+		i++
+	}
+	return b.String()
+}
+
+func (p *parser) codeSpan(s string, b *strings.Builder) (int, bool) {
+	run := runLength(s, '`')
+	for j := run; j < len(s); {
+		k := strings.IndexByte(s[j:], '`')
+		if k < 0 {
+			return 0, false
+		}
+		j += k
+		closing := runLength(s[j:], '`')
+		if closing == run {
+			code := strings.ReplaceAll(s[run:j], "\n", " ")
+			if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+				code = code[1 : len(code)-1]
+			}
+			fmt.Fprintf(b, "<code>%s</code>", html.EscapeString(code))
+			return j + closing, true
+		}
+		j += closing
+	}
+	return 0, false
+}
+
+// link parses [text](destination "title"), or an image when image is set
+// (the leading ! already consumed by the caller). end is the index of the ]
+// closing the text, see bracketClosers.
+func (p *parser) link(s string, end int, b *strings.Builder, image bool) (int, bool) {
+	if end < 0 || end+1 >= len(s) || s[end+1] != '(' {
+		return 0, false
+	}
+
+	text := s[1:end]
+	j := end + 2
+	for j < len(s) && s[j] == ' ' {
+		j++
+	}
+
+	var dest string
+	if j < len(s) && s[j] == '<' {
+		k := strings.IndexByte(s[j:], '>')
+		if k < 0 {
+			return 0, false
+		}
+		dest = s[j+1 : j+k]
+		j += k + 1
+	} else {
+		parens := 0
+		from := j
+		for ; j < len(s); j++ {
+			if j-from > maxDestinationLength {
+				return 0, false
+			}
+			ch := s[j]
+			if ch == ' ' || ch == '\n' || (ch == ')' && parens == 0) {
+				break
+			}
+			if ch == '(' {
+				parens++
+			} else if ch == ')' {
+				parens--
+			}
+		}
+		dest = s[from:j]
+	}
+
+	for j < len(s) && (s[j] == ' ' || s[j] == '\n') {
+		j++
+	}
+	title := ""
+	if j < len(s) && (s[j] == '"' || s[j] == '\'') {
+		quote := s[j]
+		k := strings.IndexByte(s[j+1:], quote)
+		if k < 0 {
+			return 0, false
+		}
+		title = s[j+1 : j+1+k]
+		j += k + 2
+		for j < len(s) && s[j] == ' ' {
+			j++
+		}
+	}
+	if j >= len(s) || s[j] != ')' {
+		return 0, false
+	}
+
+	if image {
+		fmt.Fprintf(b, `<img src="%s" alt="%s"`, html.EscapeString(dest), html.EscapeString(plainText(p.inline(text))))
+	} else {
+		fmt.Fprintf(b, `<a href="%s"`, html.EscapeString(dest))
+	}
+	if title != "" {
+		fmt.Fprintf(b, ` title="%s"`, html.EscapeString(title))
+	}
+	if image {
+		b.WriteString(">")
+	} else {
+		p.inLink = true
+		fmt.Fprintf(b, ">%s</a>", p.inline(text))
+		p.inLink = false
+	}
+	return j + 1, true
+}
+
+var emphasisTags = map[string][2]string{
+	"***": {"<em><strong>", "</strong></em>"},
+	"___": {"<em><strong>", "</strong></em>"},
+	"**":  {"<strong>", "</strong>"},
+	"__":  {"<strong>", "</strong>"},
+	"*":   {"<em>", "</em>"},
+	"_":   {"<em>", "</em>"},
+	"~~":  {"<del>", "</del>"},
+	"||":  {`<span class="spoiler">`, "</span>"},
+}
+
+// emphasis looks for the closer of the delimiter run starting at s[i]. The
+// opener can't be followed by a space and the closer can't follow one;
+// underscores additionally don't work inside words (snake_case stays as is).
+//
+// unclosed remembers where scans for a delimiter found nothing, so a line
+// full of lone asterisks isn't scanned to its end once per asterisk.
+func (p *parser) emphasis(s string, i int, unclosed map[string]int, b *strings.Builder) (int, bool) {
+	c := s[i]
+	run := runLength(s[i:], c)
+	if c == '~' || c == '|' {
+		if run != 2 {
+			return 0, false
+		}
+	} else if run > 3 {
+		return 0, false
+	}
+	delim := s[i : i+run]
+	tags, ok := emphasisTags[delim]
+	if !ok {
+		return 0, false
+	}
+
+	open := i + run
+	if open >= len(s) || isSpace(s[open]) {
+		return 0, false
+	}
+	if c == '_' && i > 0 && isWordChar(s[i-1]) {
+		return 0, false
+	}
+	if from, ok := unclosed[delim]; ok && open+1 >= from {
+		return 0, false
+	}
+
+	for j := open + 1; j < len(s); {
+		if s[j] == '`' {
+			if k := strings.IndexByte(s[j+1:], '`'); k >= 0 {
+				j += k + 2
+				continue
+			}
+		}
+		if s[j] != c {
+			j++
+			continue
+		}
+		closing := runLength(s[j:], c)
+		if closing == run && !isSpace(s[j-1]) &&
+			!(c == '_' && j+closing < len(s) && isWordChar(s[j+closing])) {
+			b.WriteString(tags[0])
+			b.WriteString(p.inline(s[open:j]))
+			b.WriteString(tags[1])
+			return j + closing - i, true
+		}
+		j += closing
+	}
+	unclosed[delim] = open + 1
+	return 0, false
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+// forward finds sep in s at or after a position. The inline scanner only
+// moves forward, so remembering the last answer keeps a paragraph full of
+// unclosed tags from being searched to its end once per tag.
+type forward struct {
+	s, sep string
+	at     int  // last answer, -1 once a search found nothing
+	done   bool // whether there is a last answer
+}
+
+func (f *forward) from(i int) int {
+	if f.done && (f.at < 0 || f.at >= i) {
+		return f.at
+	}
+	f.done = true
+	f.at = strings.Index(f.s[i:], f.sep)
+	if f.at >= 0 {
+		f.at += i
+	}
+	return f.at
+}
+
+// bracketClosers finds, for every position, the first ] after it that takes
+// the bracket depth below the depth there, i.e. the ] closing a [ at that
+// position, or -1. Brackets escaped by an odd run of backslashes don't count.
+// It is the same as scanning forward from each [, in linear time.
+func bracketClosers(s string) []int32 {
+	depth := make([]int32, len(s))
+	var d int32
+	slashes := 0
+	for k := 0; k < len(s); k++ {
+		if slashes%2 == 0 {
+			switch s[k] {
+			case '[':
+				d++
+			case ']':
+				d--
+			}
+		}
+		if s[k] == '\\' {
+			slashes++
+		} else {
+			slashes = 0
+		}
+		depth[k] = d
+	}
+
+	closers := make([]int32, len(s))
+	var pending []int32 // positions still waiting for a lower depth
+	for k := 0; k < len(s); k++ {
+		for len(pending) > 0 && depth[k] < depth[pending[len(pending)-1]] {
+			closers[pending[len(pending)-1]] = int32(k)
+			pending = pending[:len(pending)-1]
+		}
+		pending = append(pending, int32(k))
+	}
+	for _, k := range pending {
+		closers[k] = -1
+	}
+	return closers
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func isBlockquote(line string) bool {
+	return indentOf(line) < 4 && strings.HasPrefix(strings.TrimLeft(line, " "), ">")
+}
+
+// startsBlock reports whether the line interrupts a paragraph.
+func startsBlock(line string) bool {
+	return fenceRe.MatchString(line) || spoilerRe.MatchString(line) || closeBlockRe.MatchString(line) ||
+		atxHeadingRe.MatchString(line) || hrRe.MatchString(line) || isBlockquote(line) || htmlBlockRe.MatchString(line)
+}
+
+// isTableStart needs a header row followed by a delimiter row like |---|:-:|.
+func isTableStart(lines []string, i int) bool {
+	return i+1 < len(lines) && strings.Contains(lines[i], "|") &&
+		strings.Contains(lines[i+1], "|") && tableDelimRe.MatchString(lines[i+1])
+}
+
+func sameListType(marker string, ordered bool, delim string) bool {
+	if ordered {
+		return strings.HasSuffix(marker, delim) && !strings.ContainsAny(marker, "-*+")
+	}
+	return marker == delim
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func stripIndent(line string, n int) string {
+	if indent := indentOf(line); indent < n {
+		n = indent
+	}
+	return line[n:]
+}
+
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func runLength(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return n
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n'
+}
+
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+func isPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
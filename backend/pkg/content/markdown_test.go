@@ -0,0 +1,255 @@
+package content
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderBlocks(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "paragraph",
+			source: "Hello *world*",
+			want:   "<p>Hello <em>world</em></p>\n",
+		},
+		{
+			name:   "heading",
+			source: "## Route 1",
+			want:   `<h2 id="route-1">Route 1 <a class="heading-anchor" href="#route-1">#</a></h2>` + "\n",
+		},
+		{
+			name:   "spoiler",
+			source: ":::spoiler Ending\nThe rival wins\n:::",
+			want:   "<details class=\"spoiler\"><summary>Ending</summary>\n<p>The rival wins</p>\n</details>\n",
+		},
+		{
+			name:   "nested spoilers",
+			source: ":::spoiler\n:::spoiler Inner\ndeep\n:::\n:::",
+			want: "<details class=\"spoiler\"><summary>Spoiler</summary>\n" +
+				"<details class=\"spoiler\"><summary>Inner</summary>\n<p>deep</p>\n</details>\n" +
+				"</details>\n",
+		},
+		{
+			name:   "unclosed spoiler",
+			source: ":::spoiler\nhidden",
+			want:   "<details class=\"spoiler\"><summary>Spoiler</summary>\n<p>hidden</p>\n</details>\n",
+		},
+		{
+			name:   "tight list",
+			source: "- Bulbasaur\n- Charmander",
+			want:   "<ul>\n<li>Bulbasaur\n</li>\n<li>Charmander\n</li>\n</ul>\n",
+		},
+		{
+			name:   "nested list",
+			source: "1. Kanto\n   - Pallet Town\n2. Johto",
+			want:   "<ol>\n<li>Kanto\n<ul>\n<li>Pallet Town\n</li>\n</ul>\n</li>\n<li>Johto\n</li>\n</ol>\n",
+		},
+		{
+			name:   "blockquote",
+			source: "> quoted",
+			want:   "<blockquote>\n<p>quoted</p>\n</blockquote>\n",
+		},
+		{
+			name:   "fenced code",
+			source: "```go\na < b\n```",
+			want:   "<pre><code class=\"language-go\">a &lt; b\n</code></pre>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.source).HTML; got != tt.want {
+				t.Errorf("Render(%q)\n got %q\nwant %q", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderNestingLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		tag    string
+	}{
+		{
+			name:   "spoilers",
+			source: strings.Repeat(":::spoiler\n", 20) + "x\n" + strings.Repeat(":::\n", 20),
+			tag:    "<details",
+		},
+		{
+			name:   "lists",
+			source: strings.Repeat("- ", 20) + "x",
+			tag:    "<ul>",
+		},
+		{
+			name:   "blockquotes",
+			source: strings.Repeat(">", 20) + " x",
+			tag:    "<blockquote>",
+		},
+		{
+			name:   "links",
+			source: strings.Repeat("[", 20) + "x" + strings.Repeat("](/a)", 20),
+			tag:    "<a ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Render(tt.source).HTML
+			if n := strings.Count(got, tt.tag); n != maxNesting {
+				t.Errorf("got %d %s, want %d:\n%s", n, tt.tag, maxNesting, got)
+			}
+			if !strings.Contains(got, "x") {
+				t.Errorf("innermost text lost:\n%s", got)
+			}
+		})
+	}
+}
+
+// Every shape here used to take seconds or minutes, scanning the rest of the
+// input again at every level or delimiter.
+func TestRenderPathologicalInput(t *testing.T) {
+	tests := map[string]string{
+		"nested spoilers":   strings.Repeat(":::spoiler\n", 16000) + strings.Repeat(":::\n", 16000),
+		"nested lists":      strings.Repeat("- ", 60000) + "x",
+		"nested quotes":     strings.Repeat(">", 200000) + " x",
+		"nested links":      strings.Repeat("[", 16000) + "x" + strings.Repeat("](y)", 16000),
+		"unclosed brackets": strings.Repeat("[", 200000),
+		"unclosed images":   strings.Repeat("![", 100000),
+		"unclosed emphasis": strings.Repeat("*a ", 70000),
+		"unclosed tags":     strings.Repeat("<a", 200000),
+		"unclosed links":    strings.Repeat("[a](", 120000),
+		"too long":          strings.Repeat("*", maxSourceLength+1),
+	}
+
+	for name, source := range tests {
+		t.Run(name, func(t *testing.T) {
+			start := time.Now()
+			Render(source)
+			if took := time.Since(start); took > 2*time.Second {
+				t.Errorf("took %v", took)
+			}
+		})
+	}
+}
+
+func TestRenderLinks(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "relative link",
+			source: "[Route 1](/routes/1)",
+			want:   `<p><a href="/routes/1">Route 1</a></p>` + "\n",
+		},
+		{
+			name:   "external link",
+			source: `[Bulbapedia](https://bulbapedia.bulbagarden.net "wiki")`,
+			want:   `<p><a href="https://bulbapedia.bulbagarden.net" title="wiki" rel="nofollow noopener noreferrer">Bulbapedia</a></p>` + "\n",
+		},
+		{
+			name:   "nested brackets",
+			source: "[a [b] c](/x)",
+			want:   `<p><a href="/x">a [b] c</a></p>` + "\n",
+		},
+		{
+			name:   "escaped bracket",
+			source: `[a \] b](/x)`,
+			want:   `<p><a href="/x">a ] b</a></p>` + "\n",
+		},
+		{
+			name:   "javascript link",
+			source: "[click](javascript:alert(1))",
+			want:   "<p><a>click</a></p>\n",
+		},
+		{
+			name:   "whitespace in a destination is not a link",
+			source: "[click](java\tscript:alert(1))",
+			want:   "<p>[click](java    script:alert(1))</p>\n",
+		},
+		{
+			name:   "uppercase javascript link",
+			source: "[click](JAVASCRIPT:alert(1))",
+			want:   "<p><a>click</a></p>\n",
+		},
+		{
+			name:   "image",
+			source: "![Pikachu](/img/25.png)",
+			want:   `<p><img src="/img/25.png" alt="Pikachu"></p>` + "\n",
+		},
+		{
+			name:   "javascript image",
+			source: "![x](javascript:alert(1))",
+			want:   `<p><img alt="x"></p>` + "\n",
+		},
+		{
+			name:   "data image",
+			source: "![x](data:image/svg+xml;base64,PHN2Zz4=)",
+			want:   `<p><img alt="x"></p>` + "\n",
+		},
+		{
+			name:   "raw html link",
+			source: `<a href="javascript:alert(1)" onclick="x()">hi</a>`,
+			want:   "<a>hi</a>\n",
+		},
+		{
+			name:   "unclosed link",
+			source: "[a](/b",
+			want:   "<p>[a](/b</p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.source).HTML; got != tt.want {
+				t.Errorf("Render(%q)\n got %q\nwant %q", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBracketClosers(t *testing.T) {
+	tests := []struct {
+		s    string
+		open int
+		want int32
+	}{
+		{"[a]", 0, 2},
+		{"[a[b]c]", 0, 6},
+		{"[a[b]c]", 2, 4},
+		{"[a", 0, -1},
+		{`[a\]b]`, 0, 5},
+		{`[a\\]b]`, 0, 4},
+		{"[[a]", 0, -1},
+		{"[[a]", 1, 3},
+	}
+
+	for _, tt := range tests {
+		if got := bracketClosers(tt.s)[tt.open]; got != tt.want {
+			t.Errorf("bracketClosers(%q)[%d] = %d, want %d", tt.s, tt.open, got, tt.want)
+		}
+	}
+}
+
+func TestRenderTOC(t *testing.T) {
+	r := Render("# Intro\n\n## Intro\n\ntext")
+	want := []Heading{
+		{Level: 1, ID: "intro", Text: "Intro"},
+		{Level: 2, ID: "intro-1", Text: "Intro"},
+	}
+	if len(r.TOC) != len(want) {
+		t.Fatalf("got %d headings, want %d", len(r.TOC), len(want))
+	}
+	for i := range want {
+		if r.TOC[i] != want[i] {
+			t.Errorf("heading %d = %+v, want %+v", i, r.TOC[i], want[i])
+		}
+	}
+}
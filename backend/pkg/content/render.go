@@ -0,0 +1,73 @@
+package content
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// WordsPerMinute is the reading speed used for estimates.
+const WordsPerMinute = 200
+
+// Rendered is the safe, display-ready version of a piece of Markdown. It is
+// returned next to the raw source by every content API.
+type Rendered struct {
+	HTML        string    `json:"html"`
+	TOC         []Heading `json:"toc"`
+	WordCount   int       `json:"word_count"`
+	ReadingTime int       `json:"reading_time"` // minutes
+}
+
+// Heading is a table of contents entry. ID is the anchor of the heading in HTML.
+type Heading struct {
+	Level int    `json:"level"`
+	ID    string `json:"id"`
+	Text  string `json:"text"`
+}
+
+// Render converts Markdown (or HTML, which is valid Markdown) into sanitized
+// HTML along with its table of contents and reading time.
+func Render(source string) *Rendered {
+	p := newParser()
+	out := sanitize(p.markdownToHTML(source))
+
+	words := len(strings.Fields(plainText(out)))
+	minutes := (words + WordsPerMinute - 1) / WordsPerMinute
+
+	return &Rendered{
+		HTML:        out,
+		TOC:         p.toc,
+		WordCount:   words,
+		ReadingTime: minutes,
+	}
+}
+
+var (
+	anchorRe = regexp.MustCompile(`<a class="heading-anchor"[^>]*>#</a>`)
+	tagRe    = regexp.MustCompile(`<[^>]*>`)
+)
+
+// plainText strips the tags from rendered HTML, heading anchors included.
+func plainText(s string) string {
+	s = anchorRe.ReplaceAllString(s, "")
+	s = tagRe.ReplaceAllString(s, " ")
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
+
+// Excerpt returns the start of the rendered text, cut at a word boundary so
+// it is at most maxLen bytes, for previews and feed summaries.
+func (r *Rendered) Excerpt(maxLen int) string {
+	text := plainText(r.HTML)
+	if len(text) <= maxLen {
+		return text
+	}
+	cut := strings.LastIndex(text[:maxLen], " ")
+	if cut <= 0 {
+		cut = maxLen
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+	}
+	return strings.TrimRight(text[:cut], " ,;:.") + "…"
+}
@@ -0,0 +1,266 @@
+package content
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+)
+
+// allowedTags maps every tag that may reach a client to the attributes it
+// may keep. Anything else is dropped, keeping its text.
+var allowedTags = map[string][]string{
+	"a":          {"href", "title", "class"},
+	"abbr":       {"title"},
+	"b":          nil,
+	"blockquote": nil,
+	"br":         nil,
+	"code":       {"class"},
+	"dd":         nil,
+	"del":        nil,
+	"details":    {"class", "open"},
+	"div":        {"class"},
+	"dl":         nil,
+	"dt":         nil,
+	"em":         nil,
+	"h1":         {"id"},
+	"h2":         {"id"},
+	"h3":         {"id"},
+	"h4":         {"id"},
+	"h5":         {"id"},
+	"h6":         {"id"},
+	"hr":         nil,
+	"i":          nil,
+	"img":        {"src", "alt", "title", "width", "height"},
+	"kbd":        nil,
+	"li":         nil,
+	"mark":       nil,
+	"ol":         {"start"},
+	"p":          nil,
+	"pre":        nil,
+	"s":          nil,
+	"small":      nil,
+	"span":       {"class"},
+	"strong":     nil,
+	"sub":        nil,
+	"summary":    nil,
+	"sup":        nil,
+	"table":      nil,
+	"tbody":      nil,
+	"td":         {"align", "colspan", "rowspan"},
+	"th":         {"align", "colspan", "rowspan"},
+	"thead":      nil,
+	"tr":         nil,
+	"u":          nil,
+	"ul":         nil,
+}
+
+// droppedTags lose their content too, not just the tag. Embeds have no
+// content and are simply dropped like any other unknown tag.
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true,
+	"noscript": true, "template": true, "textarea": true, "select": true,
+	"svg": true, "math": true, "title": true, "head": true,
+}
+
+// rawTextTags are dropped tags whose content the tokenizer hands over as a
+// single text token, up to their end tag or the end of the input.
+var rawTextTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "noscript": true,
+	"textarea": true, "title": true,
+}
+
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+var (
+	classRe  = regexp.MustCompile(`^[a-zA-Z0-9 _-]+$`)
+	idRe     = regexp.MustCompile(`^[a-z0-9-]+$`)
+	numberRe = regexp.MustCompile(`^[0-9]{1,4}$`)
+	schemeRe = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*):`)
+)
+
+// sanitize re-serializes the HTML keeping only allow-listed tags and
+// attributes. Unclosed tags are closed and stray end tags ignored, so the
+// result can be embedded anywhere without breaking the page around it.
+//
+// The content of a dropped tag is only skipped when its end tag follows;
+// an unclosed <object> would otherwise swallow the rest of the document.
+func sanitize(source string) string {
+	var b strings.Builder
+	var open []string
+	skip := 0
+	rawText := false
+
+	ends := droppedEndTags(source) // end tags of each dropped tag still ahead
+	skipping := map[string]int{}   // dropped tags whose content is being skipped
+
+	z := xhtml.NewTokenizer(strings.NewReader(source))
+	for {
+		tt := z.Next()
+		if tt == xhtml.ErrorToken {
+			break
+		}
+		tok := z.Token()
+
+		if tt == xhtml.TextToken && rawText {
+			rawText = false
+			continue
+		}
+		rawText = false
+
+		switch tt {
+		case xhtml.TextToken:
+			if skip == 0 {
+				b.WriteString(html.EscapeString(tok.Data))
+			}
+
+		case xhtml.StartTagToken, xhtml.SelfClosingTagToken:
+			if droppedTags[tok.Data] {
+				switch {
+				case rawTextTags[tok.Data]:
+					rawText = true
+				case tt == xhtml.StartTagToken && ends[tok.Data] > skipping[tok.Data]:
+					skipping[tok.Data]++
+					skip++
+				}
+				continue
+			}
+			attrs, ok := allowedTags[tok.Data]
+			if !ok || skip > 0 {
+				continue
+			}
+			writeTag(&b, tok, attrs)
+			if voidTags[tok.Data] {
+				continue
+			}
+			if tt == xhtml.SelfClosingTagToken {
+				b.WriteString("</" + tok.Data + ">")
+				continue
+			}
+			open = append(open, tok.Data)
+
+		case xhtml.EndTagToken:
+			if droppedTags[tok.Data] {
+				ends[tok.Data]--
+				if skipping[tok.Data] > 0 {
+					skipping[tok.Data]--
+					skip--
+				}
+				continue
+			}
+			if skip > 0 {
+				continue
+			}
+			// Close everything opened after the matching tag
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == tok.Data {
+					for j := len(open) - 1; j >= i; j-- {
+						b.WriteString("</" + open[j] + ">")
+					}
+					open = open[:i]
+					break
+				}
+			}
+		}
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+	return b.String()
+}
+
+// droppedEndTags counts the end tags of every dropped tag in the source.
+func droppedEndTags(source string) map[string]int {
+	ends := map[string]int{}
+	z := xhtml.NewTokenizer(strings.NewReader(source))
+	for {
+		switch z.Next() {
+		case xhtml.ErrorToken:
+			return ends
+		case xhtml.EndTagToken:
+			name, _ := z.TagName()
+			if tag := string(name); droppedTags[tag] {
+				ends[tag]++
+			}
+		}
+	}
+}
+
+func writeTag(b *strings.Builder, tok xhtml.Token, allowed []string) {
+	b.WriteString("<" + tok.Data)
+
+	external := false
+	for _, attr := range tok.Attr {
+		if !contains(allowed, attr.Key) {
+			continue
+		}
+		val, ok := cleanAttr(tok.Data, attr.Key, attr.Val)
+		if !ok {
+			continue
+		}
+		if attr.Key == "href" && schemeRe.MatchString(val) {
+			external = true
+		}
+		b.WriteString(" " + attr.Key + `="` + html.EscapeString(val) + `"`)
+	}
+
+	if external {
+		b.WriteString(` rel="nofollow noopener noreferrer"`)
+	}
+	b.WriteString(">")
+}
+
+// cleanAttr validates a single attribute value.
+func cleanAttr(tag, key, val string) (string, bool) {
+	val = strings.TrimSpace(val)
+
+	switch key {
+	case "href":
+		return val, safeURL(val, "http", "https", "mailto")
+	case "src":
+		return val, safeURL(val, "http", "https")
+	case "class":
+		return val, classRe.MatchString(val)
+	case "id":
+		return val, idRe.MatchString(val)
+	case "align":
+		return val, val == "left" || val == "center" || val == "right"
+	case "start", "colspan", "rowspan", "width", "height":
+		return val, numberRe.MatchString(val)
+	case "open":
+		return "", tag == "details"
+	}
+	return val, true
+}
+
+// safeURL accepts relative URLs and absolute ones using one of the schemes.
+// Control characters and whitespace are removed first because browsers
+// ignore them, which is how "java\tscript:" slips past naive checks.
+func safeURL(val string, schemes ...string) bool {
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, val)
+	if cleaned == "" {
+		return false
+	}
+
+	m := schemeRe.FindStringSubmatch(cleaned)
+	if m == nil {
+		return true
+	}
+	return contains(schemes, strings.ToLower(m[1]))
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package content
+
+import "testing"
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "allowed tags",
+			source: "<p>a <strong>b</strong></p>",
+			want:   "<p>a <strong>b</strong></p>",
+		},
+		{
+			name:   "unknown tag keeps its text",
+			source: "<p><font color=red>text</font></p>",
+			want:   "<p>text</p>",
+		},
+		{
+			name:   "script",
+			source: "<p>before</p><script>alert(1)</script><p>after</p>",
+			want:   "<p>before</p><p>after</p>",
+		},
+		{
+			name:   "unclosed script",
+			source: "<p>before</p><script>alert(1)",
+			want:   "<p>before</p>",
+		},
+		{
+			name:   "self-closing script",
+			source: "<script/>alert(1)",
+			want:   "",
+		},
+		{
+			name:   "object",
+			source: "<p>before</p><object><p>fallback</p></object><p>after</p>",
+			want:   "<p>before</p><p>after</p>",
+		},
+		{
+			name:   "nested objects",
+			source: "<object><object>x</object>y</object><p>after</p>",
+			want:   "<p>after</p>",
+		},
+		{
+			name:   "unclosed object",
+			source: "<p>before</p><object data=x><p>after</p>",
+			want:   "<p>before</p><p>after</p>",
+		},
+		{
+			name:   "embed",
+			source: "<p>before</p><embed src=x><p>after</p>",
+			want:   "<p>before</p><p>after</p>",
+		},
+		{
+			name:   "iframe",
+			source: "<p>before</p><iframe src=x></iframe><p>after</p>",
+			want:   "<p>before</p><p>after</p>",
+		},
+		{
+			name:   "void tags",
+			source: "a<br>b<hr/><img src=/x.png>",
+			want:   `a<br>b<hr><img src="/x.png">`,
+		},
+		{
+			name:   "unclosed tags are closed",
+			source: "<ul><li>a",
+			want:   "<ul><li>a</li></ul>",
+		},
+		{
+			name:   "stray end tag",
+			source: "a</div>b",
+			want:   "ab",
+		},
+		{
+			name:   "event handler",
+			source: `<img src="/x.png" onerror="alert(1)">`,
+			want:   `<img src="/x.png">`,
+		},
+		{
+			name:   "javascript href",
+			source: `<a href="javascript:alert(1)">x</a>`,
+			want:   "<a>x</a>",
+		},
+		{
+			name:   "entity-encoded javascript href",
+			source: `<a href="&#106;avascript:alert(1)">x</a>`,
+			want:   "<a>x</a>",
+		},
+		{
+			name:   "javascript href with control characters",
+			source: "<a href=\"java\x01script:alert(1)\">x</a>",
+			want:   "<a>x</a>",
+		},
+		{
+			name:   "javascript href with a tab",
+			source: "<a href=\"java\tscript:alert(1)\">x</a>",
+			want:   "<a>x</a>",
+		},
+		{
+			name:   "javascript src",
+			source: `<img src="javascript:alert(1)" alt="x">`,
+			want:   `<img alt="x">`,
+		},
+		{
+			name:   "mailto is not allowed as an image",
+			source: `<img src="mailto:a@b.c">`,
+			want:   "<img>",
+		},
+		{
+			name:   "external link",
+			source: `<a href="https://example.com">x</a>`,
+			want:   `<a href="https://example.com" rel="nofollow noopener noreferrer">x</a>`,
+		},
+		{
+			name:   "bad class",
+			source: `<span class="a&quot;b">x</span>`,
+			want:   "<span>x</span>",
+		},
+		{
+			name:   "text is escaped",
+			source: "a &lt;b&gt; &amp; c",
+			want:   "a &lt;b&gt; &amp; c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitize(tt.source); got != tt.want {
+				t.Errorf("sanitize(%q)\n got %q\nwant %q", tt.source, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,416 @@
+package epub
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// MediaType is the media type of EPUB files.
+const MediaType = "application/epub+zip"
+
+// ImageTypes are the image media types every EPUB 3 reading system renders.
+var ImageTypes = map[string]bool{
+	"image/gif":     true,
+	"image/jpeg":    true,
+	"image/png":     true,
+	"image/svg+xml": true,
+	"image/webp":    true,
+}
+
+var (
+	ErrNoTitle    = errors.New("epub: the book needs a title")
+	ErrNoChapters = errors.New("epub: the book needs at least one chapter")
+)
+
+// Book is everything that goes in an EPUB. Chapters are read in order; their
+// Body is an XHTML fragment, see Rewriter, whose images point at Images by Src.
+type Book struct {
+	ID           string // unique and stable across revisions, e.g. "urn:uuid:…"
+	Title        string
+	Language     string
+	Authors      []string
+	Contributors []string
+	Description  string
+	Subjects     []string
+	Published    time.Time
+	Modified     time.Time
+	Chapters     []Chapter
+	Images       []Image
+}
+
+type Chapter struct {
+	Title    string
+	Body     string
+	Sections []Section // listed under the chapter in the table of contents
+}
+
+// Section is a heading of a chapter, Anchor being its id in the Body.
+type Section struct {
+	Title  string
+	Anchor string
+}
+
+// Image is stored as images/<Name>, the src chapters use for it.
+type Image struct {
+	Name      string
+	MediaType string
+	Data      []byte
+	Cover     bool
+}
+
+// Src is the path chapters refer to an image by.
+func Src(name string) string {
+	return "images/" + name
+}
+
+// Write packages the book as an EPUB 3, with an EPUB 2 table of contents
+// too for older e-readers.
+func Write(w io.Writer, b *Book) error {
+	if strings.TrimSpace(b.Title) == "" {
+		return ErrNoTitle
+	}
+	if len(b.Chapters) == 0 {
+		return ErrNoChapters
+	}
+
+	z := zip.NewWriter(w)
+	// The mimetype comes first and uncompressed, so the format can be sniffed
+	mt, err := z.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mt, MediaType); err != nil {
+		return err
+	}
+
+	files := []file{
+		{"META-INF/container.xml", writeContainer},
+		{"OEBPS/content.opf", b.writePackage},
+		{"OEBPS/nav.xhtml", b.writeNav},
+		{"OEBPS/toc.ncx", b.writeNCX},
+		{"OEBPS/style.css", writeStyle},
+	}
+	for i := range b.Chapters {
+		ch := &b.Chapters[i]
+		files = append(files, file{"OEBPS/" + chapterFile(i), func(w io.Writer) error { return b.writeChapter(w, ch) }})
+	}
+	for _, f := range files {
+		fw, err := z.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if err := f.write(fw); err != nil {
+			return err
+		}
+	}
+
+	for _, img := range b.Images {
+		// Images are compressed already
+		fw, err := z.CreateHeader(&zip.FileHeader{Name: "OEBPS/" + Src(img.Name), Method: zip.Store})
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(img.Data); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+// file is one file of the package besides the mimetype and the images.
+type file struct {
+	name  string
+	write func(io.Writer) error
+}
+
+/***********
+ * PACKAGE *
+ ***********/
+
+type container struct {
+	XMLName   xml.Name   `xml:"urn:oasis:names:tc:opendocument:xmlns:container container"`
+	Version   string     `xml:"version,attr"`
+	Rootfiles []rootfile `xml:"rootfiles>rootfile"`
+}
+
+type rootfile struct {
+	FullPath  string `xml:"full-path,attr"`
+	MediaType string `xml:"media-type,attr"`
+}
+
+func writeContainer(w io.Writer) error {
+	return writeXML(w, container{
+		Version:   "1.0",
+		Rootfiles: []rootfile{{FullPath: "OEBPS/content.opf", MediaType: "application/oebps-package+xml"}},
+	})
+}
+
+type opfPackage struct {
+	XMLName          xml.Name    `xml:"http://www.idpf.org/2007/opf package"`
+	Version          string      `xml:"version,attr"`
+	UniqueIdentifier string      `xml:"unique-identifier,attr"`
+	Lang             string      `xml:"xml:lang,attr"`
+	Metadata         opfMetadata `xml:"metadata"`
+	Manifest         []opfItem   `xml:"manifest>item"`
+	Spine            opfSpine    `xml:"spine"`
+}
+
+type opfMetadata struct {
+	DC           string    `xml:"xmlns:dc,attr"`
+	Identifier   opfID     `xml:"dc:identifier"`
+	Title        string    `xml:"dc:title"`
+	Language     string    `xml:"dc:language"`
+	Creators     []string  `xml:"dc:creator"`
+	Contributors []string  `xml:"dc:contributor"`
+	Description  string    `xml:"dc:description,omitempty"`
+	Subjects     []string  `xml:"dc:subject"`
+	Date         string    `xml:"dc:date,omitempty"`
+	Meta         []opfMeta `xml:"meta"`
+}
+
+type opfID struct {
+	ID    string `xml:"id,attr"`
+	Value string `xml:",chardata"`
+}
+
+// opfMeta is either an EPUB 3 property or, with Name, an EPUB 2 meta like
+// the one pointing at the cover image.
+type opfMeta struct {
+	Property string `xml:"property,attr,omitempty"`
+	Name     string `xml:"name,attr,omitempty"`
+	Content  string `xml:"content,attr,omitempty"`
+	Value    string `xml:",chardata"`
+}
+
+type opfItem struct {
+	ID         string `xml:"id,attr"`
+	Href       string `xml:"href,attr"`
+	MediaType  string `xml:"media-type,attr"`
+	Properties string `xml:"properties,attr,omitempty"`
+}
+
+type opfSpine struct {
+	Toc      string       `xml:"toc,attr"`
+	Itemrefs []opfItemref `xml:"itemref"`
+}
+
+type opfItemref struct {
+	IDRef string `xml:"idref,attr"`
+}
+
+func (b *Book) writePackage(w io.Writer) error {
+	modified := b.Modified
+	if modified.IsZero() {
+		modified = time.Now()
+	}
+	p := opfPackage{
+		Version:          "3.0",
+		UniqueIdentifier: "book-id",
+		Lang:             b.language(),
+		Metadata: opfMetadata{
+			DC:           "http://purl.org/dc/elements/1.1/",
+			Identifier:   opfID{ID: "book-id", Value: b.ID},
+			Title:        b.Title,
+			Language:     b.language(),
+			Creators:     b.Authors,
+			Contributors: b.Contributors,
+			Description:  b.Description,
+			Subjects:     b.Subjects,
+			Meta:         []opfMeta{{Property: "dcterms:modified", Value: modified.UTC().Format("2006-01-02T15:04:05Z")}},
+		},
+		Manifest: []opfItem{
+			{ID: "nav", Href: "nav.xhtml", MediaType: "application/xhtml+xml", Properties: "nav"},
+			{ID: "ncx", Href: "toc.ncx", MediaType: "application/x-dtbncx+xml"},
+			{ID: "style", Href: "style.css", MediaType: "text/css"},
+		},
+		Spine: opfSpine{Toc: "ncx"},
+	}
+	if !b.Published.IsZero() {
+		p.Metadata.Date = b.Published.UTC().Format("2006-01-02")
+	}
+
+	for i := range b.Chapters {
+		id := chapterID(i)
+		p.Manifest = append(p.Manifest, opfItem{ID: id, Href: chapterFile(i), MediaType: "application/xhtml+xml"})
+		p.Spine.Itemrefs = append(p.Spine.Itemrefs, opfItemref{IDRef: id})
+	}
+	for i, img := range b.Images {
+		item := opfItem{ID: fmt.Sprintf("img-%03d", i+1), Href: Src(img.Name), MediaType: img.MediaType}
+		if img.Cover {
+			item.Properties = "cover-image"
+			p.Metadata.Meta = append(p.Metadata.Meta, opfMeta{Name: "cover", Content: item.ID})
+		}
+		p.Manifest = append(p.Manifest, item)
+	}
+	return writeXML(w, p)
+}
+
+/*********************
+ * TABLE OF CONTENTS *
+ *********************/
+
+type navDoc struct {
+	XMLName xml.Name `xml:"http://www.w3.org/1999/xhtml html"`
+	EPUB    string   `xml:"xmlns:epub,attr"`
+	Lang    string   `xml:"lang,attr"`
+	XMLLang string   `xml:"xml:lang,attr"`
+	Title   string   `xml:"head>title"`
+	Nav     struct {
+		Type    string   `xml:"epub:type,attr"`
+		ID      string   `xml:"id,attr"`
+		Heading string   `xml:"h1"`
+		List    *navList `xml:"ol"`
+	} `xml:"body>nav"`
+}
+
+type navList struct {
+	Items []navItem `xml:"li"`
+}
+
+type navItem struct {
+	Link struct {
+		Href string `xml:"href,attr"`
+		Text string `xml:",chardata"`
+	} `xml:"a"`
+	List *navList `xml:"ol,omitempty"` // nil rather than an empty list, which is invalid
+}
+
+func newNavItem(href, text string) navItem {
+	var item navItem
+	item.Link.Href, item.Link.Text = href, text
+	return item
+}
+
+func (b *Book) writeNav(w io.Writer) error {
+	doc := navDoc{EPUB: "http://www.idpf.org/2007/ops", Lang: b.language(), XMLLang: b.language(), Title: b.Title}
+	doc.Nav.Type, doc.Nav.ID, doc.Nav.Heading = "toc", "toc", b.Title
+	doc.Nav.List = &navList{}
+	for i, ch := range b.Chapters {
+		item := newNavItem(chapterFile(i), ch.Title)
+		if len(ch.Sections) > 0 {
+			item.List = &navList{}
+			for _, s := range ch.Sections {
+				item.List.Items = append(item.List.Items, newNavItem(chapterFile(i)+"#"+s.Anchor, s.Title))
+			}
+		}
+		doc.Nav.List.Items = append(doc.Nav.List.Items, item)
+	}
+	if _, err := io.WriteString(w, xml.Header+"<!DOCTYPE html>\n"); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(doc)
+}
+
+type ncxDoc struct {
+	XMLName xml.Name   `xml:"http://www.daisy.org/z3986/2005/ncx/ ncx"`
+	Version string     `xml:"version,attr"`
+	Meta    []opfMeta  `xml:"head>meta"`
+	Title   string     `xml:"docTitle>text"`
+	Points  []ncxPoint `xml:"navMap>navPoint"`
+}
+
+type ncxPoint struct {
+	ID        string `xml:"id,attr"`
+	PlayOrder int    `xml:"playOrder,attr"`
+	Label     string `xml:"navLabel>text"`
+	Content   struct {
+		Src string `xml:"src,attr"`
+	} `xml:"content"`
+	Children []ncxPoint `xml:"navPoint,omitempty"`
+}
+
+func (b *Book) writeNCX(w io.Writer) error {
+	doc := ncxDoc{Version: "2005-1", Title: b.Title, Meta: []opfMeta{{Name: "dtb:uid", Content: b.ID}}}
+
+	order := 0
+	point := func(src, label string) ncxPoint {
+		order++
+		p := ncxPoint{ID: fmt.Sprintf("point-%d", order), PlayOrder: order, Label: label}
+		p.Content.Src = src
+		return p
+	}
+	for i, ch := range b.Chapters {
+		p := point(chapterFile(i), ch.Title)
+		for _, s := range ch.Sections {
+			p.Children = append(p.Children, point(chapterFile(i)+"#"+s.Anchor, s.Title))
+		}
+		doc.Points = append(doc.Points, p)
+	}
+	return writeXML(w, doc)
+}
+
+/************
+ * CHAPTERS *
+ ************/
+
+func (b *Book) writeChapter(w io.Writer, ch *Chapter) error {
+	var title strings.Builder
+	if err := xml.EscapeText(&title, []byte(ch.Title)); err != nil {
+		return err
+	}
+	lang := b.language()
+	_, err := fmt.Fprintf(w, `%s<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="%s" xml:lang="%s">
+<head>
+<title>%s</title>
+<link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+<section epub:type="chapter">
+<h1>%s</h1>
+%s
+</section>
+</body>
+</html>
+`, xml.Header, lang, lang, title.String(), title.String(), ch.Body)
+	return err
+}
+
+const style = `body { font-family: serif; line-height: 1.5; margin: 0 5%; }
+h1, h2, h3, h4 { font-family: sans-serif; line-height: 1.2; page-break-after: avoid; }
+img { max-width: 100%; height: auto; }
+figure { margin: 1em 0; text-align: center; }
+pre, code { font-family: monospace; font-size: 0.9em; }
+pre { white-space: pre-wrap; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #999; padding: 0.2em 0.4em; }
+blockquote { margin-left: 1.5em; font-style: italic; }
+`
+
+func writeStyle(w io.Writer) error {
+	_, err := io.WriteString(w, style)
+	return err
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func (b *Book) language() string {
+	if b.Language == "" {
+		return "en"
+	}
+	return b.Language
+}
+
+func chapterID(i int) string {
+	return fmt.Sprintf("chapter-%03d", i+1)
+}
+
+func chapterFile(i int) string {
+	return chapterID(i) + ".xhtml"
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,148 @@
+package epub
+
+import (
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Rewriter turns the sanitized HTML of rendered content into an XHTML
+// fragment that can be a chapter Body.
+type Rewriter struct {
+	// Image maps the src of an image to the Src of an image of the book.
+	// Images it refuses, like remote ones, which readers can't load offline,
+	// are replaced by their alt text.
+	Image func(src string) (string, bool)
+	// Link rewrites the href of links to other documents, e.g. to make site
+	// relative links absolute.
+	Link func(href string) string
+	// Prefix is prepended to ids, and to the links to them, so fragments of
+	// several documents can share a chapter.
+	Prefix string
+}
+
+// XHTML rewrites fragment. Heading permalinks are dropped, and the attributes
+// HTML5 made obsolete are turned into styles.
+func (r Rewriter) XHTML(fragment string) (string, error) {
+	body := &xhtml.Node{Type: xhtml.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := xhtml.ParseFragment(strings.NewReader(fragment), body)
+	if err != nil {
+		return "", err
+	}
+
+	for _, n := range nodes {
+		body.AppendChild(n)
+	}
+	r.rewrite(body)
+
+	var b strings.Builder
+	for n := body.FirstChild; n != nil; n = n.NextSibling {
+		if err := xhtml.Render(&b, n); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// rewrite fixes n and its children in place.
+func (r Rewriter) rewrite(n *xhtml.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		r.rewrite(c)
+		c = next
+	}
+	if n.Type != xhtml.ElementNode {
+		return
+	}
+
+	switch n.Data {
+	case "a":
+		if hasClass(n, "heading-anchor") {
+			drop(n)
+			return
+		}
+	case "img":
+		src, ok := "", false
+		if r.Image != nil {
+			src, ok = r.Image(attr(n, "src"))
+		}
+		if !ok {
+			replace(n, attr(n, "alt"))
+			return
+		}
+		setAttr(n, "src", src)
+		if attr(n, "alt") == "" {
+			setAttr(n, "alt", "") // required in XHTML
+		}
+	case "td", "th":
+		if align := attr(n, "align"); align != "" {
+			removeAttr(n, "align")
+			setAttr(n, "style", "text-align: "+align)
+		}
+	}
+
+	for i, a := range n.Attr {
+		switch {
+		case a.Key == "id":
+			n.Attr[i].Val = r.Prefix + a.Val
+		case a.Key == "href" && strings.HasPrefix(a.Val, "#"):
+			n.Attr[i].Val = "#" + r.Prefix + a.Val[1:]
+		case a.Key == "href" && r.Link != nil:
+			n.Attr[i].Val = r.Link(a.Val)
+		}
+	}
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func attr(n *xhtml.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func setAttr(n *xhtml.Node, key, val string) {
+	for i, a := range n.Attr {
+		if a.Key == key {
+			n.Attr[i].Val = val
+			return
+		}
+	}
+	n.Attr = append(n.Attr, xhtml.Attribute{Key: key, Val: val})
+}
+
+func removeAttr(n *xhtml.Node, key string) {
+	for i, a := range n.Attr {
+		if a.Key == key {
+			n.Attr = append(n.Attr[:i], n.Attr[i+1:]...)
+			return
+		}
+	}
+}
+
+func hasClass(n *xhtml.Node, class string) bool {
+	for _, c := range strings.Fields(attr(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// replace swaps n for a text node, or drops it when text is empty.
+func replace(n *xhtml.Node, text string) {
+	if text != "" {
+		n.Parent.InsertBefore(&xhtml.Node{Type: xhtml.TextNode, Data: text}, n)
+	}
+	drop(n)
+}
+
+func drop(n *xhtml.Node) {
+	n.Parent.RemoveChild(n)
+}
@@ -0,0 +1,58 @@
+// Package events lets a domain announce what happened, like a user being
+// mentioned, to the domains that react to it, without importing them.
+// Delivery is in process and asynchronous: handlers run after the request
+// that published the event, and an event published while nobody subscribed
+// is dropped.
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// Topic is a kind of event, carrying payloads of type E.
+type Topic[E any] struct {
+	name string
+}
+
+func NewTopic[E any](name string) Topic[E] {
+	return Topic[E]{name: name}
+}
+
+func (t Topic[E]) Name() string {
+	return t.name
+}
+
+var (
+	mu       sync.RWMutex
+	handlers = map[string][]any{}
+)
+
+// Subscribe calls handle with every event published on the topic from now on.
+// Subscriptions are made at startup and last for the life of the process.
+func (t Topic[E]) Subscribe(handle func(ctx context.Context, event E)) {
+	mu.Lock()
+	defer mu.Unlock()
+	handlers[t.name] = append(handlers[t.name], handle)
+}
+
+// Publish hands the event to every subscriber, each on its own goroutine. A
+// failing subscriber only logs.
+func (t Topic[E]) Publish(event E) {
+	mu.RLock()
+	subscribed := handlers[t.name]
+	mu.RUnlock()
+
+	for _, h := range subscribed {
+		handle := h.(func(context.Context, E))
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("%s event handler panicked: %v", t.name, r)
+				}
+			}()
+			handle(context.Background(), event)
+		}()
+	}
+}
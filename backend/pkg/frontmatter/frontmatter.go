@@ -0,0 +1,310 @@
+// Package frontmatter reads and writes Markdown files opening with a YAML
+// front matter block between "---" lines.
+//
+// Only the part of YAML such blocks use is supported: one "key: value" per
+// line, plain, quoted and block (| and >) strings, and lists written inline
+// ([a, b]) or one "- item" per line. Nested maps are rejected.
+package frontmatter
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const delimiter = "---"
+
+// Meta holds the front matter values: a string or a []string per key.
+type Meta map[string]any
+
+// String returns the value of key, empty when missing or a list.
+func (m Meta) String(key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// List returns the values of key. A single string counts as a one-item list,
+// so "tags: shiny" and "tags: [shiny]" read the same.
+func (m Meta) List(key string) []string {
+	switch v := m[key].(type) {
+	case []string:
+		return v
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	}
+	return nil
+}
+
+// Field is one front matter entry, for writing them in a stable order.
+type Field struct {
+	Key   string
+	Value any // string or []string
+}
+
+// Parse splits a file into its front matter and Markdown body. A file
+// without front matter has empty Meta and is all body.
+func Parse(src []byte) (Meta, string, error) {
+	text := strings.ReplaceAll(string(bytes.TrimPrefix(src, []byte("\ufeff"))), "\r\n", "\n")
+	meta := Meta{}
+
+	rest, ok := strings.CutPrefix(text, delimiter+"\n")
+	if !ok {
+		return meta, text, nil
+	}
+
+	lines := strings.Split(rest, "\n")
+	end := -1
+	for i, line := range lines {
+		if trimmed := strings.TrimRight(line, " \t"); trimmed == delimiter || trimmed == "..." {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return nil, "", fmt.Errorf("front matter is not closed by %q", delimiter)
+	}
+	body := strings.TrimLeft(strings.Join(lines[end+1:], "\n"), "\n")
+
+	lines = lines[:end]
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		lineNo := i + 2 // the opening delimiter is line 1
+		if isBlankOrComment(line) {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			return nil, "", fmt.Errorf("front matter line %d: unexpected indentation", lineNo)
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, "", fmt.Errorf("front matter line %d: expected \"key: value\"", lineNo)
+		}
+		if _, dup := meta[key]; dup {
+			return nil, "", fmt.Errorf("front matter line %d: duplicate key %q", lineNo, key)
+		}
+		value = strings.TrimSpace(value)
+
+		var err error
+		switch {
+		case value == "":
+			// A block list, or nothing
+			var items []string
+			for i+1 < len(lines) && (isBlankOrComment(lines[i+1]) || isListItem(lines[i+1])) {
+				i++
+				if isBlankOrComment(lines[i]) {
+					continue
+				}
+				item := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(lines[i]), "-"))
+				if item, err = scalar(item); err != nil {
+					return nil, "", fmt.Errorf("front matter line %d: %w", i+2, err)
+				}
+				items = append(items, item)
+			}
+			if items != nil {
+				meta[key] = items
+			} else {
+				meta[key] = ""
+			}
+		case value == "|" || value == ">" || value == "|-" || value == ">-":
+			var block []string
+			for i+1 < len(lines) && (strings.TrimSpace(lines[i+1]) == "" || lines[i+1][0] == ' ' || lines[i+1][0] == '\t') {
+				i++
+				block = append(block, lines[i])
+			}
+			meta[key] = blockScalar(block, value[0] == '>')
+		case strings.HasPrefix(value, "["):
+			if meta[key], err = inlineList(value); err != nil {
+				return nil, "", fmt.Errorf("front matter line %d: %w", lineNo, err)
+			}
+		case strings.HasPrefix(value, "{"):
+			return nil, "", fmt.Errorf("front matter line %d: maps are not supported", lineNo)
+		default:
+			if meta[key], err = scalar(value); err != nil {
+				return nil, "", fmt.Errorf("front matter line %d: %w", lineNo, err)
+			}
+		}
+	}
+	return meta, body, nil
+}
+
+// Format writes the fields as front matter followed by the body. Empty
+// strings and lists are left out.
+func Format(fields []Field, body string) []byte {
+	var b strings.Builder
+	b.WriteString(delimiter + "\n")
+	for _, f := range fields {
+		switch v := f.Value.(type) {
+		case string:
+			if v != "" {
+				b.WriteString(f.Key + ": " + quote(v) + "\n")
+			}
+		case []string:
+			if len(v) == 0 {
+				continue
+			}
+			b.WriteString(f.Key + ":\n")
+			for _, item := range v {
+				b.WriteString("  - " + quote(item) + "\n")
+			}
+		}
+	}
+	b.WriteString(delimiter + "\n")
+	if body = strings.TrimLeft(body, "\n"); body != "" {
+		b.WriteString("\n" + body)
+		if !strings.HasSuffix(body, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return []byte(b.String())
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func isBlankOrComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#")
+}
+
+func isListItem(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "-" || strings.HasPrefix(trimmed, "- ")
+}
+
+// scalar reads a single value: quoted, or plain up to a " #" comment.
+func scalar(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		end := closingQuote(value, '"')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string %s", value)
+		}
+		s, err := strconv.Unquote(value[:end+1])
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", value[:end+1])
+		}
+		return s, nil
+	case strings.HasPrefix(value, "'"):
+		end := closingQuote(value, '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string %s", value)
+		}
+		return strings.ReplaceAll(value[1:end], "''", "'"), nil
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = value[:i]
+	}
+	value = strings.TrimSpace(value)
+	if value == "~" || value == "null" {
+		return "", nil
+	}
+	return value, nil
+}
+
+// closingQuote finds the quote ending the string opened at value[0].
+// Double-quoted strings escape with a backslash, single-quoted ones by
+// doubling the quote.
+func closingQuote(value string, q byte) int {
+	for i := 1; i < len(value); i++ {
+		switch {
+		case q == '"' && value[i] == '\\':
+			i++
+		case value[i] == q && q == '\'' && i+1 < len(value) && value[i+1] == '\'':
+			i++
+		case value[i] == q:
+			return i
+		}
+	}
+	return -1
+}
+
+func inlineList(value string) ([]string, error) {
+	if !strings.HasSuffix(value, "]") {
+		return nil, fmt.Errorf("unterminated list %s", value)
+	}
+	inner := value[1 : len(value)-1]
+	items := []string{}
+	for {
+		inner = strings.TrimSpace(inner)
+		if inner == "" {
+			return items, nil
+		}
+
+		var raw string
+		if inner[0] == '"' || inner[0] == '\'' {
+			end := closingQuote(inner, inner[0])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in list %s", value)
+			}
+			raw, inner = inner[:end+1], strings.TrimSpace(inner[end+1:])
+			if inner != "" && inner[0] != ',' {
+				return nil, fmt.Errorf("expected a comma in list %s", value)
+			}
+			inner = strings.TrimPrefix(inner, ",")
+		} else {
+			raw, inner, _ = strings.Cut(inner, ",")
+		}
+
+		item, err := scalar(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+}
+
+// blockScalar joins the lines of a | (literal) or > (folded) string, without
+// their common indentation.
+func blockScalar(lines []string, folded bool) string {
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			lines[i] = line[indent:]
+		} else {
+			lines[i] = strings.TrimSpace(line)
+		}
+	}
+	text := strings.TrimRight(strings.Join(lines, "\n"), "\n")
+	if !folded {
+		return text
+	}
+
+	var paragraphs []string
+	for _, p := range strings.Split(text, "\n\n") {
+		paragraphs = append(paragraphs, strings.Join(strings.Fields(p), " "))
+	}
+	return strings.Join(paragraphs, "\n")
+}
+
+// quote leaves plain strings as they are and double-quotes the ones YAML
+// would read differently.
+func quote(s string) string {
+	if s == "" || s != strings.TrimSpace(s) || strings.ContainsAny(s, "\n\t\"\\") ||
+		strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") ||
+		strings.ContainsRune("-?:,[]{}#&*!|>'\"%@`~", rune(s[0])) {
+		return strconv.Quote(s)
+	}
+	switch strings.ToLower(s) {
+	case "null", "true", "false", "yes", "no", "on", "off":
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	return s
+}
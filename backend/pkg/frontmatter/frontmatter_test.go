@@ -0,0 +1,226 @@
+package frontmatter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		meta Meta
+		body string
+	}{
+		{
+			name: "no front matter",
+			src:  "# Title\n\ntext",
+			meta: Meta{},
+			body: "# Title\n\ntext",
+		},
+		{
+			name: "plain values",
+			src:  "---\ntitle: Route 1\nslug: route-1\n---\n\nbody\n",
+			meta: Meta{"title": "Route 1", "slug": "route-1"},
+			body: "body\n",
+		},
+		{
+			name: "crlf and byte order mark",
+			src:  "\ufeff---\r\ntitle: Route 1\r\n---\r\nbody",
+			meta: Meta{"title": "Route 1"},
+			body: "body",
+		},
+		{
+			name: "dots close the block",
+			src:  "---\ntitle: x\n...\nbody",
+			meta: Meta{"title": "x"},
+			body: "body",
+		},
+		{
+			name: "quoted strings",
+			src:  "---\na: \"say \\\"hi\\\"\"\nb: 'it''s'\nc: \"a: b # c\"\n---\n",
+			meta: Meta{"a": `say "hi"`, "b": "it's", "c": "a: b # c"},
+		},
+		{
+			name: "comments",
+			src:  "---\n# about\ntitle: Route 1 # the first one\ncolor: red#blue\n---\n",
+			meta: Meta{"title": "Route 1", "color": "red#blue"},
+		},
+		{
+			name: "null and empty",
+			src:  "---\na: ~\nb: null\nc:\n---\n",
+			meta: Meta{"a": "", "b": "", "c": ""},
+		},
+		{
+			name: "inline list",
+			src:  "---\ntags: [shiny, \"a, b\", 'c']\nnone: []\n---\n",
+			meta: Meta{"tags": []string{"shiny", "a, b", "c"}, "none": []string{}},
+		},
+		{
+			name: "block list",
+			src:  "---\ntags:\n  - shiny\n\n  # skipped\n  - \"a: b\"\n-\ntitle: x\n---\n",
+			meta: Meta{"tags": []string{"shiny", "a: b", ""}, "title": "x"},
+		},
+		{
+			name: "literal block",
+			src:  "---\nsummary: |\n  line one\n    indented\n\n  line three\ntitle: x\n---\n",
+			meta: Meta{"summary": "line one\n  indented\n\nline three", "title": "x"},
+		},
+		{
+			name: "folded block",
+			src:  "---\nsummary: >\n  one\n  two\n\n  three\n---\n",
+			meta: Meta{"summary": "one two\nthree"},
+		},
+		{
+			name: "body separator is kept",
+			src:  "---\ntitle: x\n---\n\n---\n\nbody",
+			meta: Meta{"title": "x"},
+			body: "---\n\nbody",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, body, err := Parse([]byte(tt.src))
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.src, err)
+			}
+			if !reflect.DeepEqual(meta, tt.meta) {
+				t.Errorf("meta\n got %#v\nwant %#v", meta, tt.meta)
+			}
+			if body != tt.body {
+				t.Errorf("body\n got %q\nwant %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		err  string
+	}{
+		{"unclosed", "---\ntitle: x\n", "not closed"},
+		{"indented key", "---\n  title: x\n---\n", "line 2: unexpected indentation"},
+		{"missing colon", "---\ntitle\n---\n", "line 2: expected"},
+		{"empty key", "---\n: x\n---\n", "line 2: expected"},
+		{"duplicate key", "---\na: x\na: y\n---\n", "line 3: duplicate key"},
+		{"map", "---\na: {b: c}\n---\n", "maps are not supported"},
+		{"unterminated string", "---\na: \"x\n---\n", "unterminated string"},
+		{"bad escape", "---\na: \"\\q\"\n---\n", "invalid string"},
+		{"unterminated list", "---\na: [x, y\n---\n", "unterminated list"},
+		{"missing comma", "---\na: ['x' 'y']\n---\n", "expected a comma"},
+		{"bad list item", "---\na:\n  - \"x\n---\n", "line 3: unterminated string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Parse([]byte(tt.src))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Parse(%q) error = %v, want one containing %q", tt.src, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestMeta(t *testing.T) {
+	meta := Meta{"title": "x", "tags": []string{"a", "b"}, "tag": "shiny", "empty": ""}
+
+	if got := meta.String("title"); got != "x" {
+		t.Errorf("String(title) = %q", got)
+	}
+	if got := meta.String("tags"); got != "" {
+		t.Errorf("String(tags) = %q, want empty", got)
+	}
+
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"tags", []string{"a", "b"}},
+		{"tag", []string{"shiny"}},
+		{"empty", nil},
+		{"missing", nil},
+	}
+	for _, tt := range tests {
+		if got := meta.List(tt.key); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("List(%s) = %#v, want %#v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	fields := []Field{
+		{Key: "title", Value: "Route 1"},
+		{Key: "summary", Value: ""},
+		{Key: "tags", Value: []string{"shiny", "a: b"}},
+		{Key: "none", Value: []string{}},
+	}
+	want := "---\ntitle: Route 1\ntags:\n  - shiny\n  - \"a: b\"\n---\n\nbody\n"
+	if got := string(Format(fields, "\n\nbody")); got != want {
+		t.Errorf("Format()\n got %q\nwant %q", got, want)
+	}
+
+	want = "---\ntitle: Route 1\n---\n"
+	if got := string(Format(fields[:1], "")); got != want {
+		t.Errorf("Format() without a body\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"Route 1", "Route 1"},
+		{"shiny-hunting", "shiny-hunting"},
+		{"", `""`},
+		{" padded", `" padded"`},
+		{"a: b", `"a: b"`},
+		{"ends:", `"ends:"`},
+		{"a #b", `"a #b"`},
+		{"- item", `"- item"`},
+		{"[x]", `"[x]"`},
+		{"'quoted'", `"'quoted'"`},
+		{"two\nlines", `"two\nlines"`},
+		{"true", `"true"`},
+		{"No", `"No"`},
+		{"null", `"null"`},
+		{"151", `"151"`},
+		{"1.5", `"1.5"`},
+	}
+
+	for _, tt := range tests {
+		if got := quote(tt.s); got != tt.want {
+			t.Errorf("quote(%q) = %s, want %s", tt.s, got, tt.want)
+		}
+	}
+}
+
+// Whatever Format writes, Parse reads back the same.
+func TestRoundTrip(t *testing.T) {
+	values := []string{
+		"Route 1", "a: b", "it's", `say "hi"`, "# not a comment", "two\nlines", "tab\there",
+		"true", "151", "~", "- item", "[x, y]", "{map}", "ends:", "Pokémon", "back\\slash",
+	}
+
+	for _, v := range values {
+		fields := []Field{{Key: "value", Value: v}, {Key: "list", Value: []string{v, "x"}}}
+		meta, body, err := Parse(Format(fields, "body"))
+		if err != nil {
+			t.Errorf("%q: %v", v, err)
+			continue
+		}
+		if got := meta.String("value"); got != v {
+			t.Errorf("value %q read back as %q", v, got)
+		}
+		if got := meta.List("list"); !reflect.DeepEqual(got, []string{v, "x"}) {
+			t.Errorf("list of %q read back as %#v", v, got)
+		}
+		if body != "body\n" {
+			t.Errorf("%q: body read back as %q", v, body)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package language
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default is the language of content that doesn't state one.
+const Default = "en"
+
+// Supported lists the languages content is written and translated in, the
+// locales of the frontend message catalogs.
+var Supported = []string{"en", "es", "pt"}
+
+var ErrUnsupported = errors.New("language must be one of " + strings.Join(Supported, ", "))
+
+// Normalize reduces a language tag to its primary subtag, so "pt-BR" and
+// "PT" are both "pt". It returns "" when the language isn't supported.
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	for _, lang := range Supported {
+		if lang == tag {
+			return lang
+		}
+	}
+	return ""
+}
+
+// Resolve settles the language of content before a save: an empty one keeps
+// the previous language, or the default for new content (previous "").
+func Resolve(lang, previous string) (string, error) {
+	if strings.TrimSpace(lang) == "" {
+		if previous != "" {
+			return previous, nil
+		}
+		return Default, nil
+	}
+	if lang = Normalize(lang); lang == "" {
+		return "", ErrUnsupported
+	}
+	return lang, nil
+}
+
+// Preferred lists the supported languages a reader asked for, most wanted
+// first: the ?lang= query parameter, then the Accept-Language header by
+// quality. Unsupported and refused (q=0) languages are left out.
+func Preferred(query, acceptLanguage string) []string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+	var ranges []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if lang := Normalize(tag); lang != "" && q > 0 {
+			ranges = append(ranges, weighted{lang, q})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	var langs []string
+	add := func(lang string) {
+		for _, l := range langs {
+			if l == lang {
+				return
+			}
+		}
+		langs = append(langs, lang)
+	}
+	if lang := Normalize(query); lang != "" {
+		add(lang)
+	}
+	for _, r := range ranges {
+		add(r.lang)
+	}
+	return langs
+}
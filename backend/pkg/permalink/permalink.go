@@ -0,0 +1,146 @@
+// Package permalink builds the public URLs of the site's pages. Feeds,
+// sitemaps and share metadata all link to the same places through it.
+package permalink
+
+import (
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// SiteName is shown in feed titles and share cards.
+const SiteName = "Pokemon"
+
+// Base is the address links point to: siteURL, the configured site address,
+// when set. It wins over the request's, which clients control.
+func Base(c *fiber.Ctx, siteURL string) string {
+	if siteURL != "" {
+		return siteURL
+	}
+	return c.BaseURL()
+}
+
+func Post(base string, id uuid.UUID) string {
+	return base + "/blog/" + id.String()
+}
+
+func News(base string, id uuid.UUID) string {
+	return base + "/news/" + id.String()
+}
+
+func Guide(base string, id uuid.UUID) string {
+	return base + "/guides/" + id.String()
+}
+
+func Walkthrough(base string, id uuid.UUID) string {
+	return base + "/walkthroughs/" + id.String()
+}
+
+func Topic(base string, id uuid.UUID) string {
+	return base + "/forum/topic/" + id.String()
+}
+
+// TopicComment links to a comment inside its topic's page.
+func TopicComment(base string, topicID, id uuid.UUID) string {
+	return Topic(base, topicID) + "#comment-" + id.String()
+}
+
+func Category(base string, id uuid.UUID) string {
+	return base + "/forum/categories/" + id.String()
+}
+
+func Team(base string, id uuid.UUID) string {
+	return base + "/teams/" + id.String()
+}
+
+func User(base string, id uuid.UUID) string {
+	return base + "/users/" + id.String()
+}
+
+func Shout(base string, id uuid.UUID) string {
+	return base + "/shouts/" + id.String()
+}
+
+// ShoutComment links to a comment inside its shout's page.
+func ShoutComment(base string, shoutID, id uuid.UUID) string {
+	return Shout(base, shoutID) + "#comment-" + id.String()
+}
+
+func Nuzlocke(base string, id uuid.UUID) string {
+	return base + "/nuzlockes/" + id.String()
+}
+
+func Snap(base string, id uuid.UUID) string {
+	return base + "/snaps/" + id.String()
+}
+
+// Hashtag links to the page of a tag, given without its #.
+func Hashtag(base, tag string) string {
+	return base + "/hashtags/" + url.PathEscape(tag)
+}
+
+// Pokemon links to a species' Pokédex entry by its national dex number.
+func Pokemon(base string, dexID int) string {
+	return base + "/pokemon/" + strconv.Itoa(dexID)
+}
+
+func Move(base, slug string) string {
+	return base + "/moves/" + slug
+}
+
+func Ability(base, slug string) string {
+	return base + "/abilities/" + slug
+}
+
+func Item(base, slug string) string {
+	return base + "/items/" + slug
+}
+
+// Kinds of page Parse recognizes.
+const (
+	KindPost        = "post"
+	KindNews        = "news"
+	KindGuide       = "guide"
+	KindWalkthrough = "walkthrough"
+	KindTopic       = "topic"
+	KindTeam        = "team"
+)
+
+// Parse reads a page URL or path built by this package back into the kind of
+// content it shows and its ID (or slug, for guides). The host is ignored.
+func Parse(rawURL string) (kind, ref string, ok bool) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", "", false
+	}
+	parts := strings.Split(strings.Trim(path.Clean("/"+u.Path), "/"), "/")
+
+	switch {
+	case len(parts) == 2 && parts[0] == "blog":
+		kind = KindPost
+	case len(parts) == 2 && parts[0] == "news":
+		kind = KindNews
+	case len(parts) == 2 && parts[0] == "guides":
+		kind = KindGuide
+	case len(parts) == 2 && parts[0] == "walkthroughs":
+		kind = KindWalkthrough
+	case len(parts) == 2 && parts[0] == "teams":
+		kind = KindTeam
+	case len(parts) == 3 && parts[0] == "forum" && parts[1] == "topic":
+		kind = KindTopic
+	default:
+		return "", "", false
+	}
+
+	ref = parts[len(parts)-1]
+	if kind != KindGuide {
+		if _, err := uuid.Parse(ref); err != nil {
+			return "", "", false
+		}
+	}
+	return kind, ref, true
+}
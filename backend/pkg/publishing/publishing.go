@@ -0,0 +1,170 @@
+package publishing
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"pokemon/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Publication states. Rows created before states existed default to
+// published so they stay live.
+const (
+	Draft     = "draft"
+	Scheduled = "scheduled"
+	Published = "published"
+)
+
+var ErrPublishAtRequired = errors.New("publish_at is required to schedule")
+
+// State is embedded in every entity that can be drafted or scheduled. Status
+// is never empty once Prepare has run.
+type State struct {
+	Status      string     `gorm:"type:varchar(20);not null;default:'published';index" json:"status"`
+	PublishAt   *time.Time `gorm:"index" json:"publish_at,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
+
+// Prepare settles the state before a save. An empty status keeps the previous
+// one (or publishes when there is none, or schedules when publish_at is in the
+// future), a schedule that is already due publishes right away, and the first
+// publication time is kept across updates.
+func (s *State) Prepare(previous *State, now time.Time) error {
+	if s.Status == "" {
+		switch {
+		case previous != nil:
+			s.Status = previous.Status
+			if s.PublishAt == nil {
+				s.PublishAt = previous.PublishAt
+			}
+		case s.PublishAt != nil && s.PublishAt.After(now):
+			s.Status = Scheduled
+		default:
+			s.Status = Published
+		}
+	}
+
+	switch s.Status {
+	case Draft:
+		s.PublishedAt = nil
+	case Scheduled:
+		if s.PublishAt == nil {
+			return ErrPublishAtRequired
+		}
+		if !s.PublishAt.After(now) {
+			s.Status = Published
+		}
+		s.PublishedAt = nil
+	case Published:
+	default:
+		return errors.New("status must be draft, scheduled or published")
+	}
+
+	if s.Status == Published {
+		switch {
+		case previous != nil && previous.PublishedAt != nil:
+			s.PublishedAt = previous.PublishedAt
+		case s.PublishAt != nil && !s.PublishAt.After(now):
+			s.PublishedAt = s.PublishAt
+		default:
+			s.PublishedAt = &now
+		}
+	}
+	return nil
+}
+
+// Live tells whether the item is public. A scheduled item whose time has come
+// is live even before the scheduler gets to it.
+func (s State) Live(now time.Time) bool {
+	switch s.Status {
+	case Published, "":
+		return true
+	case Scheduled:
+		return s.PublishAt != nil && !s.PublishAt.After(now)
+	}
+	return false
+}
+
+// VisibleTo tells whether viewer may see an item written by author. Authors
+// always see their own drafts.
+func (s State) VisibleTo(viewer, author uuid.UUID) bool {
+	return s.Live(time.Now()) || (viewer != uuid.Nil && viewer == author)
+}
+
+// LiveCondition is the SQL counterpart of Live for the given table or alias.
+func LiveCondition(table string) string {
+	return "(" + table + ".status = '" + Published + "' OR (" + table + ".status = '" + Scheduled +
+		"' AND " + table + ".publish_at <= NOW()))"
+}
+
+// Live is a GORM scope keeping only live rows of the given table.
+func Live(table string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(LiveCondition(table))
+	}
+}
+
+/*************
+ * SCHEDULER *
+ *************/
+
+// Job publishes the due items of one domain and clears its caches.
+type Job func(ctx context.Context, now time.Time) error
+
+const (
+	redisSchedulerLockKey = "publishing:scheduler:lock"
+	schedulerLockTTL      = 5 * time.Minute
+)
+
+// PublishDue flips every scheduled row of table whose time has come to
+// published and returns their ids.
+func PublishDue(ctx context.Context, db *gorm.DB, table string, now time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := db.WithContext(ctx).Raw(`UPDATE `+table+`
+		SET status = ?, published_at = publish_at, updated_at = ?
+		WHERE status = ? AND publish_at <= ?
+		RETURNING id`, Published, now, Scheduled, now).Scan(&ids).Error
+	return ids, err
+}
+
+// StartScheduler runs every job on each interval. A Redis lock makes sure only
+// one server instance publishes at a time.
+func StartScheduler(redis *redis.Client, interval time.Duration, jobs ...Job) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			runJobs(redis, jobs)
+			<-ticker.C
+		}
+	}()
+}
+
+func runJobs(redis *redis.Client, jobs []Job) {
+	token, err := utils.AcquireLock(redis, redisSchedulerLockKey, schedulerLockTTL)
+	if err != nil {
+		log.Printf("publish scheduler lock failed: %v", err)
+		return
+	}
+	if token == "" {
+		return // another instance is already on it
+	}
+	defer utils.ReleaseLock(redis, redisSchedulerLockKey, token)
+
+	ctx, cancel := context.WithTimeout(context.Background(), schedulerLockTTL)
+	defer cancel()
+
+	now := time.Now()
+	for _, job := range jobs {
+		if err := job(ctx, now); err != nil {
+			log.Printf("scheduled publishing failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package publishing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPrepare(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	earlier := now.Add(-72 * time.Hour)
+
+	tests := []struct {
+		name        string
+		state       State
+		previous    *State
+		status      string
+		publishAt   *time.Time
+		publishedAt *time.Time
+		hasError    bool
+	}{
+		{
+			name:        "new without status publishes",
+			state:       State{},
+			status:      Published,
+			publishedAt: &now,
+		},
+		{
+			name:      "new with a future publish_at schedules",
+			state:     State{PublishAt: &future},
+			status:    Scheduled,
+			publishAt: &future,
+		},
+		{
+			name:        "new with a past publish_at publishes then",
+			state:       State{PublishAt: &past},
+			status:      Published,
+			publishAt:   &past,
+			publishedAt: &past,
+		},
+		{
+			name:   "draft",
+			state:  State{Status: Draft, PublishedAt: &past},
+			status: Draft,
+		},
+		{
+			name:      "scheduled",
+			state:     State{Status: Scheduled, PublishAt: &future},
+			status:    Scheduled,
+			publishAt: &future,
+		},
+		{
+			name:        "due schedule publishes",
+			state:       State{Status: Scheduled, PublishAt: &past},
+			status:      Published,
+			publishAt:   &past,
+			publishedAt: &past,
+		},
+		{
+			name:     "schedule without publish_at",
+			state:    State{Status: Scheduled},
+			hasError: true,
+		},
+		{
+			name:     "unknown status",
+			state:    State{Status: "archived"},
+			hasError: true,
+		},
+		{
+			name:      "update keeps the status and schedule",
+			state:     State{},
+			previous:  &State{Status: Scheduled, PublishAt: &future},
+			status:    Scheduled,
+			publishAt: &future,
+		},
+		{
+			name:        "update keeps the first publication",
+			state:       State{Status: Published},
+			previous:    &State{Status: Published, PublishedAt: &earlier},
+			status:      Published,
+			publishedAt: &earlier,
+		},
+		{
+			name:        "republishing a draft keeps the first publication",
+			state:       State{Status: Published},
+			previous:    &State{Status: Draft, PublishedAt: &earlier},
+			status:      Published,
+			publishedAt: &earlier,
+		},
+		{
+			name:     "unpublishing clears the publication time",
+			state:    State{Status: Draft},
+			previous: &State{Status: Published, PublishedAt: &earlier},
+			status:   Draft,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.state
+			err := s.Prepare(tt.previous, now)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Prepare() = %+v, want an error", s)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Prepare() failed: %v", err)
+			}
+			if s.Status != tt.status {
+				t.Errorf("status = %q, want %q", s.Status, tt.status)
+			}
+			if !sameTime(s.PublishAt, tt.publishAt) {
+				t.Errorf("publish_at = %v, want %v", s.PublishAt, tt.publishAt)
+			}
+			if !sameTime(s.PublishedAt, tt.publishedAt) {
+				t.Errorf("published_at = %v, want %v", s.PublishedAt, tt.publishedAt)
+			}
+		})
+	}
+}
+
+func TestLive(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	tests := []struct {
+		name  string
+		state State
+		want  bool
+	}{
+		{"published", State{Status: Published}, true},
+		{"from before states", State{}, true},
+		{"draft", State{Status: Draft}, false},
+		{"scheduled", State{Status: Scheduled, PublishAt: &future}, false},
+		{"scheduled and due", State{Status: Scheduled, PublishAt: &past}, true},
+		{"scheduled without a time", State{Status: Scheduled}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.state.Live(now); got != tt.want {
+			t.Errorf("%s: Live() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestVisibleTo(t *testing.T) {
+	author, reader := uuid.New(), uuid.New()
+	draft := State{Status: Draft}
+
+	tests := []struct {
+		name   string
+		state  State
+		viewer uuid.UUID
+		want   bool
+	}{
+		{"published to anyone", State{Status: Published}, uuid.Nil, true},
+		{"draft to its author", draft, author, true},
+		{"draft to a reader", draft, reader, false},
+		{"draft to a guest", draft, uuid.Nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.state.VisibleTo(tt.viewer, author); got != tt.want {
+			t.Errorf("%s: VisibleTo() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// A guest is never the author of an item without one
+	if draft.VisibleTo(uuid.Nil, uuid.Nil) {
+		t.Error("draft without an author is visible to guests")
+	}
+}
+
+func TestLiveCondition(t *testing.T) {
+	want := "(t.status = 'published' OR (t.status = 'scheduled' AND t.publish_at <= NOW()))"
+	if got := LiveCondition("t"); got != want {
+		t.Errorf("LiveCondition(t)\n got %s\nwant %s", got, want)
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
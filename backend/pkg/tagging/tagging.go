@@ -0,0 +1,184 @@
+package tagging
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"pokemon/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tag tables are shared in shape by every domain that tags things: a tag
+// table with name and slug, and a many2many join table between the tagged
+// entity (the owner) and the tag. Table describes one such setup so the cloud,
+// related tags, merge and rename work the same way everywhere.
+type Table struct {
+	Tags     string // tag table, e.g. "tags"
+	Join     string // join table, e.g. "post_tags"
+	OwnerKey string // owner column in the join table, e.g. "post_id"
+	TagKey   string // tag column in the join table, e.g. "tag_id"
+	Owners   string // owner table, e.g. "posts"
+	Visible  string // condition on the owner table (aliased o) for it to count; may be empty
+}
+
+var (
+	ErrEmptyName = errors.New("tag name is required")
+	ErrTagExists = errors.New("a tag with this name already exists, merge them instead")
+	ErrSameTag   = errors.New("cannot merge a tag into itself")
+)
+
+const maxNameLength = 50
+
+// Count is a tag with the number of visible owners using it. Weight goes from
+// 1 to 5 on a log scale, for sizing a tag cloud.
+type Count struct {
+	ID     uuid.UUID `json:"id"`
+	Name   string    `json:"name"`
+	Slug   string    `json:"slug"`
+	Count  int64     `json:"count"`
+	Weight int       `json:"weight"`
+}
+
+// Normalize trims and collapses the whitespace of a tag name and returns the
+// slug that identifies it, so "Shiny  Hunting" and "shiny hunting" are one tag.
+func Normalize(name string) (string, string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return "", "", ErrEmptyName
+	}
+	if len(name) > maxNameLength {
+		return "", "", errors.New("tag names cannot be longer than 50 characters")
+	}
+	slug := utils.Slugify(name)
+	if slug == "" {
+		return "", "", errors.New("tag name must contain letters or digits")
+	}
+	return name, slug, nil
+}
+
+// Cloud lists tags by usage, most used first. Unused tags are left out.
+func (t Table) Cloud(db *gorm.DB, limit int) ([]Count, error) {
+	counts := []Count{}
+	err := db.Raw(`SELECT g.id, g.name, g.slug, COUNT(*) AS count
+		FROM `+t.Tags+` g
+		JOIN `+t.Join+` j ON j.`+t.TagKey+` = g.id
+		JOIN `+t.Owners+` o ON o.id = j.`+t.OwnerKey+`
+		WHERE `+t.visible()+`
+		GROUP BY g.id, g.name, g.slug
+		ORDER BY count DESC, g.name ASC
+		LIMIT ?`, limit).Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	weigh(counts)
+	return counts, nil
+}
+
+// Related lists the tags most often used together with the given one.
+func (t Table) Related(db *gorm.DB, tagID uuid.UUID, limit int) ([]Count, error) {
+	counts := []Count{}
+	err := db.Raw(`SELECT g.id, g.name, g.slug, COUNT(*) AS count
+		FROM `+t.Join+` a
+		JOIN `+t.Join+` b ON b.`+t.OwnerKey+` = a.`+t.OwnerKey+` AND b.`+t.TagKey+` <> a.`+t.TagKey+`
+		JOIN `+t.Tags+` g ON g.id = b.`+t.TagKey+`
+		JOIN `+t.Owners+` o ON o.id = a.`+t.OwnerKey+`
+		WHERE a.`+t.TagKey+` = ? AND `+t.visible()+`
+		GROUP BY g.id, g.name, g.slug
+		ORDER BY count DESC, g.name ASC
+		LIMIT ?`, tagID, limit).Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	weigh(counts)
+	return counts, nil
+}
+
+// Rename changes the name and slug of a tag. Renaming onto an existing slug
+// is refused: that's a merge.
+func (t Table) Rename(db *gorm.DB, tagID uuid.UUID, name string) error {
+	name, slug, err := Normalize(name)
+	if err != nil {
+		return err
+	}
+
+	var taken int64
+	err = db.Table(t.Tags).Where("slug = ? AND id <> ?", slug, tagID).Count(&taken).Error
+	if err != nil {
+		return err
+	}
+	if taken > 0 {
+		return ErrTagExists
+	}
+
+	res := db.Table(t.Tags).Where("id = ?", tagID).Updates(map[string]any{"name": name, "slug": slug})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Merge moves every use of one tag to another and deletes the first one.
+func (t Table) Merge(db *gorm.DB, fromID, intoID uuid.UUID) error {
+	if fromID == intoID {
+		return ErrSameTag
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var found int64
+		if err := tx.Table(t.Tags).Where("id IN ?", []uuid.UUID{fromID, intoID}).Count(&found).Error; err != nil {
+			return err
+		}
+		if found != 2 {
+			return gorm.ErrRecordNotFound
+		}
+
+		err := tx.Exec(`INSERT INTO `+t.Join+` (`+t.OwnerKey+`, `+t.TagKey+`)
+			SELECT `+t.OwnerKey+`, ? FROM `+t.Join+` WHERE `+t.TagKey+` = ?
+			ON CONFLICT DO NOTHING`, intoID, fromID).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Exec(`DELETE FROM `+t.Join+` WHERE `+t.TagKey+` = ?`, fromID).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`DELETE FROM `+t.Tags+` WHERE id = ?`, fromID).Error
+	})
+}
+
+/***********
+ * HELPERS *
+ ***********/
+
+func (t Table) visible() string {
+	if t.Visible == "" {
+		return "TRUE"
+	}
+	return t.Visible
+}
+
+func weigh(counts []Count) {
+	if len(counts) == 0 {
+		return
+	}
+	low, high := counts[0].Count, counts[0].Count
+	for _, c := range counts {
+		low = min(low, c.Count)
+		high = max(high, c.Count)
+	}
+
+	spread := math.Log(float64(high)) - math.Log(float64(low))
+	for i := range counts {
+		if spread == 0 {
+			counts[i].Weight = 3
+			continue
+		}
+		ratio := (math.Log(float64(counts[i].Count)) - math.Log(float64(low))) / spread
+		counts[i].Weight = 1 + int(math.Round(ratio*4))
+	}
+}
@@ -0,0 +1,77 @@
+package tagging
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		raw      string
+		name     string
+		slug     string
+		hasError bool
+	}{
+		{raw: "Shiny Hunting", name: "Shiny Hunting", slug: "shiny-hunting"},
+		{raw: "  shiny \t hunting\n", name: "shiny hunting", slug: "shiny-hunting"},
+		{raw: "Gen 9: Scarlet & Violet", name: "Gen 9: Scarlet & Violet", slug: "gen-9-scarlet-violet"},
+		{raw: "Pokémon Go", name: "Pokémon Go", slug: "pokemon-go"},
+		{raw: "ピカチュウ", name: "ピカチュウ", slug: "ピカチュウ"},
+		{raw: strings.Repeat("a", maxNameLength), name: strings.Repeat("a", maxNameLength), slug: strings.Repeat("a", maxNameLength)},
+		{raw: "", hasError: true},
+		{raw: "   ", hasError: true},
+		{raw: "!!!", hasError: true},
+		{raw: strings.Repeat("a", maxNameLength+1), hasError: true},
+	}
+
+	for _, tt := range tests {
+		name, slug, err := Normalize(tt.raw)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("Normalize(%q) = %q, %q, want an error", tt.raw, name, slug)
+			}
+			continue
+		}
+		if err != nil || name != tt.name || slug != tt.slug {
+			t.Errorf("Normalize(%q) = %q, %q, %v, want %q, %q", tt.raw, name, slug, err, tt.name, tt.slug)
+		}
+	}
+}
+
+func TestWeigh(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts []int64
+		want   []int
+	}{
+		{"empty", nil, nil},
+		{"one tag", []int64{7}, []int{3}},
+		{"same counts", []int64{4, 4, 4}, []int{3, 3, 3}},
+		{"log scale", []int64{1000, 100, 10, 1}, []int{5, 4, 2, 1}},
+		{"two tags", []int64{50, 2}, []int{5, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := make([]Count, len(tt.counts))
+			for i, n := range tt.counts {
+				counts[i].Count = n
+			}
+			weigh(counts)
+			for i := range counts {
+				if counts[i].Weight != tt.want[i] {
+					t.Errorf("count %d weighs %d, want %d", counts[i].Count, counts[i].Weight, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestVisible(t *testing.T) {
+	if got := (Table{}).visible(); got != "TRUE" {
+		t.Errorf("visible() = %q, want TRUE", got)
+	}
+	if got := (Table{Visible: "o.deleted_at IS NULL"}).visible(); got != "o.deleted_at IS NULL" {
+		t.Errorf("visible() = %q", got)
+	}
+}
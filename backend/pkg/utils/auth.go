@@ -23,3 +23,17 @@ func GetUserIDFromLocals(c *fiber.Ctx) (uuid.UUID, error) {
 		return uuid.Nil, errors.New("invalid user ID type in context")
 	}
 }
+
+// HasRole tells whether the authenticated user has one of the given roles
+func HasRole(c *fiber.Ctx, roles ...string) bool {
+	role, ok := c.Locals("role").(string)
+	if !ok {
+		return false
+	}
+	for _, r := range roles {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
@@ -279,9 +279,9 @@ func (j *JWTManager) ValidateAndBlacklistCheck(tokenString string, blacklist Bla
 // Legacy functions for backward compatibility
 
 // GenerateJWT generates a simple JWT token (legacy)
-func GenerateJWT(userID uuid.UUID, email string) (string, error) {
+func GenerateJWT(userID uuid.UUID, email, role string) (string, error) {
     manager := NewJWTManager("default-secret", 24*time.Hour, 7*24*time.Hour, "default-issuer")
-    token, _, err := manager.GenerateAccessToken(userID, email, "", "")
+    token, _, err := manager.GenerateAccessToken(userID, email, "", role)
     return token, err
 }
 
@@ -32,3 +32,26 @@ func SetCachedCount(redisClient *redis.Client, key string, count int64) error {
 func InvalidateCache(redisClient *redis.Client, key string) {
 	redisClient.Del(context.Background(), key)
 }
+
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// AcquireLock tries to take a short-lived lock shared by every server instance.
+// It returns the lock token on success and an empty string when someone else holds it.
+func AcquireLock(redisClient *redis.Client, key string, ttl time.Duration) (string, error) {
+	token := uuid.NewString()
+	ok, err := redisClient.SetNX(context.Background(), key, token, ttl).Result()
+	if err != nil || !ok {
+		return "", err
+	}
+	return token, nil
+}
+
+// ReleaseLock drops the lock only if it is still owned by the given token.
+func ReleaseLock(redisClient *redis.Client, key, token string) {
+	releaseLockScript.Run(context.Background(), redisClient, []string{key}, token)
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Slugify turns a title into a lowercase, dash separated, URL-friendly slug.
+// Accents are stripped from Latin letters so "Pokémon Centre" becomes
+// "pokemon-centre"; letters and digits of other scripts are kept with their
+// marks, so "ピカチュウ" stays "ピカチュウ".
+func Slugify(value string) string {
+	var b strings.Builder
+	dash, latin := false, false
+
+	for _, r := range norm.NFD.String(strings.ToLower(value)) {
+		switch {
+		case unicode.IsMark(r):
+			// An accent left over by NFD, dropped after a Latin letter
+			if !latin && !dash && b.Len() > 0 {
+				b.WriteRune(r)
+			}
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			dash, latin = false, r < unicode.MaxASCII
+		case !dash && b.Len() > 0:
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	return norm.NFC.String(strings.TrimSuffix(b.String(), "-"))
+}
@@ -0,0 +1,30 @@
+package utils
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"Pokémon Centre", "pokemon-centre"},
+		{"  Route 1 -- Viridian  ", "route-1-viridian"},
+		{"Gen 9: Scarlet & Violet!", "gen-9-scarlet-violet"},
+		{"FLABÉBÉ", "flabebe"},
+		{"Straße", "straße"},
+		{"ピカチュウ", "ピカチュウ"},
+		{"ガラル地方", "ガラル地方"},
+		{"Pokémon ピカチュウ", "pokemon-ピカチュウ"},
+		{"Ирида", "ирида"},
+		{"हिन्दी", "हिन्दी"},
+		{"\u0301Pokémon", "pokemon"},
+		{"!!!", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Slugify(tt.value); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}